@@ -5,6 +5,8 @@ import (
 	"github.com/almighty/almighty-core/area"
 	"github.com/almighty/almighty-core/comment"
 	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/label"
+	"github.com/almighty/almighty-core/savedquery"
 	"github.com/almighty/almighty-core/space"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
@@ -26,6 +28,9 @@ type Application interface {
 	Iterations() iteration.Repository
 	Users() account.UserRepository
 	Areas() area.Repository
+	Labels() label.Repository
+	Queries() savedquery.Repository
+	WorkItemRevisions() workitem.RevisionRepository
 }
 
 // A Transaction abstracts a database transaction. The repositories created for the transaction object make changes inside the the transaction
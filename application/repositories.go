@@ -3,15 +3,16 @@ package application
 import (
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/gormsupport"
 	"golang.org/x/net/context"
 )
 
 // TrackerRepository encapsulate storage & retrieval of tracker configuration
 type TrackerRepository interface {
 	Load(ctx context.Context, ID string) (*app.Tracker, error)
-	Save(ctx context.Context, t app.Tracker) (*app.Tracker, error)
+	Save(ctx context.Context, t app.Tracker, webhookSecret *string, pushToken *string) (*app.Tracker, error)
 	Delete(ctx context.Context, ID string) error
-	Create(ctx context.Context, url string, typeID string) (*app.Tracker, error)
+	Create(ctx context.Context, url string, typeID string, webhookSecret string, pushToken string, fieldMappings *app.FieldMappings) (*app.Tracker, error)
 	List(ctx context.Context, criteria criteria.Expression, start *int, length *int) ([]*app.Tracker, error)
 }
 
@@ -22,9 +23,10 @@ type TrackerQueryRepository interface {
 	Load(ctx context.Context, ID string) (*app.TrackerQuery, error)
 	Delete(ctx context.Context, ID string) error
 	List(ctx context.Context) ([]*app.TrackerQuery, error)
+	ListRuns(ctx context.Context, ID string) ([]*app.TrackerQueryRun, error)
 }
 
 // SearchRepository encapsulates searching of woritems,users,etc
 type SearchRepository interface {
-	SearchFullText(ctx context.Context, searchStr string, start *int, length *int) ([]*app.WorkItem, uint64, error)
+	SearchFullText(ctx context.Context, searchStr string, start *int, length *int, cursor *gormsupport.Cursor) ([]*app.WorkItem, uint64, *gormsupport.PageCursors, error)
 }
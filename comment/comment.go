@@ -29,7 +29,7 @@ type Comment struct {
 type Repository interface {
 	Create(ctx context.Context, u *Comment) error
 	Save(ctx context.Context, comment *Comment) (*Comment, error)
-	List(ctx context.Context, parent string, start *int, limit *int) ([]*Comment, uint64, error)
+	List(ctx context.Context, parent string, start *int, limit *int, cursor *gormsupport.Cursor) ([]*Comment, uint64, *gormsupport.PageCursors, error)
 	Load(ctx context.Context, id uuid.UUID) (*Comment, error)
 	Count(ctx context.Context, parent string) (int, error)
 }
@@ -89,74 +89,110 @@ func (m *GormCommentRepository) Save(ctx context.Context, comment *Comment) (*Co
 	return comment, nil
 }
 
-// List all comments related to a single item
-func (m *GormCommentRepository) List(ctx context.Context, parent string, start *int, limit *int) ([]*Comment, uint64, error) {
+// commentOrder defines the order in which comments are listed, newest first
+var commentOrder = []gormsupport.SortKey{
+	{Expression: "created_at", Descending: true},
+	{Expression: "id"},
+}
+
+// List all comments related to a single item. If a cursor is given, start is ignored
+// and the comments after (or before) the cursor are returned instead.
+func (m *GormCommentRepository) List(ctx context.Context, parent string, start *int, limit *int, cursor *gormsupport.Cursor) ([]*Comment, uint64, *gormsupport.PageCursors, error) {
 	defer goa.MeasureSince([]string{"goa", "db", "comment", "query"}, time.Now())
 
 	db := m.db.Model(&Comment{}).Where("parent_id = ?", parent)
 	orgDB := db
-	if start != nil {
-		if *start < 0 {
-			return nil, 0, errors.NewBadParameterError("start", *start)
+	if cursor != nil {
+		var err error
+		db, err = gormsupport.ApplyCursor(db, commentOrder, *cursor)
+		if err != nil {
+			return nil, 0, nil, errs.WithStack(err)
+		}
+	} else {
+		db = db.Order(gormsupport.OrderClause(commentOrder, false))
+		if start != nil {
+			if *start < 0 {
+				return nil, 0, nil, errors.NewBadParameterError("start", *start)
+			}
+			db = db.Offset(*start)
 		}
-		db = db.Offset(*start)
 	}
 	if limit != nil {
 		if *limit <= 0 {
-			return nil, 0, errors.NewBadParameterError("limit", *limit)
+			return nil, 0, nil, errors.NewBadParameterError("limit", *limit)
 		}
 		db = db.Limit(*limit)
 	}
-	db = db.Select("count(*) over () as cnt2 , *").Order("created_at desc")
+	db = db.Select("count(*) over () as cnt2 , *, " + gormsupport.SelectSortKeys(commentOrder))
 
 	rows, err := db.Rows()
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, nil, err
 	}
 	defer rows.Close()
 
 	result := []*Comment{}
 	columns, err := rows.Columns()
 	if err != nil {
-		return nil, 0, errors.NewInternalError(err.Error())
+		return nil, 0, nil, errors.NewInternalError(err.Error())
 	}
 
-	// need to set up a result for Scan() in order to extract total count.
+	// need to read the total count and the sort keys of the first and last row to compute the cursors
 	var count uint64
-	var ignore interface{}
-	columnValues := make([]interface{}, len(columns))
-
-	for index := range columnValues {
-		columnValues[index] = &ignore
-	}
-	columnValues[0] = &count
+	var firstKeys, lastKeys []*string
 	first := true
 
 	for rows.Next() {
 		value := &Comment{}
 		db.ScanRows(rows, value)
+		var countTarget *uint64
+		if first {
+			countTarget = &count
+		}
+		lastKeys, err = gormsupport.ScanSortKeys(rows, columns, countTarget)
+		if err != nil {
+			return nil, 0, nil, errs.WithStack(err)
+		}
 		if first {
 			first = false
-			if err = rows.Scan(columnValues...); err != nil {
-				return nil, 0, errors.NewInternalError(err.Error())
-			}
+			firstKeys = lastKeys
 		}
 		result = append(result, value)
 
 	}
-	if first {
+	// when paging with a cursor, the window count only covers the rows beyond the cursor
+	more := count > uint64(len(result))
+	if first || cursor != nil {
 		// means 0 rows were returned from the first query (maybe becaus of offset outside of total count),
-		// need to do a count(*) to find out total
+		// or the count is restricted by the cursor, need to do a count(*) to find out total
 		orgDB := orgDB.Select("count(*)")
 		rows2, err := orgDB.Rows()
-		defer rows2.Close()
 		if err != nil {
-			return nil, 0, err
+			return nil, 0, nil, err
 		}
+		defer rows2.Close()
 		rows2.Next() // count(*) will always return a row
 		rows2.Scan(&count)
 	}
-	return result, count, nil
+	var hasPrev, hasNext bool
+	if cursor != nil {
+		hasPrev, hasNext = cursor.Neighbours(more)
+		if cursor.Before {
+			// the rows were fetched in reverse order
+			for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+				result[i], result[j] = result[j], result[i]
+			}
+			firstKeys, lastKeys = lastKeys, firstKeys
+		}
+	} else {
+		offset := 0
+		if start != nil {
+			offset = *start
+		}
+		hasPrev = offset > 0
+		hasNext = uint64(offset+len(result)) < count
+	}
+	return result, count, gormsupport.NewPageCursors(firstKeys, lastKeys, hasPrev, hasNext), nil
 }
 
 // Count all comments related to a single item
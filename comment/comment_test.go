@@ -96,7 +96,7 @@ func (test *TestCommentRepository) TestSaveCommentWithMarkup() {
 	repo.Save(context.Background(), comment)
 	offset := 0
 	limit := 1
-	comments, _, err := repo.List(context.Background(), comment.ParentID, &offset, &limit)
+	comments, _, _, err := repo.List(context.Background(), comment.ParentID, &offset, &limit, nil)
 	// then
 	require.Nil(test.T(), err)
 	require.Equal(test.T(), 1, len(comments), "List returned more then expected based on parentID")
@@ -116,7 +116,7 @@ func (test *TestCommentRepository) TestSaveCommentWithoutMarkup() {
 	repo.Save(context.Background(), comment)
 	offset := 0
 	limit := 1
-	comments, _, err := repo.List(context.Background(), comment.ParentID, &offset, &limit)
+	comments, _, _, err := repo.List(context.Background(), comment.ParentID, &offset, &limit, nil)
 	// then
 	require.Nil(test.T(), err)
 	require.Equal(test.T(), 1, len(comments), "List returned more then expected based on parentID")
@@ -149,13 +149,56 @@ func (test *TestCommentRepository) TestListComments() {
 	// when
 	offset := 0
 	limit := 1
-	comments, _, err := repo.List(context.Background(), comment1.ParentID, &offset, &limit)
+	comments, _, _, err := repo.List(context.Background(), comment1.ParentID, &offset, &limit, nil)
 	// then
 	require.Nil(test.T(), err)
 	require.Equal(test.T(), 1, len(comments))
 	assert.Equal(test.T(), comment1.Body, comments[0].Body)
 }
 
+func (test *TestCommentRepository) TestListCommentsWithCursor() {
+	// given
+	repo := comment.NewCommentRepository(test.DB)
+	comments := []*comment.Comment{
+		newComment("C", "Test 1", rendering.SystemMarkupMarkdown),
+		newComment("C", "Test 2", rendering.SystemMarkupMarkdown),
+		newComment("C", "Test 3", rendering.SystemMarkupMarkdown),
+	}
+	test.createComments(comments)
+	limit := 2
+	// when
+	page1, count, cursors, err := repo.List(context.Background(), "C", nil, &limit, &gormsupport.Cursor{})
+	// then
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), uint64(3), count)
+	require.Equal(test.T(), 2, len(page1))
+	assert.Nil(test.T(), cursors.Prev)
+	require.NotNil(test.T(), cursors.Next)
+	// when
+	next, err := gormsupport.DecodeCursor(*cursors.Next)
+	require.Nil(test.T(), err)
+	page2, count, cursors, err := repo.List(context.Background(), "C", nil, &limit, next)
+	// then
+	require.Nil(test.T(), err)
+	assert.Equal(test.T(), uint64(3), count)
+	require.Equal(test.T(), 1, len(page2))
+	assert.Nil(test.T(), cursors.Next)
+	require.NotNil(test.T(), cursors.Prev)
+	for _, c := range page1 {
+		assert.NotEqual(test.T(), c.ID, page2[0].ID)
+	}
+	// when
+	prev, err := gormsupport.DecodeCursor(*cursors.Prev)
+	require.Nil(test.T(), err)
+	page3, _, cursors, err := repo.List(context.Background(), "C", nil, &limit, prev)
+	// then
+	require.Nil(test.T(), err)
+	require.Equal(test.T(), 2, len(page3))
+	assert.Equal(test.T(), page1[0].ID, page3[0].ID)
+	assert.Equal(test.T(), page1[1].ID, page3[1].ID)
+	assert.Nil(test.T(), cursors.Prev)
+}
+
 func (test *TestCommentRepository) TestListCommentsWrongOffset() {
 	// given
 	repo := comment.NewCommentRepository(test.DB)
@@ -166,7 +209,7 @@ func (test *TestCommentRepository) TestListCommentsWrongOffset() {
 	// when
 	offset := -1
 	limit := 1
-	_, _, err := repo.List(context.Background(), comment1.ParentID, &offset, &limit)
+	_, _, _, err := repo.List(context.Background(), comment1.ParentID, &offset, &limit, nil)
 	// then
 	assert.NotNil(test.T(), err)
 }
@@ -181,7 +224,7 @@ func (test *TestCommentRepository) TestListCommentsWrongLimit() {
 	// when
 	offset := 0
 	limit := -1
-	_, _, err := repo.List(context.Background(), comment1.ParentID, &offset, &limit)
+	_, _, _, err := repo.List(context.Background(), comment1.ParentID, &offset, &limit, nil)
 	// then
 	assert.NotNil(test.T(), err)
 }
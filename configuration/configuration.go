@@ -68,6 +68,7 @@ const (
 	varPostgresConnectionMaxRetries = "postgres.connection.maxretries"
 	varPostgresConnectionRetrySleep = "postgres.connection.retrysleep"
 	varPopulateCommonTypes          = "populate.commontypes"
+	varProcessTemplatesDir          = "process.templates.dir"
 	varHTTPAddress                  = "http.address"
 	varDeveloperModeEnabled         = "developer.mode.enabled"
 	varGithubAuthToken              = "github.auth.token"
@@ -112,6 +113,8 @@ func setConfigDefaults() {
 
 	viper.SetDefault(varPopulateCommonTypes, true)
 
+	viper.SetDefault(varProcessTemplatesDir, "")
+
 	// Auth-related defaults
 	viper.SetDefault(varTokenPublicKey, defaultTokenPublicKey)
 	viper.SetDefault(varTokenPrivateKey, defaultTokenPrivateKey)
@@ -185,6 +188,13 @@ func GetPopulateCommonTypes() bool {
 	return viper.GetBool(varPopulateCommonTypes)
 }
 
+// GetProcessTemplatesDir returns the directory (as set via config file or environment variable)
+// from which process templates are imported in addition to the built-in ones.
+// No templates are imported if it is empty.
+func GetProcessTemplatesDir() string {
+	return viper.GetString(varProcessTemplatesDir)
+}
+
 // GetHTTPAddress returns the HTTP address (as set via default, config file, or environment variable)
 // that the alm server binds to (e.g. "0.0.0.0:8080")
 func GetHTTPAddress() string {
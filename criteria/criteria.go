@@ -37,12 +37,27 @@ type BinaryExpression interface {
 	Right() Expression
 }
 
+// UnaryExpression represents expressions with a single child
+type UnaryExpression interface {
+	Expression
+	Operand() Expression
+}
+
 // ExpressionVisitor is an implementation of the visitor pattern for expressions
 type ExpressionVisitor interface {
 	Field(t *FieldExpression) interface{}
 	And(a *AndExpression) interface{}
 	Or(a *OrExpression) interface{}
+	Not(n *NotExpression) interface{}
 	Equals(e *EqualsExpression) interface{}
+	NotEquals(e *NotEqualsExpression) interface{}
+	LessThan(e *LessThanExpression) interface{}
+	LessOrEqual(e *LessOrEqualExpression) interface{}
+	GreaterThan(e *GreaterThanExpression) interface{}
+	GreaterOrEqual(e *GreaterOrEqualExpression) interface{}
+	In(e *InExpression) interface{}
+	IsNull(e *IsNullExpression) interface{}
+	Substring(e *SubstringExpression) interface{}
 	Parameter(v *ParameterExpression) interface{}
 	Literal(c *LiteralExpression) interface{}
 }
@@ -150,6 +165,23 @@ func reparent(parent BinaryExpression) Expression {
 	return parent
 }
 
+// unaryExpression is an "abstract" type for unary expressions.
+type unaryExpression struct {
+	expression
+	operand Expression
+}
+
+// Operand implements UnaryExpression
+func (exp *unaryExpression) Operand() Expression {
+	return exp.operand
+}
+
+// make sure the child has the correct parent
+func reparentUnary(parent UnaryExpression) Expression {
+	parent.Operand().setParent(parent)
+	return parent
+}
+
 // And
 
 // AndExpression represents the conjunction operation of two terms
@@ -200,3 +232,159 @@ func (t *EqualsExpression) Accept(visitor ExpressionVisitor) interface{} {
 func Equals(left Expression, right Expression) Expression {
 	return reparent(&EqualsExpression{binaryExpression{expression{}, left, right}})
 }
+
+// Not
+
+// NotExpression represents the negation of a term
+type NotExpression struct {
+	unaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *NotExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.Not(t)
+}
+
+// Not constructs a NotExpression
+func Not(operand Expression) Expression {
+	return reparentUnary(&NotExpression{unaryExpression{expression{}, operand}})
+}
+
+// !=
+
+// NotEqualsExpression represents the inequality operator
+type NotEqualsExpression struct {
+	binaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *NotEqualsExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.NotEquals(t)
+}
+
+// NotEquals constructs a NotEqualsExpression
+func NotEquals(left Expression, right Expression) Expression {
+	return reparent(&NotEqualsExpression{binaryExpression{expression{}, left, right}})
+}
+
+// <
+
+// LessThanExpression represents the "less than" operator
+type LessThanExpression struct {
+	binaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *LessThanExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.LessThan(t)
+}
+
+// LessThan constructs a LessThanExpression
+func LessThan(left Expression, right Expression) Expression {
+	return reparent(&LessThanExpression{binaryExpression{expression{}, left, right}})
+}
+
+// <=
+
+// LessOrEqualExpression represents the "less than or equal" operator
+type LessOrEqualExpression struct {
+	binaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *LessOrEqualExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.LessOrEqual(t)
+}
+
+// LessOrEqual constructs a LessOrEqualExpression
+func LessOrEqual(left Expression, right Expression) Expression {
+	return reparent(&LessOrEqualExpression{binaryExpression{expression{}, left, right}})
+}
+
+// >
+
+// GreaterThanExpression represents the "greater than" operator
+type GreaterThanExpression struct {
+	binaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *GreaterThanExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.GreaterThan(t)
+}
+
+// GreaterThan constructs a GreaterThanExpression
+func GreaterThan(left Expression, right Expression) Expression {
+	return reparent(&GreaterThanExpression{binaryExpression{expression{}, left, right}})
+}
+
+// >=
+
+// GreaterOrEqualExpression represents the "greater than or equal" operator
+type GreaterOrEqualExpression struct {
+	binaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *GreaterOrEqualExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.GreaterOrEqual(t)
+}
+
+// GreaterOrEqual constructs a GreaterOrEqualExpression
+func GreaterOrEqual(left Expression, right Expression) Expression {
+	return reparent(&GreaterOrEqualExpression{binaryExpression{expression{}, left, right}})
+}
+
+// in
+
+// InExpression represents a membership test. The right hand side is expected
+// to evaluate to a list of values, the expression is true if the left hand side
+// is equal to any of them
+type InExpression struct {
+	binaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *InExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.In(t)
+}
+
+// In constructs an InExpression
+func In(left Expression, right Expression) Expression {
+	return reparent(&InExpression{binaryExpression{expression{}, left, right}})
+}
+
+// is null
+
+// IsNullExpression tests whether its operand has no value
+type IsNullExpression struct {
+	unaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *IsNullExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.IsNull(t)
+}
+
+// IsNull constructs an IsNullExpression
+func IsNull(operand Expression) Expression {
+	return reparentUnary(&IsNullExpression{unaryExpression{expression{}, operand}})
+}
+
+// substring
+
+// SubstringExpression is true if the right hand side is contained in the left hand side.
+// The comparison is case insensitive
+type SubstringExpression struct {
+	binaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *SubstringExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.Substring(t)
+}
+
+// Substring constructs a SubstringExpression
+func Substring(left Expression, right Expression) Expression {
+	return reparent(&SubstringExpression{binaryExpression{expression{}, left, right}})
+}
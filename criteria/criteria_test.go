@@ -14,3 +14,16 @@ func TestGetParent(t *testing.T) {
 		t.Errorf("parent should be %v, but is %v", expr, l.Parent())
 	}
 }
+
+func TestGetParentUnary(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	f := Field("a")
+	isNull := IsNull(f)
+	not := Not(isNull)
+	if f.Parent() != isNull {
+		t.Errorf("parent should be %v, but is %v", isNull, f.Parent())
+	}
+	if isNull.Parent() != not {
+		t.Errorf("parent should be %v, but is %v", not, isNull.Parent())
+	}
+}
@@ -0,0 +1,18 @@
+package criteria
+
+// OrderBy is a single sort key of a query: the field to sort by and the direction.
+// A list of OrderBy values sorts by the first key, then by the second, and so on.
+type OrderBy struct {
+	FieldName  string
+	Descending bool
+}
+
+// Ascending constructs an OrderBy sorting the given field in ascending order
+func Ascending(fieldName string) OrderBy {
+	return OrderBy{FieldName: fieldName}
+}
+
+// Descending constructs an OrderBy sorting the given field in descending order
+func Descending(fieldName string) OrderBy {
+	return OrderBy{FieldName: fieldName, Descending: true}
+}
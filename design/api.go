@@ -13,6 +13,10 @@ var _ = a.API("alm", func() {
 	a.Scheme("http")
 	a.BasePath("/api")
 	a.Consumes("application/json")
+	a.Consumes("application/json-patch+json", func() {
+		a.Package("github.com/goadesign/goa")
+		a.Function("NewJSONDecoder")
+	})
 	a.Produces("application/json")
 
 	a.License(func() {
@@ -155,6 +155,8 @@ var _ = a.Resource("work-item-comments", func() {
 		a.Params(func() {
 			a.Param("page[offset]", d.String, `Paging start position is a string pointing to
 			the beginning of pagination.  The value starts from 0 onwards.`)
+			a.Param("page[cursor]", d.String, `Paging position is an opaque string taken from the
+			paging links and takes precedence over page[offset]. An empty value starts at the beginning.`)
 			a.Param("page[limit]", d.Integer, `Paging size is the number of items in a page`)
 		})
 		a.Response(d.OK, func() {
@@ -0,0 +1,103 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var label = a.Type("Label", func() {
+	a.Description(`JSONAPI store for the data of a label. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("labels")
+	})
+	a.Attribute("id", d.UUID, "ID of label", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", labelAttributes)
+	a.Attribute("relationships", labelRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var labelAttributes = a.Type("LabelAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a label. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("name", d.String, "The label name, unique within the space", func() {
+		a.Example("backend")
+	})
+	a.Attribute("color", d.String, "The color the label is displayed with", func() {
+		a.Example("#e0b4b4")
+		a.Pattern("^#[0-9a-fA-F]{6}$")
+	})
+	a.Attribute("created-at", d.DateTime, "When the label was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("version", d.Integer, "Version for optimistic concurrency control (optional during creating)", func() {
+		a.Example(23)
+	})
+})
+
+var labelRelationships = a.Type("LabelRelations", func() {
+	a.Attribute("space", relationGeneric, "This defines the owning space")
+})
+
+var labelList = JSONList(
+	"label", "Holds the list of labels",
+	label,
+	pagingLinks,
+	meta)
+
+var labelSingle = JSONSingle(
+	"label", "Holds a single label",
+	label,
+	nil)
+
+var _ = a.Resource("label", func() {
+	a.BasePath("/labels")
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Description("Retrieve label with given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(labelSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("space-labels", func() {
+	a.Parent("space")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("labels"),
+		)
+		a.Description("List the labels of the space.")
+		a.Response(d.OK, func() {
+			a.Media(labelList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("labels"),
+		)
+		a.Description("Create a label that can be put on the work items of the space using fields of kind label.")
+		a.Payload(labelSingle)
+		a.Response(d.Created, "/labels/.*", func() {
+			a.Media(labelSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -44,7 +44,9 @@ var workItem = a.MediaType("application/vnd.workitem+json", func() {
 	a.Attribute("id", d.String, "unique id per installation")
 	a.Attribute("version", d.Integer, "Version for optimistic concurrency control")
 	a.Attribute("type", d.String, "Name of the type of this work item")
+	a.Attribute("space", d.UUID, "ID of the space of this work item, its type is a system type or a type of this space")
 	a.Attribute("fields", a.HashOf(d.String, d.Any), "The field values, according to the field type")
+	a.Attribute("rank", d.String, "Position of the work item in the backlog, work items are ordered by comparing their ranks")
 
 	a.Required("id")
 	a.Required("version")
@@ -55,7 +57,9 @@ var workItem = a.MediaType("application/vnd.workitem+json", func() {
 		a.Attribute("id")
 		a.Attribute("version")
 		a.Attribute("type")
+		a.Attribute("space")
 		a.Attribute("fields")
+		a.Attribute("rank")
 	})
 })
 
@@ -76,7 +80,17 @@ var meta = a.Type("workItemListResponseMeta", func() {
 var fieldDefinition = a.Type("fieldDefinition", func() {
 	a.Description("A fieldDescription aggregates a fieldType and additional field metadata")
 	a.Attribute("required", d.Boolean)
+	a.Attribute("deprecated", d.Boolean, "Deprecated fields are kept for existing work items but should not be used anymore")
 	a.Attribute("type", fieldType)
+	a.Attribute("default", d.Any, "The value of the field of new work items that are created without one")
+	a.Attribute("defaultFrom", d.String, `The context the default of the field of new work items is drawn from: the creator (currentUser),
+the running iteration of the space of the type (currentIteration) or the creation time (now)`, func() {
+		a.Enum("currentUser", "currentIteration", "now")
+	})
+	a.Attribute("computed", d.String, `An expression the value of the field is computed with whenever a work item is saved, it
+aggregates a field over the children of the work item, i.e. the targets of its tree links`, func() {
+		a.Example("sum(children.storypoints)")
+	})
 
 	a.Required("required")
 	a.Required("type")
@@ -97,6 +111,30 @@ var fieldType = a.Type("fieldType", func() {
 	a.Required("kind")
 })
 
+// workflow restricts the changes of the system.state field of work items
+var workflow = a.Type("workflow", func() {
+	a.Description("A workflow defines the allowed changes of the system.state field of the work items of a type")
+	a.Attribute("transitions", a.ArrayOf(workflowTransition), "The allowed changes of the state, any change is allowed if there are none")
+	a.Attribute("requiredFields", a.HashOf(d.String, a.ArrayOf(d.String)), "The fields that must have a value when a work item enters the state", func() {
+		a.Example(map[string]interface{}{"resolved": []string{"system.resolution"}})
+	})
+})
+
+// workflowTransition allows work items to change from one state to another
+var workflowTransition = a.Type("workflowTransition", func() {
+	a.Attribute("from", d.String, "The state before the transition", func() {
+		a.Example("in progress")
+	})
+	a.Attribute("to", d.String, "The state after the transition", func() {
+		a.Example("resolved")
+	})
+	a.Attribute("guard", d.String, "A filter expression the work item must match after the transition", func() {
+		a.Example(`{"system.assignees":"some-user"}`)
+	})
+
+	a.Required("from", "to")
+})
+
 // workItemType is the media type representing a work item type.
 var workItemType = a.MediaType("application/vnd.workitemtype+json", func() {
 	a.TypeName("WorkItemType")
@@ -104,6 +142,8 @@ var workItemType = a.MediaType("application/vnd.workitemtype+json", func() {
 	a.Attribute("version", d.Integer, "Version for optimistic concurrency control")
 	a.Attribute("name", d.String, "User Readable Name of this item type")
 	a.Attribute("fields", a.HashOf(d.String, fieldDefinition), "Definitions of fields in this work item type")
+	a.Attribute("space", d.UUID, "ID of the space this type belongs to, system types available in all spaces have none")
+	a.Attribute("workflow", workflow, "Restrictions on the changes of the state, none if every change is allowed")
 
 	a.Required("version")
 	a.Required("name")
@@ -113,6 +153,8 @@ var workItemType = a.MediaType("application/vnd.workitemtype+json", func() {
 		a.Attribute("version")
 		a.Attribute("name")
 		a.Attribute("fields")
+		a.Attribute("space")
+		a.Attribute("workflow")
 	})
 	a.View("link", func() {
 		a.Attribute("name")
@@ -127,6 +169,7 @@ var Tracker = a.MediaType("application/vnd.tracker+json", func() {
 	a.Attribute("id", d.String, "unique id per tracker")
 	a.Attribute("url", d.String, "URL of the tracker")
 	a.Attribute("type", d.String, "Type of the tracker")
+	a.Attribute("fieldMappings", FieldMappings, "How the remote items are imported, missing if the default mapping of the tracker type is used")
 
 	a.Required("id")
 	a.Required("url")
@@ -136,6 +179,7 @@ var Tracker = a.MediaType("application/vnd.tracker+json", func() {
 		a.Attribute("id")
 		a.Attribute("url")
 		a.Attribute("type")
+		a.Attribute("fieldMappings")
 	})
 })
 
@@ -147,6 +191,9 @@ var TrackerQuery = a.MediaType("application/vnd.trackerquery+json", func() {
 	a.Attribute("query", d.String, "Search query")
 	a.Attribute("schedule", d.String, "Schedule for fetch and import")
 	a.Attribute("trackerID", d.String, "Tracker ID")
+	a.Attribute("lastRunAt", d.DateTime, "Start of the last successful run of the query")
+	a.Attribute("lastUpdatedAt", d.DateTime, "Latest update time of the imported items, the next run only fetches items updated since then")
+	a.Attribute("etag", d.String, "ETag of the last fetch, for conditional requests")
 
 	a.Required("id")
 	a.Required("query")
@@ -158,5 +205,50 @@ var TrackerQuery = a.MediaType("application/vnd.trackerquery+json", func() {
 		a.Attribute("query")
 		a.Attribute("schedule")
 		a.Attribute("trackerID")
+		a.Attribute("lastRunAt")
+		a.Attribute("lastUpdatedAt")
+		a.Attribute("etag")
+	})
+})
+
+// TrackerQueryRun represents a run of a tracker query
+var TrackerQueryRun = a.MediaType("application/vnd.trackerqueryrun+json", func() {
+	a.TypeName("TrackerQueryRun")
+	a.Description("Scheduled run of a tracker query with its outcome")
+	a.Attribute("id", d.String, "unique id per installation")
+	a.Attribute("trackerQueryID", d.String, "Tracker query ID")
+	a.Attribute("startedAt", d.DateTime, "Start of the run")
+	a.Attribute("finishedAt", d.DateTime, "End of the run, missing while the run is in progress")
+	a.Attribute("fetched", d.Integer, "Number of items fetched from the remote tracker")
+	a.Attribute("created", d.Integer, "Number of work items created from the fetched items")
+	a.Attribute("updated", d.Integer, "Number of work items updated from the fetched items")
+	a.Attribute("failed", d.Integer, "Number of fetched items that could not be imported")
+	a.Attribute("errors", a.ArrayOf(d.String), "Errors of the fetch and of the failed imports")
+	a.Attribute("rateLimitWaits", d.Integer, "Number of times the fetch waited for the rate limit of the remote tracker")
+	a.Attribute("rateLimitWaitSeconds", d.Integer, "Total time the fetch waited for rate limits")
+
+	a.Required("id")
+	a.Required("trackerQueryID")
+	a.Required("startedAt")
+	a.Required("fetched")
+	a.Required("created")
+	a.Required("updated")
+	a.Required("failed")
+	a.Required("errors")
+	a.Required("rateLimitWaits")
+	a.Required("rateLimitWaitSeconds")
+
+	a.View("default", func() {
+		a.Attribute("id")
+		a.Attribute("trackerQueryID")
+		a.Attribute("startedAt")
+		a.Attribute("finishedAt")
+		a.Attribute("fetched")
+		a.Attribute("created")
+		a.Attribute("updated")
+		a.Attribute("failed")
+		a.Attribute("errors")
+		a.Attribute("rateLimitWaits")
+		a.Attribute("rateLimitWaitSeconds")
 	})
 })
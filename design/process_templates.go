@@ -0,0 +1,58 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var processTemplate = a.Type("ProcessTemplate", func() {
+	a.Description(`JSONAPI store for the data of a process template. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("processtemplates")
+	})
+	a.Attribute("id", d.String, "Name of the process template", func() {
+		a.Example("Scrum")
+	})
+	a.Attribute("attributes", processTemplateAttributes)
+	a.Required("type", "id", "attributes")
+})
+
+var processTemplateAttributes = a.Type("ProcessTemplateAttributes", func() {
+	a.Attribute("name", d.String, "Name of the process template, used when creating a space", func() {
+		a.Example("Scrum")
+	})
+	a.Attribute("description", d.String, "Description of the process template", func() {
+		a.Example("Product backlog items are broken down into tasks that are worked on in sprints")
+	})
+	a.Attribute("states", a.ArrayOf(d.String), "The states of the work items of the template", func() {
+		a.Example([]string{"new", "approved", "committed", "done", "removed"})
+	})
+	a.Attribute("work-item-types", a.ArrayOf(d.String), "Names of the work item types of the template", func() {
+		a.Example([]string{"productbacklogitem", "sprinttask", "impediment"})
+	})
+	a.Attribute("link-types", a.ArrayOf(d.String), "Names of the work item link types of the template", func() {
+		a.Example([]string{"Backlog item task", "Impediment"})
+	})
+	a.Required("name", "states", "work-item-types", "link-types")
+})
+
+var processTemplateList = JSONList(
+	"ProcessTemplate", "Holds the list of process templates",
+	processTemplate,
+	nil,
+	nil)
+
+var _ = a.Resource("processtemplate", func() {
+	a.BasePath("/processtemplates")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the process templates that can be applied when a space is created.")
+		a.Response(d.OK, func() {
+			a.Media(processTemplateList)
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
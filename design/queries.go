@@ -0,0 +1,173 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var savedQuery = a.Type("Query", func() {
+	a.Description(`JSONAPI store for the data of a saved work item query.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("queries")
+	})
+	a.Attribute("id", d.UUID, "ID of the query", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", savedQueryAttributes)
+	a.Attribute("relationships", savedQueryRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var savedQueryAttributes = a.Type("QueryAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a saved query. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("title", d.String, "The name of the query", func() {
+		a.Example("My open bugs")
+	})
+	a.Attribute("filter", d.String, "A query language expression selecting work items, see the filter parameter of the work item list", func() {
+		a.Example("Type == 'bug' and system.state != 'closed'")
+	})
+	a.Attribute("shared", d.Boolean, "Whether the query is visible to everybody or to its creator only", func() {
+		a.Example(false)
+	})
+	a.Attribute("version", d.Integer, "Version for optimistic concurrency control (optional during creating)", func() {
+		a.Example(23)
+	})
+	a.Attribute("created-at", d.DateTime, "When the query was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("updated-at", d.DateTime, "When the query was updated", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+})
+
+var savedQueryRelationships = a.Type("QueryRelations", func() {
+	a.Attribute("space", relationGeneric, "This defines the owning space")
+	a.Attribute("creator", relationGeneric, "This defines the identity that created the query")
+	a.Attribute("workitems", relationGeneric, "This defines the work items found by the query")
+})
+
+var savedQueryList = JSONList(
+	"Query", "Holds the list of saved queries",
+	savedQuery,
+	pagingLinks,
+	meta)
+
+var savedQuerySingle = JSONSingle(
+	"Query", "Holds a single saved query",
+	savedQuery,
+	nil)
+
+var _ = a.Resource("query", func() {
+	a.BasePath("/queries")
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:id"),
+		)
+		a.Description("Retrieve the saved query with given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(savedQuerySingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:id"),
+		)
+		a.Description("Update the saved query with given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Payload(savedQuerySingle)
+		a.Response(d.OK, func() {
+			a.Media(savedQuerySingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:id"),
+		)
+		a.Description("Delete the saved query with given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("execute", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:id/workitems"),
+		)
+		a.Description("List the work items selected by the saved query with given id.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+			a.Param("page[offset]", d.String, "Paging start position")
+			a.Param("page[cursor]", d.String, "Opaque paging position taken from the paging links, takes precedence over page[offset]. An empty value starts at the beginning")
+			a.Param("page[limit]", d.Integer, "Paging size")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("space-queries", func() {
+	a.Parent("space")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("queries"),
+		)
+		a.Description("List the saved queries of the space that are shared or created by the current user.")
+		a.Response(d.OK, func() {
+			a.Media(savedQueryList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("queries"),
+		)
+		a.Description("Save a query in the space.")
+		a.Payload(savedQuerySingle)
+		a.Response(d.Created, "/queries/.*", func() {
+			a.Media(savedQuerySingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -17,6 +17,7 @@ var _ = a.Resource("workitemtype", func() {
 		a.Description("Retrieve work item type with given name.")
 		a.Params(func() {
 			a.Param("name", d.String, "name")
+			a.Param("space", d.UUID, "The ID of the space of the type, only system types are found without it")
 		})
 		a.Response(d.OK, func() {
 			a.Media(workItemType)
@@ -40,6 +41,27 @@ var _ = a.Resource("workitemtype", func() {
 		a.Response(d.Unauthorized, JSONAPIErrors)
 	})
 
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:name"),
+		)
+		a.Description(`Change the fields of the work item type with given name and of all types extending it.
+The fields of existing work items are migrated accordingly.`)
+		a.Params(func() {
+			a.Param("name", d.String, "name")
+			a.Param("space", d.UUID, "The ID of the space of the type, system types are changed without it")
+		})
+		a.Payload(UpdateWorkItemTypePayload)
+		a.Response(d.OK, func() {
+			a.Media(workItemType)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
 	a.Action("list", func() {
 		a.Routing(
 			a.GET(""),
@@ -47,6 +69,7 @@ var _ = a.Resource("workitemtype", func() {
 		a.Description("List work item types.")
 		a.Params(func() {
 			a.Param("page", d.String, "Paging in the format <start>,<limit>")
+			a.Param("space", d.UUID, "Only list the system types and the types of the space with this ID")
 		})
 		a.Response(d.OK, func() {
 			a.Media(a.CollectionOf(workItemType))
@@ -61,6 +84,7 @@ var _ = a.Resource("workitemtype", func() {
 		)
 		a.Params(func() {
 			a.Param("name", d.String, "name")
+			a.Param("space", d.UUID, "The ID of the space of the type, only system types are found without it")
 		})
 		a.Description(`Retrieve work item link types where the
 given work item type can be used in the source of the link.`)
@@ -77,6 +101,7 @@ given work item type can be used in the source of the link.`)
 		)
 		a.Params(func() {
 			a.Param("name", d.String, "name")
+			a.Param("space", d.UUID, "The ID of the space of the type, only system types are found without it")
 		})
 		a.Description(`Retrieve work item link types where the
 given work item type can be used in the target of the link.`)
@@ -210,6 +235,38 @@ var _ = a.Resource("tracker", func() {
 		a.Response(d.NotFound, JSONAPIErrors)
 		a.Response(d.Unauthorized, JSONAPIErrors)
 	})
+	a.Action("push", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:id/workitems/:workItemId/push"),
+		)
+		a.Description("Push the local changes of an imported work item back to the item of the tracker it was imported from. Fails with a conflict if a changed attribute was also changed remotely since the last import.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+			a.Param("workItemId", d.String, "ID of the imported work item")
+		})
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+	a.Action("webhook", func() {
+		a.Routing(
+			a.POST("/:id/webhook"),
+		)
+		a.Description("Receive GitHub and Jira issue webhooks and import the changed issue. Deliveries are verified with the webhook secret of the tracker, either by their X-Hub-Signature or X-Hub-Signature-256 header or, for Jira, by the secret parameter.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+			a.Param("secret", d.String, "Webhook secret of the tracker, for Jira webhooks that are not signed")
+		})
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
 
 })
 
@@ -287,4 +344,18 @@ var _ = a.Resource("trackerquery", func() {
 		a.Response(d.InternalServerError, JSONAPIErrors)
 		a.Response(d.NotFound, JSONAPIErrors)
 	})
+	a.Action("list-runs", func() {
+		a.Routing(
+			a.GET("/:id/runs"),
+		)
+		a.Description("List the recorded runs of the tracker query with the given id, the latest first.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(a.CollectionOf(TrackerQueryRun))
+		})
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
 })
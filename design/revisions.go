@@ -0,0 +1,66 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var workItemRevision = a.Type("WorkItemRevision", func() {
+	a.Description(`JSONAPI store for the data of a work item revision.  See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("revisions")
+	})
+	a.Attribute("id", d.UUID, "ID of the revision", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", workItemRevisionAttributes)
+	a.Attribute("relationships", workItemRevisionRelationships)
+	a.Required("type", "attributes")
+})
+
+var workItemRevisionAttributes = a.Type("WorkItemRevisionAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a work item revision. +See also see http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("revision-time", d.DateTime, "When the change was made", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("revision-type", d.String, "The kind of change", func() {
+		a.Enum("create", "update", "delete")
+	})
+	a.Attribute("version", d.Integer, "The version of the work item after the change", func() {
+		a.Example(2)
+	})
+	a.Attribute("old-fields", a.HashOf(d.String, d.Any), "The field values before the change", func() {
+		a.Example(map[string]interface{}{"system.state": "open"})
+	})
+	a.Attribute("fields", a.HashOf(d.String, d.Any), "The field values after the change", func() {
+		a.Example(map[string]interface{}{"system.state": "closed"})
+	})
+})
+
+var workItemRevisionRelationships = a.Type("WorkItemRevisionRelations", func() {
+	a.Attribute("modifier", relationGeneric, "This defines the identity that made the change")
+	a.Attribute("workitem", relationGeneric, "This defines the changed work item")
+})
+
+var workItemRevisionList = JSONList(
+	"WorkItemRevision", "Holds the list of revisions of a work item",
+	workItemRevision,
+	nil,
+	nil)
+
+var _ = a.Resource("work-item-revisions", func() {
+	a.Parent("workitem")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("revisions"),
+		)
+		a.Description("List the revisions of the given work item, the oldest first")
+		a.Response(d.OK, func() {
+			a.Media(workItemRevisionList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
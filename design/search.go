@@ -33,6 +33,7 @@ var _ = a.Resource("search", func() {
 					if this URL is mentioned in searchable columns of work item
 				3) "simple keywords separated by space" :- Search in Work Items based on these keywords.`)
 			a.Param("page[offset]", d.String, "Paging start position") // #428
+			a.Param("page[cursor]", d.String, "Opaque paging position taken from the paging links, takes precedence over page[offset]. An empty value starts at the beginning")
 			a.Param("page[limit]", d.Integer, "Paging size")
 			a.Required("q")
 		})
@@ -29,6 +29,10 @@ var spaceAttributes = a.Type("SpaceAttributes", func() {
 	a.Attribute("description", d.String, "Description for the space", func() {
 		a.Example("This is the foobar collaboration space")
 	})
+	a.Attribute("process-template", d.String, `Name of the process template whose work item types and link types are
+made available when the space is created (ignored on updates)`, func() {
+		a.Example("Scrum")
+	})
 	a.Attribute("version", d.Integer, "Version for optimistic concurrency control (optional during creating)", func() {
 		a.Example(23)
 	})
@@ -61,9 +61,61 @@ var CreateWorkItemTypePayload = a.Type("CreateWorkItemTypePayload", func() {
 		a.MinLength(1)
 		a.Pattern("^[\\p{L}.]+$")
 	})
+	a.Attribute("space", d.UUID, "ID of the space the type belongs to, system types available in all spaces have none", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
 	a.Required("name", "fields")
 })
 
+// UpdateWorkItemTypePayload describes the changes to the fields of a work item type
+var UpdateWorkItemTypePayload = a.Type("UpdateWorkItemTypePayload", func() {
+	a.Attribute("version", d.Integer, "Version of the work item type the changes are based on", func() {
+		a.Example(0)
+	})
+	a.Attribute("fields", a.HashOf(d.String, fieldDefinition), `Fields to add or change. New fields must be optional, existing fields
+may become optional or deprecated and enums may get additional values.`, func() {
+		a.Example(map[string]interface{}{
+			"system.estimate": map[string]interface{}{
+				"Type": map[string]interface{}{
+					"Kind": "float",
+				},
+				"Required": false,
+			},
+		})
+	})
+	a.Attribute("removedFields", a.ArrayOf(d.String), "Fields to remove from the type and from all its work items", func() {
+		a.Example([]string{"system.remote_item_id"})
+	})
+	a.Attribute("workflow", workflow, "Replaces the workflow of the type, subtypes keep their workflows")
+	a.Required("version")
+})
+
+// FieldMapping maps an attribute of the remote items of a tracker to a field of the imported work items
+var FieldMapping = a.Type("FieldMapping", func() {
+	a.Attribute("source", d.String, "Key of the attribute in the flattened remote item, a * matches the indexes of the elements of a remote list", func() {
+		a.Example("fields.components.*.name")
+		a.MinLength(1)
+	})
+	a.Attribute("field", d.String, "Name of the field of the work item type", func() {
+		a.Example("components")
+	})
+	a.Attribute("converter", d.String, "How the remote value is converted to the field value", func() {
+		a.Enum("string", "markup", "list", "enum")
+	})
+	a.Attribute("markup", d.String, "Markup of the markup converter")
+	a.Attribute("values", a.HashOf(d.String, d.String), "Field values of the remote values for the enum converter, other remote values are not imported")
+	a.Required("source", "field", "converter")
+})
+
+// FieldMappings define how the remote items of a tracker are imported
+var FieldMappings = a.Type("FieldMappings", func() {
+	a.Attribute("workItemType", d.String, "Name of the type of the imported work items", func() {
+		a.Example("system.bug")
+	})
+	a.Attribute("fields", a.ArrayOf(FieldMapping), "Mappings that extend the default mapping of the tracker type, replacing the default mapping of the same fields")
+	a.Required("workItemType", "fields")
+})
+
 // CreateTrackerAlternatePayload defines the structure of tracker payload for create
 var CreateTrackerAlternatePayload = a.Type("CreateTrackerAlternatePayload", func() {
 	a.Attribute("url", d.String, "URL of the tracker", func() {
@@ -75,6 +127,9 @@ var CreateTrackerAlternatePayload = a.Type("CreateTrackerAlternatePayload", func
 		a.Pattern("^[\\p{L}]+$")
 		a.MinLength(1)
 	})
+	a.Attribute("webhookSecret", d.String, "Secret the webhook deliveries of the tracker are verified with, webhooks are not accepted without a secret")
+	a.Attribute("pushToken", d.String, "Token that authenticates the pushes of local changes to the tracker, local changes can't be pushed to Jira without a token")
+	a.Attribute("fieldMappings", FieldMappings, "How the remote items are imported, the default mapping of the tracker type is used without them")
 	a.Required("url", "type")
 })
 
@@ -89,6 +144,9 @@ var UpdateTrackerAlternatePayload = a.Type("UpdateTrackerAlternatePayload", func
 		a.MinLength(1)
 		a.Pattern("^[\\p{L}]+$")
 	})
+	a.Attribute("webhookSecret", d.String, "Secret the webhook deliveries of the tracker are verified with, the secret is kept if not given and webhooks are disabled by an empty secret")
+	a.Attribute("pushToken", d.String, "Token that authenticates the pushes of local changes to the tracker, the token is kept if not given")
+	a.Attribute("fieldMappings", FieldMappings, "How the remote items are imported, the mappings are kept if not given and mappings without fields restore the default mapping")
 	a.Required("url", "type")
 })
 
@@ -25,6 +25,16 @@ var updateWorkItemLinkPayload = a.Type("UpdateWorkItemLinkPayload", func() {
 	a.Required("data")
 })
 
+// reorderWorkItemLinkPayload defines the sibling link before or after which a work item link is moved
+var reorderWorkItemLinkPayload = a.Type("ReorderWorkItemLinkPayload", func() {
+	a.Attribute("before", d.String, "ID of the link before which the link is moved", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("after", d.String, "ID of the link after which the link is moved", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+})
+
 // workItemLinkListMeta holds meta information for a work item link array response
 var workItemLinkListMeta = a.Type("WorkItemLinkListMeta", func() {
 	a.Attribute("totalCount", d.Integer, func() {
@@ -56,6 +66,13 @@ See also see http://jsonapi.org/format/#document-resource-object-attributes`)
 	a.Attribute("version", d.Integer, "Version for optimistic concurrency control (optional during creating)", func() {
 		a.Example(0)
 	})
+	a.Attribute("attributes", a.HashOf(d.String, d.Any), `Free-form attributes of the link, e.g. the reason why a work item is blocked.
+They must match the attributes schema of the link type if it has one.`, func() {
+		a.Example(map[string]interface{}{"reason": "waiting for the database upgrade"})
+	})
+	a.Attribute("position", d.Integer, "Position of the link among the links with the same source and link type (read-only, see the reorder action)", func() {
+		a.Example(0)
+	})
 	// IMPORTANT: We cannot require any field here because these "attributes" will be used
 	// during the creation as well as the update of a work item link type.
 	// During creation, the "name" field is required but not during update.
@@ -88,6 +105,31 @@ var relationWorkItemData = a.Type("RelationWorkItemData", func() {
 	a.Required("type", "id")
 })
 
+// workItemTraversalData is a work item reached when traversing work item links transitively
+var workItemTraversalData = a.Type("WorkItemTraversalData", func() {
+	a.Description(`A work item that was reached when walking work item links of one type transitively.`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitems")
+	})
+	a.Attribute("id", d.String, "ID of the reached work item", func() {
+		a.Example("1234")
+	})
+	a.Attribute("links", genericLinks)
+	a.Attribute("meta", workItemTraversalMeta)
+	a.Required("type", "id", "meta")
+})
+
+// workItemTraversalMeta describes how a work item was reached during a traversal
+var workItemTraversalMeta = a.Type("WorkItemTraversalMeta", func() {
+	a.Attribute("depth", d.Integer, "Number of links between the start work item and the reached one", func() {
+		a.Minimum(1)
+	})
+	a.Attribute("path", a.ArrayOf(d.String), "IDs of the work items on the shortest way from the start work item to the reached one, both included", func() {
+		a.Example([]string{"1", "2", "3"})
+	})
+	a.Required("depth", "path")
+})
+
 // ############################################################################
 //
 //  Media Type Definition
@@ -111,6 +153,16 @@ var workItemLinkList = JSONList(
 	workItemLinkListMeta,
 )
 
+// workItemTraversalList holds the work items reached by a traversal, the
+// reached work items themselves are included in the response
+var workItemTraversalList = JSONList(
+	"WorkItemTraversal",
+	"Holds the work items reached when walking work item links transitively",
+	workItemTraversalData,
+	nil,
+	workItemLinkListMeta,
+)
+
 // ############################################################################
 //
 //  Resource Definition
@@ -124,6 +176,7 @@ var _ = a.Resource("work-item-link", func() {
 	a.Action("create", createWorkItemLink)
 	a.Action("delete", deleteWorkItemLink)
 	a.Action("update", updateWorkItemLink)
+	a.Action("reorder", reorderWorkItemLink)
 })
 
 var _ = a.Resource("work-item-relationships-links", func() {
@@ -142,6 +195,33 @@ var _ = a.Resource("work-item-relationships-links", func() {
 			a.Description("This error arises when the given work item does not exist.")
 		})
 	})
+	a.Action("traverse", func() {
+		a.Description(`Walk the work item links of the given type transitively, e.g. to find all descendants of an epic
+or everything a work item depends on. Returns the reached work items ordered by their depth together with the
+shortest path to them.`)
+		a.Routing(
+			a.GET("/traverse"),
+		)
+		a.Params(func() {
+			a.Param("type", d.UUID, "ID of the work item link type whose links are followed")
+			a.Param("direction", d.String, "forward follows links from source to target (the default), reverse from target to source", func() {
+				a.Enum("forward", "reverse")
+			})
+			a.Param("depth", d.Integer, "maximum number of links to follow (defaults to 10)", func() {
+				a.Minimum(1)
+				a.Maximum(100)
+			})
+			a.Required("type")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemTraversalList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors, func() {
+			a.Description("This error arises when the given work item does not exist.")
+		})
+	})
 })
 
 // listWorkItemLinks defines the list action for endpoints that return an array
@@ -223,3 +303,23 @@ func updateWorkItemLink() {
 	a.Response(d.NotFound, JSONAPIErrors)
 	a.Response(d.Unauthorized, JSONAPIErrors)
 }
+
+func reorderWorkItemLink() {
+	a.Description(`Move the given work item link before or after a sibling, i.e. a link with the same source and link type.
+This is used to explicitly order the children of a work item.`)
+	a.Security("jwt")
+	a.Routing(
+		a.POST("/:linkId/reorder"),
+	)
+	a.Params(func() {
+		a.Param("linkId", d.String, "ID of the work item link to be moved")
+	})
+	a.Payload(reorderWorkItemLinkPayload)
+	a.Response(d.OK, func() {
+		a.Media(workItemLink)
+	})
+	a.Response(d.BadRequest, JSONAPIErrors)
+	a.Response(d.InternalServerError, JSONAPIErrors)
+	a.Response(d.NotFound, JSONAPIErrors)
+	a.Response(d.Unauthorized, JSONAPIErrors)
+}
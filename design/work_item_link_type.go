@@ -62,9 +62,13 @@ For example, if a bug blocks a user story, the forward name is "blocks". See als
 For example, if a bug blocks a user story, the reverse name name is "blocked by" as in: a user story is blocked by a bug. See also forward name.`, func() {
 		a.Example("tested by")
 	})
-	a.Attribute("topology", d.String, `The topology determines the restrictions placed on the usage of each work item link type.`, func() {
-		a.Enum("network")
+	a.Attribute("topology", d.String, `The topology determines the restrictions placed on the usage of each work item link type.
+In a "tree" every work item can have only one parent and links must not form a cycle. A "dependency" may have multiple
+sources per target but must not form a cycle either. A "network" or "directed_network" has no restrictions.`, func() {
+		a.Enum("network", "directed_network", "dependency", "tree")
 	})
+	a.Attribute("attributes_schema", a.HashOf(d.String, workItemLinkAttributeDefinition), `The optional schema of the attributes of the links of this type.
+Links of a type without a schema may have arbitrary attributes.`)
 
 	// IMPORTANT: We cannot require any field here because these "attributes" will be used
 	// during the creation as well as the update of a work item link type.
@@ -73,6 +77,15 @@ For example, if a bug blocks a user story, the reverse name name is "blocked by"
 	//a.Required("name")
 })
 
+// workItemLinkAttributeDefinition describes a single attribute of the links of a work item link type
+var workItemLinkAttributeDefinition = a.Type("WorkItemLinkAttributeDefinition", func() {
+	a.Attribute("kind", d.String, "The kind of the attribute's values", func() {
+		a.Enum("string", "integer", "float", "boolean")
+	})
+	a.Attribute("required", d.Boolean, "Whether every link of the type must have the attribute (defaults to false)")
+	a.Required("kind")
+})
+
 // rorkItemLinkTypeRelationships is the JSONAPI store for the relationships of a work item link type.
 var workItemLinkTypeRelationships = a.Type("WorkItemLinkTypeRelationships", func() {
 	a.Description(`JSONAPI store for the data of a work item link type.
@@ -39,6 +39,10 @@ var workItemRelationships = a.Type("WorkItemRelationships", func() {
 	a.Attribute("baseType", relationBaseType, "This defines type of Work Item")
 	a.Attribute("comments", relationGeneric, "This defines comments on the Work Item")
 	a.Attribute("iteration", relationGeneric, "This defines the iteration this work item belong to")
+	a.Attribute("space", relationGeneric, "This defines the space this work item belongs to, it can't be changed")
+	a.Attribute("references", a.HashOf(d.String, relationGenericList), `The users, iterations and work items referenced by
+the other fields of kind user, iteration or workitem keyed by field name. The values are also kept in the attributes
+and are changed there.`)
 })
 
 // relationBaseType is top level block for WorkItemType relationship
@@ -79,6 +83,152 @@ var workItemSingle = JSONSingle(
 	workItem2,
 	workItemLinks)
 
+// jsonPatchOperation is a single operation of a JSON Patch document, see https://tools.ietf.org/html/rfc6902
+var jsonPatchOperation = a.Type("JSONPatchOperation", func() {
+	a.Attribute("op", d.String, "The operation to perform", func() {
+		a.Enum("add", "remove", "replace", "test")
+	})
+	a.Attribute("path", d.String, "JSON pointer to a work item attribute or to an element of a list attribute", func() {
+		a.Example("/system.assignees/-")
+	})
+	a.Attribute("value", d.Any, "The value to add, to replace with or to test against", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Required("op", "path")
+})
+
+// workItemVersion references a work item in the version it is expected to have
+var workItemVersion = a.Type("WorkItemVersion", func() {
+	a.Attribute("id", d.String, "ID of the work item", func() {
+		a.Example("42")
+	})
+	a.Attribute("version", d.Integer, "The version the work item is expected to have", func() {
+		a.Example(3)
+	})
+	a.Required("id", "version")
+})
+
+// workItemBulk selects a set of work items and the changes to apply to all of them
+var workItemBulk = a.Type("WorkItemBulk", func() {
+	a.Attribute("workitems", a.ArrayOf(workItemVersion), "The work items to change, mutually exclusive with filter")
+	a.Attribute("filter", d.String, "a query language expression selecting the work items to change in their current version, mutually exclusive with workitems", func() {
+		a.Example("system.iteration == '40bbdd3d-8b5d-4fd6-ac90-7236b669af04' and system.state != 'closed'")
+	})
+	a.Attribute("attributes", a.HashOf(d.String, d.Any), "The attributes to set on every work item, other attributes are left untouched", func() {
+		a.Example(map[string]interface{}{"system.state": "closed"})
+	})
+	a.Attribute("delete", d.Boolean, "Delete the work items instead of changing their attributes")
+})
+
+// workItemReorder places a work item above or below another work item of the same space or iteration
+var workItemReorder = a.Type("WorkItemReorder", func() {
+	a.Attribute("above", d.String, "ID of the work item the moved work item is placed directly above", func() {
+		a.Example("42")
+	})
+	a.Attribute("below", d.String, "ID of the work item the moved work item is placed directly below", func() {
+		a.Example("42")
+	})
+})
+
+var workItemBulkItemResult = a.Type("WorkItemBulkItemResult", func() {
+	a.Attribute("id", d.String, "ID of the work item", func() {
+		a.Example("42")
+	})
+	a.Attribute("status", d.String, "The outcome for the work item", func() {
+		a.Enum("updated", "deleted", "conflict", "not_found", "bad_parameter")
+	})
+	a.Attribute("version", d.Integer, "The version of the work item after the update")
+	a.Attribute("detail", d.String, "Why the work item was not changed")
+	a.Required("id", "status")
+})
+
+var workItemBulkMeta = a.Type("WorkItemBulkMeta", func() {
+	a.Attribute("results", a.ArrayOf(workItemBulkItemResult))
+	a.Attribute("succeeded", d.Integer, "The number of work items that were changed")
+	a.Attribute("failed", d.Integer, "The number of work items that were not changed")
+	a.Required("results", "succeeded", "failed")
+})
+
+// workItemBulkResult reports the outcome of a bulk operation for every work item
+var workItemBulkResult = a.MediaType("application/vnd.workitembulkresult+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("WorkItemBulkResult")
+	a.Description("The outcome of a bulk operation on work items")
+	a.Attributes(func() {
+		a.Attribute("meta", workItemBulkMeta)
+		a.Required("meta")
+	})
+	a.View("default", func() {
+		a.Attribute("meta")
+		a.Required("meta")
+	})
+})
+
+// workItemBucket holds the number of work items sharing one value of the grouped field
+var workItemBucket = a.Type("WorkItemBucket", func() {
+	a.Attribute("value", d.Any, "The value of the grouped field, null for work items without a value", func() {
+		a.Example("open")
+	})
+	a.Attribute("count", d.Integer, "The number of work items with this value", func() {
+		a.Example(42)
+	})
+	a.Required("count")
+})
+
+var workItemAggregateMeta = a.Type("WorkItemAggregateMeta", func() {
+	a.Attribute("groupBy", d.String, "The field the work items are grouped by", func() {
+		a.Example("system.state")
+	})
+	a.Attribute("totalCount", d.Integer, "The number of work items matching the filter", func() {
+		a.Example(84)
+	})
+	a.Attribute("buckets", a.ArrayOf(workItemBucket))
+	a.Required("groupBy", "totalCount", "buckets")
+})
+
+// workItemAggregate holds the work item counts grouped by a field
+var workItemAggregate = a.MediaType("application/vnd.workitemaggregate+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("WorkItemAggregate")
+	a.Description("Work item counts grouped by the values of a field")
+	a.Attributes(func() {
+		a.Attribute("meta", workItemAggregateMeta)
+		a.Required("meta")
+	})
+	a.View("default", func() {
+		a.Attribute("meta")
+		a.Required("meta")
+	})
+})
+
+// workItemState is a state a work item may change to
+var workItemState = a.Type("WorkItemState", func() {
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitemstates")
+	})
+	a.Attribute("id", d.String, "The value of the system.state field", func() {
+		a.Example("resolved")
+	})
+	a.Attribute("attributes", workItemStateAttributes)
+	a.Required("type", "id", "attributes")
+})
+
+var workItemStateAttributes = a.Type("WorkItemStateAttributes", func() {
+	a.Attribute("name", d.String, "The value of the system.state field", func() {
+		a.Example("resolved")
+	})
+	a.Attribute("required-fields", a.ArrayOf(d.String), "The fields that must have a value when the work item enters the state", func() {
+		a.Example([]string{"system.resolution"})
+	})
+	a.Required("name", "required-fields")
+})
+
+var workItemStateList = JSONList(
+	"WorkItemState", "Holds the list of states a work item may change to",
+	workItemState,
+	nil,
+	nil)
+
 // new version of "list" for migration
 var _ = a.Resource("workitem", func() {
 	a.BasePath("/workitems")
@@ -105,9 +255,11 @@ var _ = a.Resource("workitem", func() {
 		a.Params(func() {
 			a.Param("filter", d.String, "a query language expression restricting the set of found work items")
 			a.Param("page[offset]", d.String, "Paging start position")
+			a.Param("page[cursor]", d.String, "Opaque paging position taken from the paging links, takes precedence over page[offset]. An empty value starts at the beginning")
 			a.Param("page[limit]", d.Integer, "Paging size")
 			a.Param("filter[assignee]", d.String, "Work Items assigned to the given user")
 			a.Param("filter[iteration]", d.String, "IterationID to filter work items")
+			a.Param("sort", d.String, "comma separated list of fields to sort by, a field prefixed with '-' is sorted in descending order, e.g. '-system.created_at,system.title', 'rank' sorts in backlog order")
 		})
 		a.Response(d.OK, func() {
 			a.Media(workItemList)
@@ -115,6 +267,24 @@ var _ = a.Resource("workitem", func() {
 		a.Response(d.BadRequest, JSONAPIErrors)
 		a.Response(d.InternalServerError, JSONAPIErrors)
 	})
+	a.Action("aggregate", func() {
+		a.Routing(
+			a.GET("/stats"),
+		)
+		a.Description("Count work items grouped by the values of a field.")
+		a.Params(func() {
+			a.Param("groupBy", d.String, "the field to group by, either 'Type' or a field of the work item types, e.g. 'system.state'")
+			a.Param("filter", d.String, "a query language expression restricting the set of counted work items")
+			a.Param("filter[assignee]", d.String, "Work Items assigned to the given user")
+			a.Param("filter[iteration]", d.String, "IterationID to filter work items")
+			a.Required("groupBy")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemAggregate)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
 	a.Action("create", func() {
 		a.Security("jwt")
 		a.Routing(
@@ -162,4 +332,75 @@ var _ = a.Resource("workitem", func() {
 		a.Response(d.NotFound, JSONAPIErrors)
 		a.Response(d.Unauthorized, JSONAPIErrors)
 	})
+	a.Action("bulk", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/bulk"),
+		)
+		a.Description(`change or delete a set of work items in one transaction. Every work item given in workitems is checked
+against its version, the work items that can not be changed are reported and do not prevent the others from being changed.
+The work items selected by a filter are changed in the version they have when the filter is evaluated, so changes made
+to them before the bulk request are overwritten. Use workitems with the expected versions to detect those changes.`)
+		a.Payload(workItemBulk)
+		a.Response(d.OK, func() {
+			a.Media(workItemBulkResult)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+	a.Action("list-next-states", func() {
+		a.Routing(
+			a.GET("/:id/next-states"),
+		)
+		a.Description(`List the states the work item with the given id may change to according to the
+workflow of its type, together with the fields that must have a value in the new state.`)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemStateList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+	a.Action("reorder", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:id/reorder"),
+		)
+		a.Description(`move the work item with the given id directly above or below another work item of the same
+space or iteration in the backlog order, see sort=rank.`)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Payload(workItemReorder)
+		a.Response(d.OK, func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+	a.Action("patch", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:id/attributes"),
+		)
+		a.Description(`apply a JSON Patch (application/json-patch+json) to the attributes of the work item with the given id.
+Other attributes are left untouched. Use a "test" operation on "/version" to only apply the patch to a known version.`)
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Payload(a.ArrayOf(jsonPatchOperation))
+		a.Response(d.OK, func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
 })
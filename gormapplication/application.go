@@ -9,7 +9,9 @@ import (
 	"github.com/almighty/almighty-core/area"
 	"github.com/almighty/almighty-core/comment"
 	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/label"
 	"github.com/almighty/almighty-core/remoteworkitem"
+	"github.com/almighty/almighty-core/savedquery"
 	"github.com/almighty/almighty-core/search"
 	"github.com/almighty/almighty-core/space"
 	"github.com/almighty/almighty-core/workitem"
@@ -127,6 +129,21 @@ func (g *GormBase) Areas() area.Repository {
 	return area.NewAreaRepository(g.db)
 }
 
+// Labels returns a label repository
+func (g *GormBase) Labels() label.Repository {
+	return label.NewLabelRepository(g.db)
+}
+
+// Queries returns a saved query repository
+func (g *GormBase) Queries() savedquery.Repository {
+	return savedquery.NewQueryRepository(g.db)
+}
+
+// WorkItemRevisions returns a work item revision repository
+func (g *GormBase) WorkItemRevisions() workitem.RevisionRepository {
+	return workitem.NewRevisionRepository(g.db)
+}
+
 func (g *GormBase) DB() *gorm.DB {
 	return g.db
 }
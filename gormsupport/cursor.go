@@ -0,0 +1,228 @@
+package gormsupport
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+)
+
+// sortKeyColumn is the prefix of the extra columns selected by SelectSortKeys
+const sortKeyColumn = "sort_key_"
+
+// SortKey is a single sql expression of an order by clause.
+type SortKey struct {
+	Expression string
+	Descending bool
+}
+
+// Cursor is a position in an ordered result set, used for keyset pagination.
+// The position is given by the values of the sort keys of the row the cursor points at.
+// A cursor without values points at the start of the result set, or at its end if Before is set.
+type Cursor struct {
+	// Values holds the text representation of the sort key values, nil represents NULL
+	Values []*string `json:"v,omitempty"`
+	// Before selects the rows before the position instead of the rows after it
+	Before bool `json:"b,omitempty"`
+}
+
+// Encode returns the opaque string representation of the cursor to be used in links
+func (c Cursor) Encode() string {
+	bytes, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+// DecodeCursor parses a cursor created by Cursor.Encode. The empty string is the cursor pointing at the start.
+// returns BadParameterError if the cursor is malformed
+func DecodeCursor(encoded string) (*Cursor, error) {
+	result := Cursor{}
+	if encoded == "" {
+		return &result, nil
+	}
+	bytes, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.NewBadParameterError("page[cursor]", encoded)
+	}
+	if err := json.Unmarshal(bytes, &result); err != nil {
+		return nil, errors.NewBadParameterError("page[cursor]", encoded)
+	}
+	return &result, nil
+}
+
+// PageCursors holds the encoded cursors of the pages before and after a page of results.
+// A nil cursor means there is no such page.
+type PageCursors struct {
+	Prev *string
+	Next *string
+}
+
+// NewPageCursors creates the cursors pointing before the first and after the last row of a page
+func NewPageCursors(first []*string, last []*string, hasPrev bool, hasNext bool) *PageCursors {
+	result := PageCursors{}
+	if hasPrev && first != nil {
+		prev := Cursor{Values: first, Before: true}.Encode()
+		result.Prev = &prev
+	}
+	if hasNext && last != nil {
+		next := Cursor{Values: last}.Encode()
+		result.Next = &next
+	}
+	return &result
+}
+
+// Neighbours tells whether there are pages before and after a page fetched with the cursor.
+// more tells whether rows beyond the page were found in the direction of the cursor.
+func (c Cursor) Neighbours(more bool) (hasPrev bool, hasNext bool) {
+	if c.Before {
+		return more, c.Values != nil
+	}
+	return c.Values != nil, more
+}
+
+// ApplyCursor restricts the query to the rows after (or before) the cursor and orders it by the given keys.
+// The keys must identify a row uniquely, otherwise rows with the same keys might be skipped.
+// When selecting the rows before the cursor, the order is reversed and the caller must reverse the result.
+// returns BadParameterError if the cursor doesn't fit the keys
+func ApplyCursor(db *gorm.DB, keys []SortKey, cursor Cursor) (*gorm.DB, error) {
+	if cursor.Values != nil {
+		if len(cursor.Values) != len(keys) {
+			return nil, errors.NewBadParameterError("page[cursor]", cursor.Encode())
+		}
+		where, parameters := keysetCondition(keys, cursor.Values, cursor.Before)
+		db = db.Where(where, parameters...)
+	}
+	return db.Order(OrderClause(keys, cursor.Before)), nil
+}
+
+// OrderClause renders the keys for use with gorm.DB.Order(), reverse inverts the direction of all keys
+func OrderClause(keys []SortKey, reverse bool) string {
+	clauses := make([]string, len(keys))
+	for i, key := range keys {
+		clauses[i] = key.Expression
+		if key.Descending != reverse {
+			clauses[i] += " desc"
+		}
+	}
+	return strings.Join(clauses, ", ")
+}
+
+// keysetCondition builds a where clause selecting the rows that come strictly after the given values
+// in the order defined by keys (or before them if before is set), e.g. for two ascending keys
+// "(a > ? or a is null) or (a = ? and (b > ? or b is null))". Postgres sorts NULL after all values
+// in ascending order and before all values in descending order, which is taken into account.
+func keysetCondition(keys []SortKey, values []*string, before bool) (string, []interface{}) {
+	var alternatives []string
+	var parameters []interface{}
+	for i, key := range keys {
+		var terms []string
+		var termParameters []interface{}
+		for j := 0; j < i; j++ {
+			if values[j] == nil {
+				terms = append(terms, keys[j].Expression+" is null")
+			} else {
+				terms = append(terms, keys[j].Expression+" = ?")
+				termParameters = append(termParameters, *values[j])
+			}
+		}
+		descending := key.Descending != before
+		switch {
+		case !descending && values[i] == nil:
+			// nothing comes after NULL in ascending order
+			continue
+		case !descending:
+			terms = append(terms, "("+key.Expression+" > ? or "+key.Expression+" is null)")
+			termParameters = append(termParameters, *values[i])
+		case values[i] == nil:
+			terms = append(terms, key.Expression+" is not null")
+		default:
+			terms = append(terms, key.Expression+" < ?")
+			termParameters = append(termParameters, *values[i])
+		}
+		alternatives = append(alternatives, "("+strings.Join(terms, " and ")+")")
+		parameters = append(parameters, termParameters...)
+	}
+	if len(alternatives) == 0 {
+		return "false", parameters
+	}
+	return "(" + strings.Join(alternatives, " or ") + ")", parameters
+}
+
+// SelectSortKeys returns a select list of the sort key expressions, to be appended to the columns of a query.
+// The values can be read with ScanSortKeys
+func SelectSortKeys(keys []SortKey) string {
+	columns := make([]string, len(keys))
+	for i, key := range keys {
+		columns[i] = fmt.Sprintf("%s as %s%d", key.Expression, sortKeyColumn, i)
+	}
+	return strings.Join(columns, ", ")
+}
+
+// ScanSortKeys reads the values of the columns selected with SelectSortKeys from the current row.
+// If count is not nil, the first column is scanned into it.
+func ScanSortKeys(rows *sql.Rows, columns []string, count *uint64) ([]*string, error) {
+	var ignore interface{}
+	keyCount := 0
+	for _, column := range columns {
+		if strings.HasPrefix(column, sortKeyColumn) {
+			keyCount++
+		}
+	}
+	keyValues := make([]interface{}, keyCount)
+	columnValues := make([]interface{}, len(columns))
+	keyIndex := 0
+	for index, column := range columns {
+		if strings.HasPrefix(column, sortKeyColumn) {
+			columnValues[index] = &keyValues[keyIndex]
+			keyIndex++
+		} else {
+			columnValues[index] = &ignore
+		}
+	}
+	if count != nil {
+		columnValues[0] = count
+	}
+	if err := rows.Scan(columnValues...); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	result := make([]*string, keyCount)
+	for i, value := range keyValues {
+		converted, err := sortKeyString(value)
+		if err != nil {
+			return nil, errs.WithStack(err)
+		}
+		result[i] = converted
+	}
+	return result, nil
+}
+
+// sortKeyString converts a value read from the database into its text representation,
+// which postgres converts back to the original type when comparing it with the sort key
+func sortKeyString(value interface{}) (*string, error) {
+	var result string
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		result = string(v)
+	case string:
+		result = v
+	case int64:
+		result = strconv.FormatInt(v, 10)
+	case float64:
+		result = strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		result = strconv.FormatBool(v)
+	case time.Time:
+		result = v.Format(time.RFC3339Nano)
+	default:
+		return nil, errors.NewInternalError(fmt.Sprintf("unexpected type of sort key value %v: %T", value, value))
+	}
+	return &result, nil
+}
@@ -0,0 +1,67 @@
+package gormsupport_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorEncoding(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	value := "2016-11-01T00:00:00Z"
+	cursor := gormsupport.Cursor{Values: []*string{&value, nil}, Before: true}
+	decoded, err := gormsupport.DecodeCursor(cursor.Encode())
+	require.Nil(t, err)
+	assert.Equal(t, cursor, *decoded)
+
+	decoded, err = gormsupport.DecodeCursor("")
+	require.Nil(t, err)
+	assert.Equal(t, gormsupport.Cursor{}, *decoded)
+
+	for _, malformed := range []string{"%%%", "bm90IGpzb24"} {
+		_, err = gormsupport.DecodeCursor(malformed)
+		require.NotNil(t, err)
+		_, ok := err.(errors.BadParameterError)
+		assert.True(t, ok)
+	}
+}
+
+func TestOrderClause(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	keys := []gormsupport.SortKey{{Expression: "created_at", Descending: true}, {Expression: "id"}}
+	assert.Equal(t, "created_at desc, id", gormsupport.OrderClause(keys, false))
+	assert.Equal(t, "created_at, id desc", gormsupport.OrderClause(keys, true))
+	assert.Equal(t, "created_at as sort_key_0, id as sort_key_1", gormsupport.SelectSortKeys(keys))
+}
+
+func TestPageCursors(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	first := "1"
+	last := "5"
+	cursors := gormsupport.NewPageCursors([]*string{&first}, []*string{&last}, true, false)
+	require.NotNil(t, cursors.Prev)
+	assert.Nil(t, cursors.Next)
+	prev, err := gormsupport.DecodeCursor(*cursors.Prev)
+	require.Nil(t, err)
+	assert.Equal(t, gormsupport.Cursor{Values: []*string{&first}, Before: true}, *prev)
+
+	start := gormsupport.Cursor{}
+	hasPrev, hasNext := start.Neighbours(true)
+	assert.False(t, hasPrev)
+	assert.True(t, hasNext)
+	end := gormsupport.Cursor{Before: true}
+	hasPrev, hasNext = end.Neighbours(true)
+	assert.True(t, hasPrev)
+	assert.False(t, hasNext)
+	middle := gormsupport.Cursor{Values: []*string{&first}}
+	hasPrev, hasNext = middle.Neighbours(false)
+	assert.True(t, hasPrev)
+	assert.False(t, hasNext)
+}
@@ -0,0 +1,35 @@
+package gormsupport
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeysetCondition(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	a := "1"
+	b := "x"
+	keys := []SortKey{{Expression: "a"}, {Expression: "b", Descending: true}}
+
+	where, parameters := keysetCondition(keys, []*string{&a, &b}, false)
+	assert.Equal(t, "(((a > ? or a is null)) or (a = ? and b < ?))", where)
+	assert.Equal(t, []interface{}{"1", "1", "x"}, parameters)
+
+	where, parameters = keysetCondition(keys, []*string{&a, &b}, true)
+	assert.Equal(t, "((a < ?) or (a = ? and (b > ? or b is null)))", where)
+	assert.Equal(t, []interface{}{"1", "1", "x"}, parameters)
+
+	where, parameters = keysetCondition(keys, []*string{nil, nil}, false)
+	assert.Equal(t, "((a is null and b is not null))", where)
+	assert.Empty(t, parameters)
+
+	where, parameters = keysetCondition(keys, []*string{nil, nil}, true)
+	assert.Equal(t, "((a is not null))", where)
+	assert.Empty(t, parameters)
+
+	where, _ = keysetCondition([]SortKey{{Expression: "a"}}, []*string{nil}, false)
+	assert.Equal(t, "false", where)
+}
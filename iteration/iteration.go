@@ -46,6 +46,7 @@ type Repository interface {
 	Load(ctx context.Context, id uuid.UUID) (*Iteration, error)
 	Save(ctx context.Context, i Iteration) (*Iteration, error)
 	CanStartIteration(ctx context.Context, i *Iteration) (bool, error)
+	LoadCurrent(ctx context.Context, spaceID uuid.UUID) (*Iteration, error)
 }
 
 // NewIterationRepository creates a new storage type.
@@ -130,3 +131,19 @@ func (m *GormIterationRepository) CanStartIteration(ctx context.Context, i *Iter
 	}
 	return true, nil
 }
+
+// LoadCurrent returns the running iteration of a space, the one with state=start
+// returns NotFoundError or InternalError
+func (m *GormIterationRepository) LoadCurrent(ctx context.Context, spaceID uuid.UUID) (*Iteration, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration", "current"}, time.Now())
+	var obj Iteration
+
+	tx := m.db.Where("space_id = ? AND state = ?", spaceID, IterationStateStart).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("current iteration of space", spaceID.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
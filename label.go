@@ -0,0 +1,83 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/label"
+	"github.com/almighty/almighty-core/rest"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// LabelController implements the label resource.
+type LabelController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewLabelController creates a label controller.
+func NewLabelController(service *goa.Service, db application.DB) *LabelController {
+	return &LabelController{Controller: service.NewController("LabelController"), db: db}
+}
+
+// Show runs the show action.
+func (c *LabelController) Show(ctx *app.ShowLabelContext) error {
+	id, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(c.db, func(appl application.Application) error {
+		l, err := appl.Labels().Load(ctx, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.LabelSingle{}
+		res.Data = ConvertLabel(ctx.RequestData, l)
+		return ctx.OK(res)
+	})
+}
+
+// ConvertLabels converts between internal and external REST representation
+func ConvertLabels(request *goa.RequestData, labels []*label.Label) []*app.Label {
+	var ls = []*app.Label{}
+	for _, l := range labels {
+		ls = append(ls, ConvertLabel(request, l))
+	}
+	return ls
+}
+
+// ConvertLabel converts between internal and external REST representation
+func ConvertLabel(request *goa.RequestData, l *label.Label) *app.Label {
+	spaceType := "spaces"
+	spaceID := l.SpaceID.String()
+	selfURL := rest.AbsoluteURL(request, app.LabelHref(l.ID))
+	spaceSelfURL := rest.AbsoluteURL(request, app.SpaceHref(spaceID))
+
+	return &app.Label{
+		Type: label.APIStringTypeLabels,
+		ID:   &l.ID,
+		Attributes: &app.LabelAttributes{
+			Name:      &l.Name,
+			Color:     &l.Color,
+			CreatedAt: &l.CreatedAt,
+			Version:   &l.Version,
+		},
+		Relationships: &app.LabelRelations{
+			Space: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: &spaceType,
+					ID:   &spaceID,
+				},
+				Links: &app.GenericLinks{
+					Self: &spaceSelfURL,
+				},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
@@ -0,0 +1,109 @@
+package label
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// APIStringTypeLabels is the JSONAPI type of labels
+const APIStringTypeLabels = "labels"
+
+// colorPattern matches the hex colors labels are displayed with, e.g. #e0b4b4
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Label describes a single label that can be put on the work items of a space
+// using fields of kind label
+type Label struct {
+	gormsupport.Lifecycle
+	ID      uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	SpaceID uuid.UUID `sql:"type:uuid"`
+	Name    string
+	Color   string
+	Version int
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m *Label) TableName() string {
+	return "labels"
+}
+
+// Repository describes interactions with Labels
+type Repository interface {
+	Create(ctx context.Context, u *Label) error
+	List(ctx context.Context, spaceID uuid.UUID) ([]*Label, error)
+	Load(ctx context.Context, id uuid.UUID) (*Label, error)
+}
+
+// NewLabelRepository creates a new storage type.
+func NewLabelRepository(db *gorm.DB) Repository {
+	return &GormLabelRepository{db: db}
+}
+
+// GormLabelRepository is the implementation of the storage interface for Labels.
+type GormLabelRepository struct {
+	db *gorm.DB
+}
+
+// Create creates a new record. Label names are unique within a space.
+// returns BadParameterError or InternalError
+func (m *GormLabelRepository) Create(ctx context.Context, u *Label) error {
+	defer goa.MeasureSince([]string{"goa", "db", "label", "create"}, time.Now())
+
+	u.Name = strings.TrimSpace(u.Name)
+	if u.Name == "" {
+		return errors.NewBadParameterError("name", u.Name).Expected("not empty")
+	}
+	if !colorPattern.MatchString(u.Color) {
+		return errors.NewBadParameterError("color", u.Color).Expected("a hex color like #e0b4b4")
+	}
+	var count int
+	if err := m.db.Model(&Label{}).Where("space_id = ? AND name = ?", u.SpaceID, u.Name).Count(&count).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if count > 0 {
+		return errors.NewBadParameterError("name", u.Name).Expected("a name that is not used by another label of the space")
+	}
+
+	u.ID = uuid.NewV4()
+	if err := m.db.Create(u).Error; err != nil {
+		goa.LogError(ctx, "error adding Label", "error", err.Error())
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// List all Labels of a space ordered by name
+func (m *GormLabelRepository) List(ctx context.Context, spaceID uuid.UUID) ([]*Label, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "label", "query"}, time.Now())
+	var objs []*Label
+	err := m.db.Where("space_id = ?", spaceID).Order("name").Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// Load a single Label
+// returns NotFoundError or InternalError
+func (m *GormLabelRepository) Load(ctx context.Context, id uuid.UUID) (*Label, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "label", "get"}, time.Now())
+	var obj Label
+
+	tx := m.db.Where("id = ?", id).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("label", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
@@ -0,0 +1,74 @@
+package label_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/label"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestLabelRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunLabelRepository(t *testing.T) {
+	suite.Run(t, &TestLabelRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestLabelRepository) SetupTest() {
+	test.clean = cleaner.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestLabelRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestLabelRepository) TestCreateListLoadLabels() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	s, err := space.NewRepository(test.DB).Create(context.Background(), &space.Space{Name: "Label space " + uuid.NewV4().String()})
+	require.Nil(t, err)
+	repo := label.NewLabelRepository(test.DB)
+
+	l := label.Label{SpaceID: s.ID, Name: " urgent ", Color: "#FF0000"}
+	require.Nil(t, repo.Create(context.Background(), &l))
+	assert.NotEqual(t, uuid.Nil, l.ID)
+	assert.Equal(t, "urgent", l.Name)
+	require.Nil(t, repo.Create(context.Background(), &label.Label{SpaceID: s.ID, Name: "backend", Color: "#00ff00"}))
+
+	for name, invalid := range map[string]label.Label{
+		"empty name":     {SpaceID: s.ID, Name: " ", Color: "#ff0000"},
+		"duplicate name": {SpaceID: s.ID, Name: "urgent", Color: "#ff0000"},
+		"named color":    {SpaceID: s.ID, Name: "red", Color: "red"},
+		"short color":    {SpaceID: s.ID, Name: "red", Color: "#f00"},
+	} {
+		err := repo.Create(context.Background(), &invalid)
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err), name)
+	}
+
+	labels, err := repo.List(context.Background(), s.ID)
+	require.Nil(t, err)
+	require.Len(t, labels, 2)
+	assert.Equal(t, "backend", labels[0].Name)
+	assert.Equal(t, "urgent", labels[1].Name)
+
+	loaded, err := repo.Load(context.Background(), l.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "#FF0000", loaded.Color)
+	_, err = repo.Load(context.Background(), uuid.NewV4())
+	assert.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/almighty/almighty-core/login"
 	"github.com/almighty/almighty-core/migration"
 	"github.com/almighty/almighty-core/models"
+	"github.com/almighty/almighty-core/process"
 	"github.com/almighty/almighty-core/remoteworkitem"
 	"github.com/almighty/almighty-core/token"
 	"github.com/almighty/almighty-core/workitem"
@@ -127,6 +128,13 @@ func main() {
 		}
 	}
 
+	// Make the configured process templates available for new spaces
+	if dir := configuration.GetProcessTemplatesDir(); dir != "" {
+		if err := process.ImportDir(dir); err != nil {
+			panic(fmt.Sprintf("ERROR: Failed to import process templates: \n%+v", err))
+		}
+	}
+
 	// Scheduler to fetch and import remote tracker items
 	scheduler = remoteworkitem.NewScheduler(db)
 	defer scheduler.Stop()
@@ -200,6 +208,10 @@ func main() {
 	workItemCommentsCtrl := NewWorkItemCommentsController(service, appDB)
 	app.MountWorkItemCommentsController(service, workItemCommentsCtrl)
 
+	// Mount "work item revisions" controller
+	workItemRevisionsCtrl := NewWorkItemRevisionsController(service, appDB)
+	app.MountWorkItemRevisionsController(service, workItemRevisionsCtrl)
+
 	// Mount "work item relationships links" controller
 	workItemRelationshipsLinksCtrl := NewWorkItemRelationshipsLinksController(service, appDB)
 	app.MountWorkItemRelationshipsLinksController(service, workItemRelationshipsLinksCtrl)
@@ -220,6 +232,10 @@ func main() {
 	spaceCtrl := NewSpaceController(service, appDB)
 	app.MountSpaceController(service, spaceCtrl)
 
+	// Mount "process templates" controller
+	processTemplateCtrl := NewProcesstemplateController(service)
+	app.MountProcesstemplateController(service, processTemplateCtrl)
+
 	// Mount "user" controller
 	userCtrl := NewUserController(service, appDB, tokenManager)
 	app.MountUserController(service, userCtrl)
@@ -259,6 +275,20 @@ func main() {
 	spaceAreaCtrl := NewSpaceAreasController(service, appDB)
 	app.MountSpaceAreasController(service, spaceAreaCtrl)
 
+	// Mount "labels" controller
+	labelCtrl := NewLabelController(service, appDB)
+	app.MountLabelController(service, labelCtrl)
+
+	spaceLabelsCtrl := NewSpaceLabelsController(service, appDB)
+	app.MountSpaceLabelsController(service, spaceLabelsCtrl)
+
+	// Mount "queries" controller
+	queryCtrl := NewQueryController(service, appDB)
+	app.MountQueryController(service, queryCtrl)
+
+	spaceQueriesCtrl := NewSpaceQueriesController(service, appDB)
+	app.MountSpaceQueriesController(service, spaceQueriesCtrl)
+
 	fmt.Println("Git Commit SHA: ", Commit)
 	fmt.Println("UTC Build Time: ", BuildTime)
 	fmt.Println("UTC Start Time: ", StartTime)
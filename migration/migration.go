@@ -157,6 +157,48 @@ func getMigrations() migrations {
 	// version 26
 	m = append(m, steps{executeSQLFile("026-areas.sql")})
 
+	// Version 27
+	m = append(m, steps{executeSQLFile("027-queries.sql")})
+
+	// Version 28
+	m = append(m, steps{executeSQLFile("028-work-item-revisions.sql")})
+
+	// Version 29
+	m = append(m, steps{executeSQLFile("029-space-scoped-work-item-types.sql")})
+
+	// Version 30
+	m = append(m, steps{executeSQLFile("030-work-item-type-workflows.sql")})
+
+	// Version 31
+	m = append(m, steps{executeSQLFile("031-labels.sql")})
+
+	// Version 32
+	m = append(m, steps{executeSQLFile("032-work-item-link-attributes.sql")})
+
+	// Version 33
+	m = append(m, steps{executeSQLFile("033-work-item-ranks.sql")})
+
+	// Version 34
+	m = append(m, steps{executeSQLFile("034-tracker-webhook-secrets.sql")})
+
+	// Version 35
+	m = append(m, steps{executeSQLFile("035-tracker-query-watermarks.sql")})
+
+	// Version 36
+	m = append(m, steps{executeSQLFile("036-tracker-query-runs.sql")})
+
+	// Version 37
+	m = append(m, steps{executeSQLFile("037-tracker-field-mappings.sql")})
+
+	// Version 38
+	m = append(m, steps{executeSQLFile("038-work-item-types-per-space.sql")})
+
+	// Version 39
+	m = append(m, steps{executeSQLFile("039-tracker-push-tokens.sql")})
+
+	// Version 40
+	m = append(m, steps{executeSQLFile("040-work-item-ranks-per-space.sql")})
+
 	// Version N
 	//
 	// In order to add an upgrade, simply append an array of MigrationFunc to the
@@ -324,7 +366,7 @@ func createOrUpdateWorkItemLinkType(ctx context.Context, linkCatRepo *link.GormW
 	cause := errs.Cause(err)
 	switch cause.(type) {
 	case errors.NotFoundError:
-		_, err := linkTypeRepo.Create(ctx, lt.Name, lt.Description, lt.SourceTypeName, lt.TargetTypeName, lt.ForwardName, lt.ReverseName, lt.Topology, lt.LinkCategoryID)
+		_, err := linkTypeRepo.Create(ctx, lt.Name, lt.Description, lt.SourceTypeName, lt.TargetTypeName, lt.ForwardName, lt.ReverseName, lt.Topology, lt.LinkCategoryID, lt.AttributesSchema)
 		if err != nil {
 			return errs.WithStack(err)
 		}
@@ -414,11 +456,11 @@ func createOrUpdatePlannerItemExtension(typeName string, ctx context.Context, wi
 }
 
 func createOrUpdateType(typeName string, extendedTypeName *string, fields map[string]app.FieldDefinition, ctx context.Context, witr *workitem.GormWorkItemTypeRepository, db *gorm.DB) error {
-	wit, err := witr.LoadTypeFromDB(typeName)
+	wit, err := witr.LoadTypeFromDB(nil, typeName)
 	cause := errs.Cause(err)
 	switch cause.(type) {
 	case errors.NotFoundError:
-		_, err := witr.Create(ctx, extendedTypeName, typeName, fields)
+		_, err := witr.Create(ctx, nil, extendedTypeName, typeName, fields)
 		if err != nil {
 			return errs.WithStack(err)
 		}
@@ -428,7 +470,7 @@ func createOrUpdateType(typeName string, extendedTypeName *string, fields map[st
 		convertedFields, err := workitem.TEMPConvertFieldTypesToModel(fields)
 		if extendedTypeName != nil {
 			log.Printf("Work item type %v extends another type %v, will copy fields from the extended type", typeName, *extendedTypeName)
-			extendedWit, err := witr.LoadTypeFromDB(*extendedTypeName)
+			extendedWit, err := witr.LoadTypeFromDB(nil, *extendedTypeName)
 			if err != nil {
 				return errs.WithStack(err)
 			}
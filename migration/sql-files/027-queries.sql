@@ -0,0 +1,17 @@
+-- saved work item queries, see savedquery.Query
+CREATE TABLE queries (
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    id uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    space_id uuid NOT NULL,
+    creator uuid NOT NULL,
+    version integer DEFAULT 0 NOT NULL,
+    title text NOT NULL CHECK (trim(title) <> ''),
+    filter text NOT NULL,
+    shared boolean DEFAULT false NOT NULL
+);
+
+CREATE INDEX queries_space_id_idx ON queries (space_id);
+-- a user can not have two queries with the same title in a space
+CREATE UNIQUE INDEX queries_title_idx ON queries (space_id, creator, title) WHERE deleted_at IS NULL;
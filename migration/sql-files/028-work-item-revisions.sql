@@ -0,0 +1,14 @@
+-- the history of changes of work items, see workitem.Revision
+CREATE TABLE work_item_revisions (
+    id uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    revision_time timestamp with time zone DEFAULT current_timestamp NOT NULL,
+    revision_type integer NOT NULL,
+    modifier_id uuid NOT NULL,
+    work_item_id bigint NOT NULL,
+    work_item_type text NOT NULL,
+    work_item_version integer NOT NULL,
+    old_fields jsonb,
+    fields jsonb
+);
+
+CREATE INDEX work_item_revisions_work_item_id_idx ON work_item_revisions (work_item_id, revision_time);
@@ -0,0 +1,6 @@
+-- work item types without a space are system types available in all spaces
+ALTER TABLE work_item_types ADD COLUMN space_id uuid REFERENCES spaces(id);
+CREATE INDEX work_item_types_space_id_idx ON work_item_types (space_id);
+
+-- the name of the process template that was applied when the space was created
+ALTER TABLE spaces ADD COLUMN process_template text NOT NULL DEFAULT '';
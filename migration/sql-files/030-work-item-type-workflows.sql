@@ -0,0 +1,2 @@
+-- the allowed changes of the state of work items, see workitem.Workflow
+ALTER TABLE work_item_types ADD COLUMN workflow jsonb;
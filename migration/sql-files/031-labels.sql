@@ -0,0 +1,12 @@
+-- labels are put on work items using fields of kind label
+CREATE TABLE labels (
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    id uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    space_id uuid NOT NULL REFERENCES spaces(id) ON DELETE CASCADE,
+    version integer DEFAULT 0 NOT NULL,
+    name text NOT NULL,
+    color text NOT NULL
+);
+CREATE UNIQUE INDEX labels_space_id_name_idx ON labels (space_id, name) WHERE deleted_at IS NULL;
@@ -0,0 +1,13 @@
+-- work item links carry free-form attributes that are validated against the
+-- optional schema of their link type
+ALTER TABLE work_item_link_types ADD COLUMN attributes_schema jsonb;
+ALTER TABLE work_item_links ADD COLUMN attributes jsonb DEFAULT '{}' NOT NULL;
+
+-- links with the same source and link type are ordered by their position,
+-- existing links keep the order in which they were created
+ALTER TABLE work_item_links ADD COLUMN position integer DEFAULT 0 NOT NULL;
+UPDATE work_item_links l SET position = o.position FROM (
+    SELECT id, row_number() OVER (PARTITION BY source_id, link_type_id ORDER BY created_at, id) - 1 AS position
+    FROM work_item_links WHERE deleted_at IS NULL
+) o WHERE l.id = o.id;
+CREATE INDEX work_item_links_source_id_link_type_id_position_idx ON work_item_links (source_id, link_type_id, position);
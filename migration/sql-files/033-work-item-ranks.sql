@@ -0,0 +1,7 @@
+-- the rank orders the work items of a backlog, it's compared byte by byte
+ALTER TABLE work_items ADD COLUMN rank text COLLATE "C";
+
+-- existing work items are ranked by their ID, the ranks must not end with a 0
+UPDATE work_items SET rank = lpad(id::text, 12, '0') || 'i';
+ALTER TABLE work_items ALTER COLUMN rank SET NOT NULL;
+CREATE INDEX work_items_rank_idx ON work_items (rank);
@@ -0,0 +1,3 @@
+-- the secret webhook deliveries of a tracker are verified with, trackers
+-- without a secret don't accept webhooks
+ALTER TABLE trackers ADD COLUMN webhook_secret text;
@@ -0,0 +1,5 @@
+-- the sync state of tracker queries, scheduled runs only fetch the items
+-- updated since the previous run
+ALTER TABLE tracker_queries ADD COLUMN last_run_at timestamp with time zone;
+ALTER TABLE tracker_queries ADD COLUMN last_updated_at timestamp with time zone;
+ALTER TABLE tracker_queries ADD COLUMN etag text;
@@ -0,0 +1,16 @@
+-- every scheduled run of a tracker query is recorded with the numbers of
+-- fetched and imported items and the errors that stopped it
+CREATE TABLE tracker_query_runs (
+    id bigserial primary key,
+    tracker_query_id bigint NOT NULL REFERENCES tracker_queries(id) ON DELETE CASCADE,
+    started_at timestamp with time zone NOT NULL,
+    finished_at timestamp with time zone,
+    fetched integer DEFAULT 0 NOT NULL,
+    created integer DEFAULT 0 NOT NULL,
+    updated integer DEFAULT 0 NOT NULL,
+    failed integer DEFAULT 0 NOT NULL,
+    errors jsonb DEFAULT '[]' NOT NULL,
+    rate_limit_waits integer DEFAULT 0 NOT NULL,
+    rate_limit_wait_seconds integer DEFAULT 0 NOT NULL
+);
+CREATE INDEX tracker_query_runs_tracker_query_id_started_at_idx ON tracker_query_runs (tracker_query_id, started_at);
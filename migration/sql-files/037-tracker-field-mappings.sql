@@ -0,0 +1,3 @@
+-- the mappings of the attributes of remote items to the fields of the
+-- imported work items, see remoteworkitem.FieldMappings
+ALTER TABLE trackers ADD COLUMN field_mappings jsonb;
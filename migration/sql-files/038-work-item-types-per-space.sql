@@ -0,0 +1,17 @@
+-- link types refer to the types of all spaces by name, so the names of work
+-- item types can't be referenced by foreign keys anymore
+ALTER TABLE work_item_link_types DROP CONSTRAINT work_item_link_types_source_type_name_fkey;
+ALTER TABLE work_item_link_types DROP CONSTRAINT work_item_link_types_target_type_name_fkey;
+
+-- work item types are identified by an ID, their names are unique within their
+-- space and system type names are unique across all spaces
+ALTER TABLE work_item_types DROP CONSTRAINT work_item_types_pkey;
+ALTER TABLE work_item_types ADD COLUMN id uuid PRIMARY KEY DEFAULT uuid_generate_v4() NOT NULL;
+CREATE UNIQUE INDEX work_item_types_name_idx ON work_item_types (name) WHERE space_id IS NULL;
+CREATE UNIQUE INDEX work_item_types_space_id_name_idx ON work_item_types (space_id, name) WHERE space_id IS NOT NULL;
+
+-- the space a work item belongs to, its type is a system type or a type of
+-- this space
+ALTER TABLE work_items ADD COLUMN space_id uuid REFERENCES spaces(id);
+UPDATE work_items SET space_id = wit.space_id FROM work_item_types wit WHERE work_items.type = wit.name AND wit.space_id IS NOT NULL;
+CREATE INDEX work_items_space_id_idx ON work_items (space_id);
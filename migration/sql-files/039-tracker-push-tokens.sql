@@ -0,0 +1,3 @@
+-- the token that authenticates the pushes of local changes to the remote
+-- items of a tracker, see remoteworkitem.Tracker
+ALTER TABLE trackers ADD COLUMN push_token text;
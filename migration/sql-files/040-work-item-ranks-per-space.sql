@@ -0,0 +1,3 @@
+-- ranks are allocated and compared within the backlog of a space
+DROP INDEX work_items_rank_idx;
+CREATE INDEX work_items_space_id_rank_idx ON work_items (space_id, rank);
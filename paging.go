@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/rest"
 	"github.com/goadesign/goa"
 	errs "github.com/pkg/errors"
@@ -114,6 +115,35 @@ func setPagingLinks(links *app.PagingLinks, path string, resultLen, offset, limi
 	links.Last = &last
 }
 
+// parseCursor decodes the page[cursor] parameter, a nil cursor means offset based paging was requested
+func parseCursor(cursorParam *string) (*gormsupport.Cursor, error) {
+	if cursorParam == nil {
+		return nil, nil
+	}
+	return gormsupport.DecodeCursor(*cursorParam)
+}
+
+// setCursorPagingLinks sets the paging links for a page fetched with a cursor.
+// Prev and Next are only set when there is such a page.
+func setCursorPagingLinks(links *app.PagingLinks, path string, limit int, cursors *gormsupport.PageCursors, additionalQuery ...string) {
+	format := func(cursor string) string {
+		query := append([]string{"page[cursor]=" + cursor, fmt.Sprintf("page[limit]=%d", limit)}, additionalQuery...)
+		return path + "?" + strings.Join(query, "&")
+	}
+	if cursors.Prev != nil {
+		prev := format(*cursors.Prev)
+		links.Prev = &prev
+	}
+	if cursors.Next != nil {
+		next := format(*cursors.Next)
+		links.Next = &next
+	}
+	first := format("")
+	links.First = &first
+	last := format(gormsupport.Cursor{Before: true}.Encode())
+	links.Last = &last
+}
+
 func buildAbsoluteURL(req *goa.RequestData) string {
 	return rest.AbsoluteURL(req, req.URL.Path)
 }
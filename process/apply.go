@@ -0,0 +1,120 @@
+package process
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
+	errs "github.com/pkg/errors"
+	satoriuuid "github.com/satori/go.uuid"
+)
+
+// Apply creates the work item types of the given template in the given space
+// and makes sure that the link types of the template exist. Each space gets
+// its own copies of the types, so that they can evolve independently. Types
+// that are available in the space already, like system types of the same
+// name, and existing link types are left unchanged. Link types are shared by
+// all spaces, they refer to the types by name.
+// returns BadParameterError or InternalError
+func Apply(ctx context.Context, spaceID satoriuuid.UUID, t Template, witRepo workitem.WorkItemTypeRepository, linkCatRepo link.WorkItemLinkCategoryRepository, linkTypeRepo link.WorkItemLinkTypeRepository) error {
+	for _, def := range t.Types {
+		_, err := witRepo.Load(ctx, &spaceID, def.Name)
+		switch errs.Cause(err).(type) {
+		case errors.NotFoundError:
+			if _, err := witRepo.Create(ctx, &spaceID, nil, def.Name, typeFields(t, def)); err != nil {
+				return errs.WithStack(err)
+			}
+		case nil:
+		default:
+			return errs.WithStack(err)
+		}
+	}
+	if len(t.LinkTypes) == 0 {
+		return nil
+	}
+
+	categoryID, err := systemLinkCategory(ctx, linkCatRepo)
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	existing, err := linkTypeRepo.List(ctx)
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	for _, def := range t.LinkTypes {
+		if hasLinkType(existing, def.Name, categoryID) {
+			continue
+		}
+		for _, typeName := range []string{def.SourceType, def.TargetType} {
+			if _, err := witRepo.Load(ctx, &spaceID, typeName); err != nil {
+				if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+					return errors.NewBadParameterError("linkTypes", typeName).Expected("the name of a type of the template or of a system type")
+				}
+				return errs.WithStack(err)
+			}
+		}
+		description := def.Description
+		if _, err := linkTypeRepo.Create(ctx, def.Name, &description, def.SourceType, def.TargetType, def.ForwardName, def.ReverseName, def.Topology, categoryID, nil); err != nil {
+			return errs.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// typeFields returns the fields of a work item type of the template: the
+// common fields of planner items, the states of the template and the fields
+// of the type definition. New work items start in the first state.
+func typeFields(t Template, def TypeDefinition) map[string]app.FieldDefinition {
+	stString := "string"
+	stUser := "user"
+	states := make([]interface{}, len(t.States))
+	for i, state := range t.States {
+		states[i] = state
+	}
+	fields := map[string]app.FieldDefinition{
+		workitem.SystemTitle:        {Type: &app.FieldType{Kind: "string"}, Required: true},
+		workitem.SystemDescription:  {Type: &app.FieldType{Kind: "markup"}, Required: false},
+		workitem.SystemCreator:      {Type: &app.FieldType{Kind: "user"}, Required: true},
+		workitem.SystemRemoteItemID: {Type: &app.FieldType{Kind: "string"}, Required: false},
+		workitem.SystemCreatedAt:    {Type: &app.FieldType{Kind: "instant"}, Required: false},
+		workitem.SystemIteration:    {Type: &app.FieldType{Kind: "iteration"}, Required: false},
+		workitem.SystemAssignees:    {Type: &app.FieldType{Kind: "list", ComponentType: &stUser}, Required: false},
+		workitem.SystemState:        {Type: &app.FieldType{Kind: "enum", BaseType: &stString, Values: states}, Required: true, Default: states[0]},
+	}
+	for name, field := range def.Fields {
+		fields[name] = field
+	}
+	return fields
+}
+
+// systemLinkCategory returns the ID of the system link category, creating
+// the category if needed
+func systemLinkCategory(ctx context.Context, linkCatRepo link.WorkItemLinkCategoryRepository) (satoriuuid.UUID, error) {
+	categories, err := linkCatRepo.List(ctx)
+	if err != nil {
+		return satoriuuid.Nil, errs.WithStack(err)
+	}
+	for _, cat := range categories.Data {
+		if cat.Attributes.Name != nil && *cat.Attributes.Name == link.SystemWorkItemLinkCategorySystem {
+			return satoriuuid.FromStringOrNil(*cat.ID), nil
+		}
+	}
+	name := link.SystemWorkItemLinkCategorySystem
+	description := "The system category is reserved for link types that are to be manipulated by the system only."
+	cat, err := linkCatRepo.Create(ctx, &name, &description)
+	if err != nil {
+		return satoriuuid.Nil, errs.WithStack(err)
+	}
+	return satoriuuid.FromStringOrNil(*cat.Data.ID), nil
+}
+
+func hasLinkType(linkTypes *app.WorkItemLinkTypeList, name string, categoryID satoriuuid.UUID) bool {
+	for _, lt := range linkTypes.Data {
+		if lt.Attributes.Name != nil && *lt.Attributes.Name == name && lt.Relationships.LinkCategory.Data.ID == categoryID.String() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package process
+
+import (
+	"fmt"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/workitem/link"
+)
+
+// Names of the built-in process templates
+const (
+	TemplateScrum  = "Scrum"
+	TemplateKanban = "Kanban"
+	TemplateAgile  = "Agile"
+)
+
+func init() {
+	for _, t := range []Template{scrum(), kanban(), agile()} {
+		if err := Register(t); err != nil {
+			panic(fmt.Sprintf("invalid built-in process template %s: %v", t.Name, err))
+		}
+	}
+}
+
+func optionalField(kind string) app.FieldDefinition {
+	return app.FieldDefinition{Type: &app.FieldType{Kind: kind}, Required: false}
+}
+
+func scrum() Template {
+	return Template{
+		Name:        TemplateScrum,
+		Description: "Product backlog items are broken down into tasks that are worked on in sprints",
+		States:      []string{"new", "approved", "committed", "done", "removed"},
+		Types: []TypeDefinition{
+			{Name: "productbacklogitem", Fields: map[string]app.FieldDefinition{"scrum.effort": optionalField("float")}},
+			{Name: "sprinttask", Fields: map[string]app.FieldDefinition{"scrum.remaining_work": optionalField("float")}},
+			{Name: "impediment"},
+		},
+		LinkTypes: []LinkTypeDefinition{
+			{
+				Name:        "Backlog item task",
+				Description: "A product backlog item is broken down into sprint tasks.",
+				Topology:    link.TopologyTree,
+				ForwardName: "has task",
+				ReverseName: "is task of",
+				SourceType:  "productbacklogitem",
+				TargetType:  "sprinttask",
+			},
+			{
+				Name:        "Impediment",
+				Description: "An impediment keeps the team from working on a product backlog item.",
+				Topology:    link.TopologyNetwork,
+				ForwardName: "impedes",
+				ReverseName: "is impeded by",
+				SourceType:  "impediment",
+				TargetType:  "productbacklogitem",
+			},
+		},
+	}
+}
+
+func kanban() Template {
+	return Template{
+		Name:        TemplateKanban,
+		Description: "Cards flow through the columns of a board",
+		States:      []string{"backlog", "ready", "in progress", "review", "done"},
+		Types: []TypeDefinition{
+			{Name: "kanbancard"},
+		},
+		LinkTypes: []LinkTypeDefinition{
+			{
+				Name:        "Card dependency",
+				Description: "A card cannot be done before the cards it depends on.",
+				Topology:    link.TopologyDependency,
+				ForwardName: "depends on",
+				ReverseName: "is dependency of",
+				SourceType:  "kanbancard",
+				TargetType:  "kanbancard",
+			},
+		},
+	}
+}
+
+func agile() Template {
+	return Template{
+		Name:        TemplateAgile,
+		Description: "Epics are broken down into user stories and stories into tasks, defects are tracked separately",
+		States:      []string{"new", "active", "resolved", "closed"},
+		Types: []TypeDefinition{
+			{Name: "epic"},
+			{Name: "agilestory", Fields: map[string]app.FieldDefinition{"agile.story_points": optionalField("integer")}},
+			{Name: "agiletask", Fields: map[string]app.FieldDefinition{"agile.remaining_work": optionalField("float")}},
+			{Name: "defect"},
+		},
+		LinkTypes: []LinkTypeDefinition{
+			{
+				Name:        "Epic story",
+				Description: "An epic is broken down into user stories.",
+				Topology:    link.TopologyTree,
+				ForwardName: "parent of",
+				ReverseName: "child of",
+				SourceType:  "epic",
+				TargetType:  "agilestory",
+			},
+			{
+				Name:        "Story task",
+				Description: "A user story is broken down into tasks.",
+				Topology:    link.TopologyTree,
+				ForwardName: "parent of",
+				ReverseName: "child of",
+				SourceType:  "agilestory",
+				TargetType:  "agiletask",
+			},
+			{
+				Name:        "Defect story",
+				Description: "A defect affects a user story.",
+				Topology:    link.TopologyNetwork,
+				ForwardName: "affects",
+				ReverseName: "is affected by",
+				SourceType:  "defect",
+				TargetType:  "agilestory",
+			},
+		},
+	}
+}
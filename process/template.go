@@ -0,0 +1,184 @@
+package process
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
+	errs "github.com/pkg/errors"
+)
+
+// Template bundles the work item types, their states and the link types of
+// a development process like Scrum or Kanban
+type Template struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// States are the values of the system.state field of all types of the template
+	States    []string             `json:"states"`
+	Types     []TypeDefinition     `json:"types"`
+	LinkTypes []LinkTypeDefinition `json:"linkTypes"`
+}
+
+// TypeDefinition describes a work item type of a process template. Besides
+// the given fields, the type gets the common fields of planner items.
+type TypeDefinition struct {
+	Name   string                         `json:"name"`
+	Fields map[string]app.FieldDefinition `json:"fields"`
+}
+
+// LinkTypeDefinition describes a work item link type of a process template.
+// The source and target types are types of the template or system types.
+type LinkTypeDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Topology    string `json:"topology"`
+	ForwardName string `json:"forwardName"`
+	ReverseName string `json:"reverseName"`
+	SourceType  string `json:"sourceType"`
+	TargetType  string `json:"targetType"`
+}
+
+// typeNamePattern is the pattern the API requires for work item type names
+var typeNamePattern = regexp.MustCompile(`^[\p{L}.]+$`)
+
+var (
+	templatesMutex sync.RWMutex
+	templates      = map[string]Template{}
+)
+
+// Register makes the given template available for new spaces.
+// returns BadParameterError
+func Register(t Template) error {
+	if err := t.validate(); err != nil {
+		return errs.WithStack(err)
+	}
+	templatesMutex.Lock()
+	defer templatesMutex.Unlock()
+	if _, exists := templates[t.Name]; exists {
+		return errors.NewBadParameterError("name", t.Name).Expected("the name of a process template that is not registered yet")
+	}
+	templates[t.Name] = t
+	return nil
+}
+
+// Import reads a template from its JSON representation and registers it
+// returns BadParameterError
+func Import(r io.Reader) (*Template, error) {
+	t := Template{}
+	if err := json.NewDecoder(r).Decode(&t); err != nil {
+		return nil, errors.NewBadParameterError("template", err.Error()).Expected("a process template in JSON")
+	}
+	if err := Register(t); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	return &t, nil
+}
+
+// ImportDir imports all templates from the *.json files in the given directory
+func ImportDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return errs.WithStack(err)
+		}
+		_, err = Import(f)
+		f.Close()
+		if err != nil {
+			return errs.Wrapf(err, "failed to import process template from %s", file)
+		}
+	}
+	return nil
+}
+
+// Lookup returns the registered template with the given name
+// returns NotFoundError
+func Lookup(name string) (*Template, error) {
+	templatesMutex.RLock()
+	defer templatesMutex.RUnlock()
+	t, ok := templates[name]
+	if !ok {
+		return nil, errors.NewNotFoundError("process template", name)
+	}
+	return &t, nil
+}
+
+// List returns all registered templates ordered by name
+func List() []Template {
+	templatesMutex.RLock()
+	defer templatesMutex.RUnlock()
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]Template, len(names))
+	for i, name := range names {
+		result[i] = templates[name]
+	}
+	return result
+}
+
+func (t Template) validate() error {
+	if t.Name == "" {
+		return errors.NewBadParameterError("name", t.Name).Expected("not empty")
+	}
+	if len(t.States) == 0 {
+		return errors.NewBadParameterError("states", t.States).Expected("at least one state")
+	}
+	states := map[string]bool{}
+	for i, state := range t.States {
+		if state == "" || states[state] {
+			return errors.NewBadParameterError(fmt.Sprintf("states[%d]", i), state).Expected("a unique, non empty state")
+		}
+		states[state] = true
+	}
+	if len(t.Types) == 0 {
+		return errors.NewBadParameterError("types", t.Types).Expected("at least one work item type")
+	}
+	types := map[string]bool{}
+	for i, typ := range t.Types {
+		if !typeNamePattern.MatchString(typ.Name) || types[typ.Name] {
+			return errors.NewBadParameterError(fmt.Sprintf("types[%d].name", i), typ.Name).Expected("a unique name matching " + typeNamePattern.String())
+		}
+		if _, ok := typ.Fields[workitem.SystemState]; ok {
+			return errors.NewBadParameterError(fmt.Sprintf("types[%d].fields", i), workitem.SystemState).Expected("the states of the template instead")
+		}
+		for name, field := range typ.Fields {
+			if field.Type == nil {
+				return errors.NewBadParameterError(fmt.Sprintf("types[%d].fields.%s.type", i, name), nil).Expected("not nil")
+			}
+		}
+		types[typ.Name] = true
+	}
+	for i, lt := range t.LinkTypes {
+		prefix := fmt.Sprintf("linkTypes[%d].", i)
+		for attribute, value := range map[string]string{
+			"name":        lt.Name,
+			"forwardName": lt.ForwardName,
+			"reverseName": lt.ReverseName,
+			"sourceType":  lt.SourceType,
+			"targetType":  lt.TargetType,
+		} {
+			if value == "" {
+				return errors.NewBadParameterError(prefix+attribute, value).Expected("not empty")
+			}
+		}
+		if err := link.CheckValidTopology(lt.Topology); err != nil {
+			return errors.NewBadParameterError(prefix+"topology", lt.Topology).Expected(link.TopologyNetwork + "|" + link.TopologyDirectedNetwork + "|" + link.TopologyDependency + "|" + link.TopologyTree)
+		}
+	}
+	return nil
+}
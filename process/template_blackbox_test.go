@@ -0,0 +1,74 @@
+package process_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/process"
+	"github.com/almighty/almighty-core/resource"
+	errs "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinTemplates(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	for _, name := range []string{process.TemplateScrum, process.TemplateKanban, process.TemplateAgile} {
+		template, err := process.Lookup(name)
+		require.Nil(t, err, name)
+		assert.Equal(t, name, template.Name)
+		assert.NotEmpty(t, template.States, name)
+		assert.NotEmpty(t, template.Types, name)
+	}
+	_, err := process.Lookup("Waterfall")
+	assert.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+
+	names := []string{}
+	for _, template := range process.List() {
+		names = append(names, template.Name)
+	}
+	for _, name := range []string{process.TemplateAgile, process.TemplateKanban, process.TemplateScrum} {
+		assert.Contains(t, names, name)
+	}
+}
+
+func TestImportTemplate(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	template, err := process.Import(strings.NewReader(`{
+		"name": "Import test",
+		"states": ["todo", "done"],
+		"types": [{"name": "importtask", "fields": {"import.size": {"type": {"kind": "integer"}, "required": false}}}],
+		"linkTypes": [{"name": "Import task dependency", "topology": "dependency", "forwardName": "depends on",
+			"reverseName": "is dependency of", "sourceType": "importtask", "targetType": "importtask"}]
+	}`))
+	require.Nil(t, err)
+	assert.Equal(t, []string{"todo", "done"}, template.States)
+	require.Len(t, template.Types, 1)
+	assert.Equal(t, "integer", template.Types[0].Fields["import.size"].Type.Kind)
+	imported, err := process.Lookup("Import test")
+	require.Nil(t, err)
+	assert.Equal(t, "importtask", imported.Types[0].Name)
+
+	for name, definition := range map[string]string{
+		"no JSON":            `states: [todo]`,
+		"no name":            `{"states": ["todo"], "types": [{"name": "importtask"}]}`,
+		"existing name":      `{"name": "Scrum", "states": ["todo"], "types": [{"name": "importtask"}]}`,
+		"no states":          `{"name": "Invalid", "types": [{"name": "importtask"}]}`,
+		"duplicate state":    `{"name": "Invalid", "states": ["todo", "todo"], "types": [{"name": "importtask"}]}`,
+		"no types":           `{"name": "Invalid", "states": ["todo"]}`,
+		"invalid type name":  `{"name": "Invalid", "states": ["todo"], "types": [{"name": "import task"}]}`,
+		"state field":        `{"name": "Invalid", "states": ["todo"], "types": [{"name": "importtask", "fields": {"system.state": {"type": {"kind": "string"}}}}]}`,
+		"invalid topology":   `{"name": "Invalid", "states": ["todo"], "types": [{"name": "importtask"}], "linkTypes": [{"name": "l", "topology": "star", "forwardName": "f", "reverseName": "r", "sourceType": "importtask", "targetType": "importtask"}]}`,
+		"link without names": `{"name": "Invalid", "states": ["todo"], "types": [{"name": "importtask"}], "linkTypes": [{"name": "l", "topology": "tree", "sourceType": "importtask", "targetType": "importtask"}]}`,
+	} {
+		_, err := process.Import(strings.NewReader(definition))
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err), name)
+	}
+	_, err = process.Lookup("Invalid")
+	assert.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+}
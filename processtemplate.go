@@ -0,0 +1,60 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/process"
+	"github.com/goadesign/goa"
+)
+
+// APIStringTypeProcessTemplates is the JSONAPI type of process templates
+const APIStringTypeProcessTemplates = "processtemplates"
+
+// ProcesstemplateController implements the processtemplate resource.
+type ProcesstemplateController struct {
+	*goa.Controller
+}
+
+// NewProcesstemplateController creates a processtemplate controller.
+func NewProcesstemplateController(service *goa.Service) *ProcesstemplateController {
+	return &ProcesstemplateController{Controller: service.NewController("ProcesstemplateController")}
+}
+
+// List runs the list action.
+func (c *ProcesstemplateController) List(ctx *app.ListProcesstemplateContext) error {
+	res := &app.ProcessTemplateList{
+		Data: ConvertProcessTemplates(process.List()),
+	}
+	return ctx.OK(res)
+}
+
+// ConvertProcessTemplates converts between internal and external REST representation
+func ConvertProcessTemplates(templates []process.Template) []*app.ProcessTemplate {
+	var ts = []*app.ProcessTemplate{}
+	for _, t := range templates {
+		ts = append(ts, ConvertProcessTemplate(t))
+	}
+	return ts
+}
+
+// ConvertProcessTemplate converts between internal and external REST representation
+func ConvertProcessTemplate(t process.Template) *app.ProcessTemplate {
+	description := t.Description
+	attributes := &app.ProcessTemplateAttributes{
+		Name:          t.Name,
+		Description:   &description,
+		States:        t.States,
+		WorkItemTypes: []string{},
+		LinkTypes:     []string{},
+	}
+	for _, wit := range t.Types {
+		attributes.WorkItemTypes = append(attributes.WorkItemTypes, wit.Name)
+	}
+	for _, lt := range t.LinkTypes {
+		attributes.LinkTypes = append(attributes.LinkTypes, lt.Name)
+	}
+	return &app.ProcessTemplate{
+		Type:       APIStringTypeProcessTemplates,
+		ID:         t.Name,
+		Attributes: attributes,
+	}
+}
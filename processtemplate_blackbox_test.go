@@ -0,0 +1,33 @@
+package main_test
+
+import (
+	"testing"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/process"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/goadesign/goa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListProcessTemplatesOK(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	svc := goa.New("ProcessTemplate-Service")
+	ctrl := NewProcesstemplateController(svc)
+
+	_, list := test.ListProcesstemplateOK(t, svc.Context, svc, ctrl)
+	require.NotNil(t, list)
+	for _, template := range list.Data {
+		if template.ID == process.TemplateScrum {
+			assert.Equal(t, APIStringTypeProcessTemplates, template.Type)
+			assert.Equal(t, []string{"new", "approved", "committed", "done", "removed"}, template.Attributes.States)
+			assert.Equal(t, []string{"productbacklogitem", "sprinttask", "impediment"}, template.Attributes.WorkItemTypes)
+			assert.Equal(t, []string{"Backlog item task", "Impediment"}, template.Attributes.LinkTypes)
+			return
+		}
+	}
+	t.Errorf("the %s process template is not listed", process.TemplateScrum)
+}
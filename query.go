@@ -0,0 +1,232 @@
+package main
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/query"
+	"github.com/almighty/almighty-core/rest"
+	"github.com/almighty/almighty-core/savedquery"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// QueryController implements the query resource.
+type QueryController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewQueryController creates a query controller.
+func NewQueryController(service *goa.Service, db application.DB) *QueryController {
+	return &QueryController{Controller: service.NewController("QueryController"), db: db}
+}
+
+// Show runs the show action.
+func (c *QueryController) Show(ctx *app.ShowQueryContext) error {
+	identity, err := contextIdentityID(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		q, err := loadVisibleQuery(ctx, appl, ctx.ID, identity)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.QuerySingle{
+			Data: ConvertQuery(ctx.RequestData, q),
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Update runs the update action.
+func (c *QueryController) Update(ctx *app.UpdateQueryContext) error {
+	identity, err := contextIdentityID(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	attributes := ctx.Payload.Data.Attributes
+	if attributes.Version == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.version", nil).Expected("not nil"))
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		q, err := loadVisibleQuery(ctx, appl, ctx.ID, identity)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if !uuid.Equal(q.Creator, identity) {
+			// need to use the goa.NewErrorClass() func as there is no native support for 403 in goa
+			return jsonapi.JSONErrorResponse(ctx, goa.NewErrorClass("forbidden", 403)("User is not the query creator"))
+		}
+		q.Version = *attributes.Version
+		if attributes.Title != nil {
+			q.Title = *attributes.Title
+		}
+		if attributes.Filter != nil {
+			q.Filter = *attributes.Filter
+		}
+		if attributes.Shared != nil {
+			q.Shared = *attributes.Shared
+		}
+		q, err = appl.Queries().Save(ctx, q)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.QuerySingle{
+			Data: ConvertQuery(ctx.RequestData, q),
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Delete runs the delete action.
+func (c *QueryController) Delete(ctx *app.DeleteQueryContext) error {
+	identity, err := contextIdentityID(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		q, err := loadVisibleQuery(ctx, appl, ctx.ID, identity)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if !uuid.Equal(q.Creator, identity) {
+			return jsonapi.JSONErrorResponse(ctx, goa.NewErrorClass("forbidden", 403)("User is not the query creator"))
+		}
+		err = appl.Queries().Delete(ctx, q.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// Execute runs the execute action, it lists the work items matching the filter of the query.
+func (c *QueryController) Execute(ctx *app.ExecuteQueryContext) error {
+	identity, err := contextIdentityID(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	cursor, err := parseCursor(ctx.PageCursor)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	offset, limit := computePagingLimts(ctx.PageOffset, ctx.PageLimit)
+	return application.Transactional(c.db, func(appl application.Application) error {
+		q, err := loadVisibleQuery(ctx, appl, ctx.ID, identity)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		exp, err := query.Parse(&q.Filter)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("could not parse filter", err))
+		}
+		// only the work items of the space of the query are listed
+		exp = criteria.And(exp, criteria.Equals(criteria.Field("SpaceID"), criteria.Literal(q.SpaceID)))
+		result, tc, cursors, err := appl.WorkItems().List(ctx.Context, exp, nil, &offset, &limit, cursor)
+		count := int(tc)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		response := app.WorkItem2List{
+			Links: &app.PagingLinks{},
+			Meta:  &app.WorkItemListResponseMeta{TotalCount: count},
+			Data:  ConvertWorkItems(ctx.RequestData, result),
+		}
+		if cursor != nil {
+			setCursorPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), limit, cursors)
+		} else {
+			setPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), len(result), offset, limit, count)
+		}
+		return ctx.OK(&response)
+	})
+}
+
+// contextIdentityID returns the ID of the identity of the current user
+func contextIdentityID(ctx context.Context) (uuid.UUID, error) {
+	identity, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return uuid.FromString(identity)
+}
+
+// loadVisibleQuery loads the query with the given id. Queries of other users
+// that are not shared are reported as not found.
+func loadVisibleQuery(ctx context.Context, appl application.Application, id string, identity uuid.UUID) (*savedquery.Query, error) {
+	queryID, err := uuid.FromString(id)
+	if err != nil {
+		return nil, errors.NewNotFoundError("query", id)
+	}
+	q, err := appl.Queries().Load(ctx, queryID)
+	if err != nil {
+		return nil, err
+	}
+	if !q.VisibleTo(identity) {
+		return nil, errors.NewNotFoundError("query", id)
+	}
+	return q, nil
+}
+
+// ConvertQueries converts between internal and external REST representation
+func ConvertQueries(request *goa.RequestData, queries []*savedquery.Query) []*app.Query {
+	var qs = []*app.Query{}
+	for _, q := range queries {
+		qs = append(qs, ConvertQuery(request, q))
+	}
+	return qs
+}
+
+// ConvertQuery converts between internal and external REST representation
+func ConvertQuery(request *goa.RequestData, q *savedquery.Query) *app.Query {
+	spaceType := "spaces"
+	spaceID := q.SpaceID.String()
+
+	selfURL := rest.AbsoluteURL(request, app.QueryHref(q.ID))
+	workItemsURL := rest.AbsoluteURL(request, app.QueryHref(q.ID)+"/workitems")
+	spaceSelfURL := rest.AbsoluteURL(request, app.SpaceHref(spaceID))
+
+	return &app.Query{
+		Type: savedquery.APIStringTypeQueries,
+		ID:   &q.ID,
+		Attributes: &app.QueryAttributes{
+			Title:     &q.Title,
+			Filter:    &q.Filter,
+			Shared:    &q.Shared,
+			Version:   &q.Version,
+			CreatedAt: &q.CreatedAt,
+			UpdatedAt: &q.UpdatedAt,
+		},
+		Relationships: &app.QueryRelations{
+			Space: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: &spaceType,
+					ID:   &spaceID,
+				},
+				Links: &app.GenericLinks{
+					Self: &spaceSelfURL,
+				},
+			},
+			Creator: &app.RelationGeneric{
+				Data: ConvertUserSimple(request, q.Creator.String()),
+			},
+			Workitems: &app.RelationGeneric{
+				Links: &app.GenericLinks{
+					Related: &workItemsURL,
+				},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
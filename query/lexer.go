@@ -0,0 +1,234 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tokenKind classifies the tokens produced by the lexer
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenLeftBracket
+	tokenRightBracket
+	tokenComma
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of input"
+	case tokenIdentifier:
+		return "identifier"
+	case tokenString:
+		return "string"
+	case tokenNumber:
+		return "number"
+	case tokenOperator:
+		return "operator"
+	case tokenLeftParen:
+		return "'('"
+	case tokenRightParen:
+		return "')'"
+	case tokenLeftBracket:
+		return "'['"
+	case tokenRightBracket:
+		return "']'"
+	case tokenComma:
+		return "','"
+	}
+	return "unknown token"
+}
+
+// token is a single lexical element of a filter expression.
+// Pos is the zero-based byte offset of the token in the input.
+type token struct {
+	kind  tokenKind
+	text  string // the raw text as found in the input
+	value string // the unquoted value for strings, the text otherwise
+	pos   int
+}
+
+// keyword reports whether the token is the given (case insensitive) keyword
+func (t token) keyword(kw string) bool {
+	return t.kind == tokenIdentifier && strings.EqualFold(t.text, kw)
+}
+
+// SyntaxError is returned when a filter expression cannot be parsed.
+// Pos is the zero-based byte offset in the input at which the problem was detected.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+// Error implements the error interface
+func (err SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", err.Pos, err.Msg)
+}
+
+func newSyntaxError(pos int, format string, args ...interface{}) SyntaxError {
+	return SyntaxError{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// operators lists all multi and single character operators known to the lexer,
+// longest first so that "<=" is not lexed as "<" followed by "="
+var operators = []string{"==", "!=", "<=", ">=", "<", ">", "="}
+
+// lex splits the input into tokens. The returned slice is always terminated by a tokenEOF token.
+func lex(input string) ([]token, error) {
+	var result []token
+	pos := 0
+	for pos < len(input) {
+		r, size := utf8.DecodeRuneInString(input[pos:])
+		switch {
+		case unicode.IsSpace(r):
+			pos += size
+		case r == '(':
+			result = append(result, token{tokenLeftParen, "(", "(", pos})
+			pos++
+		case r == ')':
+			result = append(result, token{tokenRightParen, ")", ")", pos})
+			pos++
+		case r == '[':
+			result = append(result, token{tokenLeftBracket, "[", "[", pos})
+			pos++
+		case r == ']':
+			result = append(result, token{tokenRightBracket, "]", "]", pos})
+			pos++
+		case r == ',':
+			result = append(result, token{tokenComma, ",", ",", pos})
+			pos++
+		case r == '"' || r == '\'':
+			tok, err := lexString(input, pos)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, tok)
+			pos += len(tok.text)
+		case r == '-' || r == '+' || unicode.IsDigit(r):
+			tok, err := lexNumber(input, pos)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, tok)
+			pos += len(tok.text)
+		case isIdentifierStart(r):
+			end := pos + size
+			for end < len(input) {
+				r, size := utf8.DecodeRuneInString(input[end:])
+				if !isIdentifierPart(r) {
+					break
+				}
+				end += size
+			}
+			text := input[pos:end]
+			result = append(result, token{tokenIdentifier, text, text, pos})
+			pos = end
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(input[pos:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, newSyntaxError(pos, "unexpected character %q", r)
+			}
+			result = append(result, token{tokenOperator, op, op, pos})
+			pos += len(op)
+		}
+	}
+	result = append(result, token{tokenEOF, "", "", len(input)})
+	return result, nil
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentifierPart(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// lexString reads a single or double quoted string starting at pos.
+// Backslash escapes follow the Go string literal rules.
+func lexString(input string, pos int) (token, error) {
+	quote := input[pos]
+	end := pos + 1
+	for end < len(input) {
+		switch input[end] {
+		case '\\':
+			end += 2
+			continue
+		case quote:
+			raw := input[pos : end+1]
+			value, err := unquote(raw)
+			if err != nil {
+				return token{}, newSyntaxError(pos, "invalid string literal %s", raw)
+			}
+			return token{tokenString, raw, value, pos}, nil
+		}
+		end++
+	}
+	return token{}, newSyntaxError(pos, "unterminated string literal")
+}
+
+// unquote converts a quoted string into its value, single quoted strings are treated like double quoted ones
+func unquote(raw string) (string, error) {
+	if raw[0] == '\'' {
+		body := raw[1 : len(raw)-1]
+		converted := make([]byte, 0, len(raw))
+		converted = append(converted, '"')
+		for i := 0; i < len(body); i++ {
+			switch {
+			case body[i] == '\\' && i+1 < len(body) && body[i+1] == '\'':
+				converted = append(converted, '\'')
+				i++
+			case body[i] == '\\' && i+1 < len(body):
+				converted = append(converted, body[i], body[i+1])
+				i++
+			case body[i] == '"':
+				converted = append(converted, '\\', '"')
+			default:
+				converted = append(converted, body[i])
+			}
+		}
+		raw = string(append(converted, '"'))
+	}
+	return strconv.Unquote(raw)
+}
+
+// lexNumber reads an optionally signed integer or floating point number starting at pos
+func lexNumber(input string, pos int) (token, error) {
+	end := pos
+	if input[end] == '-' || input[end] == '+' {
+		end++
+	}
+	digits := 0
+	for end < len(input) && (unicode.IsDigit(rune(input[end])) || input[end] == '.' || input[end] == 'e' || input[end] == 'E' ||
+		((input[end] == '-' || input[end] == '+') && (input[end-1] == 'e' || input[end-1] == 'E'))) {
+		if unicode.IsDigit(rune(input[end])) {
+			digits++
+		}
+		end++
+	}
+	text := input[pos:end]
+	if digits == 0 {
+		return token{}, newSyntaxError(pos, "invalid number %q", text)
+	}
+	if _, err := strconv.ParseFloat(text, 64); err != nil {
+		return token{}, newSyntaxError(pos, "invalid number %q", text)
+	}
+	return token{tokenNumber, text, text, pos}, nil
+}
@@ -0,0 +1,308 @@
+// Package query implements the textual filter language used by the list endpoints.
+//
+// A filter is a boolean expression over work item fields, for example
+//
+//	system.state != "closed" and (system.assignees in ["alice","bob"] or priority > 2)
+//
+// The grammar, in order of increasing precedence:
+//
+//	expression = term { "or" term }
+//	term       = factor { "and" factor }
+//	factor     = "not" factor | "(" expression ")" | comparison | "true" | "false"
+//	comparison = field operator value | field "is" [ "not" ] "null"
+//	operator   = "==" | "=" | "!=" | "<" | "<=" | ">" | ">=" | "in" | "contains"
+//	value      = literal | "[" [ literal { "," literal } ] "]"
+//	literal    = string | number | "true" | "false" | "null"
+//
+// Keywords are case insensitive, strings may be single or double quoted. The
+// parser compiles the text into a criteria.Expression tree. For backwards
+// compatibility a JSON object of the form {"attribute1":value1,"attribute2":value2}
+// is still accepted and interpreted as "attribute1 == value1 and attribute2 == value2".
+package query
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/pkg/errors"
+)
+
+// comparisons maps the supported comparison operators to the constructors of their expressions
+var comparisons = map[string]func(left criteria.Expression, right criteria.Expression) criteria.Expression{
+	"==":       criteria.Equals,
+	"=":        criteria.Equals,
+	"!=":       criteria.NotEquals,
+	"<":        criteria.LessThan,
+	"<=":       criteria.LessOrEqual,
+	">":        criteria.GreaterThan,
+	">=":       criteria.GreaterOrEqual,
+	"in":       criteria.In,
+	"contains": criteria.Substring,
+}
+
+// Parse parses a filter expression into a criteria.Expression
+// returns the expression "true" if the filter is nil or empty
+func Parse(exp *string) (criteria.Expression, error) {
+	if exp == nil || len(strings.TrimSpace(*exp)) == 0 {
+		return criteria.Literal(true), nil
+	}
+	if strings.HasPrefix(strings.TrimSpace(*exp), "{") {
+		return parseJSON(*exp)
+	}
+	tokens, err := lex(*exp)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	p := parser{tokens: tokens}
+	result, err := p.expression()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, errors.WithStack(newSyntaxError(p.peek().pos, "unexpected %s", describe(p.peek())))
+	}
+	return result, nil
+}
+
+// parseJSON parses strings of the form { "attribute1":value1,"attribute2":value2} into an expression of the form "attribute1=value1 and attribute2=value2"
+func parseJSON(exp string) (criteria.Expression, error) {
+	var unmarshalled map[string]interface{}
+	err := json.Unmarshal([]byte(exp), &unmarshalled)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// iterate in a stable order so that the same filter always yields the same expression
+	keys := make([]string, 0, len(unmarshalled))
+	for key := range unmarshalled {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var result criteria.Expression
+	for _, key := range keys {
+		current := criteria.Equals(criteria.Field(key), criteria.Literal(unmarshalled[key]))
+		if result == nil {
+			result = current
+		} else {
+			result = criteria.And(result, current)
+		}
+	}
+	if result == nil {
+		return criteria.Literal(true), nil
+	}
+	return result, nil
+}
+
+// parser is a recursive descent parser over the tokens produced by lex
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(kind tokenKind) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, newSyntaxError(t.pos, "expected %s but found %s", kind, describe(t))
+	}
+	return t, nil
+}
+
+func (p *parser) expression() (criteria.Expression, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().keyword("or") {
+		p.next()
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = criteria.Or(left, right)
+	}
+	return left, nil
+}
+
+func (p *parser) term() (criteria.Expression, error) {
+	left, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().keyword("and") {
+		p.next()
+		right, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		left = criteria.And(left, right)
+	}
+	return left, nil
+}
+
+func (p *parser) factor() (criteria.Expression, error) {
+	t := p.peek()
+	switch {
+	case t.keyword("not"):
+		p.next()
+		operand, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		return criteria.Not(operand), nil
+	case t.kind == tokenLeftParen:
+		p.next()
+		result, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRightParen); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case t.keyword("true"):
+		p.next()
+		return criteria.Literal(true), nil
+	case t.keyword("false"):
+		p.next()
+		return criteria.Literal(false), nil
+	case t.kind == tokenIdentifier && !isReserved(t):
+		return p.comparison()
+	}
+	return nil, newSyntaxError(t.pos, "expected field name, '(' or boolean but found %s", describe(t))
+}
+
+func (p *parser) comparison() (criteria.Expression, error) {
+	field := p.next()
+	op := p.next()
+	if op.kind != tokenOperator && op.kind != tokenIdentifier {
+		return nil, newSyntaxError(op.pos, "expected operator after field %s but found %s", field.text, describe(op))
+	}
+	if op.keyword("is") {
+		return p.isNull(field)
+	}
+	constructor, ok := comparisons[strings.ToLower(op.text)]
+	if !ok {
+		return nil, newSyntaxError(op.pos, "unsupported operator '%s'", op.text)
+	}
+	valuePos := p.peek().pos
+	isList := p.peek().kind == tokenLeftBracket
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	if op.keyword("in") && !isList {
+		return nil, newSyntaxError(valuePos, "expected a list after 'in'")
+	}
+	return constructor(criteria.Field(field.text), criteria.Literal(value)), nil
+}
+
+// isNull parses the remainder of "field is null" and "field is not null"
+func (p *parser) isNull(field token) (criteria.Expression, error) {
+	negate := false
+	if p.peek().keyword("not") {
+		p.next()
+		negate = true
+	}
+	t := p.next()
+	if !t.keyword("null") {
+		return nil, newSyntaxError(t.pos, "expected null but found %s", describe(t))
+	}
+	result := criteria.IsNull(criteria.Field(field.text))
+	if negate {
+		result = criteria.Not(result)
+	}
+	return result, nil
+}
+
+// value parses a single literal or a list of literals. Lists consisting only
+// of strings are returned as []string, other lists as []interface{}
+func (p *parser) value() (interface{}, error) {
+	if p.peek().kind != tokenLeftBracket {
+		return p.literal()
+	}
+	p.next()
+	values := []interface{}{}
+	allStrings := true
+	for p.peek().kind != tokenRightBracket {
+		if len(values) > 0 {
+			if _, err := p.expect(tokenComma); err != nil {
+				return nil, err
+			}
+		}
+		v, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		if _, isString := v.(string); !isString {
+			allStrings = false
+		}
+		values = append(values, v)
+	}
+	p.next()
+	if allStrings {
+		result := make([]string, len(values))
+		for i, v := range values {
+			result[i] = v.(string)
+		}
+		return result, nil
+	}
+	return values, nil
+}
+
+func (p *parser) literal() (interface{}, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokenString:
+		return t.value, nil
+	case t.kind == tokenNumber:
+		if i, err := strconv.Atoi(t.text); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, newSyntaxError(t.pos, "invalid number %q", t.text)
+		}
+		return f, nil
+	case t.keyword("true"):
+		return true, nil
+	case t.keyword("false"):
+		return false, nil
+	case t.keyword("null"):
+		return nil, nil
+	}
+	return nil, newSyntaxError(t.pos, "expected value but found %s", describe(t))
+}
+
+// reserved words cannot be used as field names
+var reserved = []string{"and", "or", "not", "in", "is", "null", "true", "false", "contains"}
+
+func isReserved(t token) bool {
+	for _, kw := range reserved {
+		if t.keyword(kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// describe renders a token for use in error messages
+func describe(t token) string {
+	if t.kind == tokenEOF {
+		return t.kind.String()
+	}
+	return "'" + t.text + "'"
+}
@@ -0,0 +1,137 @@
+package query_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/query"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEmpty(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	exp, err := query.Parse(nil)
+	require.Nil(t, err)
+	assert.Equal(t, criteria.Literal(true), exp)
+
+	empty := "   "
+	exp, err = query.Parse(&empty)
+	require.Nil(t, err)
+	assert.Equal(t, criteria.Literal(true), exp)
+}
+
+func TestParseLegacyJSON(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expectParse(t, `{"system.title":"foo", "system.creator":"bar"}`,
+		criteria.And(
+			criteria.Equals(criteria.Field("system.creator"), criteria.Literal("bar")),
+			criteria.Equals(criteria.Field("system.title"), criteria.Literal("foo"))))
+}
+
+func TestParseComparison(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expectParse(t, `system.state == "open"`, criteria.Equals(criteria.Field("system.state"), criteria.Literal("open")))
+	expectParse(t, `system.state = 'open'`, criteria.Equals(criteria.Field("system.state"), criteria.Literal("open")))
+	expectParse(t, `priority == 2`, criteria.Equals(criteria.Field("priority"), criteria.Literal(2)))
+	expectParse(t, `estimate == -2.5`, criteria.Equals(criteria.Field("estimate"), criteria.Literal(-2.5)))
+	expectParse(t, `blocked == TRUE`, criteria.Equals(criteria.Field("blocked"), criteria.Literal(true)))
+	expectParse(t, `system.assignees == ["alice", 'bob']`, criteria.Equals(criteria.Field("system.assignees"), criteria.Literal([]string{"alice", "bob"})))
+	expectParse(t, `numbers == [1, 2]`, criteria.Equals(criteria.Field("numbers"), criteria.Literal([]interface{}{1, 2})))
+	expectParse(t, `system.title == "say \"hi\""`, criteria.Equals(criteria.Field("system.title"), criteria.Literal(`say "hi"`)))
+	expectParse(t, `system.title == 'it\'s "here"'`, criteria.Equals(criteria.Field("system.title"), criteria.Literal(`it's "here"`)))
+}
+
+func TestParseOperators(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	state := criteria.Field("system.state")
+	expectParse(t, `system.state != "closed"`, criteria.NotEquals(state, criteria.Literal("closed")))
+	expectParse(t, `priority < 2`, criteria.LessThan(criteria.Field("priority"), criteria.Literal(2)))
+	expectParse(t, `priority <= 2`, criteria.LessOrEqual(criteria.Field("priority"), criteria.Literal(2)))
+	expectParse(t, `priority > 2`, criteria.GreaterThan(criteria.Field("priority"), criteria.Literal(2)))
+	expectParse(t, `priority >= 2`, criteria.GreaterOrEqual(criteria.Field("priority"), criteria.Literal(2)))
+	expectParse(t, `system.state in ["new", "open"]`, criteria.In(state, criteria.Literal([]string{"new", "open"})))
+	expectParse(t, `system.title contains "foo"`, criteria.Substring(criteria.Field("system.title"), criteria.Literal("foo")))
+	expectParse(t, `system.iteration is null`, criteria.IsNull(criteria.Field("system.iteration")))
+	expectParse(t, `system.iteration IS NOT NULL`, criteria.Not(criteria.IsNull(criteria.Field("system.iteration"))))
+	expectParse(t, `not system.state == "open"`, criteria.Not(criteria.Equals(state, criteria.Literal("open"))))
+	expectParse(t, `not (a == 1 or b == 2)`, criteria.Not(criteria.Or(
+		criteria.Equals(criteria.Field("a"), criteria.Literal(1)),
+		criteria.Equals(criteria.Field("b"), criteria.Literal(2)))))
+	expectParse(t, `system.state != "closed" and (system.assignees in ["alice","bob"] or priority > 2)`,
+		criteria.And(
+			criteria.NotEquals(state, criteria.Literal("closed")),
+			criteria.Or(
+				criteria.In(criteria.Field("system.assignees"), criteria.Literal([]string{"alice", "bob"})),
+				criteria.GreaterThan(criteria.Field("priority"), criteria.Literal(2)))))
+}
+
+func TestParsePrecedence(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	a := func() criteria.Expression { return criteria.Equals(criteria.Field("a"), criteria.Literal(1)) }
+	b := func() criteria.Expression { return criteria.Equals(criteria.Field("b"), criteria.Literal(2)) }
+	c := func() criteria.Expression { return criteria.Equals(criteria.Field("c"), criteria.Literal(3)) }
+
+	expectParse(t, `a == 1 or b == 2 and c == 3`, criteria.Or(a(), criteria.And(b(), c())))
+	expectParse(t, `a == 1 AND b == 2 Or c == 3`, criteria.Or(criteria.And(a(), b()), c()))
+	expectParse(t, `(a == 1 or b == 2) and c == 3`, criteria.And(criteria.Or(a(), b()), c()))
+	expectParse(t, `a == 1 and b == 2 and c == 3`, criteria.And(criteria.And(a(), b()), c()))
+	expectParse(t, `true or a == 1`, criteria.Or(criteria.Literal(true), a()))
+}
+
+func TestParseSyntaxErrors(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expectSyntaxError(t, `system.state == "open`, 16)
+	expectSyntaxError(t, `system.state "open"`, 13)
+	expectSyntaxError(t, `(a == 1`, 7)
+	expectSyntaxError(t, `a == 1 b == 2`, 7)
+	expectSyntaxError(t, `a == 1 and`, 10)
+	expectSyntaxError(t, `a == # 1`, 5)
+	expectSyntaxError(t, `a == [1 2]`, 8)
+	expectSyntaxError(t, `and == 1`, 0)
+	expectSyntaxError(t, `a == -`, 5)
+	expectSyntaxError(t, `a in "x"`, 5)
+	expectSyntaxError(t, `a is empty`, 5)
+	expectSyntaxError(t, `not`, 3)
+}
+
+func expectParse(t *testing.T, input string, expected criteria.Expression) {
+	actual, err := query.Parse(&input)
+	require.Nil(t, err, "failed to parse %s", input)
+	assert.Equal(t, render(expected), render(actual), "unexpected result when parsing %s", input)
+}
+
+func expectSyntaxError(t *testing.T, input string, pos int) {
+	_, err := query.Parse(&input)
+	require.NotNil(t, err, "expected %s to fail", input)
+	syntaxErr, ok := errors.Cause(err).(query.SyntaxError)
+	require.True(t, ok, "expected a syntax error for %s but got %v", input, err)
+	assert.Equal(t, pos, syntaxErr.Pos, "unexpected error position for %s: %s", input, syntaxErr.Msg)
+}
+
+// render produces a parenthesized textual form of an expression so that trees can be compared without
+// tripping over parent pointers
+func render(exp criteria.Expression) string {
+	switch t := exp.(type) {
+	case *criteria.FieldExpression:
+		return t.FieldName
+	case *criteria.LiteralExpression:
+		return fmt.Sprintf("%#v", t.Value)
+	case *criteria.NotExpression:
+		return "(not " + render(t.Operand()) + ")"
+	case *criteria.IsNullExpression:
+		return "(" + render(t.Operand()) + " is null)"
+	case criteria.BinaryExpression:
+		return "(" + render(t.Left()) + " " + fmt.Sprintf("%T", t) + " " + render(t.Right()) + ")"
+	}
+	return fmt.Sprintf("%T", exp)
+}
@@ -0,0 +1,40 @@
+package query
+
+import (
+	"strings"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/pkg/errors"
+)
+
+// ParseSort parses a JSON-API style sort parameter like "-system.created_at,system.title"
+// into a list of sort keys. A leading "-" sorts the field in descending order.
+// returns an empty list if the parameter is nil or empty
+func ParseSort(sort *string) ([]criteria.OrderBy, error) {
+	result := []criteria.OrderBy{}
+	if sort == nil || len(strings.TrimSpace(*sort)) == 0 {
+		return result, nil
+	}
+	pos := 0
+	for _, key := range strings.Split(*sort, ",") {
+		start := pos
+		pos += len(key) + 1
+		trimmed := strings.TrimSpace(key)
+		start += strings.Index(key, trimmed)
+		descending := strings.HasPrefix(trimmed, "-")
+		name := strings.TrimPrefix(trimmed, "-")
+		if descending {
+			start++
+		}
+		if name == "" {
+			return nil, errors.WithStack(newSyntaxError(start, "expected field name"))
+		}
+		for i, r := range name {
+			if (i == 0 && !isIdentifierStart(r)) || !isIdentifierPart(r) {
+				return nil, errors.WithStack(newSyntaxError(start+i, "unexpected character %q in field name", r))
+			}
+		}
+		result = append(result, criteria.OrderBy{FieldName: name, Descending: descending})
+	}
+	return result, nil
+}
@@ -0,0 +1,43 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/query"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSort(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	result, err := query.ParseSort(nil)
+	require.Nil(t, err)
+	assert.Empty(t, result)
+
+	sort := "-system.created_at, system.title"
+	result, err = query.ParseSort(&sort)
+	require.Nil(t, err)
+	assert.Equal(t, []criteria.OrderBy{criteria.Descending("system.created_at"), criteria.Ascending("system.title")}, result)
+}
+
+func TestParseSortErrors(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	for input, pos := range map[string]int{
+		"system.title,":       13,
+		"-":                   1,
+		"system.title, -2abc": 15,
+		"system.title;foo":    12,
+		"system.title,,foo":   13,
+	} {
+		_, err := query.ParseSort(&input)
+		require.NotNil(t, err, "expected %s to fail", input)
+		syntaxErr, ok := errors.Cause(err).(query.SyntaxError)
+		require.True(t, ok)
+		assert.Equal(t, pos, syntaxErr.Pos, "unexpected error position for %s", input)
+	}
+}
@@ -20,6 +20,11 @@ type VersionConflictError struct {
 	simpleError
 }
 
+// UnauthorizedError means that a request could not be verified
+type UnauthorizedError struct {
+	simpleError
+}
+
 // BadParameterError means that a parameter was not as required
 type BadParameterError struct {
 	parameter string
@@ -0,0 +1,321 @@
+package remoteworkitem
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+)
+
+// Converters of field mappings
+const (
+	// ConverterString imports the remote value as it is
+	ConverterString = "string"
+	// ConverterMarkup imports the remote value as markup content
+	ConverterMarkup = "markup"
+	// ConverterList imports the remote value as a list, a source with a *
+	// imports the values of all elements of a remote list
+	ConverterList = "list"
+	// ConverterEnum imports the remote value as the field value it is mapped to
+	ConverterEnum = "enum"
+)
+
+// sourceWildcard matches the indexes of the elements of a remote list in the
+// source of a field mapping, e.g. "fields.components.*.name"
+const sourceWildcard = "*"
+
+// FieldMapping maps an attribute of the remote items to a field of the
+// imported work items
+type FieldMapping struct {
+	// Source is the key of the attribute in the flattened remote item, e.g.
+	// "fields.priority.name"
+	Source string `json:"source"`
+	// Field is the name of the field of the work item type
+	Field string `json:"field"`
+	// Converter is one of ConverterString, ConverterMarkup, ConverterList and
+	// ConverterEnum
+	Converter string `json:"converter"`
+	// Markup of the markup converter, rendering.SystemMarkupDefault if empty
+	Markup string `json:"markup,omitempty"`
+	// Values maps the remote values to the field values for the enum
+	// converter, remote values that are not mapped are not imported
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// FieldMappings define how the remote items of a tracker are imported. The
+// mappings extend the default mapping of the tracker type in WorkItemKeyMaps,
+// replacing the default mapping of the same fields.
+type FieldMappings struct {
+	// WorkItemType is the name of the type of the imported work items. They
+	// belong to no space, so this is the name of a system type.
+	WorkItemType string `json:"workItemType"`
+	// Fields are the mappings of the remote attributes to the fields
+	Fields []FieldMapping `json:"fields"`
+}
+
+// IsEmpty returns true if there are no field mappings
+func (m FieldMappings) IsEmpty() bool {
+	return len(m.Fields) == 0
+}
+
+// Value implements driver.Valuer
+func (m FieldMappings) Value() (driver.Value, error) {
+	if m.IsEmpty() {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
+
+// Scan implements sql.Scanner
+func (m *FieldMappings) Scan(src interface{}) error {
+	*m = FieldMappings{}
+	if src == nil {
+		return nil
+	}
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("Scan source was not []byte but %T", src)
+	}
+	return json.Unmarshal(b, m)
+}
+
+// Validate returns an error if the field mappings don't fit the fields of
+// their work item type: every field must exist, lists can only be imported
+// into list fields, markup only into markup fields and the mapped values of
+// enums must be valid values of their field. The remote item ID can't be
+// mapped, it identifies the work item of a remote item.
+// returns BadParameterError or InternalError
+func (m FieldMappings) Validate(db *gorm.DB) error {
+	if m.IsEmpty() {
+		return nil
+	}
+	wit, err := workitem.NewWorkItemTypeRepository(db).LoadTypeFromDB(nil, m.WorkItemType)
+	if err != nil {
+		if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+			return BadParameterError{parameter: "fieldMappings.workItemType", value: m.WorkItemType}
+		}
+		return InternalError{simpleError{err.Error()}}
+	}
+	for i, f := range m.Fields {
+		parameter := "fieldMappings.fields." + strconv.Itoa(i)
+		if f.Source == "" {
+			return BadParameterError{parameter: parameter + ".source", value: f.Source}
+		}
+		definition, ok := wit.Fields[f.Field]
+		if !ok || f.Field == workitem.SystemRemoteItemID {
+			return BadParameterError{parameter: parameter + ".field", value: f.Field}
+		}
+		if strings.Contains(f.Source, sourceWildcard) && f.Converter != ConverterList {
+			// only lists collect the elements of remote lists
+			return BadParameterError{parameter: parameter + ".source", value: f.Source}
+		}
+		kind := definition.Type.GetKind()
+		switch f.Converter {
+		case ConverterString:
+			if kind == workitem.KindList {
+				return BadParameterError{parameter: parameter + ".converter", value: f.Converter}
+			}
+		case ConverterMarkup:
+			if kind != workitem.KindMarkup {
+				return BadParameterError{parameter: parameter + ".converter", value: f.Converter}
+			}
+			// the default mapping of Jira descriptions uses Jira wiki markup as well
+			if f.Markup != "" && f.Markup != rendering.SystemMarkupJiraWiki && !rendering.IsMarkupSupported(f.Markup) {
+				return BadParameterError{parameter: parameter + ".markup", value: f.Markup}
+			}
+		case ConverterList:
+			if kind != workitem.KindList {
+				return BadParameterError{parameter: parameter + ".converter", value: f.Converter}
+			}
+		case ConverterEnum:
+			if kind == workitem.KindList || len(f.Values) == 0 {
+				return BadParameterError{parameter: parameter + ".converter", value: f.Converter}
+			}
+			for remote, value := range f.Values {
+				if _, err := definition.ConvertToModel(f.Field, value); err != nil {
+					return BadParameterError{parameter: parameter + ".values." + remote, value: value}
+				}
+			}
+		default:
+			return BadParameterError{parameter: parameter + ".converter", value: f.Converter}
+		}
+	}
+	return nil
+}
+
+// workItemMap returns the default mapping of the given tracker type extended
+// by the field mappings
+func (m FieldMappings) workItemMap(trackerType string) WorkItemMap {
+	mapped := make(map[string]bool, len(m.Fields))
+	for _, f := range m.Fields {
+		mapped[f.Field] = true
+	}
+	result := WorkItemMap{}
+	for from, to := range WorkItemKeyMaps[trackerType] {
+		if !mapped[to] {
+			result[from] = to
+		}
+	}
+	for _, f := range m.Fields {
+		expression := AttributeExpression(f.Source)
+		var converter AttributeConverter
+		switch f.Converter {
+		case ConverterMarkup:
+			markup := f.Markup
+			if markup == "" {
+				markup = rendering.SystemMarkupDefault
+			}
+			converter = MarkupConverter{markup: markup}
+		case ConverterList:
+			converter = ListConverter{expression: expression}
+		case ConverterEnum:
+			converter = &EnumConverter{values: f.Values}
+		default:
+			converter = StringConverter{}
+		}
+		result[AttributeMapper{expression, converter}] = f.Field
+	}
+	return result
+}
+
+// workItemType returns the name of the type of the imported work items
+func (m FieldMappings) workItemType() string {
+	if m.IsEmpty() {
+		return workitem.SystemBug
+	}
+	return m.WorkItemType
+}
+
+// ListConverter converts a remote value to a list. If the expression it is
+// mapped from contains a *, the values of all elements of the remote list are
+// collected instead.
+type ListConverter struct {
+	expression AttributeExpression
+}
+
+// Convert returns the value as a list, nil for no value
+func (converter ListConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
+	source := string(converter.expression)
+	if !strings.Contains(source, sourceWildcard) {
+		if value == nil {
+			return nil, nil
+		}
+		return []interface{}{value}, nil
+	}
+	values := []interface{}{}
+	for i := 0; ; i++ {
+		element := item.Get(AttributeExpression(strings.Replace(source, sourceWildcard, strconv.Itoa(i), 1)))
+		if element == nil {
+			break
+		}
+		values = append(values, element)
+	}
+	return values, nil
+}
+
+// ConvertBack returns the first element of the given list, the elements of
+// remote lists can't be converted back
+func (converter ListConverter) ConvertBack(value interface{}) (interface{}, error) {
+	if strings.Contains(string(converter.expression), sourceWildcard) {
+		return nil, errs.Errorf("Elements of %s can't be converted back", converter.expression)
+	}
+	return ListStringConverter{}.ConvertBack(value)
+}
+
+// EnumConverter maps remote values to field values
+type EnumConverter struct {
+	values map[string]string
+}
+
+// Convert returns the field value the remote value is mapped to
+func (converter *EnumConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	mapped, ok := converter.values[fmt.Sprint(value)]
+	if !ok {
+		return nil, errs.Errorf("Unmapped value: %v", value)
+	}
+	return mapped, nil
+}
+
+// ConvertBack returns the remote value that is mapped to the given field value,
+// the first one in lexical order if several remote values are mapped to it
+func (converter *EnumConverter) ConvertBack(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	var result *string
+	for remote, mapped := range converter.values {
+		if mapped == fmt.Sprint(value) && (result == nil || remote < *result) {
+			r := remote
+			result = &r
+		}
+	}
+	if result == nil {
+		return nil, errs.Errorf("Unmapped value: %v", value)
+	}
+	return *result, nil
+}
+
+// trackerMapping returns the mapping of the remote items of the tracker with
+// the given ID and the type of the work items they are imported as. Unknown
+// trackers use the default mapping of the given tracker type.
+// returns InternalError
+func trackerMapping(db *gorm.DB, trackerID uint64, trackerType string) (WorkItemMap, string, error) {
+	tracker := Tracker{}
+	tx := db.First(&tracker, trackerID)
+	if tx.RecordNotFound() {
+		return WorkItemKeyMaps[trackerType], workitem.SystemBug, nil
+	}
+	if tx.Error != nil {
+		return nil, "", InternalError{simpleError{fmt.Sprintf("error while loading: %s", tx.Error.Error())}}
+	}
+	return tracker.FieldMappings.workItemMap(trackerType), tracker.FieldMappings.workItemType(), nil
+}
+
+// convertFieldMappingsToModel converts the REST representation of field
+// mappings, nil for no mappings
+func convertFieldMappingsToModel(m *app.FieldMappings) FieldMappings {
+	if m == nil {
+		return FieldMappings{}
+	}
+	result := FieldMappings{WorkItemType: m.WorkItemType}
+	for _, f := range m.Fields {
+		if f == nil {
+			continue
+		}
+		mapping := FieldMapping{Source: f.Source, Field: f.Field, Converter: f.Converter, Values: f.Values}
+		if f.Markup != nil {
+			mapping.Markup = *f.Markup
+		}
+		result.Fields = append(result.Fields, mapping)
+	}
+	return result
+}
+
+// convertFieldMappingsFromModel converts the field mappings to the REST
+// representation, nil if there are none
+func convertFieldMappingsFromModel(m FieldMappings) *app.FieldMappings {
+	if m.IsEmpty() {
+		return nil
+	}
+	result := app.FieldMappings{WorkItemType: m.WorkItemType, Fields: make([]*app.FieldMapping, len(m.Fields))}
+	for i, f := range m.Fields {
+		mapping := app.FieldMapping{Source: f.Source, Field: f.Field, Converter: f.Converter, Values: f.Values}
+		if f.Markup != "" {
+			markup := f.Markup
+			mapping.Markup = &markup
+		}
+		result.Fields[i] = &mapping
+	}
+	return &result
+}
@@ -0,0 +1,186 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const jiraIssueWithCustomFields = `{"key":"ARQ-1","self":"https://issues.jboss.org/rest/api/2/issue/12345","fields":{"summary":"linking","customfield_10010":"custom summary","description":"body of issue","status":{"name":"open"},"priority":{"name":"Blocker"},"labels":["ui","backend"],"components":[{"name":"core"},{"name":"web"}],"creator":{"key":"sbose78"},"assignee":null}}`
+
+func jiraFieldMappings(workItemType string) FieldMappings {
+	return FieldMappings{
+		WorkItemType: workItemType,
+		Fields: []FieldMapping{
+			{Source: "fields.customfield_10010", Field: workitem.SystemTitle, Converter: ConverterString},
+			{Source: "fields.priority.name", Field: "priority", Converter: ConverterEnum, Values: map[string]string{"Blocker": "high", "Critical": "high", "Minor": "low"}},
+			{Source: "fields.labels.*", Field: "labels", Converter: ConverterList},
+			{Source: "fields.components.*.name", Field: "components", Converter: ConverterList},
+		},
+	}
+}
+
+func TestFieldMappingsWorkItemMap(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	mappings := jiraFieldMappings("jira_issue")
+	item, err := NewJiraRemoteWorkItem(TrackerItem{Item: jiraIssueWithCustomFields})
+	require.Nil(t, err)
+
+	workItem, err := Map(item, mappings.workItemMap(ProviderJira))
+	require.Nil(t, err)
+	assert.Equal(t, "custom summary", workItem.Fields[workitem.SystemTitle])
+	assert.Equal(t, "high", workItem.Fields["priority"])
+	assert.Equal(t, []interface{}{"ui", "backend"}, workItem.Fields["labels"])
+	assert.Equal(t, []interface{}{"core", "web"}, workItem.Fields["components"])
+	// fields that are not mapped keep their default mapping
+	assert.Equal(t, workitem.SystemStateOpen, workItem.Fields[workitem.SystemState])
+	assert.Equal(t, "https://issues.jboss.org/rest/api/2/issue/12345", workItem.Fields[workitem.SystemRemoteItemID])
+
+	t.Log("The default mapping of a mapped field is replaced")
+	for from, to := range mappings.workItemMap(ProviderJira) {
+		if to == workitem.SystemTitle {
+			assert.Equal(t, AttributeExpression("fields.customfield_10010"), from.expression)
+		}
+	}
+
+	t.Log("Unmapped enum values are not imported")
+	item, err = NewJiraRemoteWorkItem(TrackerItem{Item: strings.Replace(jiraIssueWithCustomFields, "Blocker", "Trivial", 1)})
+	require.Nil(t, err)
+	workItem, err = Map(item, mappings.workItemMap(ProviderJira))
+	require.Nil(t, err)
+	_, ok := workItem.Fields["priority"]
+	assert.False(t, ok)
+
+	t.Log("Trackers without mappings use the default mapping")
+	assert.Equal(t, WorkItemKeyMaps[ProviderJira], FieldMappings{}.workItemMap(ProviderJira))
+	assert.Equal(t, workitem.SystemBug, FieldMappings{}.workItemType())
+}
+
+func TestFieldMappingConverters(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	enum := &EnumConverter{values: map[string]string{"Blocker": "high", "Critical": "high", "Minor": "low"}}
+	value, err := enum.ConvertBack("high")
+	require.Nil(t, err)
+	assert.Equal(t, "Blocker", value)
+	_, err = enum.ConvertBack("medium")
+	assert.NotNil(t, err)
+
+	list := ListConverter{expression: "fields.labels.*"}
+	_, err = list.ConvertBack([]interface{}{"ui"})
+	assert.NotNil(t, err)
+	list = ListConverter{expression: "fields.environment"}
+	value, err = list.Convert("production", nil)
+	require.Nil(t, err)
+	assert.Equal(t, []interface{}{"production"}, value)
+	value, err = list.ConvertBack([]interface{}{"production"})
+	require.Nil(t, err)
+	assert.Equal(t, "production", value)
+}
+
+func TestFieldMappingsJSON(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	mappings := jiraFieldMappings("jira_issue")
+	value, err := mappings.Value()
+	require.Nil(t, err)
+	var scanned FieldMappings
+	require.Nil(t, scanned.Scan(value))
+	assert.Equal(t, mappings, scanned)
+
+	value, err = FieldMappings{WorkItemType: "jira_issue"}.Value()
+	require.Nil(t, err)
+	assert.Nil(t, value)
+	require.Nil(t, scanned.Scan(nil))
+	assert.True(t, scanned.IsEmpty())
+
+	converted := convertFieldMappingsFromModel(mappings)
+	require.NotNil(t, converted)
+	assert.Equal(t, mappings, convertFieldMappingsToModel(converted))
+	assert.Nil(t, convertFieldMappingsFromModel(FieldMappings{}))
+}
+
+// createJiraIssueType creates a type extending bugs with the fields mapped
+// by jiraFieldMappings
+func createJiraIssueType(t *testing.T) string {
+	stString := string(workitem.KindString)
+	name := "jira_issue_" + strings.Replace(uuid.NewV4().String(), "-", "", -1)
+	bug := workitem.SystemBug
+	_, err := workitem.NewWorkItemTypeRepository(db).Create(context.Background(), nil, &bug, name, map[string]app.FieldDefinition{
+		"priority":   {Type: &app.FieldType{Kind: string(workitem.KindEnum), BaseType: &stString, Values: []interface{}{"high", "low"}}},
+		"labels":     {Type: &app.FieldType{Kind: string(workitem.KindList), ComponentType: &stString}},
+		"components": {Type: &app.FieldType{Kind: string(workitem.KindList), ComponentType: &stString}},
+	})
+	require.Nil(t, err)
+	return name
+}
+
+func TestFieldMappingsValidate(t *testing.T) {
+	resource.Require(t, resource.Database)
+
+	name := createJiraIssueType(t)
+	assert.Nil(t, jiraFieldMappings(name).Validate(db))
+	assert.Nil(t, FieldMappings{}.Validate(db))
+
+	for parameter, change := range map[string]func(m *FieldMappings){
+		"fieldMappings.workItemType":       func(m *FieldMappings) { m.WorkItemType = "unknown_type" },
+		"fieldMappings.fields.0.field":     func(m *FieldMappings) { m.Fields[0].Field = "unknown" },
+		"fieldMappings.fields.1.field":     func(m *FieldMappings) { m.Fields[1].Field = workitem.SystemRemoteItemID },
+		"fieldMappings.fields.0.source":    func(m *FieldMappings) { m.Fields[0].Source = "fields.labels.*" },
+		"fieldMappings.fields.2.converter": func(m *FieldMappings) { m.Fields[2].Field = workitem.SystemTitle },
+		"fieldMappings.fields.3.converter": func(m *FieldMappings) { m.Fields[3].Converter = "number" },
+		"fieldMappings.fields.1.values.Minor": func(m *FieldMappings) {
+			m.Fields[1].Values["Minor"] = "lowest"
+		},
+		"fieldMappings.fields.0.markup": func(m *FieldMappings) {
+			m.Fields[0] = FieldMapping{Source: "fields.description", Field: workitem.SystemDescription, Converter: ConverterMarkup, Markup: "html"}
+		},
+	} {
+		mappings := jiraFieldMappings(name)
+		change(&mappings)
+		err := mappings.Validate(db)
+		require.IsType(t, BadParameterError{}, err, parameter)
+		assert.Equal(t, parameter, err.(BadParameterError).parameter)
+	}
+
+	t.Log("Descriptions can be mapped as Jira wiki markup")
+	mappings := jiraFieldMappings(name)
+	mappings.Fields[0] = FieldMapping{Source: "fields.description", Field: workitem.SystemDescription, Converter: ConverterMarkup, Markup: rendering.SystemMarkupJiraWiki}
+	assert.Nil(t, mappings.Validate(db))
+}
+
+func TestConvertWithFieldMappings(t *testing.T) {
+	resource.Require(t, resource.Database)
+
+	name := createJiraIssueType(t)
+	tr := Tracker{URL: "https://issues.jboss.org", Type: ProviderJira, FieldMappings: jiraFieldMappings(name)}
+	require.Nil(t, db.Create(&tr).Error)
+	defer db.Delete(&tr)
+
+	remoteID := "https://issues.jboss.org/rest/api/2/issue/" + uuid.NewV4().String()
+	var issue map[string]interface{}
+	require.Nil(t, json.Unmarshal([]byte(jiraIssueWithCustomFields), &issue))
+	issue["self"] = remoteID
+	content, err := json.Marshal(issue)
+	require.Nil(t, err)
+
+	workItem, err := convert(db, int(tr.ID), TrackerItemContent{ID: remoteID, Content: content}, ProviderJira)
+	require.Nil(t, err)
+	defer workitem.NewWorkItemRepository(db).Delete(context.Background(), workItem.ID, uuid.Nil)
+	assert.Equal(t, name, workItem.Type)
+	assert.Equal(t, "custom summary", workItem.Fields[workitem.SystemTitle])
+	assert.Equal(t, "high", workItem.Fields["priority"])
+	assert.Equal(t, []interface{}{"ui", "backend"}, workItem.Fields["labels"])
+	assert.Equal(t, []interface{}{"core", "web"}, workItem.Fields["components"])
+}
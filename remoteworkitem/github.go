@@ -2,22 +2,33 @@ package remoteworkitem
 
 import (
 	"encoding/json"
-	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/almighty/almighty-core/configuration"
 	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
 )
 
-// githubFetcher provides issue listing
+// githubFetcher provides issue listing. A non empty etag makes the listing
+// conditional, it responds with 304 Not Modified if the result didn't change.
 type githubFetcher interface {
-	listIssues(query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error)
+	listIssues(query string, opts *github.SearchOptions, etag string) (*github.IssuesSearchResult, *github.Response, error)
 }
 
 // GithubTracker represents the Github tracker provider
 type GithubTracker struct {
 	URL   string
 	Query string
+	// Watermark restricts the fetch to the issues updated since the previous
+	// runs and records the ETag of a complete fetch, it is optional
+	Watermark *Watermark
+	// Report collects the errors and rate limit waits of the fetch, it is
+	// optional
+	Report *FetchReport
 }
 
 // GithubIssueFetcher fetch issues from github
@@ -26,8 +37,32 @@ type githubIssueFetcher struct {
 }
 
 // ListIssues list all issues
-func (f *githubIssueFetcher) listIssues(query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
-	return f.client.Search.Issues(query, opts)
+func (f *githubIssueFetcher) listIssues(query string, opts *github.SearchOptions, etag string) (*github.IssuesSearchResult, *github.Response, error) {
+	if etag == "" {
+		return f.client.Search.Issues(query, opts)
+	}
+	// the same request as Search.Issues with an If-None-Match header
+	params := url.Values{"q": {query}}
+	if opts.Sort != "" {
+		params.Set("sort", opts.Sort)
+	}
+	if opts.Order != "" {
+		params.Set("order", opts.Order)
+	}
+	if opts.Page != 0 {
+		params.Set("page", strconv.Itoa(opts.Page))
+	}
+	if opts.PerPage != 0 {
+		params.Set("per_page", strconv.Itoa(opts.PerPage))
+	}
+	req, err := f.client.NewRequest("GET", "search/issues?"+params.Encode(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("If-None-Match", etag)
+	result := new(github.IssuesSearchResult)
+	response, err := f.client.Do(req, result)
+	return result, response, err
 }
 
 // Fetch tracker items from Github
@@ -44,24 +79,59 @@ func (g *GithubTracker) Fetch() chan TrackerItemContent {
 func (g *GithubTracker) fetch(f githubFetcher) chan TrackerItemContent {
 	item := make(chan TrackerItemContent)
 	go func() {
+		// oldest first, also without a watermark, so that the watermark of
+		// a partial fetch doesn't skip issues
 		opts := &github.SearchOptions{
+			Sort:  "updated",
+			Order: "asc",
 			ListOptions: github.ListOptions{
 				PerPage: 20,
 			},
 		}
+		query := g.Query
+		var etag, fetchedETag string
+		if g.Watermark != nil {
+			if since := g.Watermark.LastUpdatedAt; since != nil {
+				query += " updated:>=" + since.UTC().Format(time.RFC3339)
+			}
+			etag = g.Watermark.ETag
+		}
+		report := g.Report
+		if report == nil {
+			report = &FetchReport{}
+		}
 		for {
-			result, response, err := f.listIssues(g.Query, opts)
-			if _, ok := err.(*github.RateLimitError); ok {
-				log.Println("reached rate limit", err)
+			result, response, err := f.listIssues(query, opts, etag)
+			if response != nil && response.Response != nil && response.StatusCode == http.StatusNotModified {
+				// nothing changed since the previous fetch
 				break
 			}
+			if e, ok := err.(*github.RateLimitError); ok {
+				if report.waitForRateLimit(e.Rate.Reset.Time) {
+					continue
+				}
+				report.addError("reached rate limit of Github, it resets at %v", e.Rate.Reset)
+				break
+			}
+			if err != nil {
+				report.addError("listing Github issues failed: %v", err)
+				break
+			}
+			if opts.Page == 0 && response.Response != nil {
+				fetchedETag = response.Header.Get("ETag")
+			}
+			// only the first page is requested conditionally
+			etag = ""
 			issues := result.Issues
 			for _, l := range issues {
 				id, _ := json.Marshal(l.URL)
 				content, _ := json.Marshal(l)
-				item <- TrackerItemContent{ID: string(id), Content: content}
+				item <- TrackerItemContent{ID: string(id), Content: content, UpdatedAt: l.UpdatedAt}
 			}
 			if response.NextPage == 0 {
+				if g.Watermark != nil {
+					g.Watermark.ETag = fetchedETag
+				}
 				break
 			}
 			opts.ListOptions.Page = response.NextPage
@@ -70,3 +140,62 @@ func (g *GithubTracker) fetch(f githubFetcher) chan TrackerItemContent {
 	}()
 	return item
 }
+
+// githubIssuePusher reads and updates single Github issues
+type githubIssuePusher struct {
+	client *github.Client
+}
+
+// newGithubIssuePusher creates a pusher authenticated with the given token of
+// the tracker or, if there is none, with the configured token
+func newGithubIssuePusher(token string) *githubIssuePusher {
+	if token == "" {
+		token = configuration.GetGithubAuthToken()
+	}
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: token},
+	)
+	tc := oauth2.NewClient(oauth2.NoContext, ts)
+	return &githubIssuePusher{client: github.NewClient(tc)}
+}
+
+// get returns the issue with the given API URL
+func (p *githubIssuePusher) get(issueURL string) ([]byte, error) {
+	req, err := p.client.NewRequest("GET", issueURL, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var content json.RawMessage
+	if _, err := p.client.Do(req, &content); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return content, nil
+}
+
+// update edits the given attributes of the issue with the given API URL
+func (p *githubIssuePusher) update(issueURL string, changes map[AttributeExpression]interface{}) ([]byte, error) {
+	request := github.IssueRequest{}
+	for expression, value := range changes {
+		s, _ := value.(string)
+		switch expression {
+		case GithubTitle:
+			request.Title = &s
+		case GithubDescription:
+			request.Body = &s
+		case GithubState:
+			request.State = &s
+		case GithubAssignee:
+			// an empty assignee removes the assignee of the issue
+			request.Assignee = &s
+		}
+	}
+	req, err := p.client.NewRequest("PATCH", issueURL, &request)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var content json.RawMessage
+	if _, err := p.client.Do(req, &content); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return content, nil
+}
@@ -9,13 +9,14 @@ import (
 	"github.com/almighty/almighty-core/resource"
 	"github.com/dnaeon/go-vcr/recorder"
 	"github.com/google/go-github/github"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 type fakeGithubIssueFetcher struct{}
 
 // ListIssues list all issues
-func (f *fakeGithubIssueFetcher) listIssues(query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+func (f *fakeGithubIssueFetcher) listIssues(query string, opts *github.SearchOptions, etag string) (*github.IssuesSearchResult, *github.Response, error) {
 	if opts.ListOptions.Page == 0 {
 		one := 1
 		i := github.Issue{ID: &one}
@@ -46,7 +47,7 @@ func TestGithubFetch(t *testing.T) {
 type fakeGithubIssueFetcherWithRateLimit struct{}
 
 // ListIssues list all issues
-func (f *fakeGithubIssueFetcherWithRateLimit) listIssues(query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+func (f *fakeGithubIssueFetcherWithRateLimit) listIssues(query string, opts *github.SearchOptions, etag string) (*github.IssuesSearchResult, *github.Response, error) {
 	isr := &github.IssuesSearchResult{}
 	r := &github.Response{}
 	r.NextPage = 0
@@ -58,11 +59,57 @@ func (f *fakeGithubIssueFetcherWithRateLimit) listIssues(query string, opts *git
 func TestGithubFetchWithRateLimit(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
 	f := fakeGithubIssueFetcherWithRateLimit{}
-	g := GithubTracker{URL: "", Query: ""}
-	fetch := g.fetch(&f)
-	if len(fetch) > 0 {
+	report := FetchReport{}
+	g := GithubTracker{URL: "", Query: "", Report: &report}
+	for range g.fetch(&f) {
 		t.Error("Channel should not have any data")
 	}
+	// the reset time of the rate limit is unknown
+	assert.Len(t, report.Errors, 1)
+	assert.Equal(t, 0, report.RateLimitWaits)
+}
+
+// fakeGithubIssueFetcherWithRateLimitReset reaches the rate limit on the
+// first request and returns one issue after the rate limit was reset
+type fakeGithubIssueFetcherWithRateLimitReset struct {
+	requests int
+}
+
+func (f *fakeGithubIssueFetcherWithRateLimitReset) listIssues(query string, opts *github.SearchOptions, etag string) (*github.IssuesSearchResult, *github.Response, error) {
+	f.requests++
+	if f.requests == 1 {
+		reset := github.Timestamp{Time: time.Now().Add(time.Minute)}
+		return nil, &github.Response{}, &github.RateLimitError{Rate: github.Rate{Reset: reset}}
+	}
+	one := 1
+	return &github.IssuesSearchResult{Issues: []github.Issue{{ID: &one}}}, &github.Response{}, nil
+}
+
+func TestGithubFetchWaitsForRateLimit(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	var waits []time.Duration
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { sleep = time.Sleep }()
+
+	f := fakeGithubIssueFetcherWithRateLimitReset{}
+	report := FetchReport{}
+	g := GithubTracker{URL: "", Query: "", Report: &report}
+	var items []TrackerItemContent
+	for i := range g.fetch(&f) {
+		items = append(items, i)
+	}
+	assert.Len(t, items, 1)
+	assert.Equal(t, 2, f.requests)
+	assert.Empty(t, report.Errors)
+	assert.Equal(t, 1, report.RateLimitWaits)
+	require.Len(t, waits, 1)
+	assert.InDelta(t, float64(time.Minute), float64(waits[0]), float64(time.Second))
+	assert.Equal(t, waits[0], report.RateLimitWait)
+
+	// waits longer than maxRateLimitWait abort the fetch
+	report = FetchReport{}
+	assert.False(t, report.waitForRateLimit(time.Now().Add(maxRateLimitWait+time.Minute)))
+	assert.Equal(t, 0, report.RateLimitWaits)
 }
 
 func TestGithubFetchWithRecording(t *testing.T) {
@@ -80,12 +127,68 @@ func TestGithubFetchWithRecording(t *testing.T) {
 	f.client = github.NewClient(h)
 	g := &GithubTracker{URL: "", Query: "is:open is:issue user:almighty-test"}
 	fetch := g.fetch(&f)
+	// the issues are fetched oldest first
 	i := <-fetch
-	if !strings.Contains(string(i.Content), `"html_url":"https://github.com/almighty-test/almighty-test-unit/issues/2"`) {
+	if !strings.Contains(string(i.Content), `"html_url":"https://github.com/almighty-test/almighty-test-unit/issues/1"`) {
 		t.Errorf("Content is not matching: %#v", string(i.Content))
 	}
 	i2 := <-fetch
-	if !strings.Contains(string(i2.Content), `"html_url":"https://github.com/almighty-test/almighty-test-unit/issues/1"`) {
+	if !strings.Contains(string(i2.Content), `"html_url":"https://github.com/almighty-test/almighty-test-unit/issues/2"`) {
 		t.Errorf("Content is not matching: %#v", string(i2.Content))
 	}
 }
+
+// fakeGithubIssueFetcherWithETag returns a single page with one issue, or 304
+// Not Modified if the etag matches
+type fakeGithubIssueFetcherWithETag struct {
+	queries []string
+	etags   []string
+}
+
+func (f *fakeGithubIssueFetcherWithETag) listIssues(query string, opts *github.SearchOptions, etag string) (*github.IssuesSearchResult, *github.Response, error) {
+	f.queries = append(f.queries, query+" sort:"+opts.Sort+" order:"+opts.Order)
+	f.etags = append(f.etags, etag)
+	header := http.Header{}
+	header.Set("ETag", `"abc"`)
+	if etag == `"abc"` {
+		r := &github.Response{Response: &http.Response{StatusCode: http.StatusNotModified, Header: header}}
+		return &github.IssuesSearchResult{}, r, &github.ErrorResponse{Response: r.Response}
+	}
+	one := 1
+	updated := time.Date(2017, 1, 2, 15, 4, 5, 0, time.UTC)
+	isr := &github.IssuesSearchResult{Issues: []github.Issue{{ID: &one, UpdatedAt: &updated}}}
+	r := &github.Response{Response: &http.Response{StatusCode: http.StatusOK, Header: header}}
+	return isr, r, nil
+}
+
+func TestGithubFetchWithWatermark(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	f := fakeGithubIssueFetcherWithETag{}
+	since := time.Date(2017, 1, 1, 10, 0, 0, 0, time.UTC)
+	w := Watermark{LastUpdatedAt: &since}
+	g := GithubTracker{URL: "", Query: "is:open", Watermark: &w}
+
+	var items []TrackerItemContent
+	for i := range g.fetch(&f) {
+		items = append(items, i)
+	}
+	require.Len(t, items, 1)
+	assert.Equal(t, time.Date(2017, 1, 2, 15, 4, 5, 0, time.UTC), *items[0].UpdatedAt)
+	assert.Equal(t, []string{"is:open updated:>=2017-01-01T10:00:00Z sort:updated order:asc"}, f.queries)
+	assert.Equal(t, []string{""}, f.etags)
+	assert.Equal(t, `"abc"`, w.ETag)
+
+	// the next fetch is conditional
+	for range g.fetch(&f) {
+		t.Error("Channel should not have any data")
+	}
+	assert.Equal(t, []string{"", `"abc"`}, f.etags)
+	assert.Equal(t, `"abc"`, w.ETag)
+
+	// the first fetch is oldest first as well
+	f = fakeGithubIssueFetcherWithETag{}
+	g = GithubTracker{URL: "", Query: "is:open"}
+	for range g.fetch(&f) {
+	}
+	assert.Equal(t, []string{"is:open sort:updated order:asc"}, f.queries)
+}
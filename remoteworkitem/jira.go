@@ -2,14 +2,101 @@ package remoteworkitem
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
 )
 
 // JiraTracker represents the Jira tracker provider
 type JiraTracker struct {
 	URL   string
 	Query string
+	// Watermark restricts the fetch to the issues updated since the previous
+	// runs, it is optional
+	Watermark *Watermark
+	// Report collects the errors and rate limit waits of the fetch, it is
+	// optional
+	Report *FetchReport
+}
+
+// JQL dates are in the time zone of the Jira user, which is unknown. The
+// watermark is moved back by the largest offset of a time zone, so that no
+// issue is missed.
+const jiraWatermarkMargin = 14 * time.Hour
+
+// jiraUpdatedLayout is the layout of the update times of Jira issues
+const jiraUpdatedLayout = "2006-01-02T15:04:05.000-0700"
+
+var jqlOrderBy = regexp.MustCompile(`(?i)\s*\border\s+by\b`)
+
+// jqlOldestFirst replaces the order of the JQL query by the update time of
+// the issues, oldest first so that the watermark of a partial fetch doesn't
+// skip issues
+func jqlOldestFirst(jql string) string {
+	jql = strings.TrimSpace(jqlWithoutOrder(jql))
+	if jql == "" {
+		return "ORDER BY updated ASC"
+	}
+	return jql + " ORDER BY updated ASC"
+}
+
+// jqlUpdatedSince restricts the JQL query to the issues updated since the
+// given time, oldest first, see jqlOldestFirst
+func jqlUpdatedSince(jql string, since time.Time) string {
+	jql = jqlWithoutOrder(jql)
+	condition := fmt.Sprintf(`updated >= "%s"`, since.UTC().Add(-jiraWatermarkMargin).Format("2006/01/02 15:04"))
+	if strings.TrimSpace(jql) != "" {
+		condition = "(" + jql + ") AND " + condition
+	}
+	return jqlOldestFirst(condition)
+}
+
+// jqlWithoutOrder returns the JQL query without its ORDER BY clause
+func jqlWithoutOrder(jql string) string {
+	if loc := jqlOrderBy.FindStringIndex(jql); loc != nil {
+		return jql[:loc[0]]
+	}
+	return jql
+}
+
+// jiraUpdatedAt returns the update time of the given Jira issue content, nil
+// if it has none
+func jiraUpdatedAt(content []byte) *time.Time {
+	var issue map[string]interface{}
+	if err := json.Unmarshal(content, &issue); err != nil {
+		return nil
+	}
+	switch updated := Flatten(issue)["fields.updated"].(type) {
+	case string:
+		if t, err := time.Parse(jiraUpdatedLayout, updated); err == nil {
+			return &t
+		}
+	case float64:
+		// milliseconds since the epoch
+		t := time.Unix(0, int64(updated)*int64(time.Millisecond))
+		return &t
+	}
+	return nil
+}
+
+// jiraRateLimited waits until the request can be retried if the response is
+// 429 Too Many Requests. It returns false if the request is not to be retried.
+func jiraRateLimited(response *jira.Response, report *FetchReport) bool {
+	if response == nil || response.Response == nil || response.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	seconds, err := strconv.Atoi(response.Header.Get("Retry-After"))
+	if err != nil {
+		return false
+	}
+	return report.waitForRateLimit(time.Now().Add(time.Duration(seconds) * time.Second))
 }
 
 type jiraFetcher interface {
@@ -40,14 +127,146 @@ func (j *JiraTracker) Fetch() chan TrackerItemContent {
 func (j *JiraTracker) fetch(f jiraFetcher) chan TrackerItemContent {
 	item := make(chan TrackerItemContent)
 	go func() {
-		issues, _, _ := f.listIssues(j.Query, nil)
+		jql := jqlOldestFirst(j.Query)
+		if j.Watermark != nil && j.Watermark.LastUpdatedAt != nil {
+			jql = jqlUpdatedSince(jql, *j.Watermark.LastUpdatedAt)
+		}
+		report := j.Report
+		if report == nil {
+			report = &FetchReport{}
+		}
+		issues, response, err := f.listIssues(jql, nil)
+		for err != nil && jiraRateLimited(response, report) {
+			issues, response, err = f.listIssues(jql, nil)
+		}
+		if err != nil {
+			report.addError("searching Jira issues failed: %v", err)
+		}
 		for _, l := range issues {
 			id, _ := json.Marshal(l.Key)
-			issue, _, _ := f.getIssue(l.Key)
+			issue, response, err := f.getIssue(l.Key)
+			for err != nil && jiraRateLimited(response, report) {
+				issue, response, err = f.getIssue(l.Key)
+			}
+			if err != nil {
+				// the following issues are not fetched either, so that the
+				// watermark doesn't skip this one
+				report.addError("loading Jira issue %s failed: %v", l.Key, err)
+				break
+			}
 			content, _ := json.Marshal(issue)
-			item <- TrackerItemContent{ID: string(id), Content: content}
+			item <- TrackerItemContent{ID: string(id), Content: content, UpdatedAt: jiraUpdatedAt(content)}
 		}
 		close(item)
 	}()
 	return item
 }
+
+// jiraIssuePusher reads and updates single Jira issues
+type jiraIssuePusher struct {
+	client *jira.Client
+}
+
+// newJiraIssuePusher creates a pusher for the Jira instance with the given URL
+// that is authenticated with the given personal access token
+// returns BadParameterError if there is no token, Jira doesn't accept
+// anonymous changes
+func newJiraIssuePusher(url string, token string) (*jiraIssuePusher, error) {
+	if token == "" {
+		return nil, BadParameterError{parameter: "pushToken", value: nil}
+	}
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: token},
+	)
+	client, err := jira.NewClient(oauth2.NewClient(oauth2.NoContext, ts), url)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &jiraIssuePusher{client: client}, nil
+}
+
+// get returns the issue with the given API URL
+func (p *jiraIssuePusher) get(url string) ([]byte, error) {
+	var content json.RawMessage
+	if err := p.do("GET", url, nil, &content); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// update edits the given attributes of the issue with the given API URL. The
+// status of an issue can't be set directly, it is changed by the transition
+// to the status of the same name.
+func (p *jiraIssuePusher) update(url string, changes map[AttributeExpression]interface{}) ([]byte, error) {
+	var transitionID string
+	if state, ok := changes[JiraState]; ok {
+		// look up the transition first, so that nothing is changed for unknown states
+		id, err := p.transitionTo(url, state)
+		if err != nil {
+			return nil, err
+		}
+		transitionID = id
+	}
+	fields := make(map[string]interface{})
+	for expression, value := range changes {
+		switch expression {
+		case JiraTitle:
+			fields["summary"] = value
+		case JiraBody:
+			fields["description"] = value
+		}
+	}
+	if len(fields) > 0 {
+		if err := p.do("PUT", url, map[string]interface{}{"fields": fields}, nil); err != nil {
+			return nil, err
+		}
+	}
+	if assignee, ok := changes[JiraAssignee]; ok {
+		// a nil name removes the assignee of the issue
+		if err := p.do("PUT", url+"/assignee", map[string]interface{}{"name": assignee}, nil); err != nil {
+			return nil, err
+		}
+	}
+	if transitionID != "" {
+		body := map[string]interface{}{"transition": map[string]interface{}{"id": transitionID}}
+		if err := p.do("POST", url+"/transitions", body, nil); err != nil {
+			return nil, err
+		}
+	}
+	return p.get(url)
+}
+
+// transitionTo returns the ID of the transition of the issue to the status with
+// the given name
+// returns BadParameterError if the issue has no such transition
+func (p *jiraIssuePusher) transitionTo(url string, state interface{}) (string, error) {
+	var result struct {
+		Transitions []struct {
+			ID string `json:"id"`
+			To struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := p.do("GET", url+"/transitions", nil, &result); err != nil {
+		return "", err
+	}
+	name, _ := state.(string)
+	for _, t := range result.Transitions {
+		if strings.EqualFold(t.To.Name, name) {
+			return t.ID, nil
+		}
+	}
+	return "", BadParameterError{parameter: "state", value: state}
+}
+
+func (p *jiraIssuePusher) do(method string, url string, body interface{}, v interface{}) error {
+	req, err := p.client.NewRequest(method, url, body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := p.client.Do(req, v); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package remoteworkitem
 
 import (
+	"errors"
 	"net/http"
 	"testing"
 	"time"
@@ -8,6 +9,8 @@ import (
 	"github.com/almighty/almighty-core/resource"
 	jira "github.com/andygrunwald/go-jira"
 	"github.com/dnaeon/go-vcr/recorder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type fakeJiraIssueFetcher struct{}
@@ -31,6 +34,53 @@ func TestJiraFetch(t *testing.T) {
 
 }
 
+// fakeJiraIssueFetcherWithErrors is rate limited on the first search and
+// fails to load the second issue
+type fakeJiraIssueFetcherWithErrors struct {
+	searches int
+}
+
+func (f *fakeJiraIssueFetcherWithErrors) listIssues(jql string, options *jira.SearchOptions) ([]jira.Issue, *jira.Response, error) {
+	f.searches++
+	if f.searches == 1 {
+		header := http.Header{}
+		header.Set("Retry-After", "30")
+		return nil, &jira.Response{Response: &http.Response{StatusCode: http.StatusTooManyRequests, Header: header}}, errors.New("429 Too Many Requests")
+	}
+	return []jira.Issue{{Key: "ARQ-1"}, {Key: "ARQ-2"}, {Key: "ARQ-3"}}, &jira.Response{}, nil
+}
+
+func (f *fakeJiraIssueFetcherWithErrors) getIssue(issueID string) (*jira.Issue, *jira.Response, error) {
+	if issueID == "ARQ-2" {
+		return nil, &jira.Response{Response: &http.Response{StatusCode: http.StatusInternalServerError}}, errors.New("500 Internal Server Error")
+	}
+	return &jira.Issue{Key: issueID}, &jira.Response{}, nil
+}
+
+func TestJiraFetchWithErrors(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	var waits []time.Duration
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { sleep = time.Sleep }()
+
+	f := fakeJiraIssueFetcherWithErrors{}
+	report := FetchReport{}
+	j := JiraTracker{URL: "", Query: "", Report: &report}
+	var items []TrackerItemContent
+	for i := range j.fetch(&f) {
+		items = append(items, i)
+	}
+	// the fetch stops at the issue that could not be loaded
+	require.Len(t, items, 1)
+	assert.Equal(t, `"ARQ-1"`, items[0].ID)
+	assert.Equal(t, 2, f.searches)
+	assert.Equal(t, 1, report.RateLimitWaits)
+	require.Len(t, waits, 1)
+	assert.InDelta(t, float64(30*time.Second), float64(waits[0]), float64(time.Second))
+	require.Len(t, report.Errors, 1)
+	assert.Contains(t, report.Errors[0], "ARQ-2")
+}
+
 func TestJiraFetchWithRecording(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
 	r, err := recorder.New("../test/data/jira_fetch_test")
@@ -50,23 +100,48 @@ func TestJiraFetchWithRecording(t *testing.T) {
 	f.client = client
 	fetch := j.fetch(&f)
 
+	// the issues are fetched oldest first
 	i := <-fetch
-	if i.ID != `"ARQ-1937"` {
+	if i.ID != `"ARQ-1956"` {
 		t.Errorf("ID is not matching: %#v", string(i.ID))
 	}
 
 	i = <-fetch
-	if i.ID != `"ARQ-1956"` {
+	if i.ID != `"ARQ-1996"` {
 		t.Errorf("ID is not matching: %#v", string(i.ID))
 	}
 
 	i = <-fetch
-	if i.ID != `"ARQ-1996"` {
+	if i.ID != `"ARQ-2009"` {
 		t.Errorf("ID is not matching: %#v", string(i.ID))
 	}
 
 	i = <-fetch
-	if i.ID != `"ARQ-2009"` {
+	if i.ID != `"ARQ-2010"` {
 		t.Errorf("ID is not matching: %#v", string(i.ID))
 	}
 }
+
+func TestJqlUpdatedSince(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	since := time.Date(2017, 1, 2, 15, 4, 5, 0, time.UTC)
+	// JQL dates are moved back by the largest time zone offset
+	assert.Equal(t, `(project = ARQ) AND updated >= "2017/01/02 01:04" ORDER BY updated ASC`, jqlUpdatedSince("project = ARQ", since))
+	assert.Equal(t, `(project = ARQ AND text ~ 'order') AND updated >= "2017/01/02 01:04" ORDER BY updated ASC`, jqlUpdatedSince("project = ARQ AND text ~ 'order' order by key desc", since))
+	assert.Equal(t, `updated >= "2017/01/02 01:04" ORDER BY updated ASC`, jqlUpdatedSince("ORDER BY key", since))
+}
+
+func TestJqlOldestFirst(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, `project = ARQ ORDER BY updated ASC`, jqlOldestFirst("project = ARQ"))
+	assert.Equal(t, `project = ARQ ORDER BY updated ASC`, jqlOldestFirst("project = ARQ order by key desc"))
+	assert.Equal(t, `ORDER BY updated ASC`, jqlOldestFirst(""))
+}
+
+func TestJiraUpdatedAt(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	updated := jiraUpdatedAt([]byte(`{"key":"ARQ-1","fields":{"updated":"2017-01-02T16:04:05.000+0100"}}`))
+	require.NotNil(t, updated)
+	assert.True(t, time.Date(2017, 1, 2, 15, 4, 5, 0, time.UTC).Equal(*updated))
+	assert.Nil(t, jiraUpdatedAt([]byte(`{"id":"1"}`)))
+}
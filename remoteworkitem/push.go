@@ -0,0 +1,190 @@
+package remoteworkitem
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// remotePusher reads and updates single items of a remote tracker
+type remotePusher interface {
+	// get returns the current content of the remote item with the given URL
+	get(url string) ([]byte, error)
+	// update changes the given attributes of the remote item with the given
+	// URL and returns its new content
+	update(url string, changes map[AttributeExpression]interface{}) ([]byte, error)
+}
+
+// lookupPusher provides the pusher for the remote items of the given tracker
+func lookupPusher(t Tracker) (remotePusher, error) {
+	switch t.Type {
+	case ProviderGithub:
+		return newGithubIssuePusher(t.PushToken), nil
+	case ProviderJira:
+		return newJiraIssuePusher(t.URL, t.PushToken)
+	}
+	return nil, BadParameterError{parameter: "type", value: t.Type}
+}
+
+// Push pushes the local changes of a work item back to the item of the given
+// tracker it was imported from
+// returns BadParameterError, NotFoundError, VersionConflictError or InternalError
+func (s *Scheduler) Push(ctx context.Context, trackerID string, workItemID string) (map[AttributeExpression]interface{}, error) {
+	return push(ctx, s.db, trackerID, workItemID, lookupPusher)
+}
+
+// push maps the work item back to the attributes of the remote item through
+// the inverse of the mapping of the tracker and updates the attributes that were changed
+// locally since the last import. If such an attribute was changed on the
+// remote side as well, nothing is updated and a VersionConflictError is
+// returned. The new content of the remote item is stored as the tracker item,
+// so that it is the base of the next push.
+func push(ctx context.Context, db *gorm.DB, trackerID string, workItemID string, lookup func(Tracker) (remotePusher, error)) (map[AttributeExpression]interface{}, error) {
+	id, err := strconv.ParseUint(trackerID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, NotFoundError{"tracker", trackerID}
+	}
+	tracker := Tracker{}
+	tx := db.First(&tracker, id)
+	if tx.RecordNotFound() {
+		return nil, NotFoundError{"tracker", trackerID}
+	}
+	if tx.Error != nil {
+		return nil, InternalError{simpleError{fmt.Sprintf("error while loading: %s", tx.Error.Error())}}
+	}
+	if _, ok := WorkItemKeyMaps[tracker.Type]; !ok {
+		return nil, BadParameterError{parameter: "type", value: tracker.Type}
+	}
+	mapping := tracker.FieldMappings.workItemMap(tracker.Type)
+
+	wi, err := workitem.NewWorkItemRepository(db).Load(ctx, workItemID)
+	if err != nil {
+		if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+			return nil, NotFoundError{"work item", workItemID}
+		}
+		return nil, InternalError{simpleError{err.Error()}}
+	}
+	remoteID, _ := wi.Fields[workitem.SystemRemoteItemID].(string)
+	if remoteID == "" {
+		// only imported work items can be pushed
+		return nil, BadParameterError{parameter: workitem.SystemRemoteItemID, value: nil}
+	}
+	ti, err := loadTrackerItem(db, tracker, mapping, remoteID)
+	if err != nil {
+		return nil, err
+	}
+	imported, err := RemoteWorkItemImplRegistry[tracker.Type](*ti)
+	if err != nil {
+		return nil, InternalError{simpleError{" Error parsing the tracker data "}}
+	}
+
+	pusher, err := lookup(tracker)
+	if err != nil {
+		return nil, err
+	}
+	content, err := pusher.get(remoteID)
+	if err != nil {
+		return nil, InternalError{simpleError{fmt.Sprintf("error while loading remote item %s: %s", remoteID, err.Error())}}
+	}
+	current, err := RemoteWorkItemImplRegistry[tracker.Type](TrackerItem{Item: string(content)})
+	if err != nil {
+		return nil, InternalError{simpleError{" Error parsing the remote item "}}
+	}
+
+	local := ReverseMap(pushedWorkItem(ctx, db, *wi), mapping)
+	changes := make(map[AttributeExpression]interface{})
+	for _, expression := range PushedAttributes[tracker.Type] {
+		value, ok := local[expression]
+		if !ok || sameValue(value, imported.Get(expression)) {
+			continue
+		}
+		if !sameValue(current.Get(expression), imported.Get(expression)) {
+			return nil, VersionConflictError{simpleError{fmt.Sprintf("attribute %s of remote item %s was changed since the last import", expression, remoteID)}}
+		}
+		changes[expression] = value
+	}
+	if len(changes) == 0 {
+		return changes, nil
+	}
+
+	content, err = pusher.update(remoteID, changes)
+	if err != nil {
+		if _, ok := errs.Cause(err).(BadParameterError); ok {
+			return nil, err
+		}
+		return nil, InternalError{simpleError{fmt.Sprintf("error while updating remote item %s: %s", remoteID, err.Error())}}
+	}
+	ti.Item = string(content)
+	if err := db.Save(ti).Error; err != nil {
+		return nil, InternalError{simpleError{err.Error()}}
+	}
+	return changes, nil
+}
+
+// loadTrackerItem returns the item of the tracker that was imported as the
+// work item with the given remote item ID
+// returns NotFoundError or InternalError
+func loadTrackerItem(db *gorm.DB, tracker Tracker, mapping WorkItemMap, remoteID string) (*TrackerItem, error) {
+	var expression AttributeExpression
+	for from, to := range mapping {
+		if to == workitem.SystemRemoteItemID {
+			expression = from.expression
+		}
+	}
+	ti := TrackerItem{}
+	tx := db.Where("tracker_id = ? AND item::jsonb->>? = ?", tracker.ID, string(expression), remoteID).First(&ti)
+	if tx.RecordNotFound() {
+		return nil, NotFoundError{"tracker item", remoteID}
+	}
+	if tx.Error != nil {
+		return nil, InternalError{simpleError{fmt.Sprintf("error while loading: %s", tx.Error.Error())}}
+	}
+	return &ti, nil
+}
+
+// pushedWorkItem returns a copy of the work item whose assignees that are
+// local identities are replaced by the user names of the identities, which
+// are expected to match the user names of the remote tracker
+func pushedWorkItem(ctx context.Context, db *gorm.DB, wi app.WorkItem) app.WorkItem {
+	fields := make(map[string]interface{}, len(wi.Fields))
+	for key, value := range wi.Fields {
+		fields[key] = value
+	}
+	if assignees, ok := fields[workitem.SystemAssignees].([]interface{}); ok {
+		identities := account.NewIdentityRepository(db)
+		names := make([]interface{}, len(assignees))
+		for i, assignee := range assignees {
+			names[i] = assignee
+			s, _ := assignee.(string)
+			if id, err := uuid.FromString(s); err == nil {
+				if identity, err := identities.Load(ctx, id); err == nil && identity.Username != "" {
+					names[i] = identity.Username
+				}
+			}
+		}
+		fields[workitem.SystemAssignees] = names
+	}
+	wi.Fields = fields
+	return wi
+}
+
+// sameValue returns true if the values are equal, nil and the empty string
+// being the same value
+func sameValue(a interface{}, b interface{}) bool {
+	if a == "" {
+		a = nil
+	}
+	if b == "" {
+		b = nil
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,167 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/google/go-github/github"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGithubIssue serves a single Github issue that can be read and edited
+type fakeGithubIssue struct {
+	issue   map[string]interface{}
+	patches []map[string]interface{}
+}
+
+func (f *fakeGithubIssue) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "PATCH" {
+		var patch map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.patches = append(f.patches, patch)
+		for key, value := range patch {
+			if key == "assignee" {
+				f.issue[key] = map[string]interface{}{"login": value}
+			} else {
+				f.issue[key] = value
+			}
+		}
+	}
+	json.NewEncoder(w).Encode(f.issue)
+}
+
+func TestPushGithubIssue(t *testing.T) {
+	resource.Require(t, resource.Database)
+
+	remote := &fakeGithubIssue{}
+	server := httptest.NewServer(remote)
+	defer server.Close()
+	remoteID := server.URL + "/repos/almighty-test/almighty-test-unit/issues/1"
+	remote.issue = map[string]interface{}{"title": "linking", "url": remoteID, "state": "open", "body": "body of issue", "user": map[string]interface{}{"login": "sbose78"}, "assignee": map[string]interface{}{"login": "pranav"}}
+	lookup := func(Tracker) (remotePusher, error) {
+		return &githubIssuePusher{client: github.NewClient(nil)}, nil
+	}
+
+	tr := Tracker{URL: "https://api.github.com/", Type: ProviderGithub}
+	require.Nil(t, db.Create(&tr).Error)
+	defer db.Delete(&tr)
+	trackerID := strconv.FormatUint(tr.ID, 10)
+
+	// import the issue
+	content, err := json.Marshal(remote.issue)
+	require.Nil(t, err)
+	item := TrackerItemContent{ID: remoteID, Content: content}
+	require.Nil(t, upload(db, int(tr.ID), item))
+	defer db.Where("tracker_id = ?", tr.ID).Delete(TrackerItem{})
+	workItem, err := convert(db, int(tr.ID), item, ProviderGithub)
+	require.Nil(t, err)
+	wir := workitem.NewWorkItemRepository(db)
+	defer wir.Delete(context.Background(), workItem.ID, uuid.Nil)
+
+	t.Log("Nothing is pushed for unchanged work items")
+	changes, err := push(context.Background(), db, trackerID, workItem.ID, lookup)
+	require.Nil(t, err)
+	assert.Empty(t, changes)
+	assert.Empty(t, remote.patches)
+
+	t.Log("Local changes are pushed")
+	workItem.Fields[workitem.SystemState] = workitem.SystemStateClosed
+	workItem.Fields[workitem.SystemAssignees] = []interface{}{"sbose78"}
+	workItem, err = wir.Save(context.Background(), *workItem, uuid.Nil)
+	require.Nil(t, err)
+	changes, err = push(context.Background(), db, trackerID, workItem.ID, lookup)
+	require.Nil(t, err)
+	assert.Equal(t, map[AttributeExpression]interface{}{GithubState: "closed", GithubAssignee: "sbose78"}, changes)
+	require.Len(t, remote.patches, 1)
+	assert.Equal(t, map[string]interface{}{"state": "closed", "assignee": "sbose78"}, remote.patches[0])
+	ti, err := loadTrackerItem(db, tr, WorkItemKeyMaps[ProviderGithub], remoteID)
+	require.Nil(t, err)
+	assert.Contains(t, ti.Item, `"state":"closed"`)
+
+	t.Log("Attributes changed on both sides are conflicts")
+	remote.issue["title"] = "changed remotely"
+	workItem.Fields[workitem.SystemTitle] = "changed locally"
+	workItem, err = wir.Save(context.Background(), *workItem, uuid.Nil)
+	require.Nil(t, err)
+	_, err = push(context.Background(), db, trackerID, workItem.ID, lookup)
+	require.NotNil(t, err)
+	assert.IsType(t, VersionConflictError{}, err)
+	assert.Len(t, remote.patches, 1)
+
+	t.Log("Work items that were not imported can't be pushed")
+	local, err := wir.Create(context.Background(), nil, workitem.SystemBug, map[string]interface{}{workitem.SystemTitle: "local", workitem.SystemState: workitem.SystemStateNew}, "xx")
+	require.Nil(t, err)
+	defer wir.Delete(context.Background(), local.ID, uuid.Nil)
+	_, err = push(context.Background(), db, trackerID, local.ID, lookup)
+	assert.IsType(t, BadParameterError{}, err)
+	_, err = push(context.Background(), db, "0", workItem.ID, lookup)
+	assert.IsType(t, NotFoundError{}, err)
+}
+
+func TestJiraIssuePusher(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	issue := map[string]interface{}{"fields": map[string]interface{}{"summary": "linking", "status": map[string]interface{}{"name": "Open"}}}
+	var requests []string
+	var bodies []map[string]interface{}
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/rest/api/2/issue/10000/transitions":
+			json.NewEncoder(w).Encode(map[string]interface{}{"transitions": []interface{}{
+				map[string]interface{}{"id": "11", "to": map[string]interface{}{"name": "In Progress"}},
+				map[string]interface{}{"id": "21", "to": map[string]interface{}{"name": "Closed"}},
+			}})
+		case r.Method == "GET":
+			json.NewEncoder(w).Encode(issue)
+		default:
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			bodies = append(bodies, body)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	_, err := newJiraIssuePusher(server.URL, "")
+	assert.IsType(t, BadParameterError{}, err)
+	p, err := newJiraIssuePusher(server.URL, "t0ken")
+	require.Nil(t, err)
+	url := server.URL + "/rest/api/2/issue/10000"
+
+	content, err := p.update(url, map[AttributeExpression]interface{}{JiraTitle: "linking-updated", JiraState: "closed"})
+	require.Nil(t, err)
+	assert.Contains(t, string(content), `"summary":"linking"`)
+	assert.Equal(t, []string{
+		"GET /rest/api/2/issue/10000/transitions",
+		"PUT /rest/api/2/issue/10000",
+		"POST /rest/api/2/issue/10000/transitions",
+		"GET /rest/api/2/issue/10000",
+	}, requests)
+	require.Len(t, bodies, 2)
+	assert.Equal(t, map[string]interface{}{"fields": map[string]interface{}{"summary": "linking-updated"}}, bodies[0])
+	assert.Equal(t, map[string]interface{}{"transition": map[string]interface{}{"id": "21"}}, bodies[1])
+	for _, authorization := range authorizations {
+		assert.Equal(t, "Bearer t0ken", authorization)
+	}
+
+	// nothing is changed if there is no transition to the state
+	requests = nil
+	_, err = p.update(url, map[AttributeExpression]interface{}{JiraTitle: "linking-updated", JiraState: "resolved"})
+	assert.IsType(t, BadParameterError{}, err)
+	assert.Equal(t, []string{"GET /rest/api/2/issue/10000/transitions"}, requests)
+}
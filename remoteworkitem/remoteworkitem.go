@@ -54,10 +54,24 @@ var WorkItemKeyMaps = map[string]WorkItemMap{
 	},
 }
 
+// PushedAttributes lists the attributes of the remote items that local changes
+// are pushed to, per provider
+var PushedAttributes = map[string][]AttributeExpression{
+	ProviderGithub: {GithubTitle, GithubDescription, GithubState, GithubAssignee},
+	ProviderJira:   {JiraTitle, JiraBody, JiraState, JiraAssignee},
+}
+
 type AttributeConverter interface {
 	Convert(interface{}, AttributeAccessor) (interface{}, error)
 }
 
+// ReverseConverter is implemented by the attribute converters whose conversion
+// can be reverted, i.e. that can convert a local value back to the value of the
+// remote attribute
+type ReverseConverter interface {
+	ConvertBack(interface{}) (interface{}, error)
+}
+
 type StateConverter interface{}
 
 type StringConverter struct{}
@@ -119,6 +133,74 @@ func (jhc JiraStateConverter) Convert(value interface{}, item AttributeAccessor)
 	return value, nil
 }
 
+// ConvertBack returns the given value if it is a string
+func (sc StringConverter) ConvertBack(value interface{}) (interface{}, error) {
+	switch value.(type) {
+	case nil, string:
+		return value, nil
+	default:
+		return nil, errors.Errorf("Unexpected type of value to convert back: %T", value)
+	}
+}
+
+// ConvertBack returns the content of the given markup content
+func (converter MarkupConverter) ConvertBack(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return v, nil
+	case rendering.MarkupContent:
+		return v.Content, nil
+	case map[string]interface{}:
+		return rendering.NewMarkupContentFromMap(v).Content, nil
+	default:
+		return nil, errors.Errorf("Unexpected type of value to convert back: %T", value)
+	}
+}
+
+// ConvertBack returns the first element of the given list, remote items have a
+// single value only
+func (sc ListStringConverter) ConvertBack(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		if len(v) == 0 {
+			return nil, nil
+		}
+		return v[0], nil
+	case []string:
+		if len(v) == 0 {
+			return nil, nil
+		}
+		return v[0], nil
+	default:
+		return nil, errors.Errorf("Unexpected type of value to convert back: %T", value)
+	}
+}
+
+// ConvertBack maps the local state to the open and closed states of Github
+func (ghc GithubStateConverter) ConvertBack(value interface{}) (interface{}, error) {
+	state, ok := value.(string)
+	if !ok {
+		return nil, errors.Errorf("Unexpected type of value to convert back: %T", value)
+	}
+	if state == workitem.SystemStateClosed {
+		return "closed", nil
+	}
+	return "open", nil
+}
+
+// ConvertBack returns the local state as the name of the Jira status
+func (jhc JiraStateConverter) ConvertBack(value interface{}) (interface{}, error) {
+	state, ok := value.(string)
+	if !ok {
+		return nil, errors.Errorf("Unexpected type of value to convert back: %T", value)
+	}
+	return state, nil
+}
+
 type AttributeMapper struct {
 	expression         AttributeExpression
 	attributeConverter AttributeConverter
@@ -197,3 +279,21 @@ func Map(item AttributeAccessor, mapping WorkItemMap) (app.WorkItem, error) {
 	}
 	return workItem, nil
 }
+
+// ReverseMap maps a local WorkItem back to the attributes of a remote work item
+// using the inverse of the given mapping. Attributes whose converter can't be
+// reverted or whose local value can't be converted back are left out.
+func ReverseMap(workItem app.WorkItem, mapping WorkItemMap) map[AttributeExpression]interface{} {
+	attributes := make(map[AttributeExpression]interface{})
+	for from, to := range mapping {
+		converter, ok := from.attributeConverter.(ReverseConverter)
+		if !ok {
+			continue
+		}
+		value, err := converter.ConvertBack(workItem.Fields[to])
+		if err == nil {
+			attributes[from.expression] = value
+		}
+	}
+	return attributes
+}
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/rendering"
 	"github.com/almighty/almighty-core/resource"
 	"github.com/almighty/almighty-core/test"
 	"github.com/almighty/almighty-core/workitem"
@@ -349,3 +351,34 @@ func TestRemoteWorkItemImplRegistry(t *testing.T) {
 	assert.True(t, ok)
 
 }
+
+func TestReverseMap(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	workItem := app.WorkItem{Fields: map[string]interface{}{
+		workitem.SystemTitle:        "abc",
+		workitem.SystemDescription:  rendering.NewMarkupContent("some *body*", rendering.SystemMarkupMarkdown),
+		workitem.SystemState:        workitem.SystemStateResolved,
+		workitem.SystemAssignees:    []interface{}{"sbose78"},
+		workitem.SystemRemoteItemID: "https://api.github.com/repos/almighty-test/almighty-test-unit/issues/2",
+	}}
+
+	attributes := ReverseMap(workItem, WorkItemKeyMaps[ProviderGithub])
+	assert.Equal(t, "abc", attributes[GithubTitle])
+	assert.Equal(t, "some *body*", attributes[GithubDescription])
+	// Github only knows open and closed issues
+	assert.Equal(t, "open", attributes[GithubState])
+	assert.Equal(t, "sbose78", attributes[GithubAssignee])
+	assert.Equal(t, workItem.Fields[workitem.SystemRemoteItemID], attributes[GithubID])
+
+	workItem.Fields[workitem.SystemState] = workitem.SystemStateClosed
+	workItem.Fields[workitem.SystemAssignees] = []interface{}{}
+	attributes = ReverseMap(workItem, WorkItemKeyMaps[ProviderGithub])
+	assert.Equal(t, "closed", attributes[GithubState])
+	assert.Nil(t, attributes[GithubAssignee])
+
+	// the description is converted back by the markup converter only
+	attributes = ReverseMap(workItem, WorkItemKeyMaps[ProviderJira])
+	assert.Equal(t, "some *body*", attributes[JiraBody])
+	assert.Equal(t, workitem.SystemStateClosed, attributes[JiraState])
+}
@@ -1,8 +1,11 @@
 package remoteworkitem
 
 import (
+	"fmt"
 	"log"
+	"time"
 
+	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/models"
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
@@ -12,11 +15,12 @@ import (
 
 // TrackerSchedule capture all configuration
 type trackerSchedule struct {
-	TrackerID   int
-	URL         string
-	TrackerType string
-	Query       string
-	Schedule    string
+	TrackerQueryID uint64
+	TrackerID      int
+	URL            string
+	TrackerType    string
+	Query          string
+	Schedule       string
 }
 
 // Scheduler represents scheduler
@@ -49,41 +53,146 @@ func (s *Scheduler) ScheduleAllQueries() {
 
 	trackerQueries := fetchTrackerQueries(s.db)
 	for _, tq := range trackerQueries {
+		tq := tq
 		cr.AddFunc(tq.Schedule, func() {
-			tr := lookupProvider(tq)
-			for i := range tr.Fetch() {
-				models.Transactional(s.db, func(tx *gorm.DB) error {
-					// Save the remote items in a 'temporary' table.
-					err := upload(tx, tq.TrackerID, i)
-					if err != nil {
-						return errors.WithStack(err)
-					}
-					// Convert the remote item into a local work item and persist in the DB.
-					_, err = convert(tx, tq.TrackerID, i, tq.TrackerType)
-					return errors.WithStack(err)
-				})
-			}
+			s.run(tq)
 		})
 	}
 	cr.Start()
 }
 
+// run fetches and imports the items of the tracker query that were updated
+// since its watermark and records the run. The run only counts as successful
+// and the watermark only advances if all items were fetched and imported, so
+// that the next run fetches the skipped items again.
+func (s *Scheduler) run(tq trackerSchedule) {
+	run := TrackerQueryRun{TrackerQueryID: tq.TrackerQueryID, StartedAt: time.Now()}
+	if err := s.db.Create(&run).Error; err != nil {
+		log.Printf("Recording the run of tracker query %d failed %v\n", tq.TrackerQueryID, err)
+		return
+	}
+	defer s.finish(&run)
+
+	w, err := loadWatermark(s.db, tq.TrackerQueryID)
+	if err != nil {
+		run.Errors = append(run.Errors, fmt.Sprintf("loading the watermark failed: %v", err))
+		return
+	}
+	etag := w.ETag
+	report := FetchReport{}
+	tr := lookupProvider(tq, &w, &report)
+	if tr == nil {
+		run.Errors = append(run.Errors, fmt.Sprintf("unknown type of tracker %d: %s", tq.TrackerID, tq.TrackerType))
+		return
+	}
+	var lastUpdatedAt *time.Time
+	for i := range tr.Fetch() {
+		run.Fetched++
+		created := false
+		err := models.Transactional(s.db, func(tx *gorm.DB) error {
+			created = tx.Where("remote_item_id = ? AND tracker_id = ?", i.ID, tq.TrackerID).Find(&TrackerItem{}).RecordNotFound()
+			_, err := importItem(tx, tq.TrackerID, i, tq.TrackerType)
+			return err
+		})
+		switch {
+		case err != nil:
+			run.Failed++
+			run.Errors = append(run.Errors, fmt.Sprintf("importing item %s failed: %v", i.ID, err))
+		case created:
+			run.Created++
+		default:
+			run.Updated++
+		}
+		if i.UpdatedAt != nil && (lastUpdatedAt == nil || i.UpdatedAt.After(*lastUpdatedAt)) {
+			lastUpdatedAt = i.UpdatedAt
+		}
+	}
+	run.Errors = append(run.Errors, report.Errors...)
+	run.RateLimitWaits = report.RateLimitWaits
+	run.RateLimitWaitSeconds = int(report.RateLimitWait / time.Second)
+	if run.Failed > 0 {
+		// don't skip the failed items in the next run
+		w.ETag = etag
+	}
+	// the errors of failed imports and of the fetch both fail the run
+	if len(run.Errors) == 0 {
+		w.LastRunAt = &run.StartedAt
+		if lastUpdatedAt != nil && (w.LastUpdatedAt == nil || lastUpdatedAt.After(*w.LastUpdatedAt)) {
+			w.LastUpdatedAt = lastUpdatedAt
+		}
+	}
+	if err := saveWatermark(s.db, tq.TrackerQueryID, w); err != nil {
+		run.Errors = append(run.Errors, fmt.Sprintf("saving the watermark failed: %v", err))
+	}
+}
+
+// finish records the end of the run and deletes the oldest runs of its
+// tracker query, so that at most maxTrackerQueryRuns are kept
+func (s *Scheduler) finish(run *TrackerQueryRun) {
+	now := time.Now()
+	run.FinishedAt = &now
+	for _, message := range run.Errors {
+		log.Printf("Run %d of tracker query %d: %s\n", run.ID, run.TrackerQueryID, message)
+	}
+	if err := s.db.Save(run).Error; err != nil {
+		log.Printf("Recording the run of tracker query %d failed %v\n", run.TrackerQueryID, err)
+		return
+	}
+	err := s.db.Exec("DELETE FROM tracker_query_runs WHERE tracker_query_id = ? AND id NOT IN (SELECT id FROM tracker_query_runs WHERE tracker_query_id = ? ORDER BY started_at DESC, id DESC LIMIT ?)", run.TrackerQueryID, run.TrackerQueryID, maxTrackerQueryRuns).Error
+	if err != nil {
+		log.Printf("Deleting old runs of tracker query %d failed %v\n", run.TrackerQueryID, err)
+	}
+}
+
+// loadWatermark returns the watermark of the tracker query with the given ID
+func loadWatermark(db *gorm.DB, trackerQueryID uint64) (Watermark, error) {
+	tq := TrackerQuery{}
+	if err := db.First(&tq, trackerQueryID).Error; err != nil {
+		return Watermark{}, errors.WithStack(err)
+	}
+	return tq.Watermark, nil
+}
+
+// saveWatermark stores the watermark of the tracker query with the given ID
+func saveWatermark(db *gorm.DB, trackerQueryID uint64, w Watermark) error {
+	err := db.Model(&TrackerQuery{ID: trackerQueryID}).UpdateColumns(map[string]interface{}{
+		"last_run_at":     w.LastRunAt,
+		"last_updated_at": w.LastUpdatedAt,
+		"etag":            w.ETag,
+	}).Error
+	return errors.WithStack(err)
+}
+
+// importItem saves the remote item and converts it into a local work item
+func importItem(db *gorm.DB, trackerID int, item TrackerItemContent, provider string) (*app.WorkItem, error) {
+	// Save the remote items in a 'temporary' table.
+	err := upload(db, trackerID, item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// Convert the remote item into a local work item and persist in the DB.
+	wi, err := convert(db, trackerID, item, provider)
+	return wi, errors.WithStack(err)
+}
+
 func fetchTrackerQueries(db *gorm.DB) []trackerSchedule {
 	tsList := []trackerSchedule{}
-	err := db.Table("tracker_queries").Select("trackers.id as tracker_id, trackers.url, trackers.type as tracker_type, tracker_queries.query, tracker_queries.schedule").Joins("left join trackers on tracker_queries.tracker_id = trackers.id").Where("trackers.deleted_at is NULL AND tracker_queries.deleted_at is NULL").Scan(&tsList).Error
+	err := db.Table("tracker_queries").Select("tracker_queries.id as tracker_query_id, trackers.id as tracker_id, trackers.url, trackers.type as tracker_type, tracker_queries.query, tracker_queries.schedule").Joins("left join trackers on tracker_queries.tracker_id = trackers.id").Where("trackers.deleted_at is NULL AND tracker_queries.deleted_at is NULL").Scan(&tsList).Error
 	if err != nil {
 		log.Printf("Fetch failed %v\n", err)
 	}
 	return tsList
 }
 
-// lookupProvider provides the respective tracker based on the type
-func lookupProvider(ts trackerSchedule) TrackerProvider {
+// lookupProvider provides the respective tracker based on the type. The
+// optional watermark restricts the fetch to the items updated since then, the
+// optional report collects the errors of the fetch.
+func lookupProvider(ts trackerSchedule, w *Watermark, report *FetchReport) TrackerProvider {
 	switch ts.TrackerType {
 	case ProviderGithub:
-		return &GithubTracker{URL: ts.URL, Query: ts.Query}
+		return &GithubTracker{URL: ts.URL, Query: ts.Query, Watermark: w, Report: report}
 	case ProviderJira:
-		return &JiraTracker{URL: ts.URL, Query: ts.Query}
+		return &JiraTracker{URL: ts.URL, Query: ts.Query, Watermark: w, Report: report}
 	}
 	return nil
 }
@@ -92,6 +201,8 @@ func lookupProvider(ts trackerSchedule) TrackerProvider {
 type TrackerItemContent struct {
 	ID      string
 	Content []byte
+	// UpdatedAt is the time the remote item was last updated, nil if unknown
+	UpdatedAt *time.Time
 }
 
 // TrackerProvider represents a remote tracker
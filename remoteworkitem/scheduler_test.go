@@ -2,8 +2,11 @@ package remoteworkitem
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/almighty/almighty-core/configuration"
 	"github.com/almighty/almighty-core/migration"
@@ -12,6 +15,8 @@ import (
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/jinzhu/gorm"
 	_ "github.com/lib/pq"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
 )
@@ -55,14 +60,138 @@ func TestNewScheduler(t *testing.T) {
 func TestLookupProvider(t *testing.T) {
 	resource.Require(t, resource.Database)
 	ts1 := trackerSchedule{TrackerType: ProviderGithub}
-	tp1 := lookupProvider(ts1)
+	tp1 := lookupProvider(ts1, nil, nil)
 	require.NotNil(t, tp1)
 
 	ts2 := trackerSchedule{TrackerType: ProviderJira}
-	tp2 := lookupProvider(ts2)
+	tp2 := lookupProvider(ts2, nil, nil)
 	require.NotNil(t, tp2)
 
 	ts3 := trackerSchedule{TrackerType: "unknown"}
-	tp3 := lookupProvider(ts3)
+	tp3 := lookupProvider(ts3, nil, nil)
 	require.Nil(t, tp3)
 }
+
+func TestSchedulerRun(t *testing.T) {
+	resource.Require(t, resource.Database)
+
+	remoteID := "https://issues.jboss.org/rest/api/2/issue/" + uuid.NewV4().String()
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/rest/api/2/search":
+			fmt.Fprint(w, `{"issues":[{"key":"ARQ-1"}]}`)
+		case failing:
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			fmt.Fprintf(w, `{"key":"ARQ-1","self":"%s","fields":{"summary":"linking","status":{"name":"open"},"updated":"2017-01-02T16:04:05.000+0100"}}`, remoteID)
+		}
+	}))
+	defer server.Close()
+
+	tr := Tracker{URL: server.URL, Type: ProviderJira}
+	require.Nil(t, db.Create(&tr).Error)
+	defer db.Delete(&tr)
+	tq := TrackerQuery{Query: "project = ARQ", Schedule: "0 0 0 * * *", TrackerID: tr.ID}
+	require.Nil(t, db.Create(&tq).Error)
+	defer db.Delete(&tq)
+	defer db.Where("tracker_query_id = ?", tq.ID).Delete(TrackerQueryRun{})
+	defer db.Where("tracker_id = ?", tr.ID).Delete(TrackerItem{})
+	defer db.Where("fields->>? = ?", workitem.SystemRemoteItemID, remoteID).Delete(workitem.WorkItem{})
+	s := NewScheduler(db)
+	ts := trackerSchedule{TrackerQueryID: tq.ID, TrackerID: int(tr.ID), URL: tr.URL, TrackerType: tr.Type, Query: tq.Query}
+	runs := func() []TrackerQueryRun {
+		var result []TrackerQueryRun
+		require.Nil(t, db.Where("tracker_query_id = ?", tq.ID).Order("id").Find(&result).Error)
+		return result
+	}
+
+	s.run(ts)
+	s.run(ts)
+	result := runs()
+	require.Len(t, result, 2)
+	assert.Equal(t, 1, result[0].Fetched)
+	assert.Equal(t, 1, result[0].Created)
+	assert.Equal(t, 0, result[0].Updated)
+	assert.Empty(t, result[0].Errors)
+	assert.NotNil(t, result[0].FinishedAt)
+	assert.Equal(t, 1, result[1].Updated)
+	w, err := loadWatermark(db, tq.ID)
+	require.Nil(t, err)
+	require.NotNil(t, w.LastRunAt)
+	lastRunAt := *w.LastRunAt
+	require.NotNil(t, w.LastUpdatedAt)
+	lastUpdatedAt := *w.LastUpdatedAt
+
+	t.Log("Fetch errors are recorded and the run doesn't count as successful")
+	failing = true
+	s.run(ts)
+	result = runs()
+	require.Len(t, result, 3)
+	assert.Equal(t, 0, result[2].Fetched)
+	require.Len(t, result[2].Errors, 1)
+	assert.Contains(t, result[2].Errors[0], "ARQ-1")
+	w, err = loadWatermark(db, tq.ID)
+	require.Nil(t, err)
+	assert.True(t, lastRunAt.Equal(*w.LastRunAt))
+	// the watermark agrees with the failed run
+	assert.True(t, lastUpdatedAt.Equal(*w.LastUpdatedAt))
+}
+
+func TestSchedulerRunKeepsWatermarkOfPartialFetch(t *testing.T) {
+	resource.Require(t, resource.Database)
+
+	remoteID := "https://issues.jboss.org/rest/api/2/issue/" + uuid.NewV4().String()
+	failing := true
+	var jqls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/rest/api/2/search":
+			jqls = append(jqls, r.URL.Query().Get("jql"))
+			fmt.Fprint(w, `{"issues":[{"key":"ARQ-1"},{"key":"ARQ-2"}]}`)
+		case r.URL.Path == "/rest/api/2/issue/ARQ-1":
+			fmt.Fprintf(w, `{"key":"ARQ-1","self":"%s-1","fields":{"summary":"linking","status":{"name":"open"},"updated":"2017-01-02T16:04:05.000+0100"}}`, remoteID)
+		case failing:
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			fmt.Fprintf(w, `{"key":"ARQ-2","self":"%s-2","fields":{"summary":"linking","status":{"name":"open"},"updated":"2017-01-03T16:04:05.000+0100"}}`, remoteID)
+		}
+	}))
+	defer server.Close()
+
+	tr := Tracker{URL: server.URL, Type: ProviderJira}
+	require.Nil(t, db.Create(&tr).Error)
+	defer db.Delete(&tr)
+	tq := TrackerQuery{Query: "project = ARQ ORDER BY key", Schedule: "0 0 0 * * *", TrackerID: tr.ID}
+	require.Nil(t, db.Create(&tq).Error)
+	defer db.Delete(&tq)
+	defer db.Where("tracker_query_id = ?", tq.ID).Delete(TrackerQueryRun{})
+	defer db.Where("tracker_id = ?", tr.ID).Delete(TrackerItem{})
+	defer db.Where("fields->>? LIKE ?", workitem.SystemRemoteItemID, remoteID+"%").Delete(workitem.WorkItem{})
+	s := NewScheduler(db)
+	ts := trackerSchedule{TrackerQueryID: tq.ID, TrackerID: int(tr.ID), URL: tr.URL, TrackerType: tr.Type, Query: tq.Query}
+
+	// the first fetch is oldest first as well and fails after the first issue
+	s.run(ts)
+	assert.Equal(t, []string{"project = ARQ ORDER BY updated ASC"}, jqls)
+	var run TrackerQueryRun
+	require.Nil(t, db.Where("tracker_query_id = ?", tq.ID).First(&run).Error)
+	assert.Equal(t, 1, run.Fetched)
+	assert.Equal(t, 1, run.Created)
+	require.Len(t, run.Errors, 1)
+	assert.Contains(t, run.Errors[0], "ARQ-2")
+	w, err := loadWatermark(db, tq.ID)
+	require.Nil(t, err)
+	assert.Nil(t, w.LastUpdatedAt)
+	assert.Nil(t, w.LastRunAt)
+
+	// the next run fetches all issues again and advances the watermark
+	failing = false
+	s.run(ts)
+	w, err = loadWatermark(db, tq.ID)
+	require.Nil(t, err)
+	require.NotNil(t, w.LastUpdatedAt)
+	assert.True(t, time.Date(2017, 1, 3, 15, 4, 5, 0, time.UTC).Equal(*w.LastUpdatedAt))
+	require.Len(t, jqls, 2)
+	assert.Equal(t, jqls[0], jqls[1])
+}
@@ -10,4 +10,13 @@ type Tracker struct {
 	URL string
 	// Type of the tracker (jira, github, bugzilla, trello etc.)
 	Type string
+	// WebhookSecret verifies the webhook deliveries of the tracker, trackers
+	// without a secret don't accept webhooks
+	WebhookSecret string
+	// PushToken authenticates the pushes of local changes to the tracker,
+	// the configured token is used for Github trackers without one
+	PushToken string
+	// FieldMappings define how the remote items are imported, the default
+	// mapping of the tracker type is used without them
+	FieldMappings FieldMappings `sql:"type:jsonb"`
 }
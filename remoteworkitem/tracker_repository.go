@@ -25,9 +25,12 @@ func NewTrackerRepository(db *gorm.DB) *GormTrackerRepository {
 	return &GormTrackerRepository{db}
 }
 
-// Create creates a new tracker configuration in the repository
+// Create creates a new tracker configuration in the repository. An empty
+// webhook secret disables webhooks for the tracker, an empty push token
+// disables pushes to Jira trackers and without field mappings the default
+// mapping of the tracker type is used.
 // returns BadParameterError, ConversionError or InternalError
-func (r *GormTrackerRepository) Create(ctx context.Context, url string, typeID string) (*app.Tracker, error) {
+func (r *GormTrackerRepository) Create(ctx context.Context, url string, typeID string, webhookSecret string, pushToken string, fieldMappings *app.FieldMappings) (*app.Tracker, error) {
 	//URL Validation
 	isValid := govalidator.IsURL(url)
 	if isValid != true {
@@ -40,17 +43,21 @@ func (r *GormTrackerRepository) Create(ctx context.Context, url string, typeID s
 		return nil, BadParameterError{parameter: "type", value: typeID}
 	}
 	t := Tracker{
-		URL:  url,
-		Type: typeID}
+		URL:           url,
+		Type:          typeID,
+		WebhookSecret: webhookSecret,
+		PushToken:     pushToken,
+		FieldMappings: convertFieldMappingsToModel(fieldMappings)}
+	if err := t.FieldMappings.Validate(r.db); err != nil {
+		return nil, err
+	}
 	tx := r.db
 	if err := tx.Create(&t).Error; err != nil {
 		return nil, InternalError{simpleError{err.Error()}}
 	}
-	log.Printf("created tracker %v\n", t)
-	t2 := app.Tracker{
-		ID:   strconv.FormatUint(t.ID, 10),
-		URL:  url,
-		Type: typeID}
+	// the webhook secret and the push token must not be logged
+	log.Printf("created tracker %d of type %s for %s\n", t.ID, t.Type, t.URL)
+	t2 := convertTrackerFromModel(t)
 
 	return &t2, nil
 }
@@ -68,16 +75,13 @@ func (r *GormTrackerRepository) Load(ctx context.Context, ID string) (*app.Track
 	res := Tracker{}
 	tx := r.db.First(&res, id)
 	if tx.RecordNotFound() {
-		log.Printf("not found, res=%v", res)
+		log.Printf("not found, id=%d", id)
 		return nil, NotFoundError{"tracker", ID}
 	}
 	if tx.Error != nil {
 		return nil, InternalError{simpleError{fmt.Sprintf("error while loading: %s", tx.Error.Error())}}
 	}
-	t := app.Tracker{
-		ID:   strconv.FormatUint(res.ID, 10),
-		URL:  res.URL,
-		Type: res.Type}
+	t := convertTrackerFromModel(res)
 
 	return &t, nil
 }
@@ -105,18 +109,17 @@ func (r *GormTrackerRepository) List(ctx context.Context, criteria criteria.Expr
 	result := make([]*app.Tracker, len(rows))
 
 	for i, tracker := range rows {
-		t := app.Tracker{
-			ID:   strconv.FormatUint(tracker.ID, 10),
-			URL:  tracker.URL,
-			Type: tracker.Type}
+		t := convertTrackerFromModel(tracker)
 		result[i] = &t
 	}
 	return result, nil
 }
 
-// Save updates the given tracker in storage.
+// Save updates the given tracker in storage. The webhook secret, the push token
+// and the field mappings are only changed if they are given, field mappings
+// without fields restore the default mapping of the tracker type.
 // returns NotFoundError, ConversionError or InternalError
-func (r *GormTrackerRepository) Save(ctx context.Context, t app.Tracker) (*app.Tracker, error) {
+func (r *GormTrackerRepository) Save(ctx context.Context, t app.Tracker, webhookSecret *string, pushToken *string) (*app.Tracker, error) {
 	res := Tracker{}
 	id, err := strconv.ParseUint(t.ID, 10, 64)
 	if err != nil || id == 0 {
@@ -126,7 +129,7 @@ func (r *GormTrackerRepository) Save(ctx context.Context, t app.Tracker) (*app.T
 	log.Printf("looking for id %d", id)
 	tx := r.db.First(&res, id)
 	if tx.RecordNotFound() {
-		log.Printf("not found, res=%v", res)
+		log.Printf("not found, id=%d", id)
 		return nil, NotFoundError{entity: "tracker", ID: t.ID}
 	}
 	_, present := RemoteWorkItemImplRegistry[t.Type]
@@ -136,19 +139,31 @@ func (r *GormTrackerRepository) Save(ctx context.Context, t app.Tracker) (*app.T
 	}
 
 	newT := Tracker{
-		ID:   id,
-		URL:  t.URL,
-		Type: t.Type}
+		ID:            id,
+		URL:           t.URL,
+		Type:          t.Type,
+		WebhookSecret: res.WebhookSecret,
+		PushToken:     res.PushToken,
+		FieldMappings: res.FieldMappings}
+	if webhookSecret != nil {
+		newT.WebhookSecret = *webhookSecret
+	}
+	if pushToken != nil {
+		newT.PushToken = *pushToken
+	}
+	if t.FieldMappings != nil {
+		newT.FieldMappings = convertFieldMappingsToModel(t.FieldMappings)
+		if err := newT.FieldMappings.Validate(r.db); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := tx.Save(&newT).Error; err != nil {
 		log.Print(err.Error())
 		return nil, InternalError{simpleError{err.Error()}}
 	}
-	log.Printf("updated tracker to %v\n", newT)
-	t2 := app.Tracker{
-		ID:   strconv.FormatUint(id, 10),
-		URL:  t.URL,
-		Type: t.Type}
+	log.Printf("updated tracker %d to type %s for %s\n", newT.ID, newT.Type, newT.URL)
+	t2 := convertTrackerFromModel(newT)
 
 	return &t2, nil
 }
@@ -172,3 +187,13 @@ func (r *GormTrackerRepository) Delete(ctx context.Context, ID string) error {
 	}
 	return nil
 }
+
+// convertTrackerFromModel converts the tracker and its field mappings to the
+// REST representation, the webhook secret and the push token are never exposed
+func convertTrackerFromModel(t Tracker) app.Tracker {
+	return app.Tracker{
+		ID:            strconv.FormatUint(t.ID, 10),
+		URL:           t.URL,
+		Type:          t.Type,
+		FieldMappings: convertFieldMappingsFromModel(t.FieldMappings)}
+}
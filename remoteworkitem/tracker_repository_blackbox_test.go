@@ -52,7 +52,10 @@ func (s *trackerRepoBlackBoxTest) TestFailDeleteZeroID() {
 	_, err := s.repo.Create(
 		context.Background(),
 		"http://api.github.com",
-		remoteworkitem.ProviderGithub)
+		remoteworkitem.ProviderGithub,
+		"",
+		"",
+		nil)
 
 	if err != nil {
 		s.T().Error("Could not create tracker", err)
@@ -69,14 +72,17 @@ func (s *trackerRepoBlackBoxTest) TestFailSaveZeroID() {
 	tr, err := s.repo.Create(
 		context.Background(),
 		"http://api.github.com",
-		remoteworkitem.ProviderGithub)
+		remoteworkitem.ProviderGithub,
+		"",
+		"",
+		nil)
 
 	if err != nil {
 		s.T().Error("Could not create tracker", err)
 	}
 	tr.ID = "0"
 
-	_, err = s.repo.Save(context.Background(), *tr)
+	_, err = s.repo.Save(context.Background(), *tr, nil, nil)
 	require.IsType(s.T(), remoteworkitem.NotFoundError{}, err)
 }
 
@@ -87,7 +93,10 @@ func (s *trackerRepoBlackBoxTest) TestFaiLoadZeroID() {
 	_, err := s.repo.Create(
 		context.Background(),
 		"http://api.github.com",
-		remoteworkitem.ProviderGithub)
+		remoteworkitem.ProviderGithub,
+		"",
+		"",
+		nil)
 
 	if err != nil {
 		s.T().Error("Could not create tracker", err)
@@ -12,15 +12,16 @@ import (
 	"github.com/almighty/almighty-core/resource"
 	"github.com/jinzhu/gorm"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTrackerCreate(t *testing.T) {
 	doWithTrackerRepository(t, func(trackerRepo application.TrackerRepository) {
-		tracker, err := trackerRepo.Create(context.Background(), "gugus", "dada")
+		tracker, err := trackerRepo.Create(context.Background(), "gugus", "dada", "", "", nil)
 		assert.IsType(t, BadParameterError{}, err)
 		assert.Nil(t, tracker)
 
-		tracker, err = trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub)
+		tracker, err = trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub, "", "", nil)
 		assert.Nil(t, err)
 		assert.NotNil(t, tracker)
 		assert.Equal(t, "http://api.github.com", tracker.URL)
@@ -34,30 +35,30 @@ func TestTrackerCreate(t *testing.T) {
 
 func TestTrackerSave(t *testing.T) {
 	doWithTrackerRepository(t, func(trackerRepo application.TrackerRepository) {
-		tracker, err := trackerRepo.Save(context.Background(), app.Tracker{})
+		tracker, err := trackerRepo.Save(context.Background(), app.Tracker{}, nil, nil)
 		assert.IsType(t, NotFoundError{}, err)
 		assert.Nil(t, tracker)
 
-		tracker, _ = trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub)
+		tracker, _ = trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub, "", "", nil)
 		tracker.Type = "blabla"
-		tracker2, err := trackerRepo.Save(context.Background(), *tracker)
+		tracker2, err := trackerRepo.Save(context.Background(), *tracker, nil, nil)
 		log.Println("--------", tracker2)
 		assert.IsType(t, BadParameterError{}, err)
 		assert.Nil(t, tracker2)
 
 		tracker.Type = ProviderJira
 		tracker.URL = "blabla"
-		tracker, err = trackerRepo.Save(context.Background(), *tracker)
+		tracker, err = trackerRepo.Save(context.Background(), *tracker, nil, nil)
 		assert.Equal(t, ProviderJira, tracker.Type)
 		assert.Equal(t, "blabla", tracker.URL)
 
 		tracker.ID = "10000"
-		tracker2, err = trackerRepo.Save(context.Background(), *tracker)
+		tracker2, err = trackerRepo.Save(context.Background(), *tracker, nil, nil)
 		assert.IsType(t, NotFoundError{}, err)
 		assert.Nil(t, tracker2)
 
 		tracker.ID = "asdf"
-		tracker2, err = trackerRepo.Save(context.Background(), *tracker)
+		tracker2, err = trackerRepo.Save(context.Background(), *tracker, nil, nil)
 		assert.IsType(t, NotFoundError{}, err)
 		assert.Nil(t, tracker2)
 
@@ -75,7 +76,7 @@ func TestTrackerDelete(t *testing.T) {
 		err = trackerRepo.Delete(context.Background(), "10000")
 		assert.IsType(t, NotFoundError{}, err)
 
-		tracker, _ := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub)
+		tracker, _ := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub, "", "", nil)
 		err = trackerRepo.Delete(context.Background(), tracker.ID)
 		assert.Nil(t, err)
 
@@ -89,14 +90,69 @@ func TestTrackerDelete(t *testing.T) {
 	})
 }
 
+func TestTrackerPushToken(t *testing.T) {
+	doWithTransaction(t, func(db *gorm.DB) {
+		trackerRepo := NewTrackerRepository(db)
+		tracker, err := trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira, "", "t0ken", nil)
+		require.Nil(t, err)
+		load := func() Tracker {
+			var result Tracker
+			require.Nil(t, db.First(&result, tracker.ID).Error)
+			return result
+		}
+		assert.Equal(t, "t0ken", load().PushToken)
+
+		// the token is kept if none is given
+		_, err = trackerRepo.Save(context.Background(), *tracker, nil, nil)
+		require.Nil(t, err)
+		assert.Equal(t, "t0ken", load().PushToken)
+		empty := ""
+		_, err = trackerRepo.Save(context.Background(), *tracker, nil, &empty)
+		require.Nil(t, err)
+		assert.Equal(t, "", load().PushToken)
+	})
+}
+
+func TestTrackerFieldMappings(t *testing.T) {
+	resource.Require(t, resource.Database)
+	name := createJiraIssueType(t)
+	mappings := convertFieldMappingsFromModel(jiraFieldMappings(name))
+
+	doWithTrackerRepository(t, func(trackerRepo application.TrackerRepository) {
+		tracker, err := trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira, "", "", mappings)
+		require.Nil(t, err)
+		assert.Equal(t, mappings, tracker.FieldMappings)
+
+		// mappings are kept if none are given
+		tracker.FieldMappings = nil
+		tracker, err = trackerRepo.Save(context.Background(), *tracker, nil, nil)
+		require.Nil(t, err)
+		assert.Equal(t, mappings, tracker.FieldMappings)
+
+		invalid := convertFieldMappingsFromModel(jiraFieldMappings(name))
+		invalid.Fields[1].Field = "unknown"
+		tracker.FieldMappings = invalid
+		_, err = trackerRepo.Save(context.Background(), *tracker, nil, nil)
+		assert.IsType(t, BadParameterError{}, err)
+		_, err = trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira, "", "", invalid)
+		assert.IsType(t, BadParameterError{}, err)
+
+		// mappings without fields restore the default mapping
+		tracker.FieldMappings = &app.FieldMappings{WorkItemType: name, Fields: []*app.FieldMapping{}}
+		tracker, err = trackerRepo.Save(context.Background(), *tracker, nil, nil)
+		require.Nil(t, err)
+		assert.Nil(t, tracker.FieldMappings)
+	})
+}
+
 func TestTrackerList(t *testing.T) {
 	doWithTrackerRepository(t, func(trackerRepo application.TrackerRepository) {
 		trackers, _ := trackerRepo.List(context.Background(), criteria.Literal(true), nil, nil)
 
-		trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub)
-		trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira)
-		trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira)
-		trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub)
+		trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub, "", "", nil)
+		trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira, "", "", nil)
+		trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira, "", "", nil)
+		trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub, "", "", nil)
 
 		trackers2, _ := trackerRepo.List(context.Background(), criteria.Literal(true), nil, nil)
 
@@ -10,6 +10,7 @@ import (
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 )
 
 // upload imports the items into database
@@ -46,7 +47,11 @@ func convert(db *gorm.DB, tID int, item TrackerItemContent, provider string) (*a
 	if err != nil {
 		return nil, InternalError{simpleError{message: " Error parsing the tracker data "}}
 	}
-	workItem, err := Map(remoteTrackerItem, WorkItemKeyMaps[provider])
+	mapping, workItemType, err := trackerMapping(db, uint64(tID), provider)
+	if err != nil {
+		return nil, err
+	}
+	workItem, err := Map(remoteTrackerItem, mapping)
 	if err != nil {
 		return nil, ConversionError{simpleError{message: " Error mapping to local work item "}}
 	}
@@ -59,7 +64,7 @@ func convert(db *gorm.DB, tID int, item TrackerItemContent, provider string) (*a
 	var newWorkItem *app.WorkItem
 
 	// Querying the database
-	existingWorkItems, _, err := wir.List(context.Background(), sqlExpression, nil, nil)
+	existingWorkItems, _, _, err := wir.List(context.Background(), sqlExpression, nil, nil, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +75,7 @@ func convert(db *gorm.DB, tID int, item TrackerItemContent, provider string) (*a
 		for key, value := range workItem.Fields {
 			existingWorkItem.Fields[key] = value
 		}
-		newWorkItem, err = wir.Save(context.Background(), *existingWorkItem)
+		newWorkItem, err = wir.Save(context.Background(), *existingWorkItem, uuid.Nil)
 		if err != nil {
 			fmt.Println("Error updating work item : ", err)
 		}
@@ -81,7 +86,7 @@ func convert(db *gorm.DB, tID int, item TrackerItemContent, provider string) (*a
 		if c != nil {
 			creator = c.(string)
 		}
-		newWorkItem, err = wir.Create(context.Background(), workitem.SystemBug, workItem.Fields, creator)
+		newWorkItem, err = wir.Create(context.Background(), nil, workItemType, workItem.Fields, creator)
 		if err != nil {
 			fmt.Println("Error creating work item : ", err)
 		}
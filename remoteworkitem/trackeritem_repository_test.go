@@ -12,6 +12,7 @@ import (
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -54,7 +55,7 @@ func TestConvertNewWorkItem(t *testing.T) {
 		assert.Equal(t, rendering.SystemMarkupMarkdown, description.Markup)
 
 		wir := workitem.NewWorkItemRepository(db)
-		wir.Delete(context.Background(), workItem.ID)
+		wir.Delete(context.Background(), workItem.ID, uuid.Nil)
 
 		return errors.WithStack(err)
 	})
@@ -110,7 +111,7 @@ func TestConvertExistingWorkItem(t *testing.T) {
 		assert.Equal(t, "closed", workItemUpdated.Fields[workitem.SystemState])
 
 		wir := workitem.NewWorkItemRepository(tx)
-		wir.Delete(context.Background(), workItemUpdated.ID)
+		wir.Delete(context.Background(), workItemUpdated.ID, uuid.Nil)
 
 		return errors.WithStack(err)
 	})
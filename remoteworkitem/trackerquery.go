@@ -1,6 +1,10 @@
 package remoteworkitem
 
-import "github.com/almighty/almighty-core/gormsupport"
+import (
+	"time"
+
+	"github.com/almighty/almighty-core/gormsupport"
+)
 
 // TrackerQuery represents tracker query
 type TrackerQuery struct {
@@ -12,4 +16,18 @@ type TrackerQuery struct {
 	Schedule string
 	// TrackerID is a foreign key for a tracker
 	TrackerID uint64 `gorm:"ForeignKey:Tracker"`
+	// Watermark of the previous runs of the query
+	Watermark
+}
+
+// Watermark is the sync state of a tracker query. Scheduled runs only fetch
+// the items that were updated since the latest update time of the items
+// fetched before.
+type Watermark struct {
+	// LastRunAt is the start of the last successful run
+	LastRunAt *time.Time
+	// LastUpdatedAt is the latest update time of the fetched items
+	LastUpdatedAt *time.Time
+	// ETag of the first page of the last fetch, for conditional requests
+	ETag string `gorm:"column:etag"`
 }
@@ -63,12 +63,7 @@ func (r *GormTrackerQueryRepository) Load(ctx context.Context, ID string) (*app.
 		log.Printf("not found, res=%v", res)
 		return nil, NotFoundError{"tracker query", ID}
 	}
-	tq := app.TrackerQuery{
-		ID:        strconv.FormatUint(res.ID, 10),
-		Query:     res.Query,
-		Schedule:  res.Schedule,
-		TrackerID: strconv.FormatUint(res.TrackerID, 10)}
-
+	tq := convertTrackerQueryFromModel(res)
 	return &tq, nil
 }
 
@@ -111,18 +106,17 @@ func (r *GormTrackerQueryRepository) Save(ctx context.Context, tq app.TrackerQue
 		Schedule:  tq.Schedule,
 		Query:     tq.Query,
 		TrackerID: tid}
+	// the watermark is only valid for the items of the same query
+	if res.Query == newTq.Query && res.TrackerID == newTq.TrackerID {
+		newTq.Watermark = res.Watermark
+	}
 
 	if err := tx.Save(&newTq).Error; err != nil {
 		log.Print(err.Error())
 		return nil, InternalError{simpleError{err.Error()}}
 	}
 	log.Printf("updated tracker query to %v\n", newTq)
-	t2 := app.TrackerQuery{
-		ID:        tq.ID,
-		Schedule:  tq.Schedule,
-		Query:     tq.Query,
-		TrackerID: tq.TrackerID}
-
+	t2 := convertTrackerQueryFromModel(newTq)
 	return &t2, nil
 }
 
@@ -155,12 +149,67 @@ func (r *GormTrackerQueryRepository) List(ctx context.Context) ([]*app.TrackerQu
 	}
 	result := make([]*app.TrackerQuery, len(rows))
 	for i, tq := range rows {
-		t := app.TrackerQuery{
-			ID:        strconv.FormatUint(tq.ID, 10),
-			Schedule:  tq.Schedule,
-			Query:     tq.Query,
-			TrackerID: strconv.FormatUint(tq.TrackerID, 10)}
+		t := convertTrackerQueryFromModel(tq)
 		result[i] = &t
 	}
 	return result, nil
 }
+
+// ListRuns returns the recorded runs of the tracker query with the given id,
+// the latest first
+// returns NotFoundError or InternalError
+func (r *GormTrackerQueryRepository) ListRuns(ctx context.Context, ID string) ([]*app.TrackerQueryRun, error) {
+	id, err := strconv.ParseUint(ID, 10, 64)
+	if err != nil || id == 0 {
+		// treating this as a not found error: the fact that we're using number internal is implementation detail
+		return nil, NotFoundError{"tracker query", ID}
+	}
+	tx := r.db.First(&TrackerQuery{}, id)
+	if tx.RecordNotFound() {
+		return nil, NotFoundError{"tracker query", ID}
+	}
+	if tx.Error != nil {
+		return nil, InternalError{simpleError{fmt.Sprintf("error while loading: %s", tx.Error.Error())}}
+	}
+	var rows []TrackerQueryRun
+	if err := r.db.Where("tracker_query_id = ?", id).Order("started_at desc, id desc").Find(&rows).Error; err != nil {
+		return nil, InternalError{simpleError{err.Error()}}
+	}
+	result := make([]*app.TrackerQueryRun, len(rows))
+	for i, run := range rows {
+		messages := []string(run.Errors)
+		if messages == nil {
+			messages = []string{}
+		}
+		result[i] = &app.TrackerQueryRun{
+			ID:                   strconv.FormatUint(run.ID, 10),
+			TrackerQueryID:       ID,
+			StartedAt:            run.StartedAt,
+			FinishedAt:           run.FinishedAt,
+			Fetched:              run.Fetched,
+			Created:              run.Created,
+			Updated:              run.Updated,
+			Failed:               run.Failed,
+			Errors:               messages,
+			RateLimitWaits:       run.RateLimitWaits,
+			RateLimitWaitSeconds: run.RateLimitWaitSeconds}
+	}
+	return result, nil
+}
+
+// convertTrackerQueryFromModel converts the tracker query and its watermark
+// to the REST representation
+func convertTrackerQueryFromModel(tq TrackerQuery) app.TrackerQuery {
+	t := app.TrackerQuery{
+		ID:            strconv.FormatUint(tq.ID, 10),
+		Query:         tq.Query,
+		Schedule:      tq.Schedule,
+		TrackerID:     strconv.FormatUint(tq.TrackerID, 10),
+		LastRunAt:     tq.LastRunAt,
+		LastUpdatedAt: tq.LastUpdatedAt}
+	if tq.ETag != "" {
+		etag := tq.ETag
+		t.Etag = &etag
+	}
+	return t
+}
@@ -54,7 +54,10 @@ func (s *trackerQueryRepoBlackBoxTest) TestFailDeleteZeroID() {
 	tr, err := s.trRepo.Create(
 		context.Background(),
 		"http://api.github.com",
-		remoteworkitem.ProviderGithub)
+		remoteworkitem.ProviderGithub,
+		"",
+		"",
+		nil)
 	if err != nil {
 		s.T().Error("Could not create tracker", err)
 	}
@@ -79,7 +82,10 @@ func (s *trackerQueryRepoBlackBoxTest) TestFailSaveZeroID() {
 	tr, err := s.trRepo.Create(
 		context.Background(),
 		"http://api.github.com",
-		remoteworkitem.ProviderGithub)
+		remoteworkitem.ProviderGithub,
+		"",
+		"",
+		nil)
 	if err != nil {
 		s.T().Error("Could not create tracker", err)
 	}
@@ -105,7 +111,10 @@ func (s *trackerQueryRepoBlackBoxTest) TestFaiLoadZeroID() {
 	tr, err := s.trRepo.Create(
 		context.Background(),
 		"http://api.github.com",
-		remoteworkitem.ProviderGithub)
+		remoteworkitem.ProviderGithub,
+		"",
+		"",
+		nil)
 	if err != nil {
 		s.T().Error("Could not create tracker", err)
 	}
@@ -1,13 +1,16 @@
 package remoteworkitem
 
 import (
+	"strconv"
 	"testing"
+	"time"
 
 	"golang.org/x/net/context"
 
 	"github.com/almighty/almighty-core/application"
 	"github.com/jinzhu/gorm"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTrackerQueryCreate(t *testing.T) {
@@ -16,7 +19,7 @@ func TestTrackerQueryCreate(t *testing.T) {
 		assert.IsType(t, NotFoundError{}, err)
 		assert.Nil(t, query)
 
-		tracker, err := trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira)
+		tracker, err := trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira, "", "", nil)
 		query, err = queryRepo.Create(context.Background(), "abc", "xyz", tracker.ID)
 		assert.Nil(t, err)
 		assert.Equal(t, "abc", query.Query)
@@ -35,8 +38,8 @@ func TestTrackerQuerySave(t *testing.T) {
 		assert.IsType(t, NotFoundError{}, err)
 		assert.Nil(t, query)
 
-		tracker, err := trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira)
-		tracker2, err := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub)
+		tracker, err := trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira, "", "", nil)
+		tracker2, err := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub, "", "", nil)
 		query, err = queryRepo.Create(context.Background(), "abc", "xyz", tracker.ID)
 		query2, err := queryRepo.Load(context.Background(), query.ID)
 		assert.Nil(t, err)
@@ -62,12 +65,82 @@ func TestTrackerQuerySave(t *testing.T) {
 	})
 }
 
+func TestTrackerQueryWatermark(t *testing.T) {
+	doWithTransaction(t, func(db *gorm.DB) {
+		trackerRepo := NewTrackerRepository(db)
+		queryRepo := NewTrackerQueryRepository(db)
+		tracker, err := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub, "", "", nil)
+		require.Nil(t, err)
+		query, err := queryRepo.Create(context.Background(), "is:open is:issue user:arquillian", "15 * * * * *", tracker.ID)
+		require.Nil(t, err)
+		id, err := strconv.ParseUint(query.ID, 10, 64)
+		require.Nil(t, err)
+
+		now := time.Now().UTC().Truncate(time.Second)
+		require.Nil(t, saveWatermark(db, id, Watermark{LastRunAt: &now, LastUpdatedAt: &now, ETag: `"abc"`}))
+		query, err = queryRepo.Load(context.Background(), query.ID)
+		require.Nil(t, err)
+		require.NotNil(t, query.LastUpdatedAt)
+		assert.True(t, now.Equal(*query.LastUpdatedAt))
+		require.NotNil(t, query.Etag)
+		assert.Equal(t, `"abc"`, *query.Etag)
+
+		// the watermark is kept as long as the query doesn't change
+		query.Schedule = "30 * * * * *"
+		query, err = queryRepo.Save(context.Background(), *query)
+		require.Nil(t, err)
+		assert.NotNil(t, query.LastUpdatedAt)
+		query.Query = "is:closed is:issue user:arquillian"
+		query, err = queryRepo.Save(context.Background(), *query)
+		require.Nil(t, err)
+		assert.Nil(t, query.LastRunAt)
+		assert.Nil(t, query.LastUpdatedAt)
+		assert.Nil(t, query.Etag)
+	})
+}
+
+func TestTrackerQueryListRuns(t *testing.T) {
+	doWithTransaction(t, func(db *gorm.DB) {
+		trackerRepo := NewTrackerRepository(db)
+		queryRepo := NewTrackerQueryRepository(db)
+		_, err := queryRepo.ListRuns(context.Background(), "100000")
+		assert.IsType(t, NotFoundError{}, err)
+
+		tracker, err := trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira, "", "", nil)
+		require.Nil(t, err)
+		query, err := queryRepo.Create(context.Background(), "project = ARQ", "15 * * * * *", tracker.ID)
+		require.Nil(t, err)
+		id, err := strconv.ParseUint(query.ID, 10, 64)
+		require.Nil(t, err)
+		runs, err := queryRepo.ListRuns(context.Background(), query.ID)
+		require.Nil(t, err)
+		assert.Empty(t, runs)
+
+		started := time.Now().Add(-time.Hour)
+		finished := started.Add(time.Minute)
+		require.Nil(t, db.Create(&TrackerQueryRun{TrackerQueryID: id, StartedAt: started, FinishedAt: &finished, Fetched: 2, Created: 1, Failed: 1, Errors: RunErrors{"importing item failed"}}).Error)
+		require.Nil(t, db.Create(&TrackerQueryRun{TrackerQueryID: id, StartedAt: time.Now(), RateLimitWaits: 1, RateLimitWaitSeconds: 60}).Error)
+		runs, err = queryRepo.ListRuns(context.Background(), query.ID)
+		require.Nil(t, err)
+		require.Len(t, runs, 2)
+		// the latest first
+		assert.Nil(t, runs[0].FinishedAt)
+		assert.Equal(t, []string{}, runs[0].Errors)
+		assert.Equal(t, 60, runs[0].RateLimitWaitSeconds)
+		assert.Equal(t, query.ID, runs[1].TrackerQueryID)
+		assert.Equal(t, 2, runs[1].Fetched)
+		assert.Equal(t, 1, runs[1].Created)
+		assert.Equal(t, 1, runs[1].Failed)
+		assert.Equal(t, []string{"importing item failed"}, runs[1].Errors)
+	})
+}
+
 func TestTrackerQueryDelete(t *testing.T) {
 	doWithTrackerRepositories(t, func(trackerRepo application.TrackerRepository, queryRepo application.TrackerQueryRepository) {
 		err := queryRepo.Delete(context.Background(), "asdf")
 		assert.IsType(t, NotFoundError{}, err)
 
-		tracker, _ := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub)
+		tracker, _ := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub, "", "", nil)
 		tq, _ := queryRepo.Create(context.Background(), "is:open is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker.ID)
 		err = queryRepo.Delete(context.Background(), tq.ID)
 		assert.Nil(t, err)
@@ -86,11 +159,11 @@ func TestTrackerQueryList(t *testing.T) {
 	doWithTrackerRepositories(t, func(trackerRepo application.TrackerRepository, queryRepo application.TrackerQueryRepository) {
 		trackerqueries1, _ := queryRepo.List(context.Background())
 
-		tracker1, _ := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub)
+		tracker1, _ := trackerRepo.Create(context.Background(), "http://api.github.com", ProviderGithub, "", "", nil)
 		queryRepo.Create(context.Background(), "is:open is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker1.ID)
 		queryRepo.Create(context.Background(), "is:close is:issue user:arquillian author:aslakknutsen", "", tracker1.ID)
 
-		tracker2, _ := trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira)
+		tracker2, _ := trackerRepo.Create(context.Background(), "http://issues.jboss.com", ProviderJira, "", "", nil)
 		queryRepo.Create(context.Background(), "project = ARQ AND text ~ 'arquillian'", "15 * * * * *", tracker2.ID)
 		queryRepo.Create(context.Background(), "project = ARQ AND text ~ 'javadoc'", "15 * * * * *", tracker2.ID)
 
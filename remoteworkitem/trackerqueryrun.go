@@ -0,0 +1,104 @@
+package remoteworkitem
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TrackerQueryRun records a scheduled run of a tracker query
+type TrackerQueryRun struct {
+	ID uint64 `gorm:"primary_key"`
+	// TrackerQueryID is a foreign key for a tracker query
+	TrackerQueryID uint64
+	StartedAt      time.Time
+	// FinishedAt is nil while the run is in progress
+	FinishedAt *time.Time
+	// Fetched is the number of items fetched from the remote tracker
+	Fetched int
+	// Created and Updated are the numbers of work items imported from the
+	// fetched items
+	Created int
+	Updated int
+	// Failed is the number of fetched items that could not be imported
+	Failed int
+	// Errors of the fetch and of the failed imports
+	Errors RunErrors `sql:"type:jsonb"`
+	// RateLimitWaits is the number of times the fetch waited for the rate
+	// limit of the remote tracker to reset
+	RateLimitWaits int
+	// RateLimitWaitSeconds is the total time spent waiting for rate limits
+	RateLimitWaitSeconds int
+}
+
+// RunErrors are the error messages of a tracker query run
+type RunErrors []string
+
+// Value implements driver.Valuer
+func (e RunErrors) Value() (driver.Value, error) {
+	if e == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(e)
+}
+
+// Scan implements sql.Scanner
+func (e *RunErrors) Scan(src interface{}) error {
+	*e = nil
+	if src == nil {
+		return nil
+	}
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("Scan source was not []byte but %T", src)
+	}
+	return json.Unmarshal(b, e)
+}
+
+// maxTrackerQueryRuns is the number of runs kept per tracker query, older
+// runs are deleted
+const maxTrackerQueryRuns = 100
+
+// maxRateLimitWait is the longest a fetch waits for rate limits in total,
+// the fetch is aborted if it would have to wait longer
+const maxRateLimitWait = 15 * time.Minute
+
+// sleep pauses the fetch, tests replace it to not actually wait
+var sleep = time.Sleep
+
+// FetchReport collects the errors and the rate limit waits of a fetch, so that
+// they are recorded with the run of the tracker query
+type FetchReport struct {
+	// Errors of the requests to the remote tracker
+	Errors []string
+	// RateLimitWaits is the number of times the fetch waited for a rate limit
+	RateLimitWaits int
+	// RateLimitWait is the total time the fetch waited for rate limits
+	RateLimitWait time.Duration
+}
+
+// addError records an error of the fetch
+func (r *FetchReport) addError(format string, args ...interface{}) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+// waitForRateLimit waits until the given reset time of a rate limit. It
+// returns false without waiting if the reset time is unknown or if the fetch
+// would exceed maxRateLimitWait.
+func (r *FetchReport) waitForRateLimit(reset time.Time) bool {
+	if reset.IsZero() {
+		return false
+	}
+	wait := reset.Sub(time.Now())
+	if wait < time.Second {
+		wait = time.Second
+	}
+	if r.RateLimitWait+wait > maxRateLimitWait {
+		return false
+	}
+	r.RateLimitWaits++
+	r.RateLimitWait += wait
+	sleep(wait)
+	return true
+}
@@ -0,0 +1,155 @@
+package remoteworkitem
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/models"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Headers of the webhook deliveries
+const (
+	GithubEventHeader        = "X-GitHub-Event"
+	GithubSignatureHeader    = "X-Hub-Signature"
+	GithubSignature256Header = "X-Hub-Signature-256"
+)
+
+// HandleWebhook verifies a webhook delivery to the given tracker and imports
+// the issue it carries like the scheduled fetches do. Github deliveries are
+// signed with the webhook secret of the tracker, Jira deliveries either are
+// signed the same way or carry the secret as the given query parameter.
+// Deliveries that don't change an issue are ignored and return a nil work
+// item.
+// returns NotFoundError, UnauthorizedError, BadParameterError or InternalError
+func (s *Scheduler) HandleWebhook(trackerID string, header http.Header, secret *string, body []byte) (*app.WorkItem, error) {
+	id, err := strconv.ParseUint(trackerID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, NotFoundError{"tracker", trackerID}
+	}
+	tracker := Tracker{}
+	tx := s.db.First(&tracker, id)
+	if tx.RecordNotFound() {
+		return nil, NotFoundError{"tracker", trackerID}
+	}
+	if tx.Error != nil {
+		return nil, InternalError{simpleError{fmt.Sprintf("error while loading: %s", tx.Error.Error())}}
+	}
+	if !verifyWebhook(tracker, header, secret, body) {
+		return nil, UnauthorizedError{simpleError{fmt.Sprintf("webhook delivery to tracker %s could not be verified", trackerID)}}
+	}
+
+	var item *TrackerItemContent
+	switch tracker.Type {
+	case ProviderGithub:
+		item, err = githubWebhookItem(header.Get(GithubEventHeader), body)
+	case ProviderJira:
+		item, err = jiraWebhookItem(body)
+	default:
+		return nil, BadParameterError{parameter: "type", value: tracker.Type}
+	}
+	if err != nil || item == nil {
+		return nil, err
+	}
+	var wi *app.WorkItem
+	err = models.Transactional(s.db, func(tx *gorm.DB) error {
+		wi, err = importItem(tx, int(tracker.ID), *item, tracker.Type)
+		return err
+	})
+	if err != nil {
+		return nil, InternalError{simpleError{fmt.Sprintf("error while importing the webhook delivery: %s", err.Error())}}
+	}
+	return wi, nil
+}
+
+// verifyWebhook returns true if the body is signed with the webhook secret of
+// the tracker or if the given secret is the webhook secret of a Jira tracker
+func verifyWebhook(tracker Tracker, header http.Header, secret *string, body []byte) bool {
+	if tracker.WebhookSecret == "" {
+		return false
+	}
+	if signature := header.Get(GithubSignature256Header); signature != "" {
+		return validSignature(sha256.New, "sha256=", tracker.WebhookSecret, signature, body)
+	}
+	if signature := header.Get(GithubSignatureHeader); signature != "" {
+		return validSignature(sha1.New, "sha1=", tracker.WebhookSecret, signature, body)
+	}
+	// Jira webhooks are not necessarily signed
+	return tracker.Type == ProviderJira && secret != nil && hmac.Equal([]byte(*secret), []byte(tracker.WebhookSecret))
+}
+
+// validSignature returns true if the signature is the prefixed hex encoded
+// HMAC of the body
+func validSignature(h func() hash.Hash, prefix string, secret string, signature string, body []byte) bool {
+	if !strings.HasPrefix(signature, prefix) {
+		return false
+	}
+	actual, err := hex.DecodeString(strings.TrimPrefix(signature, prefix))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(actual, mac.Sum(nil))
+}
+
+// githubWebhookItem returns the issue of a Github "issues" event as it is
+// returned by the fetches, or nil for other events
+// returns BadParameterError
+func githubWebhookItem(event string, body []byte) (*TrackerItemContent, error) {
+	if event != "issues" {
+		return nil, nil
+	}
+	var payload struct {
+		Issue map[string]interface{} `json:"issue"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, BadParameterError{parameter: "body", value: err.Error()}
+	}
+	return webhookItem(payload.Issue, GithubID)
+}
+
+// jiraWebhookItem returns the issue of a Jira "jira:issue_created" or
+// "jira:issue_updated" event as it is returned by the fetches, or nil for
+// other events
+// returns BadParameterError
+func jiraWebhookItem(body []byte) (*TrackerItemContent, error) {
+	var payload struct {
+		WebhookEvent string                 `json:"webhookEvent"`
+		Issue        map[string]interface{} `json:"issue"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, BadParameterError{parameter: "body", value: err.Error()}
+	}
+	if payload.WebhookEvent != "jira:issue_created" && payload.WebhookEvent != "jira:issue_updated" {
+		return nil, nil
+	}
+	return webhookItem(payload.Issue, "key")
+}
+
+// webhookItem returns the tracker item of the given issue, identified by the
+// given key like the fetched items
+func webhookItem(issue map[string]interface{}, key string) (*TrackerItemContent, error) {
+	if issue[key] == nil {
+		return nil, BadParameterError{parameter: "issue." + key, value: nil}
+	}
+	id, err := json.Marshal(issue[key])
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	content, err := json.Marshal(issue)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &TrackerItemContent{ID: string(id), Content: content}, nil
+}
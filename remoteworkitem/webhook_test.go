@@ -0,0 +1,133 @@
+package remoteworkitem
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	body := []byte(`{"action":"closed"}`)
+	github := Tracker{Type: ProviderGithub, WebhookSecret: "s3cr3t"}
+	jira := Tracker{Type: ProviderJira, WebhookSecret: "s3cr3t"}
+	secret := "s3cr3t"
+	wrongSecret := "secret"
+
+	signed := http.Header{}
+	signed.Set(GithubSignature256Header, sign("s3cr3t", body))
+	assert.True(t, verifyWebhook(github, signed, nil, body))
+	assert.True(t, verifyWebhook(jira, signed, nil, body))
+	assert.False(t, verifyWebhook(github, signed, nil, []byte(`{"action":"opened"}`)))
+	// trackers without a secret don't accept webhooks
+	assert.False(t, verifyWebhook(Tracker{Type: ProviderGithub}, signed, nil, body))
+
+	mac := hmac.New(sha1.New, []byte("s3cr3t"))
+	mac.Write(body)
+	signedSHA1 := http.Header{}
+	signedSHA1.Set(GithubSignatureHeader, "sha1="+hex.EncodeToString(mac.Sum(nil)))
+	assert.True(t, verifyWebhook(github, signedSHA1, nil, body))
+
+	wronglySigned := http.Header{}
+	wronglySigned.Set(GithubSignature256Header, sign("secret", body))
+	assert.False(t, verifyWebhook(github, wronglySigned, nil, body))
+	assert.False(t, verifyWebhook(jira, wronglySigned, &secret, body))
+
+	// only Jira deliveries may carry the secret instead of a signature
+	assert.True(t, verifyWebhook(jira, http.Header{}, &secret, body))
+	assert.False(t, verifyWebhook(jira, http.Header{}, &wrongSecret, body))
+	assert.False(t, verifyWebhook(jira, http.Header{}, nil, body))
+	assert.False(t, verifyWebhook(github, http.Header{}, &secret, body))
+}
+
+func TestWebhookItems(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	item, err := githubWebhookItem("issues", []byte(`{"action":"closed","issue":{"url":"https://api.github.com/repos/almighty-test/almighty-test-unit/issues/1","title":"linking","state":"closed"}}`))
+	require.Nil(t, err)
+	require.NotNil(t, item)
+	// the same ID as the one of fetched issues
+	assert.Equal(t, `"https://api.github.com/repos/almighty-test/almighty-test-unit/issues/1"`, item.ID)
+	assert.Equal(t, `{"state":"closed","title":"linking","url":"https://api.github.com/repos/almighty-test/almighty-test-unit/issues/1"}`, string(item.Content))
+
+	item, err = githubWebhookItem("ping", []byte(`{"zen":"Keep it logically awesome."}`))
+	require.Nil(t, err)
+	assert.Nil(t, item)
+	_, err = githubWebhookItem("issues", []byte(`{"action":"closed","issue":{"title":"linking"}}`))
+	assert.IsType(t, BadParameterError{}, err)
+
+	item, err = jiraWebhookItem([]byte(`{"webhookEvent":"jira:issue_updated","issue":{"key":"ARQ-1","self":"https://issues.jboss.org/rest/api/2/issue/12345","fields":{"summary":"linking"}}}`))
+	require.Nil(t, err)
+	require.NotNil(t, item)
+	assert.Equal(t, `"ARQ-1"`, item.ID)
+
+	item, err = jiraWebhookItem([]byte(`{"webhookEvent":"jira:issue_deleted","issue":{"key":"ARQ-1"}}`))
+	require.Nil(t, err)
+	assert.Nil(t, item)
+	_, err = jiraWebhookItem([]byte(`not json`))
+	assert.IsType(t, BadParameterError{}, err)
+}
+
+func TestHandleWebhook(t *testing.T) {
+	resource.Require(t, resource.Database)
+
+	tr := Tracker{URL: "https://api.github.com/", Type: ProviderGithub, WebhookSecret: "s3cr3t"}
+	require.Nil(t, db.Create(&tr).Error)
+	defer db.Delete(&tr)
+	defer db.Where("tracker_id = ?", tr.ID).Delete(TrackerItem{})
+	trackerID := strconv.FormatUint(tr.ID, 10)
+	s := NewScheduler(db)
+	remoteID := "https://api.github.com/repos/almighty-test/almighty-test-unit/issues/" + uuid.NewV4().String()
+
+	body := []byte(`{"action":"opened","issue":{"url":"` + remoteID + `","title":"linking","state":"open","body":"body of issue","user":{"login":"sbose78"}}}`)
+	header := http.Header{}
+	header.Set(GithubEventHeader, "issues")
+	header.Set(GithubSignature256Header, sign("s3cr3t", body))
+	wi, err := s.HandleWebhook(trackerID, header, nil, body)
+	require.Nil(t, err)
+	require.NotNil(t, wi)
+	defer workitem.NewWorkItemRepository(db).Delete(context.Background(), wi.ID, uuid.Nil)
+	assert.Equal(t, "linking", wi.Fields[workitem.SystemTitle])
+	assert.Equal(t, remoteID, wi.Fields[workitem.SystemRemoteItemID])
+
+	t.Log("Only the changed issue is updated")
+	body = []byte(`{"action":"closed","issue":{"url":"` + remoteID + `","title":"linking","state":"closed","body":"body of issue","user":{"login":"sbose78"}}}`)
+	header.Set(GithubSignature256Header, sign("s3cr3t", body))
+	updated, err := s.HandleWebhook(trackerID, header, nil, body)
+	require.Nil(t, err)
+	assert.Equal(t, wi.ID, updated.ID)
+	assert.Equal(t, "closed", updated.Fields[workitem.SystemState])
+
+	t.Log("Unverified deliveries are rejected")
+	header.Set(GithubSignature256Header, sign("secret", body))
+	_, err = s.HandleWebhook(trackerID, header, nil, body)
+	assert.IsType(t, UnauthorizedError{}, err)
+	_, err = s.HandleWebhook("0", header, nil, body)
+	assert.IsType(t, NotFoundError{}, err)
+
+	t.Log("Other events are ignored")
+	header.Set(GithubEventHeader, "ping")
+	header.Set(GithubSignature256Header, sign("s3cr3t", body))
+	ignored, err := s.HandleWebhook(trackerID, header, nil, body)
+	require.Nil(t, err)
+	assert.Nil(t, ignored)
+}
@@ -0,0 +1,178 @@
+package savedquery
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/query"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// APIStringTypeQueries is the JSONAPI type of saved queries
+const APIStringTypeQueries = "queries"
+
+// Query is a named work item filter expression stored for a user in a space.
+// Shared queries are visible to everybody, the others only to their creator.
+type Query struct {
+	gormsupport.Lifecycle
+	ID      uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	SpaceID uuid.UUID `sql:"type:uuid"`
+	Creator uuid.UUID `sql:"type:uuid"` // Belongs To Identity
+	Title   string
+	// Filter is an expression of the query language, see query.Parse
+	Filter  string
+	Shared  bool
+	Version int
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (q *Query) TableName() string {
+	return "queries"
+}
+
+// VisibleTo returns true if the query may be seen by the given identity
+func (q *Query) VisibleTo(identity uuid.UUID) bool {
+	return q.Shared || uuid.Equal(q.Creator, identity)
+}
+
+// Repository describes interactions with saved queries
+type Repository interface {
+	Create(ctx context.Context, q *Query) (*Query, error)
+	Save(ctx context.Context, q *Query) (*Query, error)
+	Load(ctx context.Context, id uuid.UUID) (*Query, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, spaceID uuid.UUID, identity uuid.UUID) ([]*Query, error)
+}
+
+// NewQueryRepository creates a new storage type.
+func NewQueryRepository(db *gorm.DB) Repository {
+	return &GormQueryRepository{db: db}
+}
+
+// GormQueryRepository is the implementation of the storage interface for saved queries.
+type GormQueryRepository struct {
+	db *gorm.DB
+}
+
+// validate checks that the query has a title and that its filter is a valid
+// expression that can be compiled to a work item query
+func validate(q *Query) error {
+	if strings.TrimSpace(q.Title) == "" {
+		return errors.NewBadParameterError("title", q.Title).Expected("not empty")
+	}
+	exp, err := query.Parse(&q.Filter)
+	if err != nil {
+		return errors.NewBadParameterError("filter", q.Filter).Expected(err.Error())
+	}
+	if _, _, compileErrors := workitem.Compile(exp); len(compileErrors) > 0 {
+		return errors.NewBadParameterError("filter", q.Filter).Expected(compileErrors[0].Error())
+	}
+	return nil
+}
+
+// convertError maps constraint violations to the corresponding BadParameterError
+func convertError(err error, q *Query) error {
+	if gormsupport.IsCheckViolation(err, "queries_title_check") {
+		return errors.NewBadParameterError("title", q.Title).Expected("not empty")
+	}
+	if gormsupport.IsUniqueViolation(err, "queries_title_idx") {
+		return errors.NewBadParameterError("title", q.Title).Expected("unique")
+	}
+	return errors.NewInternalError(err.Error())
+}
+
+// Create creates a new record.
+// returns BadParameterError or InternalError
+func (m *GormQueryRepository) Create(ctx context.Context, q *Query) (*Query, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "query", "create"}, time.Now())
+	if err := validate(q); err != nil {
+		return nil, err
+	}
+	q.ID = uuid.NewV4()
+	if err := m.db.Create(q).Error; err != nil {
+		goa.LogError(ctx, "error adding Query", "error", err.Error())
+		return nil, convertError(err, q)
+	}
+	return q, nil
+}
+
+// Save updates the given query in the db. Version must be the same as the one in the stored version
+// returns NotFoundError, BadParameterError, VersionConflictError or InternalError
+func (m *GormQueryRepository) Save(ctx context.Context, q *Query) (*Query, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "query", "save"}, time.Now())
+	if err := validate(q); err != nil {
+		return nil, err
+	}
+	existing := Query{}
+	tx := m.db.Where("id = ?", q.ID).First(&existing)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("query", q.ID.String())
+	}
+	if err := tx.Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	oldVersion := q.Version
+	q.Version++
+	tx = tx.Where("Version = ?", oldVersion).Save(q)
+	if err := tx.Error; err != nil {
+		return nil, convertError(err, q)
+	}
+	if tx.RowsAffected == 0 {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+	log.Printf("updated query to %v\n", q)
+	return q, nil
+}
+
+// Load a single query
+// returns NotFoundError or InternalError
+func (m *GormQueryRepository) Load(ctx context.Context, id uuid.UUID) (*Query, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "query", "get"}, time.Now())
+	var obj Query
+
+	tx := m.db.Where("id = ?", id).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("query", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// Delete deletes the query with the given id
+// returns NotFoundError or InternalError
+func (m *GormQueryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "query", "delete"}, time.Now())
+	if id == uuid.Nil {
+		return errors.NewNotFoundError("query", id.String())
+	}
+	tx := m.db.Delete(&Query{ID: id})
+	if err := tx.Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("query", id.String())
+	}
+	return nil
+}
+
+// List returns the queries of a space visible to the given identity,
+// i.e. the shared ones and the ones created by the identity, ordered by title
+func (m *GormQueryRepository) List(ctx context.Context, spaceID uuid.UUID, identity uuid.UUID) ([]*Query, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "query", "query"}, time.Now())
+	var objs []*Query
+	err := m.db.Where("space_id = ? and (shared or creator = ?)", spaceID, identity).Order("title").Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
@@ -0,0 +1,183 @@
+package savedquery_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/savedquery"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestQueryRepository struct {
+	gormsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunQueryRepository(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestQueryRepository{DBTestSuite: gormsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestQueryRepository) SetupTest() {
+	test.clean = cleaner.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestQueryRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestQueryRepository) TestCreateAndLoadQuery() {
+	t := test.T()
+	repo := savedquery.NewQueryRepository(test.DB)
+	q := savedquery.Query{
+		SpaceID: uuid.NewV4(),
+		Creator: uuid.NewV4(),
+		Title:   "My open bugs",
+		Filter:  "Type == 'bug' and system.state != 'closed'",
+	}
+	_, err := repo.Create(context.Background(), &q)
+	require.Nil(t, err)
+	require.NotEqual(t, uuid.Nil, q.ID)
+
+	loaded, err := repo.Load(context.Background(), q.ID)
+	require.Nil(t, err)
+	assert.Equal(t, q.Title, loaded.Title)
+	assert.Equal(t, q.Filter, loaded.Filter)
+	assert.False(t, loaded.Shared)
+}
+
+func (test *TestQueryRepository) TestCreateInvalidQuery() {
+	t := test.T()
+	repo := savedquery.NewQueryRepository(test.DB)
+	_, err := repo.Create(context.Background(), &savedquery.Query{
+		SpaceID: uuid.NewV4(),
+		Creator: uuid.NewV4(),
+		Title:   "broken",
+		Filter:  "Type ==",
+	})
+	require.NotNil(t, err)
+	assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+
+	_, err = repo.Create(context.Background(), &savedquery.Query{
+		SpaceID: uuid.NewV4(),
+		Creator: uuid.NewV4(),
+		Title:   " ",
+		Filter:  "Type == 'bug'",
+	})
+	require.NotNil(t, err)
+	assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+
+	// the filter parses, but can't be compiled to a work item query
+	_, err = repo.Create(context.Background(), &savedquery.Query{
+		SpaceID: uuid.NewV4(),
+		Creator: uuid.NewV4(),
+		Title:   "uncompilable",
+		Filter:  "system.title contains 5",
+	})
+	require.NotNil(t, err)
+	assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+}
+
+func (test *TestQueryRepository) TestCreateDuplicateTitle() {
+	t := test.T()
+	repo := savedquery.NewQueryRepository(test.DB)
+	q := savedquery.Query{
+		SpaceID: uuid.NewV4(),
+		Creator: uuid.NewV4(),
+		Title:   "duplicate",
+		Filter:  "Type == 'bug'",
+	}
+	_, err := repo.Create(context.Background(), &q)
+	require.Nil(t, err)
+	_, err = repo.Create(context.Background(), &savedquery.Query{SpaceID: q.SpaceID, Creator: q.Creator, Title: q.Title, Filter: q.Filter})
+	require.NotNil(t, err)
+	assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+}
+
+func (test *TestQueryRepository) TestSaveQuery() {
+	t := test.T()
+	repo := savedquery.NewQueryRepository(test.DB)
+	q := savedquery.Query{
+		SpaceID: uuid.NewV4(),
+		Creator: uuid.NewV4(),
+		Title:   "to be renamed",
+		Filter:  "Type == 'bug'",
+	}
+	_, err := repo.Create(context.Background(), &q)
+	require.Nil(t, err)
+
+	q.Title = "renamed"
+	q.Shared = true
+	saved, err := repo.Save(context.Background(), &q)
+	require.Nil(t, err)
+	assert.Equal(t, 1, saved.Version)
+
+	loaded, err := repo.Load(context.Background(), q.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "renamed", loaded.Title)
+	assert.True(t, loaded.Shared)
+
+	// saving with the outdated version fails
+	loaded.Version = 0
+	_, err = repo.Save(context.Background(), loaded)
+	require.NotNil(t, err)
+	assert.IsType(t, errors.VersionConflictError{}, errs.Cause(err))
+}
+
+func (test *TestQueryRepository) TestListQueries() {
+	t := test.T()
+	repo := savedquery.NewQueryRepository(test.DB)
+	spaceID := uuid.NewV4()
+	me := uuid.NewV4()
+	other := uuid.NewV4()
+	create := func(title string, creator uuid.UUID, spaceID uuid.UUID, shared bool) {
+		_, err := repo.Create(context.Background(), &savedquery.Query{
+			SpaceID: spaceID,
+			Creator: creator,
+			Title:   title,
+			Filter:  "Type == 'bug'",
+			Shared:  shared,
+		})
+		require.Nil(t, err)
+	}
+	create("b mine", me, spaceID, false)
+	create("a shared by other", other, spaceID, true)
+	create("private to other", other, spaceID, false)
+	create("mine in other space", me, uuid.NewV4(), false)
+
+	queries, err := repo.List(context.Background(), spaceID, me)
+	require.Nil(t, err)
+	require.Len(t, queries, 2)
+	assert.Equal(t, "a shared by other", queries[0].Title)
+	assert.Equal(t, "b mine", queries[1].Title)
+}
+
+func (test *TestQueryRepository) TestDeleteQuery() {
+	t := test.T()
+	repo := savedquery.NewQueryRepository(test.DB)
+	q := savedquery.Query{
+		SpaceID: uuid.NewV4(),
+		Creator: uuid.NewV4(),
+		Title:   "to be deleted",
+		Filter:  "Type == 'bug'",
+	}
+	_, err := repo.Create(context.Background(), &q)
+	require.Nil(t, err)
+
+	err = repo.Delete(context.Background(), q.ID)
+	require.Nil(t, err)
+	_, err = repo.Load(context.Background(), q.ID)
+	assert.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	err = repo.Delete(context.Background(), q.ID)
+	assert.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+}
@@ -57,6 +57,11 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 		return ctx.BadRequest(jerrors)
 	}
 
+	cursor, err := parseCursor(ctx.PageCursor)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
 	// ToDo : Keep URL registeration central somehow.
 	hostString := ctx.RequestData.Host
 	if hostString == "" {
@@ -69,7 +74,7 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 
 	return application.Transactional(c.db, func(appl application.Application) error {
 		//return transaction.Do(c.ts, func() error {
-		result, c, err := appl.SearchItems().SearchFullText(ctx.Context, ctx.Q, &offset, &limit)
+		result, c, cursors, err := appl.SearchItems().SearchFullText(ctx.Context, ctx.Q, &offset, &limit, cursor)
 		count := int(c)
 		if err != nil {
 			cause := errs.Cause(err)
@@ -90,6 +95,11 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 			Data:  ConvertWorkItems(ctx.RequestData, result),
 		}
 
+		if cursor != nil {
+			setCursorPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), limit, cursors, "q="+ctx.Q)
+			return ctx.OK(&response)
+		}
+
 		// prev link
 		if offset > 0 && count > 0 {
 			var prevStart int
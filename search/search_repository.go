@@ -16,6 +16,7 @@ import (
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/asaskevich/govalidator"
 	"github.com/jinzhu/gorm"
@@ -265,22 +266,17 @@ func generateSQLSearchInfo(keywords searchKeyword) (sqlParameter string) {
 	return searchStr
 }
 
+// searchOrder defines the order of search results, best match first
+var searchOrder = []gormsupport.SortKey{
+	{Expression: "search_rank", Descending: true},
+	{Expression: workitem.WorkItem{}.TableName() + ".updated_at", Descending: true},
+	{Expression: workitem.WorkItem{}.TableName() + ".id"},
+}
+
 // extracted this function from List() in order to close the rows object with "defer" for more readability
 // workaround for https://github.com/lib/pq/issues/81
-func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParameter string, workItemTypes []string, start *int, limit *int) ([]workitem.WorkItem, uint64, error) {
+func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParameter string, workItemTypes []string, start *int, limit *int, cursor *gormsupport.Cursor) ([]workitem.WorkItem, uint64, *gormsupport.PageCursors, error) {
 	db := r.db.Model(workitem.WorkItem{}).Where("tsv @@ query")
-	if start != nil {
-		if *start < 0 {
-			return nil, 0, errors.NewBadParameterError("start", *start)
-		}
-		db = db.Offset(*start)
-	}
-	if limit != nil {
-		if *limit <= 0 {
-			return nil, 0, errors.NewBadParameterError("limit", *limit)
-		}
-		db = db.Limit(*limit)
-	}
 	if len(workItemTypes) > 0 {
 		// restrict to all given types and their subtypes
 		query := fmt.Sprintf("%[1]s.type in ("+
@@ -289,14 +285,36 @@ func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParamet
 			"where supertype.name in (?))", workitem.WorkItem{}.TableName(), workitem.WorkItemType{}.TableName())
 		db = db.Where(query, workItemTypes)
 	}
+	db = db.Joins(", to_tsquery('english', ?) as query, ts_rank(tsv, query) as search_rank", sqlSearchQueryParameter)
+	orgDB := db
+
+	if cursor != nil {
+		var err error
+		db, err = gormsupport.ApplyCursor(db, searchOrder, *cursor)
+		if err != nil {
+			return nil, 0, nil, errs.WithStack(err)
+		}
+	} else {
+		db = db.Order(gormsupport.OrderClause(searchOrder, false))
+		if start != nil {
+			if *start < 0 {
+				return nil, 0, nil, errors.NewBadParameterError("start", *start)
+			}
+			db = db.Offset(*start)
+		}
+	}
+	if limit != nil {
+		if *limit <= 0 {
+			return nil, 0, nil, errors.NewBadParameterError("limit", *limit)
+		}
+		db = db.Limit(*limit)
+	}
 
-	db = db.Select("count(*) over () as cnt2 , *")
-	db = db.Joins(", to_tsquery('english', ?) as query, ts_rank(tsv, query) as rank", sqlSearchQueryParameter)
-	db = db.Order(fmt.Sprintf("rank desc,%s.updated_at desc", workitem.WorkItem{}.TableName()))
+	db = db.Select("count(*) over () as cnt2 , *, " + gormsupport.SelectSortKeys(searchOrder))
 
 	rows, err := db.Rows()
 	if err != nil {
-		return nil, 0, errs.WithStack(err)
+		return nil, 0, nil, errs.WithStack(err)
 	}
 	defer rows.Close()
 
@@ -304,71 +322,101 @@ func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParamet
 	value := workitem.WorkItem{}
 	columns, err := rows.Columns()
 	if err != nil {
-		return nil, 0, errors.NewInternalError(err.Error())
+		return nil, 0, nil, errors.NewInternalError(err.Error())
 	}
 
-	// need to set up a result for Scan() in order to extract total count.
+	// need to read the total count and the sort keys of the first and last row to compute the cursors
 	var count uint64
-	var ignore interface{}
-	columnValues := make([]interface{}, len(columns))
-
-	for index := range columnValues {
-		columnValues[index] = &ignore
-	}
-	columnValues[0] = &count
+	var firstKeys, lastKeys []*string
 	first := true
 
 	for rows.Next() {
 		db.ScanRows(rows, &value)
+		var countTarget *uint64
+		if first {
+			countTarget = &count
+		}
+		lastKeys, err = gormsupport.ScanSortKeys(rows, columns, countTarget)
+		if err != nil {
+			return nil, 0, nil, errs.WithStack(err)
+		}
 		if first {
 			first = false
-			if err = rows.Scan(columnValues...); err != nil {
-				return nil, 0, errors.NewInternalError(err.Error())
-			}
+			firstKeys = lastKeys
 		}
 		result = append(result, value)
 
 	}
-	if first {
+	// when paging with a cursor, the window count only covers the rows beyond the cursor
+	more := count > uint64(len(result))
+	if cursor != nil {
+		// need to do a count(*) to find out total
+		orgDB := orgDB.Select("count(*)")
+		rows2, err := orgDB.Rows()
+		if err != nil {
+			return nil, 0, nil, errs.WithStack(err)
+		}
+		defer rows2.Close()
+		rows2.Next() // count(*) will always return a row
+		rows2.Scan(&count)
+	} else if first {
 		// means 0 rows were returned from the first query,
 		count = 0
 	}
-	return result, count, nil
-	//*/
+	var hasPrev, hasNext bool
+	if cursor != nil {
+		hasPrev, hasNext = cursor.Neighbours(more)
+		if cursor.Before {
+			// the rows were fetched in reverse order
+			for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+				result[i], result[j] = result[j], result[i]
+			}
+			firstKeys, lastKeys = lastKeys, firstKeys
+		}
+	} else {
+		offset := 0
+		if start != nil {
+			offset = *start
+		}
+		hasPrev = offset > 0
+		hasNext = uint64(offset+len(result)) < count
+	}
+	return result, count, gormsupport.NewPageCursors(firstKeys, lastKeys, hasPrev, hasNext), nil
 }
 
-// SearchFullText Search returns work items for the given query
-func (r *GormSearchRepository) SearchFullText(ctx context.Context, rawSearchString string, start *int, limit *int) ([]*app.WorkItem, uint64, error) {
+// SearchFullText Search returns work items for the given query.
+// If a cursor is given, start is ignored and the items after (or before) the cursor are returned instead.
+func (r *GormSearchRepository) SearchFullText(ctx context.Context, rawSearchString string, start *int, limit *int, cursor *gormsupport.Cursor) ([]*app.WorkItem, uint64, *gormsupport.PageCursors, error) {
 	// parse
 	// generateSearchQuery
 	// ....
 	parsedSearchDict, err := parseSearchString(rawSearchString)
 	if err != nil {
-		return nil, 0, errs.WithStack(err)
+		return nil, 0, nil, errs.WithStack(err)
 	}
 
 	sqlSearchQueryParameter := generateSQLSearchInfo(parsedSearchDict)
 	var rows []workitem.WorkItem
-	rows, count, err := r.search(ctx, sqlSearchQueryParameter, parsedSearchDict.workItemTypes, start, limit)
+	rows, count, cursors, err := r.search(ctx, sqlSearchQueryParameter, parsedSearchDict.workItemTypes, start, limit, cursor)
 	if err != nil {
-		return nil, 0, errs.WithStack(err)
+		return nil, 0, nil, errs.WithStack(err)
 	}
 	result := make([]*app.WorkItem, len(rows))
 
 	for index, value := range rows {
 		var err error
 		// FIXME: Against best practice http://go-database-sql.org/retrieving.html
-		wiType, err := r.wir.LoadTypeFromDB(value.Type)
+		wiType, err := r.wir.LoadTypeFromDB(value.SpaceID, value.Type)
 		if err != nil {
-			return nil, 0, errors.NewInternalError(err.Error())
+			return nil, 0, nil, errors.NewInternalError(err.Error())
 		}
 		result[index], err = convertFromModel(*wiType, value)
 		if err != nil {
-			return nil, 0, errors.NewConversionError(err.Error())
+			return nil, 0, nil, errors.NewConversionError(err.Error())
 		}
 	}
 
-	return result, count, nil
+	return result, count, cursors, nil
 }
 
 func init() {
@@ -13,6 +13,7 @@ import (
 	testsupport "github.com/almighty/almighty-core/test"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -50,76 +51,74 @@ func (s *searchRepositoryBlackboxTest) TestRestrictByType() {
 	searchRepo := search.NewGormSearchRepository(s.DB)
 
 	ctx := context.Background()
-	res, count, err := searchRepo.SearchFullText(ctx, "TestRestrictByType", nil, nil)
+	res, count, _, err := searchRepo.SearchFullText(ctx, "TestRestrictByType", nil, nil, nil)
 	require.Nil(s.T(), err)
 	require.True(s.T(), count == uint64(len(res))) // safety check for many, many instances of bogus search results.
 	for _, wi := range res {
-		wiRepo.Delete(ctx, wi.ID)
+		wiRepo.Delete(ctx, wi.ID, uuid.Nil)
 	}
 
-	s.DB.Unscoped().Delete(&workitem.WorkItemType{Name: "base"})
-	s.DB.Unscoped().Delete(&workitem.WorkItemType{Name: "sub1"})
-	s.DB.Unscoped().Delete(&workitem.WorkItemType{Name: "subtwo"})
+	s.DB.Unscoped().Where("name IN (?)", []string{"base", "sub1", "subtwo"}).Delete(&workitem.WorkItemType{})
 
 	extended := workitem.SystemBug
-	base, err := typeRepo.Create(ctx, &extended, "base", map[string]app.FieldDefinition{})
+	base, err := typeRepo.Create(ctx, nil, &extended, "base", map[string]app.FieldDefinition{})
 	require.NotNil(s.T(), base)
 	require.Nil(s.T(), err)
 
 	extended = "base"
-	sub1, err := typeRepo.Create(ctx, &extended, "sub1", map[string]app.FieldDefinition{})
+	sub1, err := typeRepo.Create(ctx, nil, &extended, "sub1", map[string]app.FieldDefinition{})
 	require.NotNil(s.T(), sub1)
 	require.Nil(s.T(), err)
 
-	sub2, err := typeRepo.Create(ctx, &extended, "subtwo", map[string]app.FieldDefinition{})
+	sub2, err := typeRepo.Create(ctx, nil, &extended, "subtwo", map[string]app.FieldDefinition{})
 	require.NotNil(s.T(), sub2)
 	require.Nil(s.T(), err)
 
-	wi1, err := wiRepo.Create(ctx, "sub1", map[string]interface{}{
+	wi1, err := wiRepo.Create(ctx, nil, "sub1", map[string]interface{}{
 		workitem.SystemTitle: "Test TestRestrictByType",
 		workitem.SystemState: "closed",
 	}, testsupport.TestIdentity.ID.String())
 	require.NotNil(s.T(), wi1)
 	require.Nil(s.T(), err)
 
-	wi2, err := wiRepo.Create(ctx, "subtwo", map[string]interface{}{
+	wi2, err := wiRepo.Create(ctx, nil, "subtwo", map[string]interface{}{
 		workitem.SystemTitle: "Test TestRestrictByType 2",
 		workitem.SystemState: "closed",
 	}, testsupport.TestIdentity.ID.String())
 	require.NotNil(s.T(), wi2)
 	require.Nil(s.T(), err)
 
-	res, count, err = searchRepo.SearchFullText(ctx, "TestRestrictByType", nil, nil)
+	res, count, _, err = searchRepo.SearchFullText(ctx, "TestRestrictByType", nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(2), count)
 
-	res, count, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:sub1", nil, nil)
+	res, count, _, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:sub1", nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(1), count)
 	if count == 1 {
 		assert.Equal(s.T(), wi1.ID, res[0].ID)
 	}
 
-	res, count, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:subtwo", nil, nil)
+	res, count, _, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:subtwo", nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(1), count)
 	if count == 1 {
 		assert.Equal(s.T(), wi2.ID, res[0].ID)
 	}
 
-	_, count, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:base", nil, nil)
+	_, count, _, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:base", nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(2), count)
 
-	_, count, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:subtwo type:sub1", nil, nil)
+	_, count, _, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:subtwo type:sub1", nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(2), count)
 
-	_, count, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:base type:sub1", nil, nil)
+	_, count, _, err = searchRepo.SearchFullText(ctx, "TestRestrictByType type:base type:sub1", nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(2), count)
 
-	_, count, err = searchRepo.SearchFullText(ctx, "TRBTgorxi type:base", nil, nil)
+	_, count, _, err = searchRepo.SearchFullText(ctx, "TRBTgorxi type:base", nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(0), count)
 }
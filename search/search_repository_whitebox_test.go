@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/migration"
@@ -18,6 +19,7 @@ import (
 	"github.com/jinzhu/gorm"
 	_ "github.com/lib/pq"
 	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"golang.org/x/net/context"
@@ -51,8 +53,19 @@ type SearchTestDescriptor struct {
 	minimumResults int
 }
 
+// createAssignee creates an identity that the test work items are assigned to
+func (s *searchRepositoryWhiteboxTest) createAssignee() *account.Identity {
+	identity := account.Identity{ID: uuid.NewV4(), Username: "pranav"}
+	if err := account.NewIdentityRepository(s.DB).Create(context.Background(), &identity); err != nil {
+		s.T().Fatalf("Couldn't create test identity: %+v", err)
+	}
+	return &identity
+}
+
 func (s *searchRepositoryWhiteboxTest) TestSearchByText() {
 	wir := workitem.NewWorkItemRepository(s.DB)
+	assignee := s.createAssignee()
+	defer s.DB.Delete(assignee)
 
 	testDataSet := []SearchTestDescriptor{
 		{
@@ -61,7 +74,7 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByText() {
 					workitem.SystemTitle:       "test sbose title '12345678asdfgh'",
 					workitem.SystemDescription: rendering.NewMarkupContentFromLegacy(`"description" for search test`),
 					workitem.SystemCreator:     "sbose78",
-					workitem.SystemAssignees:   []string{"pranav"},
+					workitem.SystemAssignees:   []string{assignee.ID.String()},
 					workitem.SystemState:       "closed",
 				},
 			},
@@ -74,7 +87,7 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByText() {
 					workitem.SystemTitle:       "add new error types in models/errors.go'",
 					workitem.SystemDescription: rendering.NewMarkupContentFromLegacy(`Make sure remoteworkitem can access..`),
 					workitem.SystemCreator:     "sbose78",
-					workitem.SystemAssignees:   []string{"pranav"},
+					workitem.SystemAssignees:   []string{assignee.ID.String()},
 					workitem.SystemState:       "closed",
 				},
 			},
@@ -87,7 +100,7 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByText() {
 					workitem.SystemTitle:       "test sbose title '12345678asdfgh'",
 					workitem.SystemDescription: rendering.NewMarkupContentFromLegacy(`"description" for search test`),
 					workitem.SystemCreator:     "sbose78",
-					workitem.SystemAssignees:   []string{"pranav"},
+					workitem.SystemAssignees:   []string{assignee.ID.String()},
 					workitem.SystemState:       "closed",
 				},
 			},
@@ -100,7 +113,7 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByText() {
 				Fields: map[string]interface{}{
 					workitem.SystemTitle:     "test nofield sbose title '12345678asdfgh'",
 					workitem.SystemCreator:   "sbose78",
-					workitem.SystemAssignees: []string{"pranav"},
+					workitem.SystemAssignees: []string{assignee.ID.String()},
 					workitem.SystemState:     "closed",
 				},
 			},
@@ -113,7 +126,7 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByText() {
 				Fields: map[string]interface{}{
 					workitem.SystemTitle:     "test should return 0 results'",
 					workitem.SystemCreator:   "sbose78",
-					workitem.SystemAssignees: []string{"pranav"},
+					workitem.SystemAssignees: []string{assignee.ID.String()},
 					workitem.SystemState:     "closed",
 				},
 			},
@@ -125,7 +138,7 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByText() {
 				Fields: map[string]interface{}{
 					workitem.SystemTitle:     "Bug reported by administrator for input = (value)",
 					workitem.SystemCreator:   "pgore",
-					workitem.SystemAssignees: []string{"pranav"},
+					workitem.SystemAssignees: []string{assignee.ID.String()},
 					workitem.SystemState:     "new",
 				},
 			},
@@ -137,7 +150,7 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByText() {
 				Fields: map[string]interface{}{
 					workitem.SystemTitle:     "trial for braces (pranav) {shoubhik} [aslak]",
 					workitem.SystemCreator:   "pgore",
-					workitem.SystemAssignees: []string{"pranav"},
+					workitem.SystemAssignees: []string{assignee.ID.String()},
 					workitem.SystemState:     "new",
 				},
 			},
@@ -154,12 +167,12 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByText() {
 			minimumResults := testData.minimumResults
 			workItemURLInSearchString := "http://demo.almighty.io/work-item/list/detail/"
 
-			createdWorkItem, err := wir.Create(context.Background(), workitem.SystemBug, workItem.Fields, testsupport.TestIdentity.ID.String())
+			createdWorkItem, err := wir.Create(context.Background(), nil, workitem.SystemBug, workItem.Fields, testsupport.TestIdentity.ID.String())
 			if err != nil {
 				s.T().Fatal("Couldnt create test data")
 			}
 
-			defer wir.Delete(context.Background(), createdWorkItem.ID)
+			defer wir.Delete(context.Background(), createdWorkItem.ID, uuid.Nil)
 
 			// create the URL and use it in the search string
 			workItemURLInSearchString = workItemURLInSearchString + createdWorkItem.ID
@@ -171,7 +184,7 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByText() {
 			s.T().Log("using search string: " + searchString)
 			sr := NewGormSearchRepository(tx)
 			var start, limit int = 0, 100
-			workItemList, _, err := sr.SearchFullText(context.Background(), searchString, &start, &limit)
+			workItemList, _, _, err := sr.SearchFullText(context.Background(), searchString, &start, &limit, nil)
 			if err != nil {
 				s.T().Fatal("Error getting search result ", err)
 			}
@@ -241,6 +254,8 @@ func stringInSlice(str string, list []string) bool {
 }
 
 func (s *searchRepositoryWhiteboxTest) TestSearchByID() {
+	assignee := s.createAssignee()
+	defer s.DB.Delete(assignee)
 
 	models.Transactional(s.DB, func(tx *gorm.DB) error {
 		wir := workitem.NewWorkItemRepository(tx)
@@ -251,21 +266,21 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByID() {
 			workitem.SystemTitle:       "Search Test Sbose",
 			workitem.SystemDescription: rendering.NewMarkupContentFromLegacy("Description"),
 			workitem.SystemCreator:     "sbose78",
-			workitem.SystemAssignees:   []string{"pranav"},
+			workitem.SystemAssignees:   []string{assignee.ID.String()},
 			workitem.SystemState:       "closed",
 		}
 
-		createdWorkItem, err := wir.Create(context.Background(), workitem.SystemBug, workItem.Fields, testsupport.TestIdentity.ID.String())
+		createdWorkItem, err := wir.Create(context.Background(), nil, workitem.SystemBug, workItem.Fields, testsupport.TestIdentity.ID.String())
 		if err != nil {
 			s.T().Fatalf("Couldn't create test data: %+v", err)
 		}
-		defer wir.Delete(context.Background(), createdWorkItem.ID)
+		defer wir.Delete(context.Background(), createdWorkItem.ID, uuid.Nil)
 
 		// Create a new workitem to have the ID in it's title. This should not come
 		// up in search results
 
 		workItem.Fields[workitem.SystemTitle] = "Search test sbose " + createdWorkItem.ID
-		_, err = wir.Create(context.Background(), workitem.SystemBug, workItem.Fields, testsupport.TestIdentity.ID.String())
+		_, err = wir.Create(context.Background(), nil, workitem.SystemBug, workItem.Fields, testsupport.TestIdentity.ID.String())
 		if err != nil {
 			s.T().Fatalf("Couldn't create test data: %+v", err)
 		}
@@ -274,7 +289,7 @@ func (s *searchRepositoryWhiteboxTest) TestSearchByID() {
 
 		var start, limit int = 0, 100
 		searchString := "id:" + createdWorkItem.ID
-		workItemList, _, err := sr.SearchFullText(context.Background(), searchString, &start, &limit)
+		workItemList, _, _, err := sr.SearchFullText(context.Background(), searchString, &start, &limit, nil)
 		if err != nil {
 			s.T().Fatal("Error gettig search result ", err)
 		}
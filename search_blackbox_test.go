@@ -41,7 +41,7 @@ func TestSearch(t *testing.T) {
 	wiRepo := workitem.NewWorkItemRepository(DB)
 
 	_, err := wiRepo.Create(
-		context.Background(),
+		context.Background(), nil,
 		workitem.SystemBug,
 		map[string]interface{}{
 			workitem.SystemTitle:       "specialwordforsearch",
@@ -53,7 +53,7 @@ func TestSearch(t *testing.T) {
 	require.Nil(t, err)
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := "specialwordforsearch"
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, q)
 	require.NotEmpty(t, sr.Data)
 	r := sr.Data[0]
 	assert.Equal(t, "specialwordforsearch", r.Attributes[workitem.SystemTitle])
@@ -67,7 +67,7 @@ func TestSearchPagination(t *testing.T) {
 	wiRepo := workitem.NewWorkItemRepository(DB)
 
 	_, err := wiRepo.Create(
-		context.Background(),
+		context.Background(), nil,
 		workitem.SystemBug,
 		map[string]interface{}{
 			workitem.SystemTitle:       "specialwordforsearch2",
@@ -80,7 +80,7 @@ func TestSearchPagination(t *testing.T) {
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := "specialwordforsearch2"
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, q)
 	assert.Equal(t, "http:///api/search?q=specialwordforsearch2&page[offset]=0&page[limit]=100", *sr.Links.First)
 	assert.Equal(t, "http:///api/search?q=specialwordforsearch2&page[offset]=0&page[limit]=100", *sr.Links.Last)
 	require.NotEmpty(t, sr.Data)
@@ -95,7 +95,7 @@ func TestSearchWithEmptyValue(t *testing.T) {
 	wiRepo := workitem.NewWorkItemRepository(DB)
 
 	_, err := wiRepo.Create(
-		context.Background(),
+		context.Background(), nil,
 		workitem.SystemBug,
 		map[string]interface{}{
 			workitem.SystemTitle:       "specialwordforsearch",
@@ -108,7 +108,7 @@ func TestSearchWithEmptyValue(t *testing.T) {
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := ""
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, q)
 	require.NotNil(t, sr.Data)
 	assert.Empty(t, sr.Data)
 }
@@ -122,7 +122,7 @@ func TestSearchWithDomainPortCombination(t *testing.T) {
 	description := "http://localhost:8080/detail/154687364529310 is related issue"
 	expectedDescription := rendering.NewMarkupContentFromLegacy(description)
 	_, err := wiRepo.Create(
-		context.Background(),
+		context.Background(), nil,
 		workitem.SystemBug,
 		map[string]interface{}{
 			workitem.SystemTitle:       "specialwordforsearch_new",
@@ -134,7 +134,7 @@ func TestSearchWithDomainPortCombination(t *testing.T) {
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := `"http://localhost:8080/detail/154687364529310"`
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, q)
 	require.NotEmpty(t, sr.Data)
 	r := sr.Data[0]
 	assert.Equal(t, description, r.Attributes[workitem.SystemDescription])
@@ -149,7 +149,7 @@ func TestSearchURLWithoutPort(t *testing.T) {
 	description := "This issue is related to http://localhost/detail/876394"
 	expectedDescription := rendering.NewMarkupContentFromLegacy(description)
 	_, err := wiRepo.Create(
-		context.Background(),
+		context.Background(), nil,
 		workitem.SystemBug,
 		map[string]interface{}{
 			workitem.SystemTitle:       "specialwordforsearch_without_port",
@@ -162,7 +162,7 @@ func TestSearchURLWithoutPort(t *testing.T) {
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := `"http://localhost/detail/876394"`
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, q)
 	require.NotEmpty(t, sr.Data)
 	r := sr.Data[0]
 	assert.Equal(t, description, r.Attributes[workitem.SystemDescription])
@@ -177,7 +177,7 @@ func TestUnregisteredURLWithPort(t *testing.T) {
 	description := "Related to http://some-other-domain:8080/different-path/154687364529310/ok issue"
 	expectedDescription := rendering.NewMarkupContentFromLegacy(description)
 	_, err := wiRepo.Create(
-		context.Background(),
+		context.Background(), nil,
 		workitem.SystemBug,
 		map[string]interface{}{
 			workitem.SystemTitle:       "specialwordforsearch_new",
@@ -190,7 +190,7 @@ func TestUnregisteredURLWithPort(t *testing.T) {
 
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	q := `http://some-other-domain:8080/different-path/`
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, q)
 	require.NotEmpty(t, sr.Data)
 	r := sr.Data[0]
 	assert.Equal(t, description, r.Attributes[workitem.SystemDescription])
@@ -205,7 +205,7 @@ func TestUnwantedCharactersRelatedToSearchLogic(t *testing.T) {
 	expectedDescription := rendering.NewMarkupContentFromLegacy("Related to http://example-domain:8080/different-path/ok issue")
 
 	_, err := wiRepo.Create(
-		context.Background(),
+		context.Background(), nil,
 		workitem.SystemBug,
 		map[string]interface{}{
 			workitem.SystemTitle:       "specialwordforsearch_new",
@@ -219,7 +219,7 @@ func TestUnwantedCharactersRelatedToSearchLogic(t *testing.T) {
 	controller := NewSearchController(service, gormapplication.NewGormDB(DB))
 	// add url: in the query, that is not expected by the code hence need to make sure it gives expected result.
 	q := `http://url:some-random-other-domain:8080/different-path/`
-	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(t, nil, nil, controller, nil, nil, nil, q)
 	require.NotNil(t, sr.Data)
 	assert.Empty(t, sr.Data)
 }
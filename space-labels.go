@@ -0,0 +1,95 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/label"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/rest"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// SpaceLabelsController implements the space-labels resource.
+type SpaceLabelsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSpaceLabelsController creates a space-labels controller.
+func NewSpaceLabelsController(service *goa.Service, db application.DB) *SpaceLabelsController {
+	return &SpaceLabelsController{Controller: service.NewController("SpaceLabelsController"), db: db}
+}
+
+// Create runs the create action.
+func (c *SpaceLabelsController) Create(ctx *app.CreateSpaceLabelsContext) error {
+	_, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	spaceID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	// Validate Request
+	if ctx.Payload.Data == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data", nil).Expected("not nil"))
+	}
+	reqLabel := ctx.Payload.Data
+	if reqLabel.Attributes.Name == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.name", nil).Expected("not nil"))
+	}
+	if reqLabel.Attributes.Color == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.color", nil).Expected("not nil"))
+	}
+
+	return application.Transactional(c.db, func(appl application.Application) error {
+		_, err = appl.Spaces().Load(ctx, spaceID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+
+		newLabel := label.Label{
+			SpaceID: spaceID,
+			Name:    *reqLabel.Attributes.Name,
+			Color:   *reqLabel.Attributes.Color,
+		}
+		err = appl.Labels().Create(ctx, &newLabel)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.LabelSingle{
+			Data: ConvertLabel(ctx.RequestData, &newLabel),
+		}
+		ctx.ResponseData.Header().Set("Location", rest.AbsoluteURL(ctx.RequestData, app.LabelHref(res.Data.ID)))
+		return ctx.Created(res)
+	})
+}
+
+// List runs the list action.
+func (c *SpaceLabelsController) List(ctx *app.ListSpaceLabelsContext) error {
+	spaceID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(c.db, func(appl application.Application) error {
+		_, err = appl.Spaces().Load(ctx, spaceID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+
+		labels, err := appl.Labels().List(ctx, spaceID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.LabelList{}
+		res.Data = ConvertLabels(ctx.RequestData, labels)
+		return ctx.OK(res)
+	})
+}
@@ -0,0 +1,105 @@
+package main_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/label"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSpaceLabelREST struct {
+	gormsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	clean func()
+}
+
+func TestRunSpaceLabelREST(t *testing.T) {
+	suite.Run(t, &TestSpaceLabelREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestSpaceLabelREST) SetupTest() {
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = cleaner.DeleteCreatedEntities(rest.DB)
+}
+
+func (rest *TestSpaceLabelREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestSpaceLabelREST) SecuredController() (*goa.Service, *SpaceLabelsController) {
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	svc := testsupport.ServiceAsUser("Label-Service", almtoken.NewManager(pub), testsupport.TestIdentity)
+	return svc, NewSpaceLabelsController(svc, rest.db)
+}
+
+func (rest *TestSpaceLabelREST) UnSecuredController() (*goa.Service, *SpaceLabelsController) {
+	svc := goa.New("Label-Service")
+	return svc, NewSpaceLabelsController(svc, rest.db)
+}
+
+func (rest *TestSpaceLabelREST) TestCreateListShowLabels() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	s, err := space.NewRepository(rest.DB).Create(context.Background(), &space.Space{Name: "Label space " + uuid.NewV4().String()})
+	require.Nil(t, err)
+	svc, ctrl := rest.SecuredController()
+
+	_, created := test.CreateSpaceLabelsCreated(t, svc.Context, svc, ctrl, s.ID.String(), createSpaceLabel("frontend", "#e0b4b4"))
+	require.NotNil(t, created.Data.ID)
+	assert.Equal(t, "#e0b4b4", *created.Data.Attributes.Color)
+	assert.Equal(t, s.ID.String(), *created.Data.Relationships.Space.Data.ID)
+	test.CreateSpaceLabelsCreated(t, svc.Context, svc, ctrl, s.ID.String(), createSpaceLabel("backend", "#00ff00"))
+
+	// label names are unique within the space
+	test.CreateSpaceLabelsBadRequest(t, svc.Context, svc, ctrl, s.ID.String(), createSpaceLabel("frontend", "#ffffff"))
+	test.CreateSpaceLabelsNotFound(t, svc.Context, svc, ctrl, uuid.NewV4().String(), createSpaceLabel("frontend", "#ffffff"))
+
+	_, list := test.ListSpaceLabelsOK(t, svc.Context, svc, ctrl, s.ID.String())
+	require.Len(t, list.Data, 2)
+	assert.Equal(t, "backend", *list.Data[0].Attributes.Name)
+	assert.Equal(t, "frontend", *list.Data[1].Attributes.Name)
+
+	labelSvc := goa.New("Label-Service")
+	labelCtrl := NewLabelController(labelSvc, rest.db)
+	_, shown := test.ShowLabelOK(t, labelSvc.Context, labelSvc, labelCtrl, created.Data.ID.String())
+	assert.Equal(t, "frontend", *shown.Data.Attributes.Name)
+	test.ShowLabelNotFound(t, labelSvc.Context, labelSvc, labelCtrl, uuid.NewV4().String())
+}
+
+func (rest *TestSpaceLabelREST) TestCreateLabelUnauthorized() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc, ctrl := rest.UnSecuredController()
+	test.CreateSpaceLabelsUnauthorized(t, svc.Context, svc, ctrl, uuid.NewV4().String(), createSpaceLabel("frontend", "#e0b4b4"))
+}
+
+func createSpaceLabel(name string, color string) *app.CreateSpaceLabelsPayload {
+	return &app.CreateSpaceLabelsPayload{
+		Data: &app.Label{
+			Type: label.APIStringTypeLabels,
+			Attributes: &app.LabelAttributes{
+				Name:  &name,
+				Color: &color,
+			},
+		},
+	}
+}
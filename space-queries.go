@@ -0,0 +1,104 @@
+package main
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/rest"
+	"github.com/almighty/almighty-core/savedquery"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// SpaceQueriesController implements the space-queries resource.
+type SpaceQueriesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSpaceQueriesController creates a space-queries controller.
+func NewSpaceQueriesController(service *goa.Service, db application.DB) *SpaceQueriesController {
+	return &SpaceQueriesController{Controller: service.NewController("SpaceQueriesController"), db: db}
+}
+
+// Create runs the create action.
+func (c *SpaceQueriesController) Create(ctx *app.CreateSpaceQueriesContext) error {
+	identity, err := contextIdentityID(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	spaceID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	// Validate Request
+	if ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	reqQuery := ctx.Payload.Data.Attributes
+	if reqQuery.Title == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.title", nil).Expected("not nil"))
+	}
+	if reqQuery.Filter == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.filter", nil).Expected("not nil"))
+	}
+
+	return application.Transactional(c.db, func(appl application.Application) error {
+		_, err = appl.Spaces().Load(ctx, spaceID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+
+		newQuery := savedquery.Query{
+			SpaceID: spaceID,
+			Creator: identity,
+			Title:   *reqQuery.Title,
+			Filter:  *reqQuery.Filter,
+		}
+		if reqQuery.Shared != nil {
+			newQuery.Shared = *reqQuery.Shared
+		}
+
+		q, err := appl.Queries().Create(ctx, &newQuery)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.QuerySingle{
+			Data: ConvertQuery(ctx.RequestData, q),
+		}
+		ctx.ResponseData.Header().Set("Location", rest.AbsoluteURL(ctx.RequestData, app.QueryHref(res.Data.ID)))
+		return ctx.Created(res)
+	})
+}
+
+// List runs the list action.
+func (c *SpaceQueriesController) List(ctx *app.ListSpaceQueriesContext) error {
+	identity, err := contextIdentityID(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	spaceID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(c.db, func(appl application.Application) error {
+		_, err = appl.Spaces().Load(ctx, spaceID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+		}
+
+		queries, err := appl.Queries().List(ctx, spaceID, identity)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+
+		res := &app.QueryList{}
+		res.Data = ConvertQueries(ctx.RequestData, queries)
+
+		return ctx.OK(res)
+	})
+}
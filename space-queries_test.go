@@ -0,0 +1,177 @@
+package main_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	. "github.com/almighty/almighty-core"
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/savedquery"
+	"github.com/almighty/almighty-core/space"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSpaceQueryREST struct {
+	gormsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	clean func()
+}
+
+func TestRunSpaceQueryREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestSpaceQueryREST{DBTestSuite: gormsupport.NewDBTestSuite("config.yaml")})
+}
+
+func (rest *TestSpaceQueryREST) SetupTest() {
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = cleaner.DeleteCreatedEntities(rest.DB)
+}
+
+func (rest *TestSpaceQueryREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestSpaceQueryREST) service(identity account.Identity) *goa.Service {
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+	return testsupport.ServiceAsUser("Query-Service", almtoken.NewManager(pub), identity)
+}
+
+func (rest *TestSpaceQueryREST) createSpace() *space.Space {
+	var p *space.Space
+	application.Transactional(rest.db, func(appl application.Application) error {
+		var err error
+		p, err = appl.Spaces().Create(context.Background(), &space.Space{
+			Name: "Test Space " + uuid.NewV4().String(),
+		})
+		require.Nil(rest.T(), err)
+		return nil
+	})
+	return p
+}
+
+func newSavedQueryPayload(title string, filter string, shared bool) *app.QuerySingle {
+	return &app.QuerySingle{
+		Data: &app.Query{
+			Type: savedquery.APIStringTypeQueries,
+			Attributes: &app.QueryAttributes{
+				Title:  &title,
+				Filter: &filter,
+				Shared: &shared,
+			},
+		},
+	}
+}
+
+func (rest *TestSpaceQueryREST) TestCreateAndListQueries() {
+	t := rest.T()
+	p := rest.createSpace()
+	svc := rest.service(testsupport.TestIdentity)
+	ctrl := NewSpaceQueriesController(svc, rest.db)
+
+	_, created := test.CreateSpaceQueriesCreated(t, svc.Context, svc, ctrl, p.ID.String(), newSavedQueryPayload("My open bugs", "Type == 'bug'", false))
+	require.NotNil(t, created.Data.ID)
+	assert.Equal(t, "My open bugs", *created.Data.Attributes.Title)
+	assert.Equal(t, p.ID.String(), *created.Data.Relationships.Space.Data.ID)
+	assert.Equal(t, testsupport.TestIdentity.ID.String(), *created.Data.Relationships.Creator.Data.ID)
+	test.CreateSpaceQueriesCreated(t, svc.Context, svc, ctrl, p.ID.String(), newSavedQueryPayload("Shared bugs", "Type == 'bug'", true))
+
+	_, list := test.ListSpaceQueriesOK(t, svc.Context, svc, ctrl, p.ID.String())
+	assert.Len(t, list.Data, 2)
+
+	// the private query is not visible to others
+	svc2 := rest.service(testsupport.TestIdentity2)
+	ctrl2 := NewSpaceQueriesController(svc2, rest.db)
+	_, list = test.ListSpaceQueriesOK(t, svc2.Context, svc2, ctrl2, p.ID.String())
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, "Shared bugs", *list.Data[0].Attributes.Title)
+}
+
+func (rest *TestSpaceQueryREST) TestCreateQueryBadFilter() {
+	t := rest.T()
+	p := rest.createSpace()
+	svc := rest.service(testsupport.TestIdentity)
+	ctrl := NewSpaceQueriesController(svc, rest.db)
+
+	test.CreateSpaceQueriesBadRequest(t, svc.Context, svc, ctrl, p.ID.String(), newSavedQueryPayload("broken", "Type ==", false))
+	test.CreateSpaceQueriesNotFound(t, svc.Context, svc, ctrl, uuid.NewV4().String(), newSavedQueryPayload("no space", "Type == 'bug'", false))
+}
+
+func (rest *TestSpaceQueryREST) TestShowUpdateDeleteQuery() {
+	t := rest.T()
+	p := rest.createSpace()
+	svc := rest.service(testsupport.TestIdentity)
+	ctrl := NewSpaceQueriesController(svc, rest.db)
+	queryCtrl := NewQueryController(svc, rest.db)
+	svc2 := rest.service(testsupport.TestIdentity2)
+	queryCtrl2 := NewQueryController(svc2, rest.db)
+
+	_, private := test.CreateSpaceQueriesCreated(t, svc.Context, svc, ctrl, p.ID.String(), newSavedQueryPayload("private", "Type == 'bug'", false))
+	_, shared := test.CreateSpaceQueriesCreated(t, svc.Context, svc, ctrl, p.ID.String(), newSavedQueryPayload("shared", "Type == 'bug'", true))
+	privateID := private.Data.ID.String()
+	sharedID := shared.Data.ID.String()
+
+	test.ShowQueryOK(t, svc.Context, svc, queryCtrl, privateID)
+	test.ShowQueryNotFound(t, svc2.Context, svc2, queryCtrl2, privateID)
+	test.ShowQueryOK(t, svc2.Context, svc2, queryCtrl2, sharedID)
+
+	update := newSavedQueryPayload("renamed", "Type == 'bug' and system.state == 'open'", true)
+	update.Data.Attributes.Version = shared.Data.Attributes.Version
+	test.UpdateQueryForbidden(t, svc2.Context, svc2, queryCtrl2, sharedID, update)
+	_, updated := test.UpdateQueryOK(t, svc.Context, svc, queryCtrl, sharedID, update)
+	assert.Equal(t, "renamed", *updated.Data.Attributes.Title)
+	assert.Equal(t, *shared.Data.Attributes.Version+1, *updated.Data.Attributes.Version)
+
+	test.DeleteQueryForbidden(t, svc2.Context, svc2, queryCtrl2, sharedID)
+	test.DeleteQueryOK(t, svc.Context, svc, queryCtrl, sharedID)
+	test.ShowQueryNotFound(t, svc.Context, svc, queryCtrl, sharedID)
+}
+
+func (rest *TestSpaceQueryREST) TestExecuteQuery() {
+	t := rest.T()
+	p := rest.createSpace()
+	svc := rest.service(testsupport.TestIdentity)
+	ctrl := NewSpaceQueriesController(svc, rest.db)
+	queryCtrl := NewQueryController(svc, rest.db)
+
+	_, created := test.CreateSpaceQueriesCreated(t, svc.Context, svc, ctrl, p.ID.String(), newSavedQueryPayload("nothing", "system.title == '"+uuid.NewV4().String()+"'", false))
+	limit := 10
+	_, result := test.ExecuteQueryOK(t, svc.Context, svc, queryCtrl, created.Data.ID.String(), nil, &limit, nil)
+	assert.Len(t, result.Data, 0)
+	assert.Equal(t, 0, result.Meta.TotalCount)
+	require.NotNil(t, result.Links.First)
+
+	t.Log("Work items of other spaces are not listed")
+	title := uuid.NewV4().String()
+	other := rest.createSpace()
+	application.Transactional(rest.db, func(appl application.Application) error {
+		for _, spaceID := range []uuid.UUID{p.ID, other.ID} {
+			_, err := appl.WorkItems().Create(context.Background(), &spaceID, workitem.SystemBug, map[string]interface{}{
+				workitem.SystemTitle: title,
+				workitem.SystemState: workitem.SystemStateNew,
+			}, testsupport.TestIdentity.ID.String())
+			require.Nil(t, err)
+		}
+		return nil
+	})
+	_, created = test.CreateSpaceQueriesCreated(t, svc.Context, svc, ctrl, p.ID.String(), newSavedQueryPayload("titled", "system.title == '"+title+"'", false))
+	_, result = test.ExecuteQueryOK(t, svc.Context, svc, queryCtrl, created.Data.ID.String(), nil, &limit, nil)
+	require.Len(t, result.Data, 1)
+	require.NotNil(t, result.Data[0].Relationships.Space)
+	assert.Equal(t, p.ID.String(), *result.Data[0].Relationships.Space.Data.ID)
+}
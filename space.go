@@ -8,9 +8,12 @@ import (
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/process"
 	"github.com/almighty/almighty-core/rest"
 	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/workitem"
 	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
 	satoriuuid "github.com/satori/go.uuid"
 )
 
@@ -36,26 +39,46 @@ func (c *SpaceController) Create(ctx *app.CreateSpaceContext) error {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
 
-	return application.Transactional(c.db, func(appl application.Application) error {
-		reqSpace := ctx.Payload.Data
-
-		newSpace := space.Space{
-			Name: *reqSpace.Attributes.Name,
-		}
-		if reqSpace.Attributes.Description != nil {
-			newSpace.Description = *reqSpace.Attributes.Description
+	reqSpace := ctx.Payload.Data
+	newSpace := space.Space{
+		Name: *reqSpace.Attributes.Name,
+	}
+	if reqSpace.Attributes.Description != nil {
+		newSpace.Description = *reqSpace.Attributes.Description
+	}
+	var template *process.Template
+	if reqSpace.Attributes.ProcessTemplate != nil {
+		template, err = process.Lookup(*reqSpace.Attributes.ProcessTemplate)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.process-template", *reqSpace.Attributes.ProcessTemplate).Expected("the name of a process template"))
 		}
+		newSpace.ProcessTemplate = template.Name
+	}
 
+	var res *app.SpaceSingle
+	err = application.Transactional(c.db, func(appl application.Application) error {
 		space, err := appl.Spaces().Create(ctx, &newSpace)
 		if err != nil {
-			return jsonapi.JSONErrorResponse(ctx, err)
+			return errs.WithStack(err)
+		}
+		// errors are returned to roll back the space and the types of a partially applied template
+		if template != nil {
+			if err := process.Apply(ctx, space.ID, *template, appl.WorkItemTypes(), appl.WorkItemLinkCategories(), appl.WorkItemLinkTypes()); err != nil {
+				return errs.WithStack(err)
+			}
 		}
-		res := &app.SpaceSingle{
+		res = &app.SpaceSingle{
 			Data: ConvertSpace(ctx.RequestData, space),
 		}
-		ctx.ResponseData.Header().Set("Location", rest.AbsoluteURL(ctx.RequestData, app.SpaceHref(res.Data.ID)))
-		return ctx.Created(res)
+		return nil
 	})
+	// types cached while the template was applied were never valid after a rollback
+	workitem.ClearGlobalWorkItemTypeCache()
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	ctx.ResponseData.Header().Set("Location", rest.AbsoluteURL(ctx.RequestData, app.SpaceHref(res.Data.ID)))
+	return ctx.Created(res)
 }
 
 // Delete runs the delete action.
@@ -210,7 +233,7 @@ func ConvertSpaces(request *goa.RequestData, spaces []*space.Space, additional .
 func ConvertSpace(request *goa.RequestData, p *space.Space, additional ...SpaceConvertFunc) *app.Space {
 	selfURL := rest.AbsoluteURL(request, app.SpaceHref(p.ID))
 	relatedIterationList := rest.AbsoluteURL(request, fmt.Sprintf("/api/spaces/%s/iterations", p.ID.String()))
-	return &app.Space{
+	s := &app.Space{
 		ID:   &p.ID,
 		Type: "spaces",
 		Attributes: &app.SpaceAttributes{
@@ -231,4 +254,8 @@ func ConvertSpace(request *goa.RequestData, p *space.Space, additional ...SpaceC
 			},
 		},
 	}
+	if p.ProcessTemplate != "" {
+		s.Attributes.ProcessTemplate = &p.ProcessTemplate
+	}
+	return s
 }
@@ -20,6 +20,8 @@ type Space struct {
 	Version     int
 	Name        string
 	Description string
+	// ProcessTemplate is the name of the process template applied when the space was created
+	ProcessTemplate string
 }
 
 // Ensure Fields implements the Equaler interface
@@ -45,6 +47,9 @@ func (p Space) Equal(u convert.Equaler) bool {
 	if p.Description != other.Description {
 		return false
 	}
+	if p.ProcessTemplate != other.ProcessTemplate {
+		return false
+	}
 	return true
 }
 
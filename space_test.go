@@ -7,15 +7,21 @@ import (
 	. "github.com/almighty/almighty-core"
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormapplication"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/process"
 	"github.com/almighty/almighty-core/resource"
 	testsupport "github.com/almighty/almighty-core/test"
 	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/workitem"
 	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -117,6 +123,111 @@ func (rest *TestSpaceREST) TestSuccessCreateSpaceWithDescription() {
 	assert.NotNil(t, created.Data.Links.Self)
 }
 
+func (rest *TestSpaceREST) TestSuccessCreateSpaceWithProcessTemplate() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	defer workitem.ClearGlobalWorkItemTypeCache()
+
+	name := "Test 27"
+	template := process.TemplateKanban
+
+	p := minimumRequiredCreateSpace()
+	p.Data.Attributes.Name = &name
+	p.Data.Attributes.ProcessTemplate = &template
+
+	svc, ctrl := rest.SecuredController()
+	_, created := test.CreateSpaceCreated(t, svc.Context, svc, ctrl, p)
+	require.NotNil(t, created.Data.Attributes.ProcessTemplate)
+	assert.Equal(t, template, *created.Data.Attributes.ProcessTemplate)
+
+	// the types of the template are available in the space with the states of the template
+	application.Transactional(rest.db, func(appl application.Application) error {
+		wit, err := appl.WorkItemTypes().Load(svc.Context, created.Data.ID, "kanbancard")
+		require.Nil(t, err)
+		require.NotNil(t, wit.Space)
+		assert.Equal(t, *created.Data.ID, *wit.Space)
+		require.NotNil(t, wit.Fields[workitem.SystemState])
+		assert.Equal(t, []interface{}{"backlog", "ready", "in progress", "review", "done"}, wit.Fields[workitem.SystemState].Type.Values)
+		// the types of a template are no system types
+		_, err = appl.WorkItemTypes().Load(svc.Context, nil, "kanbancard")
+		assert.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+		return nil
+	})
+}
+
+func (rest *TestSpaceREST) TestSuccessEvolveProcessTemplateTypesPerSpace() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	defer workitem.ClearGlobalWorkItemTypeCache()
+
+	template := process.TemplateScrum
+	svc, ctrl := rest.SecuredController()
+	var spaceIDs []uuid.UUID
+	for _, name := range []string{"Test 29", "Test 30"} {
+		p := minimumRequiredCreateSpace()
+		p.Data.Attributes.Name = &name
+		p.Data.Attributes.ProcessTemplate = &template
+		_, created := test.CreateSpaceCreated(t, svc.Context, svc, ctrl, p)
+		spaceIDs = append(spaceIDs, *created.Data.ID)
+	}
+
+	// changing the type of one space leaves the type of the other space unchanged
+	err := application.Transactional(rest.db, func(appl application.Application) error {
+		wit, err := appl.WorkItemTypes().Load(svc.Context, &spaceIDs[0], "productbacklogitem")
+		require.Nil(t, err)
+		_, err = appl.WorkItemTypes().Update(svc.Context, &spaceIDs[0], "productbacklogitem", wit.Version, map[string]app.FieldDefinition{
+			"scrum.businessvalue": {Required: false, Type: &app.FieldType{Kind: "integer"}},
+		}, nil, nil)
+		return err
+	})
+	require.Nil(t, err)
+	workitem.ClearGlobalWorkItemTypeCache()
+
+	application.Transactional(rest.db, func(appl application.Application) error {
+		changed, err := appl.WorkItemTypes().Load(svc.Context, &spaceIDs[0], "productbacklogitem")
+		require.Nil(t, err)
+		assert.NotNil(t, changed.Fields["scrum.businessvalue"])
+		other, err := appl.WorkItemTypes().Load(svc.Context, &spaceIDs[1], "productbacklogitem")
+		require.Nil(t, err)
+		assert.Nil(t, other.Fields["scrum.businessvalue"])
+		assert.Equal(t, 0, other.Version)
+
+		// work items get the type of their space
+		wi, err := appl.WorkItems().Create(svc.Context, &spaceIDs[1], "productbacklogitem", map[string]interface{}{
+			workitem.SystemTitle: "Backlog item",
+		}, uuid.NewV4().String())
+		require.Nil(t, err)
+		require.NotNil(t, wi.Space)
+		assert.Equal(t, spaceIDs[1], *wi.Space)
+		_, err = appl.WorkItems().Update(svc.Context, wi.ID, wi.Version, map[string]interface{}{"scrum.businessvalue": 3}, uuid.Nil)
+		require.Nil(t, err)
+		loaded, err := appl.WorkItems().Load(svc.Context, wi.ID)
+		require.Nil(t, err)
+		assert.Nil(t, loaded.Fields["scrum.businessvalue"])
+		// the types of a space are not available in other spaces
+		_, err = appl.WorkItems().Create(svc.Context, nil, "productbacklogitem", map[string]interface{}{
+			workitem.SystemTitle: "Backlog item",
+		}, uuid.NewV4().String())
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+		return appl.WorkItems().Delete(svc.Context, wi.ID, uuid.Nil)
+	})
+}
+
+func (rest *TestSpaceREST) TestFailCreateSpaceUnknownProcessTemplate() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	name := "Test 28"
+	template := "Waterfall"
+
+	p := minimumRequiredCreateSpace()
+	p.Data.Attributes.Name = &name
+	p.Data.Attributes.ProcessTemplate = &template
+
+	svc, ctrl := rest.SecuredController()
+	test.CreateSpaceBadRequest(t, svc.Context, svc, ctrl, p)
+}
+
 func (rest *TestSpaceREST) TestSuccessUpdateProject() {
 	t := rest.T()
 	resource.Require(t, resource.Database)
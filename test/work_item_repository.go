@@ -6,7 +6,9 @@ import (
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
 	"golang.org/x/net/context"
 )
 
@@ -21,49 +23,102 @@ type WorkItemRepository struct {
 		result1 *app.WorkItem
 		result2 error
 	}
-	SaveStub        func(ctx context.Context, wi app.WorkItem) (*app.WorkItem, error)
+	SaveStub        func(ctx context.Context, wi app.WorkItem, modifierID uuid.UUID) (*app.WorkItem, error)
 	saveMutex       sync.RWMutex
 	saveArgsForCall []struct {
-		ctx context.Context
-		wi  app.WorkItem
+		ctx        context.Context
+		wi         app.WorkItem
+		modifierID uuid.UUID
 	}
 	saveReturns struct {
 		result1 *app.WorkItem
 		result2 error
 	}
-	DeleteStub        func(ctx context.Context, ID string) error
+	DeleteStub        func(ctx context.Context, ID string, modifierID uuid.UUID) error
 	deleteMutex       sync.RWMutex
 	deleteArgsForCall []struct {
-		ctx context.Context
-		ID  string
+		ctx        context.Context
+		ID         string
+		modifierID uuid.UUID
 	}
 	deleteReturns struct {
 		result1 error
 	}
-	CreateStub        func(ctx context.Context, typeID string, fields map[string]interface{}) (*app.WorkItem, error)
+	CreateStub        func(ctx context.Context, spaceID *uuid.UUID, typeID string, fields map[string]interface{}) (*app.WorkItem, error)
 	createMutex       sync.RWMutex
 	createArgsForCall []struct {
-		ctx    context.Context
-		typeID string
-		fields map[string]interface{}
+		ctx     context.Context
+		spaceID *uuid.UUID
+		typeID  string
+		fields  map[string]interface{}
 	}
 	createReturns struct {
 		result1 *app.WorkItem
 		result2 error
 	}
-	ListStub        func(ctx context.Context, criteria criteria.Expression, start *int, length *int) ([]*app.WorkItem, uint64, error)
+	ListStub        func(ctx context.Context, criteria criteria.Expression, orderBy []criteria.OrderBy, start *int, length *int, cursor *gormsupport.Cursor) ([]*app.WorkItem, uint64, *gormsupport.PageCursors, error)
 	listMutex       sync.RWMutex
 	listArgsForCall []struct {
 		ctx      context.Context
 		criteria criteria.Expression
+		orderBy  []criteria.OrderBy
 		start    *int
 		length   *int
+		cursor   *gormsupport.Cursor
 	}
 	listReturns struct {
 		result1 []*app.WorkItem
 		result2 uint64
+		result3 *gormsupport.PageCursors
+		result4 error
+	}
+	UpdateStub        func(ctx context.Context, ID string, version int, fields map[string]interface{}, modifierID uuid.UUID) (*app.WorkItem, error)
+	updateMutex       sync.RWMutex
+	updateArgsForCall []struct {
+		ctx        context.Context
+		ID         string
+		version    int
+		fields     map[string]interface{}
+		modifierID uuid.UUID
+	}
+	updateReturns struct {
+		result1 *app.WorkItem
+		result2 error
+	}
+	AggregateStub        func(ctx context.Context, criteria criteria.Expression, groupBy string) ([]workitem.Bucket, uint64, error)
+	aggregateMutex       sync.RWMutex
+	aggregateArgsForCall []struct {
+		ctx      context.Context
+		criteria criteria.Expression
+		groupBy  string
+	}
+	aggregateReturns struct {
+		result1 []workitem.Bucket
+		result2 uint64
 		result3 error
 	}
+	NextStatesStub        func(ctx context.Context, ID string) ([]workitem.NextState, error)
+	nextStatesMutex       sync.RWMutex
+	nextStatesArgsForCall []struct {
+		ctx context.Context
+		ID  string
+	}
+	nextStatesReturns struct {
+		result1 []workitem.NextState
+		result2 error
+	}
+	ReorderStub        func(ctx context.Context, ID string, direction string, otherID string) (*app.WorkItem, error)
+	reorderMutex       sync.RWMutex
+	reorderArgsForCall []struct {
+		ctx       context.Context
+		ID        string
+		direction string
+		otherID   string
+	}
+	reorderReturns struct {
+		result1 *app.WorkItem
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -103,16 +158,17 @@ func (fake *WorkItemRepository) LoadReturns(result1 *app.WorkItem, result2 error
 	}{result1, result2}
 }
 
-func (fake *WorkItemRepository) Save(ctx context.Context, wi app.WorkItem) (*app.WorkItem, error) {
+func (fake *WorkItemRepository) Save(ctx context.Context, wi app.WorkItem, modifierID uuid.UUID) (*app.WorkItem, error) {
 	fake.saveMutex.Lock()
 	fake.saveArgsForCall = append(fake.saveArgsForCall, struct {
-		ctx context.Context
-		wi  app.WorkItem
-	}{ctx, wi})
-	fake.recordInvocation("Save", []interface{}{ctx, wi})
+		ctx        context.Context
+		wi         app.WorkItem
+		modifierID uuid.UUID
+	}{ctx, wi, modifierID})
+	fake.recordInvocation("Save", []interface{}{ctx, wi, modifierID})
 	fake.saveMutex.Unlock()
 	if fake.SaveStub != nil {
-		return fake.SaveStub(ctx, wi)
+		return fake.SaveStub(ctx, wi, modifierID)
 	} else {
 		return fake.saveReturns.result1, fake.saveReturns.result2
 	}
@@ -124,10 +180,10 @@ func (fake *WorkItemRepository) SaveCallCount() int {
 	return len(fake.saveArgsForCall)
 }
 
-func (fake *WorkItemRepository) SaveArgsForCall(i int) (context.Context, app.WorkItem) {
+func (fake *WorkItemRepository) SaveArgsForCall(i int) (context.Context, app.WorkItem, uuid.UUID) {
 	fake.saveMutex.RLock()
 	defer fake.saveMutex.RUnlock()
-	return fake.saveArgsForCall[i].ctx, fake.saveArgsForCall[i].wi
+	return fake.saveArgsForCall[i].ctx, fake.saveArgsForCall[i].wi, fake.saveArgsForCall[i].modifierID
 }
 
 func (fake *WorkItemRepository) SaveReturns(result1 *app.WorkItem, result2 error) {
@@ -138,16 +194,17 @@ func (fake *WorkItemRepository) SaveReturns(result1 *app.WorkItem, result2 error
 	}{result1, result2}
 }
 
-func (fake *WorkItemRepository) Delete(ctx context.Context, ID string) error {
+func (fake *WorkItemRepository) Delete(ctx context.Context, ID string, modifierID uuid.UUID) error {
 	fake.deleteMutex.Lock()
 	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
-		ctx context.Context
-		ID  string
-	}{ctx, ID})
-	fake.recordInvocation("Delete", []interface{}{ctx, ID})
+		ctx        context.Context
+		ID         string
+		modifierID uuid.UUID
+	}{ctx, ID, modifierID})
+	fake.recordInvocation("Delete", []interface{}{ctx, ID, modifierID})
 	fake.deleteMutex.Unlock()
 	if fake.DeleteStub != nil {
-		return fake.DeleteStub(ctx, ID)
+		return fake.DeleteStub(ctx, ID, modifierID)
 	} else {
 		return fake.deleteReturns.result1
 	}
@@ -159,10 +216,10 @@ func (fake *WorkItemRepository) DeleteCallCount() int {
 	return len(fake.deleteArgsForCall)
 }
 
-func (fake *WorkItemRepository) DeleteArgsForCall(i int) (context.Context, string) {
+func (fake *WorkItemRepository) DeleteArgsForCall(i int) (context.Context, string, uuid.UUID) {
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
-	return fake.deleteArgsForCall[i].ctx, fake.deleteArgsForCall[i].ID
+	return fake.deleteArgsForCall[i].ctx, fake.deleteArgsForCall[i].ID, fake.deleteArgsForCall[i].modifierID
 }
 
 func (fake *WorkItemRepository) DeleteReturns(result1 error) {
@@ -172,17 +229,18 @@ func (fake *WorkItemRepository) DeleteReturns(result1 error) {
 	}{result1}
 }
 
-func (fake *WorkItemRepository) Create(ctx context.Context, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error) {
+func (fake *WorkItemRepository) Create(ctx context.Context, spaceID *uuid.UUID, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error) {
 	fake.createMutex.Lock()
 	fake.createArgsForCall = append(fake.createArgsForCall, struct {
-		ctx    context.Context
-		typeID string
-		fields map[string]interface{}
-	}{ctx, typeID, fields})
-	fake.recordInvocation("Create", []interface{}{ctx, typeID, fields})
+		ctx     context.Context
+		spaceID *uuid.UUID
+		typeID  string
+		fields  map[string]interface{}
+	}{ctx, spaceID, typeID, fields})
+	fake.recordInvocation("Create", []interface{}{ctx, spaceID, typeID, fields})
 	fake.createMutex.Unlock()
 	if fake.CreateStub != nil {
-		return fake.CreateStub(ctx, typeID, fields)
+		return fake.CreateStub(ctx, spaceID, typeID, fields)
 	} else {
 		return fake.createReturns.result1, fake.createReturns.result2
 	}
@@ -194,10 +252,10 @@ func (fake *WorkItemRepository) CreateCallCount() int {
 	return len(fake.createArgsForCall)
 }
 
-func (fake *WorkItemRepository) CreateArgsForCall(i int) (context.Context, string, map[string]interface{}) {
+func (fake *WorkItemRepository) CreateArgsForCall(i int) (context.Context, *uuid.UUID, string, map[string]interface{}) {
 	fake.createMutex.RLock()
 	defer fake.createMutex.RUnlock()
-	return fake.createArgsForCall[i].ctx, fake.createArgsForCall[i].typeID, fake.createArgsForCall[i].fields
+	return fake.createArgsForCall[i].ctx, fake.createArgsForCall[i].spaceID, fake.createArgsForCall[i].typeID, fake.createArgsForCall[i].fields
 }
 
 func (fake *WorkItemRepository) CreateReturns(result1 *app.WorkItem, result2 error) {
@@ -208,20 +266,27 @@ func (fake *WorkItemRepository) CreateReturns(result1 *app.WorkItem, result2 err
 	}{result1, result2}
 }
 
-func (fake *WorkItemRepository) List(ctx context.Context, c criteria.Expression, start *int, length *int) ([]*app.WorkItem, uint64, error) {
+func (fake *WorkItemRepository) List(ctx context.Context, c criteria.Expression, orderBy []criteria.OrderBy, start *int, length *int, cursor *gormsupport.Cursor) ([]*app.WorkItem, uint64, *gormsupport.PageCursors, error) {
+	var orderByCopy []criteria.OrderBy
+	if orderBy != nil {
+		orderByCopy = make([]criteria.OrderBy, len(orderBy))
+		copy(orderByCopy, orderBy)
+	}
 	fake.listMutex.Lock()
 	fake.listArgsForCall = append(fake.listArgsForCall, struct {
 		ctx      context.Context
 		criteria criteria.Expression
+		orderBy  []criteria.OrderBy
 		start    *int
 		length   *int
-	}{ctx, c, start, length})
-	fake.recordInvocation("List", []interface{}{ctx, c, start, length})
+		cursor   *gormsupport.Cursor
+	}{ctx, c, orderByCopy, start, length, cursor})
+	fake.recordInvocation("List", []interface{}{ctx, c, orderByCopy, start, length, cursor})
 	fake.listMutex.Unlock()
 	if fake.ListStub != nil {
-		return fake.ListStub(ctx, c, start, length)
+		return fake.ListStub(ctx, c, orderBy, start, length, cursor)
 	} else {
-		return fake.listReturns.result1, fake.listReturns.result2, fake.listReturns.result3
+		return fake.listReturns.result1, fake.listReturns.result2, fake.listReturns.result3, fake.listReturns.result4
 	}
 }
 
@@ -231,21 +296,169 @@ func (fake *WorkItemRepository) ListCallCount() int {
 	return len(fake.listArgsForCall)
 }
 
-func (fake *WorkItemRepository) ListArgsForCall(i int) (context.Context, criteria.Expression, *int, *int) {
+func (fake *WorkItemRepository) ListArgsForCall(i int) (context.Context, criteria.Expression, []criteria.OrderBy, *int, *int, *gormsupport.Cursor) {
 	fake.listMutex.RLock()
 	defer fake.listMutex.RUnlock()
-	return fake.listArgsForCall[i].ctx, fake.listArgsForCall[i].criteria, fake.listArgsForCall[i].start, fake.listArgsForCall[i].length
+	return fake.listArgsForCall[i].ctx, fake.listArgsForCall[i].criteria, fake.listArgsForCall[i].orderBy, fake.listArgsForCall[i].start, fake.listArgsForCall[i].length, fake.listArgsForCall[i].cursor
 }
 
-func (fake *WorkItemRepository) ListReturns(result1 []*app.WorkItem, result2 uint64, result3 error) {
+func (fake *WorkItemRepository) ListReturns(result1 []*app.WorkItem, result2 uint64, result3 *gormsupport.PageCursors, result4 error) {
 	fake.ListStub = nil
 	fake.listReturns = struct {
 		result1 []*app.WorkItem
 		result2 uint64
+		result3 *gormsupport.PageCursors
+		result4 error
+	}{result1, result2, result3, result4}
+}
+
+func (fake *WorkItemRepository) Update(ctx context.Context, ID string, version int, fields map[string]interface{}, modifierID uuid.UUID) (*app.WorkItem, error) {
+	fake.updateMutex.Lock()
+	fake.updateArgsForCall = append(fake.updateArgsForCall, struct {
+		ctx        context.Context
+		ID         string
+		version    int
+		fields     map[string]interface{}
+		modifierID uuid.UUID
+	}{ctx, ID, version, fields, modifierID})
+	fake.recordInvocation("Update", []interface{}{ctx, ID, version, fields, modifierID})
+	fake.updateMutex.Unlock()
+	if fake.UpdateStub != nil {
+		return fake.UpdateStub(ctx, ID, version, fields, modifierID)
+	} else {
+		return fake.updateReturns.result1, fake.updateReturns.result2
+	}
+}
+
+func (fake *WorkItemRepository) UpdateCallCount() int {
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	return len(fake.updateArgsForCall)
+}
+
+func (fake *WorkItemRepository) UpdateArgsForCall(i int) (context.Context, string, int, map[string]interface{}, uuid.UUID) {
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	return fake.updateArgsForCall[i].ctx, fake.updateArgsForCall[i].ID, fake.updateArgsForCall[i].version, fake.updateArgsForCall[i].fields, fake.updateArgsForCall[i].modifierID
+}
+
+func (fake *WorkItemRepository) UpdateReturns(result1 *app.WorkItem, result2 error) {
+	fake.UpdateStub = nil
+	fake.updateReturns = struct {
+		result1 *app.WorkItem
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *WorkItemRepository) Aggregate(ctx context.Context, c criteria.Expression, groupBy string) ([]workitem.Bucket, uint64, error) {
+	fake.aggregateMutex.Lock()
+	fake.aggregateArgsForCall = append(fake.aggregateArgsForCall, struct {
+		ctx      context.Context
+		criteria criteria.Expression
+		groupBy  string
+	}{ctx, c, groupBy})
+	fake.recordInvocation("Aggregate", []interface{}{ctx, c, groupBy})
+	fake.aggregateMutex.Unlock()
+	if fake.AggregateStub != nil {
+		return fake.AggregateStub(ctx, c, groupBy)
+	} else {
+		return fake.aggregateReturns.result1, fake.aggregateReturns.result2, fake.aggregateReturns.result3
+	}
+}
+
+func (fake *WorkItemRepository) AggregateCallCount() int {
+	fake.aggregateMutex.RLock()
+	defer fake.aggregateMutex.RUnlock()
+	return len(fake.aggregateArgsForCall)
+}
+
+func (fake *WorkItemRepository) AggregateArgsForCall(i int) (context.Context, criteria.Expression, string) {
+	fake.aggregateMutex.RLock()
+	defer fake.aggregateMutex.RUnlock()
+	return fake.aggregateArgsForCall[i].ctx, fake.aggregateArgsForCall[i].criteria, fake.aggregateArgsForCall[i].groupBy
+}
+
+func (fake *WorkItemRepository) AggregateReturns(result1 []workitem.Bucket, result2 uint64, result3 error) {
+	fake.AggregateStub = nil
+	fake.aggregateReturns = struct {
+		result1 []workitem.Bucket
+		result2 uint64
 		result3 error
 	}{result1, result2, result3}
 }
 
+func (fake *WorkItemRepository) NextStates(ctx context.Context, ID string) ([]workitem.NextState, error) {
+	fake.nextStatesMutex.Lock()
+	fake.nextStatesArgsForCall = append(fake.nextStatesArgsForCall, struct {
+		ctx context.Context
+		ID  string
+	}{ctx, ID})
+	fake.recordInvocation("NextStates", []interface{}{ctx, ID})
+	fake.nextStatesMutex.Unlock()
+	if fake.NextStatesStub != nil {
+		return fake.NextStatesStub(ctx, ID)
+	} else {
+		return fake.nextStatesReturns.result1, fake.nextStatesReturns.result2
+	}
+}
+
+func (fake *WorkItemRepository) NextStatesCallCount() int {
+	fake.nextStatesMutex.RLock()
+	defer fake.nextStatesMutex.RUnlock()
+	return len(fake.nextStatesArgsForCall)
+}
+
+func (fake *WorkItemRepository) NextStatesArgsForCall(i int) (context.Context, string) {
+	fake.nextStatesMutex.RLock()
+	defer fake.nextStatesMutex.RUnlock()
+	return fake.nextStatesArgsForCall[i].ctx, fake.nextStatesArgsForCall[i].ID
+}
+
+func (fake *WorkItemRepository) NextStatesReturns(result1 []workitem.NextState, result2 error) {
+	fake.NextStatesStub = nil
+	fake.nextStatesReturns = struct {
+		result1 []workitem.NextState
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *WorkItemRepository) Reorder(ctx context.Context, ID string, direction string, otherID string) (*app.WorkItem, error) {
+	fake.reorderMutex.Lock()
+	fake.reorderArgsForCall = append(fake.reorderArgsForCall, struct {
+		ctx       context.Context
+		ID        string
+		direction string
+		otherID   string
+	}{ctx, ID, direction, otherID})
+	fake.recordInvocation("Reorder", []interface{}{ctx, ID, direction, otherID})
+	fake.reorderMutex.Unlock()
+	if fake.ReorderStub != nil {
+		return fake.ReorderStub(ctx, ID, direction, otherID)
+	} else {
+		return fake.reorderReturns.result1, fake.reorderReturns.result2
+	}
+}
+
+func (fake *WorkItemRepository) ReorderCallCount() int {
+	fake.reorderMutex.RLock()
+	defer fake.reorderMutex.RUnlock()
+	return len(fake.reorderArgsForCall)
+}
+
+func (fake *WorkItemRepository) ReorderArgsForCall(i int) (context.Context, string, string, string) {
+	fake.reorderMutex.RLock()
+	defer fake.reorderMutex.RUnlock()
+	return fake.reorderArgsForCall[i].ctx, fake.reorderArgsForCall[i].ID, fake.reorderArgsForCall[i].direction, fake.reorderArgsForCall[i].otherID
+}
+
+func (fake *WorkItemRepository) ReorderReturns(result1 *app.WorkItem, result2 error) {
+	fake.ReorderStub = nil
+	fake.reorderReturns = struct {
+		result1 *app.WorkItem
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *WorkItemRepository) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -259,6 +472,14 @@ func (fake *WorkItemRepository) Invocations() map[string][][]interface{} {
 	defer fake.createMutex.RUnlock()
 	fake.listMutex.RLock()
 	defer fake.listMutex.RUnlock()
+	fake.updateMutex.RLock()
+	defer fake.updateMutex.RUnlock()
+	fake.aggregateMutex.RLock()
+	defer fake.aggregateMutex.RUnlock()
+	fake.nextStatesMutex.RLock()
+	defer fake.nextStatesMutex.RUnlock()
+	fake.reorderMutex.RLock()
+	defer fake.reorderMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -2,12 +2,14 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"log"
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/jsonapi"
-	query "github.com/almighty/almighty-core/query/simple"
+	"github.com/almighty/almighty-core/query"
 	"github.com/almighty/almighty-core/remoteworkitem"
 	"github.com/goadesign/goa"
 	errs "github.com/pkg/errors"
@@ -28,7 +30,14 @@ func NewTrackerController(service *goa.Service, db application.DB, scheduler *re
 // Create runs the create action.
 func (c *TrackerController) Create(ctx *app.CreateTrackerContext) error {
 	result := application.Transactional(c.db, func(appl application.Application) error {
-		t, err := appl.Trackers().Create(ctx.Context, ctx.Payload.URL, ctx.Payload.Type)
+		var webhookSecret, pushToken string
+		if ctx.Payload.WebhookSecret != nil {
+			webhookSecret = *ctx.Payload.WebhookSecret
+		}
+		if ctx.Payload.PushToken != nil {
+			pushToken = *ctx.Payload.PushToken
+		}
+		t, err := appl.Trackers().Create(ctx.Context, ctx.Payload.URL, ctx.Payload.Type, webhookSecret, pushToken, ctx.Payload.FieldMappings)
 		if err != nil {
 			cause := errs.Cause(err)
 			switch cause.(type) {
@@ -116,11 +125,12 @@ func (c *TrackerController) Update(ctx *app.UpdateTrackerContext) error {
 	result := application.Transactional(c.db, func(appl application.Application) error {
 
 		toSave := app.Tracker{
-			ID:   ctx.ID,
-			URL:  ctx.Payload.URL,
-			Type: ctx.Payload.Type,
+			ID:            ctx.ID,
+			URL:           ctx.Payload.URL,
+			Type:          ctx.Payload.Type,
+			FieldMappings: ctx.Payload.FieldMappings,
 		}
-		t, err := appl.Trackers().Save(ctx.Context, toSave)
+		t, err := appl.Trackers().Save(ctx.Context, toSave, ctx.Payload.WebhookSecret, ctx.Payload.PushToken)
 
 		if err != nil {
 			cause := errs.Cause(err)
@@ -138,3 +148,56 @@ func (c *TrackerController) Update(ctx *app.UpdateTrackerContext) error {
 	c.scheduler.ScheduleAllQueries()
 	return result
 }
+
+// Push runs the push action.
+func (c *TrackerController) Push(ctx *app.PushTrackerContext) error {
+	if _, err := c.scheduler.Push(ctx.Context, ctx.ID, ctx.WorkItemID); err != nil {
+		cause := errs.Cause(err)
+		switch cause.(type) {
+		case remoteworkitem.BadParameterError, remoteworkitem.ConversionError:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
+			return ctx.BadRequest(jerrors)
+		case remoteworkitem.NotFoundError:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrNotFound(err.Error()))
+			return ctx.NotFound(jerrors)
+		case remoteworkitem.VersionConflictError:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewVersionConflictError(err.Error()))
+			return ctx.Conflict(jerrors)
+		default:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(err.Error()))
+			return ctx.InternalServerError(jerrors)
+		}
+	}
+	return ctx.OK([]byte{})
+}
+
+// Webhook runs the webhook action.
+func (c *TrackerController) Webhook(ctx *app.WebhookTrackerContext) error {
+	var body []byte
+	if ctx.Request.Body != nil {
+		b, err := ioutil.ReadAll(ctx.Request.Body)
+		if err != nil {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
+			return ctx.BadRequest(jerrors)
+		}
+		body = b
+	}
+	if _, err := c.scheduler.HandleWebhook(ctx.ID, ctx.Request.Header, ctx.Secret, body); err != nil {
+		cause := errs.Cause(err)
+		switch cause.(type) {
+		case remoteworkitem.BadParameterError, remoteworkitem.ConversionError:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
+			return ctx.BadRequest(jerrors)
+		case remoteworkitem.NotFoundError:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrNotFound(err.Error()))
+			return ctx.NotFound(jerrors)
+		case remoteworkitem.UnauthorizedError:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+			return ctx.Unauthorized(jerrors)
+		default:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(err.Error()))
+			return ctx.InternalServerError(jerrors)
+		}
+	}
+	return ctx.OK([]byte{})
+}
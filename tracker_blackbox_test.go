@@ -116,6 +116,22 @@ func getTrackerTestData(t *testing.T) []testSecureAPI {
 			payload:            createTrackerPayload,
 			jwtToken:           "",
 		},
+		// Push work item API
+		{
+			method:             http.MethodPost,
+			url:                "/api/trackers/12345/workitems/1/push",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedErrorCode:  jsonapi.ErrorCodeJWTSecurityError,
+			payload:            nil,
+			jwtToken:           getExpiredAuthHeader(t, privatekey),
+		}, {
+			method:             http.MethodPost,
+			url:                "/api/trackers/12345/workitems/1/push",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedErrorCode:  jsonapi.ErrorCodeJWTSecurityError,
+			payload:            nil,
+			jwtToken:           "",
+		},
 		// Try fetching a random tracker
 		// We do not have security on GET hence this should return 404 not found
 		{
@@ -100,3 +100,37 @@ func TestCreateTrackerValidId(t *testing.T) {
 	}
 	test.DeleteTrackerOK(t, nil, nil, &controller, tracker.ID)
 }
+
+func TestPushTrackerNotFound(t *testing.T) {
+	resource.Require(t, resource.Database)
+	controller := TrackerController{Controller: nil, db: gormapplication.NewGormDB(DB), scheduler: RwiScheduler}
+	payload := app.CreateTrackerAlternatePayload{
+		URL:  "http://issues.jboss.com",
+		Type: "jira",
+	}
+
+	_, result := test.CreateTrackerCreated(t, nil, nil, &controller, &payload)
+	test.PushTrackerNotFound(t, nil, nil, &controller, result.ID, "2398475203")
+	test.PushTrackerNotFound(t, nil, nil, &controller, "088481764871", "2398475203")
+}
+
+func TestWebhookTrackerUnauthorized(t *testing.T) {
+	resource.Require(t, resource.Database)
+	controller := TrackerController{Controller: nil, db: gormapplication.NewGormDB(DB), scheduler: RwiScheduler}
+	secret := "s3cr3t"
+	payload := app.CreateTrackerAlternatePayload{
+		URL:           "http://issues.jboss.com",
+		Type:          "jira",
+		WebhookSecret: &secret,
+	}
+
+	_, result := test.CreateTrackerCreated(t, nil, nil, &controller, &payload)
+	test.WebhookTrackerUnauthorized(t, nil, nil, &controller, result.ID, nil)
+	wrongSecret := "secret"
+	test.WebhookTrackerUnauthorized(t, nil, nil, &controller, result.ID, &wrongSecret)
+	// an empty secret disables webhooks
+	empty := ""
+	test.UpdateTrackerOK(t, nil, nil, &controller, result.ID, &app.UpdateTrackerAlternatePayload{URL: payload.URL, Type: payload.Type, WebhookSecret: &empty})
+	test.WebhookTrackerUnauthorized(t, nil, nil, &controller, result.ID, &empty)
+	test.WebhookTrackerNotFound(t, nil, nil, &controller, "088481764871", &secret)
+}
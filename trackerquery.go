@@ -127,3 +127,22 @@ func (c *TrackerqueryController) List(ctx *app.ListTrackerqueryContext) error {
 	})
 
 }
+
+// ListRuns runs the list-runs action.
+func (c *TrackerqueryController) ListRuns(ctx *app.ListRunsTrackerqueryContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		result, err := appl.TrackerQueries().ListRuns(ctx.Context, ctx.ID)
+		if err != nil {
+			cause := errs.Cause(err)
+			switch cause.(type) {
+			case remoteworkitem.NotFoundError:
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrNotFound(err.Error()))
+				return ctx.NotFound(jerrors)
+			default:
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(fmt.Sprintf("Error listing runs of tracker query: %s", err.Error())))
+				return ctx.InternalServerError(jerrors)
+			}
+		}
+		return ctx.OK(result)
+	})
+}
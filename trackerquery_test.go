@@ -164,3 +164,26 @@ func TestCreateTrackerQueryValidId(t *testing.T) {
 	}
 	test.DeleteTrackerqueryOK(t, nil, nil, &tqController, trackerquery.ID)
 }
+
+func TestListTrackerQueryRuns(t *testing.T) {
+	resource.Require(t, resource.Database)
+	controller := TrackerController{Controller: nil, db: gormapplication.NewGormDB(DB), scheduler: RwiScheduler}
+	payload := app.CreateTrackerAlternatePayload{
+		URL:  "http://api.github.com",
+		Type: "github",
+	}
+	_, result := test.CreateTrackerCreated(t, nil, nil, &controller, &payload)
+	tqController := TrackerqueryController{Controller: nil, db: gormapplication.NewGormDB(DB), scheduler: RwiScheduler}
+	tqpayload := app.CreateTrackerQueryAlternatePayload{
+		Query:     "is:open is:issue user:arquillian author:aslakknutsen",
+		Schedule:  "15 * * * * *",
+		TrackerID: result.ID,
+	}
+	_, trackerquery := test.CreateTrackerqueryCreated(t, nil, nil, &tqController, &tqpayload)
+	_, runs := test.ListRunsTrackerqueryOK(t, nil, nil, &tqController, trackerquery.ID)
+	if len(runs) != 0 {
+		t.Errorf("Expected no runs of the new tracker query, found %d", len(runs))
+	}
+	test.DeleteTrackerqueryOK(t, nil, nil, &tqController, trackerquery.ID)
+	test.ListRunsTrackerqueryNotFound(t, nil, nil, &tqController, trackerquery.ID)
+}
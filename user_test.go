@@ -12,7 +12,9 @@ import (
 	"github.com/almighty/almighty-core/area"
 	"github.com/almighty/almighty-core/comment"
 	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/label"
 	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/savedquery"
 	"github.com/almighty/almighty-core/space"
 	almtoken "github.com/almighty/almighty-core/token"
 	"github.com/almighty/almighty-core/workitem"
@@ -240,6 +242,21 @@ func (g *GormTestBase) Areas() area.Repository {
 	return nil
 }
 
+// Labels returns a label repository
+func (g *GormTestBase) Labels() label.Repository {
+	return nil
+}
+
+// Queries returns a saved query repository
+func (g *GormTestBase) Queries() savedquery.Repository {
+	return nil
+}
+
+// WorkItemRevisions returns a work item revision repository
+func (g *GormTestBase) WorkItemRevisions() workitem.RevisionRepository {
+	return nil
+}
+
 func (g *GormTestBase) DB() *gorm.DB {
 	return nil
 }
@@ -65,6 +65,10 @@ func (c *WorkItemCommentsController) Create(ctx *app.CreateWorkItemCommentsConte
 
 // List runs the list action.
 func (c *WorkItemCommentsController) List(ctx *app.ListWorkItemCommentsContext) error {
+	cursor, err := parseCursor(ctx.PageCursor)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
 	offset, limit := computePagingLimts(ctx.PageOffset, ctx.PageLimit)
 	return application.Transactional(c.db, func(appl application.Application) error {
 		_, err := appl.WorkItems().Load(ctx, ctx.ID)
@@ -75,15 +79,19 @@ func (c *WorkItemCommentsController) List(ctx *app.ListWorkItemCommentsContext)
 		res := &app.CommentList{}
 		res.Data = []*app.Comment{}
 
-		comments, tc, err := appl.Comments().List(ctx, ctx.ID, &offset, &limit)
+		comments, tc, cursors, err := appl.Comments().List(ctx, ctx.ID, &offset, &limit, cursor)
 		count := int(tc)
 		if err != nil {
-			return jsonapi.JSONErrorResponse(ctx, goa.ErrInternal(err.Error()))
+			return jsonapi.JSONErrorResponse(ctx, err)
 		}
 		res.Meta = &app.CommentListMeta{TotalCount: count}
 		res.Data = ConvertComments(ctx.RequestData, comments)
 		res.Links = &app.PagingLinks{}
-		setPagingLinks(res.Links, buildAbsoluteURL(ctx.RequestData), len(comments), offset, limit, count)
+		if cursor != nil {
+			setCursorPagingLinks(res.Links, buildAbsoluteURL(ctx.RequestData), limit, cursors)
+		} else {
+			setPagingLinks(res.Links, buildAbsoluteURL(ctx.RequestData), len(comments), offset, limit, count)
+		}
 
 		return ctx.OK(res)
 	})
@@ -99,7 +107,7 @@ func (c *WorkItemCommentsController) Relations(ctx *app.RelationsWorkItemComment
 			return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
 		}
 
-		comments, tc, err := appl.Comments().List(ctx, ctx.ID, &offset, &limit)
+		comments, tc, _, err := appl.Comments().List(ctx, ctx.ID, &offset, &limit, nil)
 		count := int(tc)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, goa.ErrInternal(err.Error()))
@@ -78,7 +78,7 @@ func (rest *TestCommentREST) createDefaultWorkItem() string {
 	err := application.Transactional(rest.db, func(appl application.Application) error {
 		repo := appl.WorkItems()
 		wi, err := repo.Create(
-			context.Background(),
+			context.Background(), nil,
 			workitem.SystemBug,
 			map[string]interface{}{
 				workitem.SystemTitle: "A",
@@ -150,14 +150,14 @@ func (rest *TestCommentREST) TestListCommentsByParentWorkItem() {
 	svc, ctrl := rest.UnSecuredController()
 	offset := "0"
 	limit := 3
-	_, cs := test.ListWorkItemCommentsOK(rest.T(), svc.Context, svc, ctrl, wiid, &limit, &offset)
+	_, cs := test.ListWorkItemCommentsOK(rest.T(), svc.Context, svc, ctrl, wiid, nil, &limit, &offset)
 	// then
 	require.Equal(rest.T(), 3, len(cs.Data))
 	rest.assertComment(cs.Data[0], "Test 3", rendering.SystemMarkupDefault) // items are returned in reverse order or creation
 	// given
 	wiid2 := rest.createDefaultWorkItem()
 	// when
-	_, cs2 := test.ListWorkItemCommentsOK(rest.T(), svc.Context, svc, ctrl, wiid2, &limit, &offset)
+	_, cs2 := test.ListWorkItemCommentsOK(rest.T(), svc.Context, svc, ctrl, wiid2, nil, &limit, &offset)
 	// then
 	assert.Equal(rest.T(), 0, len(cs2.Data))
 }
@@ -169,7 +169,7 @@ func (rest *TestCommentREST) TestEmptyListCommentsByParentWorkItem() {
 	svc, ctrl := rest.UnSecuredController()
 	offset := "0"
 	limit := 1
-	_, cs := test.ListWorkItemCommentsOK(rest.T(), svc.Context, svc, ctrl, wiid, &limit, &offset)
+	_, cs := test.ListWorkItemCommentsOK(rest.T(), svc.Context, svc, ctrl, wiid, nil, &limit, &offset)
 	// then
 	assert.Equal(rest.T(), 0, len(cs.Data))
 }
@@ -209,5 +209,5 @@ func (rest *TestCommentREST) TestListCommentsByMissingParentWorkItem() {
 	// when/then
 	offset := "0"
 	limit := 1
-	test.ListWorkItemCommentsNotFound(rest.T(), svc.Context, svc, ctrl, "0000000", &limit, &offset)
+	test.ListWorkItemCommentsNotFound(rest.T(), svc.Context, svc, ctrl, "0000000", nil, &limit, &offset)
 }
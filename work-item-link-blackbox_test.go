@@ -27,6 +27,7 @@ import (
 	"github.com/goadesign/goa"
 	"github.com/jinzhu/gorm"
 	satoriuuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -143,6 +144,12 @@ func (s *workItemLinkSuite) cleanup() {
 	// rather than ID, unlike the work items or work item links.
 	db = db.Unscoped().Delete(&link.WorkItemLinkType{Name: "test-bug-blocker"})
 	require.Nil(s.T(), db.Error)
+	db = db.Unscoped().Delete(&link.WorkItemLinkType{Name: "test-bug-parent"})
+	require.Nil(s.T(), db.Error)
+	db = db.Unscoped().Delete(&link.WorkItemLinkType{Name: "test-bug-dependency"})
+	require.Nil(s.T(), db.Error)
+	db = db.Unscoped().Delete(&link.WorkItemLinkType{Name: "test-bug-blocked-by"})
+	require.Nil(s.T(), db.Error)
 	db = db.Unscoped().Delete(&link.WorkItemLinkCategory{Name: "test-user"})
 	require.Nil(s.T(), db.Error)
 
@@ -431,6 +438,108 @@ func (s *workItemLinkSuite) TestCreateWorkItemRelationshipsLinksBadRequestDueToB
 	_, _ = test.CreateWorkItemRelationshipsLinksBadRequest(s.T(), nil, nil, s.workItemRelsLinksCtrl, strconv.FormatUint(s.bug1ID, 10), createPayload)
 }
 
+// createLinkTypeWithTopology creates a bug to bug link type with the given topology
+func (s *workItemLinkSuite) createLinkTypeWithTopology(name string, topology string) string {
+	createLinkTypePayload := CreateWorkItemLinkType(name, workitem.SystemBug, workitem.SystemBug, s.userLinkCategoryID)
+	createLinkTypePayload.Data.Attributes.Topology = &topology
+	_, workItemLinkType := test.CreateWorkItemLinkTypeCreated(s.T(), nil, nil, s.workItemLinkTypeCtrl, createLinkTypePayload)
+	require.NotNil(s.T(), workItemLinkType)
+	return *workItemLinkType.Data.ID
+}
+
+func (s *workItemLinkSuite) TestCreateWorkItemLinkBadRequestDueToTreeTopology() {
+	parentLinkTypeID := s.createLinkTypeWithTopology("test-bug-parent", link.TopologyTree)
+	test.CreateWorkItemLinkCreated(s.T(), nil, nil, s.workItemLinkCtrl, CreateWorkItemLink(s.bug1ID, s.bug2ID, parentLinkTypeID))
+	_, workItemLink := test.CreateWorkItemLinkCreated(s.T(), nil, nil, s.workItemLinkCtrl, CreateWorkItemLink(s.bug2ID, s.bug3ID, parentLinkTypeID))
+
+	// bug2 already has a parent
+	test.CreateWorkItemLinkBadRequest(s.T(), nil, nil, s.workItemLinkCtrl, CreateWorkItemLink(s.bug3ID, s.bug2ID, parentLinkTypeID))
+	// bug3 is a grand child of bug1
+	_, jerrors := test.CreateWorkItemLinkBadRequest(s.T(), nil, nil, s.workItemLinkCtrl, CreateWorkItemLink(s.bug3ID, s.bug1ID, parentLinkTypeID))
+	require.Len(s.T(), jerrors.Errors, 1)
+	cycle := fmt.Sprintf("%d -> %d -> %d -> %d", s.bug3ID, s.bug1ID, s.bug2ID, s.bug3ID)
+	assert.Contains(s.T(), jerrors.Errors[0].Detail, cycle)
+	// links of other types are not affected
+	test.CreateWorkItemLinkCreated(s.T(), nil, nil, s.workItemLinkCtrl, CreateWorkItemLink(s.bug3ID, s.bug1ID, s.bugBlockerLinkTypeID))
+
+	// moving bug3 to another parent is fine but a work item cannot be its own parent
+	updateLinkPayload := &app.UpdateWorkItemLinkPayload{Data: workItemLink.Data}
+	updateLinkPayload.Data.Relationships.Source.Data.ID = strconv.FormatUint(s.bug1ID, 10)
+	_, workItemLink = test.UpdateWorkItemLinkOK(s.T(), nil, nil, s.workItemLinkCtrl, *workItemLink.Data.ID, updateLinkPayload)
+	updateLinkPayload = &app.UpdateWorkItemLinkPayload{Data: workItemLink.Data}
+	updateLinkPayload.Data.Relationships.Source.Data.ID = strconv.FormatUint(s.bug3ID, 10)
+	test.UpdateWorkItemLinkBadRequest(s.T(), nil, nil, s.workItemLinkCtrl, *workItemLink.Data.ID, updateLinkPayload)
+}
+
+func (s *workItemLinkSuite) TestCreateWorkItemLinkBadRequestDueToDependencyCycle() {
+	dependencyLinkTypeID := s.createLinkTypeWithTopology("test-bug-dependency", link.TopologyDependency)
+	test.CreateWorkItemLinkCreated(s.T(), nil, nil, s.workItemLinkCtrl, CreateWorkItemLink(s.bug1ID, s.bug2ID, dependencyLinkTypeID))
+	test.CreateWorkItemLinkCreated(s.T(), nil, nil, s.workItemLinkCtrl, CreateWorkItemLink(s.bug2ID, s.bug3ID, dependencyLinkTypeID))
+	// a work item may depend on multiple others
+	test.CreateWorkItemLinkCreated(s.T(), nil, nil, s.workItemLinkCtrl, CreateWorkItemLink(s.bug1ID, s.bug3ID, dependencyLinkTypeID))
+
+	_, jerrors := test.CreateWorkItemLinkBadRequest(s.T(), nil, nil, s.workItemLinkCtrl, CreateWorkItemLink(s.bug3ID, s.bug1ID, dependencyLinkTypeID))
+	require.Len(s.T(), jerrors.Errors, 1)
+	// the shortest of both cycles is reported
+	assert.Contains(s.T(), jerrors.Errors[0].Detail, fmt.Sprintf("%d -> %d -> %d", s.bug3ID, s.bug1ID, s.bug3ID))
+	assert.NotContains(s.T(), jerrors.Errors[0].Detail, fmt.Sprintf("%d -> %d -> %d -> %d", s.bug3ID, s.bug1ID, s.bug2ID, s.bug3ID))
+	test.CreateWorkItemLinkBadRequest(s.T(), nil, nil, s.workItemLinkCtrl, CreateWorkItemLink(s.bug2ID, s.bug2ID, dependencyLinkTypeID))
+}
+
+func (s *workItemLinkSuite) TestCreateAndUpdateWorkItemLinkWithAttributes() {
+	required := true
+	createLinkTypePayload := CreateWorkItemLinkType("test-bug-blocked-by", workitem.SystemBug, workitem.SystemBug, s.userLinkCategoryID)
+	createLinkTypePayload.Data.Attributes.AttributesSchema = map[string]*app.WorkItemLinkAttributeDefinition{
+		"reason": {Kind: link.AttributeKindString, Required: &required},
+	}
+	_, workItemLinkType := test.CreateWorkItemLinkTypeCreated(s.T(), nil, nil, s.workItemLinkTypeCtrl, createLinkTypePayload)
+	require.Contains(s.T(), workItemLinkType.Data.Attributes.AttributesSchema, "reason")
+	linkTypeID := *workItemLinkType.Data.ID
+
+	// the reason is required
+	test.CreateWorkItemLinkBadRequest(s.T(), nil, nil, s.workItemLinkCtrl, CreateWorkItemLink(s.bug1ID, s.bug2ID, linkTypeID))
+	createPayload := CreateWorkItemLink(s.bug1ID, s.bug2ID, linkTypeID)
+	createPayload.Data.Attributes.Attributes = map[string]interface{}{"reason": "waiting for the database upgrade"}
+	_, workItemLink := test.CreateWorkItemLinkCreated(s.T(), nil, nil, s.workItemLinkCtrl, createPayload)
+	assert.Equal(s.T(), "waiting for the database upgrade", workItemLink.Data.Attributes.Attributes["reason"])
+
+	updateLinkPayload := &app.UpdateWorkItemLinkPayload{Data: workItemLink.Data}
+	updateLinkPayload.Data.Attributes.Attributes = map[string]interface{}{"reason": 42}
+	test.UpdateWorkItemLinkBadRequest(s.T(), nil, nil, s.workItemLinkCtrl, *workItemLink.Data.ID, updateLinkPayload)
+	updateLinkPayload.Data.Attributes.Attributes = map[string]interface{}{"reason": "waiting for review"}
+	_, workItemLink = test.UpdateWorkItemLinkOK(s.T(), nil, nil, s.workItemLinkCtrl, *workItemLink.Data.ID, updateLinkPayload)
+	assert.Equal(s.T(), "waiting for review", workItemLink.Data.Attributes.Attributes["reason"])
+
+	// links of types without a schema may have any attributes
+	createPayload = CreateWorkItemLink(s.bug1ID, s.bug2ID, s.bugBlockerLinkTypeID)
+	createPayload.Data.Attributes.Attributes = map[string]interface{}{"note": "anything", "weight": 3}
+	test.CreateWorkItemLinkCreated(s.T(), nil, nil, s.workItemLinkCtrl, createPayload)
+}
+
+func (s *workItemLinkSuite) TestReorderWorkItemLinks() {
+	parentLinkTypeID := s.createLinkTypeWithTopology("test-bug-parent", link.TopologyTree)
+	_, link1 := test.CreateWorkItemLinkCreated(s.T(), nil, nil, s.workItemLinkCtrl, CreateWorkItemLink(s.bug1ID, s.bug2ID, parentLinkTypeID))
+	_, link2 := test.CreateWorkItemLinkCreated(s.T(), nil, nil, s.workItemLinkCtrl, CreateWorkItemLink(s.bug1ID, s.bug3ID, parentLinkTypeID))
+	_, other := test.CreateWorkItemLinkCreated(s.T(), nil, nil, s.workItemLinkCtrl, CreateWorkItemLink(s.bug1ID, s.bug3ID, s.bugBlockerLinkTypeID))
+	assert.Equal(s.T(), 0, *link1.Data.Attributes.Position)
+	assert.Equal(s.T(), 1, *link2.Data.Attributes.Position)
+	assert.Equal(s.T(), 0, *other.Data.Attributes.Position)
+
+	_, moved := test.ReorderWorkItemLinkOK(s.T(), nil, nil, s.workItemLinkCtrl, *link2.Data.ID, &app.ReorderWorkItemLinkPayload{Before: link1.Data.ID})
+	assert.Equal(s.T(), 0, *moved.Data.Attributes.Position)
+	_, reloaded := test.ShowWorkItemLinkOK(s.T(), nil, nil, s.workItemLinkCtrl, *link1.Data.ID)
+	assert.Equal(s.T(), 1, *reloaded.Data.Attributes.Position)
+
+	_, moved = test.ReorderWorkItemLinkOK(s.T(), nil, nil, s.workItemLinkCtrl, *link2.Data.ID, &app.ReorderWorkItemLinkPayload{After: link1.Data.ID})
+	assert.Equal(s.T(), 1, *moved.Data.Attributes.Position)
+
+	// siblings must have the same source and link type
+	test.ReorderWorkItemLinkBadRequest(s.T(), nil, nil, s.workItemLinkCtrl, *link2.Data.ID, &app.ReorderWorkItemLinkPayload{Before: other.Data.ID})
+	test.ReorderWorkItemLinkBadRequest(s.T(), nil, nil, s.workItemLinkCtrl, *link2.Data.ID, &app.ReorderWorkItemLinkPayload{Before: link2.Data.ID})
+	test.ReorderWorkItemLinkBadRequest(s.T(), nil, nil, s.workItemLinkCtrl, *link2.Data.ID, &app.ReorderWorkItemLinkPayload{})
+	test.ReorderWorkItemLinkNotFound(s.T(), nil, nil, s.workItemLinkCtrl, "88727441-4a21-4b35-aabe-007f8273cd19", &app.ReorderWorkItemLinkPayload{Before: link1.Data.ID})
+}
+
 func (s *workItemLinkSuite) TestDeleteWorkItemLinkNotFound() {
 	test.DeleteWorkItemLinkNotFound(s.T(), nil, nil, s.workItemLinkCtrl, "1e9a8b53-73a6-40de-b028-5177add79ffa")
 }
@@ -619,6 +728,56 @@ func (s *workItemLinkSuite) TestListWorkItemRelationshipsLinksNotFoundDueToInval
 	_, _ = test.ListWorkItemRelationshipsLinksNotFound(s.T(), nil, nil, s.workItemRelsLinksCtrl, filterByWorkItemID)
 }
 
+// Traverse /api/workitems/:id/relationships/links/traverse
+func (s *workItemLinkSuite) TestTraverseWorkItemRelationshipsLinksOK() {
+	test.CreateWorkItemLinkCreated(s.T(), nil, nil, s.workItemLinkCtrl, CreateWorkItemLink(s.bug1ID, s.bug2ID, s.bugBlockerLinkTypeID))
+	test.CreateWorkItemLinkCreated(s.T(), nil, nil, s.workItemLinkCtrl, CreateWorkItemLink(s.bug2ID, s.bug3ID, s.bugBlockerLinkTypeID))
+	linkTypeID := satoriuuid.FromStringOrNil(s.bugBlockerLinkTypeID)
+	bug1 := strconv.FormatUint(s.bug1ID, 10)
+	bug2 := strconv.FormatUint(s.bug2ID, 10)
+	bug3 := strconv.FormatUint(s.bug3ID, 10)
+
+	_, reached := test.TraverseWorkItemRelationshipsLinksOK(s.T(), nil, nil, s.workItemRelsLinksCtrl, bug1, nil, nil, linkTypeID)
+	require.Len(s.T(), reached.Data, 2)
+	assert.Equal(s.T(), bug2, reached.Data[0].ID)
+	assert.Equal(s.T(), 1, reached.Data[0].Meta.Depth)
+	assert.Equal(s.T(), bug3, reached.Data[1].ID)
+	assert.Equal(s.T(), 2, reached.Data[1].Meta.Depth)
+	assert.Equal(s.T(), []string{bug1, bug2, bug3}, reached.Data[1].Meta.Path)
+	assert.Len(s.T(), reached.Included, 2)
+	assert.Equal(s.T(), 2, reached.Meta.TotalCount)
+
+	depth := 1
+	_, reached = test.TraverseWorkItemRelationshipsLinksOK(s.T(), nil, nil, s.workItemRelsLinksCtrl, bug1, &depth, nil, linkTypeID)
+	require.Len(s.T(), reached.Data, 1)
+	assert.Equal(s.T(), bug2, reached.Data[0].ID)
+
+	reverse := link.TraverseReverse
+	_, reached = test.TraverseWorkItemRelationshipsLinksOK(s.T(), nil, nil, s.workItemRelsLinksCtrl, bug3, nil, &reverse, linkTypeID)
+	require.Len(s.T(), reached.Data, 2)
+	assert.Equal(s.T(), []string{bug3, bug2, bug1}, reached.Data[1].Meta.Path)
+
+	_, reached = test.TraverseWorkItemRelationshipsLinksOK(s.T(), nil, nil, s.workItemRelsLinksCtrl, bug3, nil, nil, linkTypeID)
+	assert.Len(s.T(), reached.Data, 0)
+
+	// cyclic links end at the start work item and only the shortest paths are returned
+	test.CreateWorkItemLinkCreated(s.T(), nil, nil, s.workItemLinkCtrl, CreateWorkItemLink(s.bug3ID, s.bug1ID, s.bugBlockerLinkTypeID))
+	test.CreateWorkItemLinkCreated(s.T(), nil, nil, s.workItemLinkCtrl, CreateWorkItemLink(s.bug1ID, s.bug3ID, s.bugBlockerLinkTypeID))
+	_, reached = test.TraverseWorkItemRelationshipsLinksOK(s.T(), nil, nil, s.workItemRelsLinksCtrl, bug1, nil, nil, linkTypeID)
+	require.Len(s.T(), reached.Data, 3)
+	assert.Equal(s.T(), []string{bug1, bug2}, reached.Data[0].Meta.Path)
+	assert.Equal(s.T(), []string{bug1, bug3}, reached.Data[1].Meta.Path)
+	assert.Equal(s.T(), bug1, reached.Data[2].ID)
+	assert.Equal(s.T(), 2, reached.Data[2].Meta.Depth)
+	assert.Equal(s.T(), []string{bug1, bug3, bug1}, reached.Data[2].Meta.Path)
+}
+
+func (s *workItemLinkSuite) TestTraverseWorkItemRelationshipsLinksFailures() {
+	notExistingWorkItemID := strconv.FormatUint(math.MaxUint32, 10)
+	test.TraverseWorkItemRelationshipsLinksNotFound(s.T(), nil, nil, s.workItemRelsLinksCtrl, notExistingWorkItemID, nil, nil, satoriuuid.FromStringOrNil(s.bugBlockerLinkTypeID))
+	test.TraverseWorkItemRelationshipsLinksBadRequest(s.T(), nil, nil, s.workItemRelsLinksCtrl, strconv.FormatUint(s.bug1ID, 10), nil, nil, satoriuuid.NewV4())
+}
+
 func getWorkItemLinkTestData(t *testing.T) []testSecureAPI {
 	privatekey, err := jwt.ParseRSAPrivateKeyFromPEM((configuration.GetTokenPrivateKey()))
 	if err != nil {
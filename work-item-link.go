@@ -242,7 +242,7 @@ func createWorkItemLink(ctx *workItemLinkContext, funcs createWorkItemLinkFuncs,
 		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(err)
 		return funcs.BadRequest(jerrors)
 	}
-	link, err := ctx.Application.WorkItemLinks().Create(ctx.Context, model.SourceID, model.TargetID, model.LinkTypeID)
+	link, err := ctx.Application.WorkItemLinks().Create(ctx.Context, model.SourceID, model.TargetID, model.LinkTypeID, model.Attributes)
 	if err != nil {
 		cause := errs.Cause(err)
 		switch cause.(type) {
@@ -372,3 +372,28 @@ func (c *WorkItemLinkController) Update(ctx *app.UpdateWorkItemLinkContext) erro
 		return updateWorkItemLink(newWorkItemLinkContext(ctx.Context, appl, c.db, ctx.RequestData, ctx.ResponseData, app.WorkItemLinkHref), ctx, ctx.Payload)
 	})
 }
+
+// Reorder runs the reorder action.
+func (c *WorkItemLinkController) Reorder(ctx *app.ReorderWorkItemLinkContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		if (ctx.Payload.Before == nil) == (ctx.Payload.After == nil) {
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(errors.NewBadParameterError("before|after", nil).Expected("the ID of exactly one sibling link"))
+			return ctx.BadRequest(jerrors)
+		}
+		direction, siblingID := link.ReorderBefore, ctx.Payload.Before
+		if ctx.Payload.After != nil {
+			direction, siblingID = link.ReorderAfter, ctx.Payload.After
+		}
+		l, err := appl.WorkItemLinks().Reorder(ctx.Context, ctx.LinkID, direction, *siblingID)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		linkCtx := newWorkItemLinkContext(ctx.Context, appl, c.db, ctx.RequestData, ctx.ResponseData, app.WorkItemLinkHref)
+		if err := enrichLinkSingle(linkCtx, l); err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		return ctx.OK(l)
+	})
+}
@@ -6,7 +6,9 @@ import (
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/rest"
 	"github.com/almighty/almighty-core/workitem/link"
 	"github.com/goadesign/goa"
 )
@@ -77,6 +79,65 @@ func (c *WorkItemRelationshipsLinksController) List(ctx *app.ListWorkItemRelatio
 	})
 }
 
+// defaultTraversalDepth is the number of links followed by a traversal if no depth is given
+const defaultTraversalDepth = 10
+
+// Traverse runs the traverse action.
+func (c *WorkItemRelationshipsLinksController) Traverse(ctx *app.TraverseWorkItemRelationshipsLinksContext) error {
+	direction := link.TraverseForward
+	if ctx.Direction != nil {
+		direction = *ctx.Direction
+	}
+	depth := defaultTraversalDepth
+	if ctx.Depth != nil {
+		depth = *ctx.Depth
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		reached, err := appl.WorkItemLinks().Traverse(ctx.Context, ctx.ID, ctx.Type, direction, depth)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		res := &app.WorkItemTraversalList{
+			Data:     make([]*app.WorkItemTraversalData, len(reached)),
+			Included: []interface{}{},
+			Meta:     &app.WorkItemLinkListMeta{TotalCount: len(reached)},
+		}
+		if len(reached) == 0 {
+			return ctx.OK(res)
+		}
+		ids := make([]uint64, len(reached))
+		for i, r := range reached {
+			ids[i] = r.WorkItemID
+			id := strconv.FormatUint(r.WorkItemID, 10)
+			selfURL := rest.AbsoluteURL(ctx.RequestData, app.WorkitemHref(id))
+			path := make([]string, len(r.Path))
+			for j, wiID := range r.Path {
+				path[j] = strconv.FormatUint(wiID, 10)
+			}
+			res.Data[i] = &app.WorkItemTraversalData{
+				Type:  link.EndpointWorkItems,
+				ID:    id,
+				Links: &app.GenericLinks{Self: &selfURL},
+				Meta: &app.WorkItemTraversalMeta{
+					Depth: r.Depth,
+					Path:  path,
+				},
+			}
+		}
+		// include the reached work items, all of them are loaded with a single query
+		wis, _, _, err := appl.WorkItems().List(ctx.Context, criteria.In(criteria.Field("ID"), criteria.Literal(ids)), nil, nil, nil, nil)
+		if err != nil {
+			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
+			return ctx.ResponseData.Service.Send(ctx.Context, httpStatusCode, jerrors)
+		}
+		for _, wi := range ConvertWorkItems(ctx.RequestData, wis) {
+			res.Included = append(res.Included, wi)
+		}
+		return ctx.OK(res)
+	})
+}
+
 func getSrcTgt(wilData *app.WorkItemLinkData) (*string, *string) {
 	var src, tgt *string
 	if wilData != nil && wilData.Relationships != nil {
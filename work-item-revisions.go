@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/rest"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeRevisions is the JSONAPI type of work item revisions
+const APIStringTypeRevisions = "revisions"
+
+// WorkItemRevisionsController implements the work-item-revisions resource.
+type WorkItemRevisionsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemRevisionsController creates a work-item-revisions controller.
+func NewWorkItemRevisionsController(service *goa.Service, db application.DB) *WorkItemRevisionsController {
+	return &WorkItemRevisionsController{Controller: service.NewController("WorkItemRevisionsController"), db: db}
+}
+
+// List runs the list action.
+func (c *WorkItemRevisionsController) List(ctx *app.ListWorkItemRevisionsContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		revisions, err := appl.WorkItemRevisions().List(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if len(revisions) == 0 {
+			// work items created before revisions were recorded have none,
+			// all other work items without revisions do not exist
+			_, err = appl.WorkItems().Load(ctx, ctx.ID)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
+		res := &app.WorkItemRevisionList{}
+		res.Data = ConvertRevisions(ctx.RequestData, revisions)
+		return ctx.OK(res)
+	})
+}
+
+// ConvertRevisions converts between internal and external REST representation
+func ConvertRevisions(request *goa.RequestData, revisions []*workitem.Revision) []*app.WorkItemRevision {
+	var rs = []*app.WorkItemRevision{}
+	for _, r := range revisions {
+		rs = append(rs, ConvertRevision(request, r))
+	}
+	return rs
+}
+
+// ConvertRevision converts between internal and external REST representation
+func ConvertRevision(request *goa.RequestData, r *workitem.Revision) *app.WorkItemRevision {
+	workItemType := APIStringTypeWorkItem
+	workItemID := strconv.FormatUint(r.WorkItemID, 10)
+	workItemSelfURL := rest.AbsoluteURL(request, app.WorkitemHref(workItemID))
+	revisionType := r.Type.String()
+
+	revision := &app.WorkItemRevision{
+		Type: APIStringTypeRevisions,
+		ID:   &r.ID,
+		Attributes: &app.WorkItemRevisionAttributes{
+			RevisionTime: &r.Time,
+			RevisionType: &revisionType,
+			Version:      &r.WorkItemVersion,
+			OldFields:    r.OldFields,
+			Fields:       r.Fields,
+		},
+		Relationships: &app.WorkItemRevisionRelations{
+			Workitem: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: &workItemType,
+					ID:   &workItemID,
+				},
+				Links: &app.GenericLinks{
+					Self: &workItemSelfURL,
+				},
+			},
+		},
+	}
+	if !uuid.Equal(r.ModifierIdentity, uuid.Nil) {
+		revision.Relationships.Modifier = &app.RelationGeneric{
+			Data: ConvertUserSimple(request, r.ModifierIdentity.String()),
+		}
+	}
+	return revision
+}
@@ -9,26 +9,30 @@ import (
 
 	"reflect"
 
+	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
 	"github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/iteration"
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/login"
-	query "github.com/almighty/almighty-core/query/simple"
+	"github.com/almighty/almighty-core/query"
 	"github.com/almighty/almighty-core/rendering"
 	"github.com/almighty/almighty-core/rest"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
 	errs "github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 )
 
 // Defines the constants to be used in json api "type" attribute
 const (
-	APIStringTypeUser         = "identities"
-	APIStringTypeWorkItem     = "workitems"
-	APIStringTypeWorkItemType = "workitemtypes"
+	APIStringTypeUser          = "identities"
+	APIStringTypeWorkItem      = "workitems"
+	APIStringTypeWorkItemType  = "workitemtypes"
+	APIStringTypeWorkItemState = "workitemstates"
 )
 
 // WorkitemController implements the workitem resource.
@@ -49,24 +53,24 @@ func NewWorkitemController(service *goa.Service, db application.DB) *WorkitemCon
 // Prev and Next links will be present only when there actually IS a next or previous page.
 // Last will always be present. Total Item count needs to be computed from the "Last" link.
 func (c *WorkitemController) List(ctx *app.ListWorkitemContext) error {
-	var additionalQuery []string
-	exp, err := query.Parse(ctx.Filter)
+	exp, additionalQuery, err := parseWorkItemFilter(ctx.Filter, ctx.FilterAssignee, ctx.FilterIteration)
 	if err != nil {
-		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("could not parse filter", err))
+		return jsonapi.JSONErrorResponse(ctx, err)
 	}
-	if ctx.FilterAssignee != nil {
-		assignee := ctx.FilterAssignee
-		exp = criteria.And(exp, criteria.Equals(criteria.Field("system.assignees"), criteria.Literal([]string{*assignee})))
-		additionalQuery = append(additionalQuery, "filter[assignee]="+*assignee)
+	orderBy, err := query.ParseSort(ctx.Sort)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("could not parse sort", err))
 	}
-	if ctx.FilterIteration != nil {
-		iteration := ctx.FilterIteration
-		exp = criteria.And(exp, criteria.Equals(criteria.Field(workitem.SystemIteration), criteria.Literal(string(*iteration))))
-		additionalQuery = append(additionalQuery, "filter[iteration]="+*iteration)
+	if ctx.Sort != nil && len(orderBy) > 0 {
+		additionalQuery = append(additionalQuery, "sort="+*ctx.Sort)
+	}
+	cursor, err := parseCursor(ctx.PageCursor)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
 	}
 	offset, limit := computePagingLimts(ctx.PageOffset, ctx.PageLimit)
 	return application.Transactional(c.db, func(tx application.Application) error {
-		result, tc, err := tx.WorkItems().List(ctx.Context, exp, &offset, &limit)
+		result, tc, cursors, err := tx.WorkItems().List(ctx.Context, exp, orderBy, &offset, &limit, cursor)
 		count := int(tc)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, "Error listing work items"))
@@ -76,44 +80,276 @@ func (c *WorkitemController) List(ctx *app.ListWorkitemContext) error {
 			Meta:  &app.WorkItemListResponseMeta{TotalCount: count},
 			Data:  ConvertWorkItems(ctx.RequestData, result),
 		}
-		setPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), len(result), offset, limit, count, additionalQuery...)
+		if cursor != nil {
+			setCursorPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), limit, cursors, additionalQuery...)
+		} else {
+			setPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), len(result), offset, limit, count, additionalQuery...)
+		}
 		return ctx.OK(&response)
 	})
 }
 
-// Update does PATCH workitem
+// Aggregate runs the aggregate action, it counts the work items matching the
+// filter grouped by the values of a field.
+func (c *WorkitemController) Aggregate(ctx *app.AggregateWorkitemContext) error {
+	exp, _, err := parseWorkItemFilter(ctx.Filter, ctx.FilterAssignee, ctx.FilterIteration)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return application.Transactional(c.db, func(tx application.Application) error {
+		buckets, total, err := tx.WorkItems().Aggregate(ctx.Context, exp, ctx.GroupBy)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, "Error aggregating work items"))
+		}
+		meta := &app.WorkItemAggregateMeta{
+			GroupBy:    ctx.GroupBy,
+			TotalCount: int(total),
+			Buckets:    []*app.WorkItemBucket{},
+		}
+		for _, b := range buckets {
+			meta.Buckets = append(meta.Buckets, &app.WorkItemBucket{Value: b.Value, Count: int(b.Count)})
+		}
+		return ctx.OK(&app.WorkItemAggregate{Meta: meta})
+	})
+}
+
+// parseWorkItemFilter combines the filter expression with the assignee and
+// iteration shortcuts. It also returns the query parameters to repeat in links.
+func parseWorkItemFilter(filter, assignee, iteration *string) (criteria.Expression, []string, error) {
+	var additionalQuery []string
+	exp, err := query.Parse(filter)
+	if err != nil {
+		return nil, nil, errors.NewBadParameterError("could not parse filter", err)
+	}
+	if assignee != nil {
+		exp = criteria.And(exp, criteria.Equals(criteria.Field("system.assignees"), criteria.Literal([]string{*assignee})))
+		additionalQuery = append(additionalQuery, "filter[assignee]="+*assignee)
+	}
+	if iteration != nil {
+		exp = criteria.And(exp, criteria.Equals(criteria.Field(workitem.SystemIteration), criteria.Literal(string(*iteration))))
+		additionalQuery = append(additionalQuery, "filter[iteration]="+*iteration)
+	}
+	return exp, additionalQuery, nil
+}
+
+// Update does PATCH workitem, only the supplied attributes and relationships are changed
 func (c *WorkitemController) Update(ctx *app.UpdateWorkitemContext) error {
+	currentUser, err := contextIdentityID(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
 	return application.Transactional(c.db, func(appl application.Application) error {
 		if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Data.ID == nil {
 			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("missing data.ID element in request", nil))
 		}
-		wi, err := appl.WorkItems().Load(ctx, *ctx.Payload.Data.ID)
+		// Type changes of WI are not allowed which is why the type of the
+		// converted changes is ignored.
+		changes := app.WorkItem{Fields: map[string]interface{}{}}
+		err := ConvertJSONAPIToWorkItem(appl, *ctx.Payload.Data, &changes)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		wi, err := appl.WorkItems().Update(ctx, *ctx.Payload.Data.ID, changes.Version, changes.Fields, currentUser)
 		if err != nil {
-			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, fmt.Sprintf("Failed to load work item with id %v", *ctx.Payload.Data.ID)))
+			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, "Error updating work item"))
 		}
-		// Type changes of WI are not allowed which is why we overwrite it the
-		// type with the old one after the WI has been converted.
-		oldType := wi.Type
-		err = ConvertJSONAPIToWorkItem(appl, *ctx.Payload.Data, wi)
+		resp, err := convertWorkItemSingle(ctx, appl, ctx.RequestData, wi)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, err)
 		}
-		wi.Type = oldType
-		wi, err = appl.WorkItems().Save(ctx, *wi)
+		resp.Links = &app.WorkItemLinks{
+			Self: buildAbsoluteURL(ctx.RequestData),
+		}
+		return ctx.OK(resp)
+	})
+}
+
+// Patch applies a JSON Patch to the attributes of a work item
+func (c *WorkitemController) Patch(ctx *app.PatchWorkitemContext) error {
+	currentUser, err := contextIdentityID(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	var ops []workitem.PatchOperation
+	for _, op := range ctx.Payload {
+		ops = append(ops, workitem.PatchOperation{Op: op.Op, Path: op.Path, Value: op.Value})
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		wi, err := appl.WorkItems().Load(ctx, ctx.ID)
 		if err != nil {
-			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, "Error updating work item"))
+			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, fmt.Sprintf("Failed to load work item with id %v", ctx.ID)))
 		}
-		wi2 := ConvertWorkItem(ctx.RequestData, wi)
-		resp := &app.WorkItem2Single{
-			Data: wi2,
-			Links: &app.WorkItemLinks{
-				Self: buildAbsoluteURL(ctx.RequestData),
-			},
+		document := map[string]interface{}{"version": wi.Version}
+		for name, value := range wi.Fields {
+			document[name] = value
+		}
+		changes, err := workitem.ApplyPatch(document, ops)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if _, ok := changes["version"]; ok {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("path", "/version").Expected("only test operations on the version"))
+		}
+		// the loaded version guards against changes made since the work item was loaded
+		wi, err = appl.WorkItems().Update(ctx, wi.ID, wi.Version, changes, currentUser)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, "Error patching work item"))
+		}
+		resp, err := convertWorkItemSingle(ctx, appl, ctx.RequestData, wi)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		resp.Links = &app.WorkItemLinks{
+			Self: buildAbsoluteURL(ctx.RequestData),
 		}
 		return ctx.OK(resp)
 	})
 }
 
+// bulkSizeMax is the maximum number of work items changed by one bulk request
+const bulkSizeMax = 500
+
+// Outcomes of a bulk operation for a single work item
+const (
+	bulkStatusUpdated      = "updated"
+	bulkStatusDeleted      = "deleted"
+	bulkStatusConflict     = "conflict"
+	bulkStatusNotFound     = "not_found"
+	bulkStatusBadParameter = "bad_parameter"
+)
+
+// Bulk changes or deletes a set of work items in one transaction
+func (c *WorkitemController) Bulk(ctx *app.BulkWorkitemContext) error {
+	currentUser, err := contextIdentityID(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	payload := ctx.Payload
+	if (payload.Workitems == nil) == (payload.Filter == nil) {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("workitems", nil).Expected("either workitems or filter"))
+	}
+	if len(payload.Workitems) > bulkSizeMax {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("workitems", len(payload.Workitems)).Expected(fmt.Sprintf("at most %d work items", bulkSizeMax)))
+	}
+	del := payload.Delete != nil && *payload.Delete
+	if del == (len(payload.Attributes) > 0) {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("attributes", payload.Attributes).Expected("either attributes or delete"))
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		changes := app.WorkItem{Fields: map[string]interface{}{}}
+		if !del {
+			err := ConvertJSONAPIToWorkItem(appl, app.WorkItem2{Attributes: payload.Attributes}, &changes)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			delete(changes.Fields, "version")
+		}
+		targets := payload.Workitems
+		if payload.Filter != nil {
+			exp, err := query.Parse(payload.Filter)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("could not parse filter", err))
+			}
+			start, limit := 0, bulkSizeMax+1
+			result, _, _, err := appl.WorkItems().List(ctx, exp, nil, &start, &limit, nil)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, "Error listing work items"))
+			}
+			if len(result) > bulkSizeMax {
+				return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("filter", *payload.Filter).Expected(fmt.Sprintf("at most %d work items", bulkSizeMax)))
+			}
+			// the client doesn't know the selected work items, so they are changed in their
+			// current version and earlier changes are overwritten. The versions still guard
+			// against the work items being changed by others during this transaction.
+			for _, wi := range result {
+				targets = append(targets, &app.WorkItemVersion{ID: wi.ID, Version: wi.Version})
+			}
+		}
+
+		meta := &app.WorkItemBulkMeta{Results: []*app.WorkItemBulkItemResult{}}
+		for _, target := range targets {
+			result, err := applyBulkChange(ctx, appl, target, changes.Fields, del, currentUser)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, fmt.Sprintf("Error changing work item with id %v", target.ID)))
+			}
+			if result.Status == bulkStatusUpdated || result.Status == bulkStatusDeleted {
+				meta.Succeeded++
+			} else {
+				meta.Failed++
+			}
+			meta.Results = append(meta.Results, result)
+		}
+		return ctx.OK(&app.WorkItemBulkResult{Meta: meta})
+	})
+}
+
+// applyBulkChange updates or deletes a single work item of a bulk request. Errors that only
+// concern the work item are reported in the result, all other errors are returned.
+func applyBulkChange(ctx context.Context, appl application.Application, target *app.WorkItemVersion, fields map[string]interface{}, del bool, modifierID uuid.UUID) (*app.WorkItemBulkItemResult, error) {
+	result := &app.WorkItemBulkItemResult{ID: target.ID}
+	var err error
+	if del {
+		var wi *app.WorkItem
+		wi, err = appl.WorkItems().Load(ctx, target.ID)
+		if err == nil && wi.Version != target.Version {
+			err = errors.NewVersionConflictError("version conflict")
+		}
+		if err == nil {
+			err = appl.WorkItems().Delete(ctx, target.ID, modifierID)
+		}
+		result.Status = bulkStatusDeleted
+	} else {
+		var wi *app.WorkItem
+		wi, err = appl.WorkItems().Update(ctx, target.ID, target.Version, fields, modifierID)
+		if err == nil {
+			result.Version = &wi.Version
+		}
+		result.Status = bulkStatusUpdated
+	}
+	if err == nil {
+		return result, nil
+	}
+	switch errs.Cause(err).(type) {
+	case errors.VersionConflictError:
+		result.Status = bulkStatusConflict
+	case errors.NotFoundError:
+		result.Status = bulkStatusNotFound
+	case errors.BadParameterError:
+		result.Status = bulkStatusBadParameter
+	default:
+		return nil, err
+	}
+	detail := errs.Cause(err).Error()
+	result.Detail = &detail
+	return result, nil
+}
+
+// referenceKind returns the kind of the entities referenced by a field of kind user,
+// iteration or workitem or by a list of them, "" for all other fields
+func referenceKind(def *app.FieldDefinition) workitem.Kind {
+	if def == nil || def.Type == nil {
+		return ""
+	}
+	kind := workitem.Kind(def.Type.Kind)
+	if kind == workitem.KindList && def.Type.ComponentType != nil {
+		kind = workitem.Kind(*def.Type.ComponentType)
+	}
+	switch kind {
+	case workitem.KindUser, workitem.KindIteration, workitem.KindWorkitemReference:
+		return kind
+	}
+	return ""
+}
+
+// referenceIDs returns the referenced IDs of a stored field value
+func referenceIDs(value interface{}) []string {
+	var result []string
+	for _, ref := range workitem.ReferenceValues(value) {
+		result = append(result, fmt.Sprint(ref))
+	}
+	return result
+}
+
 // Create does POST workitem
 func (c *WorkitemController) Create(ctx *app.CreateWorkitemContext) error {
 	currentUser, err := login.ContextIdentity(ctx)
@@ -129,6 +365,14 @@ func (c *WorkitemController) Create(ctx *app.CreateWorkitemContext) error {
 	if wit == nil { // TODO Figure out path source etc. Should be a required relation
 		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("Data.Relationships.BaseType.Data.ID", err))
 	}
+	var spaceID *uuid.UUID
+	if ctx.Payload.Data.Relationships.Space != nil && ctx.Payload.Data.Relationships.Space.Data != nil && ctx.Payload.Data.Relationships.Space.Data.ID != nil {
+		id, err := uuid.FromString(*ctx.Payload.Data.Relationships.Space.Data.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.relationships.space.data.id", *ctx.Payload.Data.Relationships.Space.Data.ID))
+		}
+		spaceID = &id
+	}
 	wi := app.WorkItem{
 		Fields: make(map[string]interface{}),
 	}
@@ -137,19 +381,18 @@ func (c *WorkitemController) Create(ctx *app.CreateWorkitemContext) error {
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, fmt.Sprintf("Error creating work item")))
 		}
-
-		wi, err := appl.WorkItems().Create(ctx, *wit, wi.Fields, currentUser)
+		wi, err := appl.WorkItems().Create(ctx, spaceID, *wit, wi.Fields, currentUser)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, fmt.Sprintf("Error creating work item")))
 		}
-		wi2 := ConvertWorkItem(ctx.RequestData, wi)
-		resp := &app.WorkItem2Single{
-			Data: wi2,
-			Links: &app.WorkItemLinks{
-				Self: buildAbsoluteURL(ctx.RequestData),
-			},
+		resp, err := convertWorkItemSingle(ctx, appl, ctx.RequestData, wi)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		resp.Links = &app.WorkItemLinks{
+			Self: buildAbsoluteURL(ctx.RequestData),
 		}
-		ctx.ResponseData.Header().Set("Location", app.WorkitemHref(wi2.ID))
+		ctx.ResponseData.Header().Set("Location", app.WorkitemHref(resp.Data.ID))
 		return ctx.Created(resp)
 	})
 }
@@ -162,18 +405,80 @@ func (c *WorkitemController) Show(ctx *app.ShowWorkitemContext) error {
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, fmt.Sprintf("Fail to load work item with id %v", ctx.ID)))
 		}
-		wi2 := ConvertWorkItem(ctx.RequestData, wi, comments)
-		resp := &app.WorkItem2Single{
-			Data: wi2,
+		resp, err := convertWorkItemSingle(ctx, appl, ctx.RequestData, wi, comments)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(resp)
+	})
+}
+
+// ListNextStates runs the list-next-states action.
+func (c *WorkitemController) ListNextStates(ctx *app.ListNextStatesWorkitemContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		states, err := appl.WorkItems().NextStates(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, fmt.Sprintf("Fail to list the next states of work item with id %v", ctx.ID)))
+		}
+		return ctx.OK(&app.WorkItemStateList{
+			Data: ConvertWorkItemStates(states),
+		})
+	})
+}
+
+// Reorder moves a work item above or below another one in the backlog order
+func (c *WorkitemController) Reorder(ctx *app.ReorderWorkitemContext) error {
+	if _, err := contextIdentityID(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	if (ctx.Payload.Above == nil) == (ctx.Payload.Below == nil) {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("above|below", nil).Expected("the ID of exactly one work item"))
+	}
+	direction, otherID := workitem.ReorderAbove, ctx.Payload.Above
+	if ctx.Payload.Below != nil {
+		direction, otherID = workitem.ReorderBelow, ctx.Payload.Below
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		wi, err := appl.WorkItems().Reorder(ctx, ctx.ID, direction, *otherID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, fmt.Sprintf("Fail to reorder work item with id %v", ctx.ID)))
+		}
+		resp, err := convertWorkItemSingle(ctx, appl, ctx.RequestData, wi)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
 		}
 		return ctx.OK(resp)
 	})
 }
 
+// ConvertWorkItemStates converts between internal and external REST representation
+func ConvertWorkItemStates(states []workitem.NextState) []*app.WorkItemState {
+	var result = []*app.WorkItemState{}
+	for _, s := range states {
+		requiredFields := s.RequiredFields
+		if requiredFields == nil {
+			requiredFields = []string{}
+		}
+		result = append(result, &app.WorkItemState{
+			Type: APIStringTypeWorkItemState,
+			ID:   s.State,
+			Attributes: &app.WorkItemStateAttributes{
+				Name:           s.State,
+				RequiredFields: requiredFields,
+			},
+		})
+	}
+	return result
+}
+
 // Delete does DELETE workitem
 func (c *WorkitemController) Delete(ctx *app.DeleteWorkitemContext) error {
+	currentUser, err := contextIdentityID(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
 	return application.Transactional(c.db, func(appl application.Application) error {
-		err := appl.WorkItems().Delete(ctx, ctx.ID)
+		err := appl.WorkItems().Delete(ctx, ctx.ID, currentUser)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, fmt.Sprintf("Error deleting work item")))
 		}
@@ -193,7 +498,7 @@ func ConvertJSONAPIToWorkItem(appl application.Application, source app.WorkItem2
 
 	if source.Relationships != nil && source.Relationships.Assignees != nil {
 		if source.Relationships.Assignees.Data == nil {
-			delete(target.Fields, workitem.SystemAssignees)
+			target.Fields[workitem.SystemAssignees] = nil
 		} else {
 			var ids []string
 			for _, d := range source.Relationships.Assignees.Data {
@@ -211,7 +516,7 @@ func ConvertJSONAPIToWorkItem(appl application.Application, source app.WorkItem2
 	}
 	if source.Relationships != nil && source.Relationships.Iteration != nil {
 		if source.Relationships.Iteration.Data == nil {
-			delete(target.Fields, workitem.SystemIteration)
+			target.Fields[workitem.SystemIteration] = nil
 		} else {
 			d := source.Relationships.Iteration.Data
 			iterationUUID, err := uuid.FromString(*d.ID)
@@ -258,6 +563,150 @@ func getVersion(version interface{}) (int, error) {
 	return -1, nil
 }
 
+// convertWorkItemSingle converts a single work item together with the relationships of all
+// its reference fields and includes the referenced users, iterations and work items
+func convertWorkItemSingle(ctx context.Context, appl application.Application, request *goa.RequestData, wi *app.WorkItem, additional ...WorkItemConvertFunc) (*app.WorkItem2Single, error) {
+	wit, err := appl.WorkItemTypes().Load(ctx, wi.Space, wi.Type)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	wi2 := ConvertWorkItem(request, wi, append(additional, WorkItemIncludeReferences(wit))...)
+	included, err := includeReferences(ctx, appl, request, wi2)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	return &app.WorkItem2Single{
+		Data:     wi2,
+		Included: included,
+	}, nil
+}
+
+// WorkItemIncludeReferences adds the relationships of the fields of kind user, iteration or
+// workitem that have no relationship of their own
+func WorkItemIncludeReferences(wit *app.WorkItemType) WorkItemConvertFunc {
+	return func(request *goa.RequestData, wi *app.WorkItem, wi2 *app.WorkItem2) {
+		for name, def := range wit.Fields {
+			kind := referenceKind(def)
+			switch name {
+			case workitem.SystemAssignees, workitem.SystemCreator, workitem.SystemIteration:
+				continue
+			}
+			if kind == "" || wi.Fields[name] == nil {
+				continue
+			}
+			data := []*app.GenericData{}
+			for _, id := range referenceIDs(wi.Fields[name]) {
+				data = append(data, convertReferenceSimple(request, kind, id))
+			}
+			if wi2.Relationships.References == nil {
+				wi2.Relationships.References = map[string]*app.RelationGenericList{}
+			}
+			wi2.Relationships.References[name] = &app.RelationGenericList{Data: data}
+		}
+	}
+}
+
+// convertReferenceSimple converts the ID of a referenced user, iteration or work item into a
+// Generic Relationship
+func convertReferenceSimple(request *goa.RequestData, kind workitem.Kind, id string) *app.GenericData {
+	switch kind {
+	case workitem.KindUser:
+		return ConvertUserSimple(request, id)
+	case workitem.KindIteration:
+		return ConvertIterationSimple(request, id)
+	}
+	t := APIStringTypeWorkItem
+	selfURL := rest.AbsoluteURL(request, app.WorkitemHref(id))
+	return &app.GenericData{
+		Type: &t,
+		ID:   &id,
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
+
+// includeReferences returns the users, iterations and work items referenced by the
+// relationships of the work item. References to entities that do not exist (anymore), e.g.
+// the creators of imported work items, are left out.
+func includeReferences(ctx context.Context, appl application.Application, request *goa.RequestData, wi2 *app.WorkItem2) ([]interface{}, error) {
+	var refs []*app.GenericData
+	if wi2.Relationships.Assignees != nil {
+		refs = append(refs, wi2.Relationships.Assignees.Data...)
+	}
+	if wi2.Relationships.Creator != nil && wi2.Relationships.Creator.Data != nil {
+		refs = append(refs, wi2.Relationships.Creator.Data)
+	}
+	if wi2.Relationships.Iteration != nil && wi2.Relationships.Iteration.Data != nil {
+		refs = append(refs, wi2.Relationships.Iteration.Data)
+	}
+	for _, rel := range wi2.Relationships.References {
+		refs = append(refs, rel.Data...)
+	}
+	included := []interface{}{}
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		if ref.Type == nil || ref.ID == nil || seen[*ref.Type+"/"+*ref.ID] {
+			continue
+		}
+		seen[*ref.Type+"/"+*ref.ID] = true
+		resource, err := loadReference(ctx, appl, request, *ref.Type, *ref.ID)
+		if err != nil {
+			return nil, errs.WithStack(err)
+		}
+		if resource != nil {
+			included = append(included, resource)
+		}
+	}
+	return included, nil
+}
+
+// loadReference returns the REST representation of a referenced entity, nil if it does not exist
+func loadReference(ctx context.Context, appl application.Application, request *goa.RequestData, apiType string, id string) (interface{}, error) {
+	if apiType == APIStringTypeWorkItem {
+		wi, err := appl.WorkItems().Load(ctx, id)
+		if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, errs.WithStack(err)
+		}
+		return ConvertWorkItem(request, wi), nil
+	}
+	uid, err := uuid.FromString(id)
+	if err != nil {
+		return nil, nil
+	}
+	switch apiType {
+	case APIStringTypeUser:
+		identity, err := appl.Identities().Load(ctx, uid)
+		if errs.Cause(err) == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, errs.WithStack(err)
+		}
+		var user *account.User
+		if identity.UserID.Valid {
+			user, err = appl.Users().Load(ctx, identity.UserID.UUID)
+			if err != nil {
+				return nil, errs.WithStack(err)
+			}
+		}
+		return ConvertUser(request, identity, user).Data, nil
+	case iteration.APIStringTypeIteration:
+		itr, err := appl.Iterations().Load(ctx, uid)
+		if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, errs.WithStack(err)
+		}
+		return ConvertIteration(request, itr), nil
+	}
+	return nil, nil
+}
+
 // WorkItemConvertFunc is a open ended function to add additional links/data/relations to a Comment during
 // conversion from internal to API
 type WorkItemConvertFunc func(*goa.RequestData, *app.WorkItem, *app.WorkItem2)
@@ -299,6 +748,23 @@ func ConvertWorkItem(request *goa.RequestData, wi *app.WorkItem, additional ...W
 			TargetLinkTypes: &targetLinkTypesURL,
 		},
 	}
+	if wi.Rank != nil {
+		op.Attributes["rank"] = *wi.Rank
+	}
+	if wi.Space != nil {
+		spaceType := "spaces"
+		spaceID := wi.Space.String()
+		spaceSelfURL := rest.AbsoluteURL(request, app.SpaceHref(spaceID))
+		op.Relationships.Space = &app.RelationGeneric{
+			Data: &app.GenericData{
+				Type: &spaceType,
+				ID:   &spaceID,
+			},
+			Links: &app.GenericLinks{
+				Self: &spaceSelfURL,
+			},
+		}
+	}
 
 	// Move fields into Relationships or Attributes as needed
 	// TODO: Loop based on WorKItemType and match against Field.Type instead of directly to field value
@@ -0,0 +1,43 @@
+package workitem
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/almighty/almighty-core/errors"
+)
+
+// groupElement is the alias of the list elements joined by CompileGroupBy
+const groupElement = "group_element"
+
+// CompileGroupBy compiles the name of the field work items are grouped by to a sql expression that yields
+// the value of the field as jsonb, or NULL if the work item has no value. For list fields the returned
+// join must be added to the query; it yields one row per element of the list, so that a work item is
+// counted once for each of its values.
+func CompileGroupBy(fieldName string, fields FieldDefinitions) (expression string, join string, err error) {
+	if fieldName == "Type" {
+		return "to_jsonb(Type)", "", nil
+	}
+	def, ok := fields[fieldName]
+	if !ok || strings.Contains(fieldName, "'") {
+		return "", "", errors.NewBadParameterError("groupBy", fieldName).Expected("Type or a field of a work item type")
+	}
+	if !isGroupable(elementKind(def.Type)) {
+		return "", "", errors.NewBadParameterError("groupBy", fieldName).Expected("a field with a discrete set of values")
+	}
+	if def.Type.GetKind() == KindList {
+		value := "Fields->'" + fieldName + "'"
+		join = fmt.Sprintf("left join lateral jsonb_array_elements(case when jsonb_typeof(%[1]s) = 'array' then %[1]s else '[]'::jsonb end) as %[2]s on true", value, groupElement)
+		return "nullif(" + groupElement + ", 'null'::jsonb)", join, nil
+	}
+	return "nullif(Fields->'" + fieldName + "', 'null'::jsonb)", "", nil
+}
+
+// isGroupable tells whether it is meaningful to count the work items per value of the given kind
+func isGroupable(kind Kind) bool {
+	switch kind {
+	case KindString, KindInteger, KindURL, KindIteration, KindWorkitemReference, KindUser, KindEnum, KindBoolean, KindDate, KindLabel, KindArea:
+		return true
+	}
+	return false
+}
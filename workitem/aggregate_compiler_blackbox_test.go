@@ -0,0 +1,46 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	. "github.com/almighty/almighty-core/workitem"
+	errs "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileGroupBy(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+
+	t.Run("column", func(t *testing.T) {
+		expression, join, err := CompileGroupBy("Type", typedFields)
+		require.Nil(t, err)
+		assert.Equal(t, "to_jsonb(Type)", expression)
+		assert.Equal(t, "", join)
+	})
+
+	t.Run("simple field", func(t *testing.T) {
+		expression, join, err := CompileGroupBy(SystemState, typedFields)
+		require.Nil(t, err)
+		assert.Equal(t, "nullif(Fields->'system.state', 'null'::jsonb)", expression)
+		assert.Equal(t, "", join)
+	})
+
+	t.Run("list field", func(t *testing.T) {
+		expression, join, err := CompileGroupBy(SystemAssignees, typedFields)
+		require.Nil(t, err)
+		assert.Equal(t, "nullif(group_element, 'null'::jsonb)", expression)
+		assert.Equal(t, "left join lateral jsonb_array_elements(case when jsonb_typeof(Fields->'system.assignees') = 'array' then Fields->'system.assignees' else '[]'::jsonb end) as group_element on true", join)
+	})
+
+	t.Run("invalid fields", func(t *testing.T) {
+		for _, name := range []string{"unknown", SystemDescription, "duedate", "estimate", "ID"} {
+			_, _, err := CompileGroupBy(name, typedFields)
+			require.NotNil(t, err, name)
+			assert.IsType(t, errors.BadParameterError{}, errs.Cause(err), name)
+		}
+	})
+}
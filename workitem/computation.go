@@ -0,0 +1,63 @@
+package workitem
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Aggregate functions of computations
+const (
+	ComputationSum   = "sum"
+	ComputationMin   = "min"
+	ComputationMax   = "max"
+	ComputationCount = "count"
+)
+
+// Computation aggregates a field over the children of a work item, i.e. the
+// targets of its links of tree topology. It is written as
+// sum(children.<field>), min(children.<field>), max(children.<field>) or
+// count(children).
+type Computation struct {
+	Function string
+	// Field is the aggregated field of the children, empty for count
+	Field string
+}
+
+var computationPattern = regexp.MustCompile(`^\s*(\w+)\s*\(\s*children(?:\.([^\s)]+))?\s*\)\s*$`)
+
+// ParseComputation parses the computation of a computed field
+func ParseComputation(exp string) (*Computation, error) {
+	match := computationPattern.FindStringSubmatch(exp)
+	if match == nil {
+		return nil, fmt.Errorf("an expression like sum(children.<field>) or count(children), but is %q", exp)
+	}
+	c := Computation{Function: strings.ToLower(match[1]), Field: match[2]}
+	switch c.Function {
+	case ComputationSum, ComputationMin, ComputationMax:
+		if c.Field == "" {
+			return nil, fmt.Errorf("a field of the children to %s, like %s(children.<field>)", c.Function, c.Function)
+		}
+	case ComputationCount:
+		if c.Field != "" {
+			return nil, fmt.Errorf("count(children) without a field, but is %q", exp)
+		}
+	default:
+		return nil, fmt.Errorf("one of the functions %s, %s, %s or %s, but is %q", ComputationSum, ComputationMin, ComputationMax, ComputationCount, c.Function)
+	}
+	return &c, nil
+}
+
+// sql returns the select expression of the computation over the children
+// joined as c, and its parameters. Values that are no numbers are ignored.
+func (c Computation) sql() (string, []interface{}) {
+	if c.Function == ComputationCount {
+		return "count(c.id)", nil
+	}
+	value := "(case when jsonb_typeof(c.fields->?) = 'number' then (c.fields->>?)::numeric end)"
+	parameters := []interface{}{c.Field, c.Field}
+	if c.Function == ComputationSum {
+		return "coalesce(sum" + value + ", 0)", parameters
+	}
+	return c.Function + value, parameters
+}
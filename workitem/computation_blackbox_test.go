@@ -0,0 +1,29 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/resource"
+	. "github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseComputation(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	for exp, expected := range map[string]Computation{
+		"sum(children.storypoints)":     {Function: ComputationSum, Field: "storypoints"},
+		" MAX( children.scrum.effort )": {Function: ComputationMax, Field: "scrum.effort"},
+		"min(children.x)":               {Function: ComputationMin, Field: "x"},
+		"count(children)":               {Function: ComputationCount},
+	} {
+		c, err := ParseComputation(exp)
+		require.Nil(t, err, exp)
+		assert.Equal(t, expected, *c, exp)
+	}
+	for _, exp := range []string{"", "sum", "sum(children)", "count(children.x)", "avg(children.x)", "sum(parent.x)", "sum(children.x) + 1"} {
+		_, err := ParseComputation(exp)
+		assert.NotNil(t, err, exp)
+	}
+}
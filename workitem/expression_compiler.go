@@ -1,11 +1,17 @@
 package workitem
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/rendering"
 )
 
 const (
@@ -15,11 +21,23 @@ const (
 // Compile takes an expression and compiles it to a where clause for use with gorm.DB.Where()
 // Returns the number of expected parameters for the query and a slice of errors if something goes wrong
 func Compile(where criteria.Expression) (whereClause string, parameters []interface{}, err []error) {
+	return CompileWithFields(where, nil)
+}
+
+// CompileWithFields works like Compile, but uses the given field definitions to compare json fields
+// according to their kind: numbers and instants are cast to the matching Postgres type, list fields
+// are tested for containment and comparisons that make no sense for a kind are rejected with a
+// BadParameterError. Fields without a definition are compared as text.
+func CompileWithFields(where criteria.Expression, fields FieldDefinitions) (whereClause string, parameters []interface{}, err []error) {
 	criteria.IteratePostOrder(where, bubbleUpJSONContext)
 
 	compiler := newExpressionCompiler()
+	compiler.fields = fields
 	compiled := where.Accept(&compiler)
-
+	if compiled == nil {
+		// errors have been accumulated by the compiler
+		return "", compiler.parameters, compiler.err
+	}
 	return compiled.(string), compiler.parameters, compiler.err
 }
 
@@ -30,9 +48,11 @@ func bubbleUpJSONContext(exp criteria.Expression) bool {
 		if isJSONField(t.FieldName) {
 			t.SetAnnotation(jsonAnnotation, true)
 		}
-	case *criteria.EqualsExpression:
-		if t.Left().Annotation(jsonAnnotation) == true || t.Right().Annotation(jsonAnnotation) == true {
-			t.SetAnnotation(jsonAnnotation, true)
+	case *criteria.EqualsExpression, *criteria.NotEqualsExpression:
+		// (in)equality on json fields is compiled to a containment check
+		b := t.(criteria.BinaryExpression)
+		if b.Left().Annotation(jsonAnnotation) == true || b.Right().Annotation(jsonAnnotation) == true {
+			b.SetAnnotation(jsonAnnotation, true)
 		}
 	}
 	return true
@@ -40,11 +60,24 @@ func bubbleUpJSONContext(exp criteria.Expression) bool {
 
 // does the field name reference a json field or a column?
 func isJSONField(fieldName string) bool {
+	_, isColumn := columnName(fieldName)
+	return !isColumn
+}
+
+// columnName returns the name of the column that stores the given field, if it isn't stored in the json fields
+func columnName(fieldName string) (string, bool) {
 	switch fieldName {
 	case "ID", "Type", "Version":
-		return false
+		return fieldName, true
+	case "rank":
+		return "rank", true
+	case "SpaceID":
+		return "space_id", true
+	case SystemCreatedAt:
+		// the creation time is taken from the lifecycle of the work item
+		return "created_at", true
 	}
-	return true
+	return "", false
 }
 
 func newExpressionCompiler() expressionCompiler {
@@ -54,23 +87,42 @@ func newExpressionCompiler() expressionCompiler {
 // expressionCompiler takes an expression and compiles it to a where clause for our gorm models
 // implements criteria.ExpressionVisitor
 type expressionCompiler struct {
-	parameters []interface{} // records the number of parameter expressions encountered
-	err        []error       // record any errors found in the expression
+	parameters []interface{}    // records the number of parameter expressions encountered
+	err        []error          // record any errors found in the expression
+	fields     FieldDefinitions // definitions of the json fields, may be nil
 }
 
 // visitor implementation
 // the convention is to return nil when the expression cannot be compiled and to append an error to the err field
 
 func (c *expressionCompiler) Field(f *criteria.FieldExpression) interface{} {
-	if !isJSONField(f.FieldName) {
-		return f.FieldName
+	if column, ok := columnName(f.FieldName); ok {
+		return column
 	}
 	if strings.Contains(f.FieldName, "'") {
 		// beware of injection, it's a reasonable restriction for field names, make sure it's not allowed when creating wi types
 		c.err = append(c.err, fmt.Errorf("single quote not allowed in field name"))
 		return nil
 	}
-	return "Fields@>'{\"" + f.FieldName + "\""
+	return jsonFieldValue(f.FieldName, c.fields)
+}
+
+// jsonFieldValue returns the sql expression for the value of a json field.
+// Fields with a known definition are cast to the Postgres type matching their kind,
+// the text value is returned for all others. The field name must not contain single quotes
+func jsonFieldValue(fieldName string, fields FieldDefinitions) string {
+	if def, ok := fields[fieldName]; ok {
+		switch def.Type.GetKind() {
+		case KindList:
+			return "Fields->'" + fieldName + "'"
+		case KindMarkup:
+			return "(Fields->'" + fieldName + "'->>'" + rendering.ContentKey + "')"
+		}
+		if cast := sqlCast(elementKind(def.Type)); cast != "" {
+			return "(Fields->>'" + fieldName + "')::" + cast
+		}
+	}
+	return "Fields->>'" + fieldName + "'"
 }
 
 func (c *expressionCompiler) And(a *criteria.AndExpression) interface{} {
@@ -91,78 +143,372 @@ func (c *expressionCompiler) Or(a *criteria.OrExpression) interface{} {
 	return c.binary(a, "or")
 }
 
+func (c *expressionCompiler) Not(n *criteria.NotExpression) interface{} {
+	operand := n.Operand().Accept(c)
+	if operand == nil {
+		return nil
+	}
+	return "(not " + operand.(string) + ")"
+}
+
 func (c *expressionCompiler) Equals(e *criteria.EqualsExpression) interface{} {
-	if isInJSONContext(e.Left()) {
-		return c.binary(e, ":")
+	if e.Annotation(jsonAnnotation) == true {
+		return c.containment(e)
 	}
 	return c.binary(e, "=")
 }
 
-func (c *expressionCompiler) Parameter(v *criteria.ParameterExpression) interface{} {
-	c.err = append(c.err, fmt.Errorf("Parameter expression not supported"))
-	return nil
+func (c *expressionCompiler) NotEquals(e *criteria.NotEqualsExpression) interface{} {
+	if e.Annotation(jsonAnnotation) == true {
+		// negate the containment check, this also matches items that don't have the field at all
+		compiled := c.containment(e)
+		if compiled == nil {
+			return nil
+		}
+		return "(not " + compiled.(string) + ")"
+	}
+	return c.binary(e, "<>")
 }
 
-// iterate the parent chain to see if this expression references json fields
-func isInJSONContext(exp criteria.Expression) bool {
-	result := false
-	criteria.IterateParents(exp, func(exp criteria.Expression) bool {
-		if exp.Annotation(jsonAnnotation) == true {
-			result = true
-			return false
+// containment compiles the (in)equality of a json field and a literal to a check whether the
+// fields contain a document with the field set to the literal. The document is marshalled to
+// json and passed as a parameter, so the values never become part of the query.
+func (c *expressionCompiler) containment(e criteria.BinaryExpression) interface{} {
+	field, literalExp := e.Left(), e.Right()
+	if _, ok := field.(*criteria.FieldExpression); !ok {
+		field, literalExp = literalExp, field
+	}
+	f, ok := field.(*criteria.FieldExpression)
+	literal, isLiteral := literalExp.(*criteria.LiteralExpression)
+	if !ok || !isLiteral {
+		c.err = append(c.err, fmt.Errorf("json fields can only be compared with literal values"))
+		return nil
+	}
+	var value interface{}
+	if def, ok := c.fields[f.FieldName]; ok {
+		if value, ok = c.typedJSONValue(f.FieldName, &def, literal.Value); !ok {
+			return nil
 		}
-		return true
-	})
-	return result
+	} else {
+		switch literal.Value.(type) {
+		case float64, int, int64, uint, uint64, string, bool, []string, map[string]interface{}:
+			value = literal.Value
+		default:
+			c.err = append(c.err, fmt.Errorf("unknown value type of %v: %T", literal.Value, literal.Value))
+			return nil
+		}
+	}
+	document, err := json.Marshal(map[string]interface{}{f.FieldName: value})
+	if err != nil {
+		c.err = append(c.err, err)
+		return nil
+	}
+	c.parameters = append(c.parameters, string(document))
+	return "(Fields @> ?::jsonb)"
 }
 
-// literal values need to be converted differently depending on whether they are used in a JSON context or a regular SQL expression.
-// JSON values are always strings (delimited with "'"), but operators can be used depending on the dynamic type. For example,
-// you can write "a->'foo' < '5'" and it will return true for the json object { "a": 40 }.
-func (c *expressionCompiler) Literal(v *criteria.LiteralExpression) interface{} {
-	json := isInJSONContext(v)
-	if json {
-		stringVal, err := c.convertToString(v.Value)
-		if err == nil {
-			return stringVal + "}'"
+func (c *expressionCompiler) LessThan(e *criteria.LessThanExpression) interface{} {
+	return c.ordering(e, "<")
+}
+
+func (c *expressionCompiler) LessOrEqual(e *criteria.LessOrEqualExpression) interface{} {
+	return c.ordering(e, "<=")
+}
+
+func (c *expressionCompiler) GreaterThan(e *criteria.GreaterThanExpression) interface{} {
+	return c.ordering(e, ">")
+}
+
+func (c *expressionCompiler) GreaterOrEqual(e *criteria.GreaterOrEqualExpression) interface{} {
+	return c.ordering(e, ">=")
+}
+
+// ordering compiles the relational operators. If the left hand side is a json field with a known
+// definition, the field kind must support ordering and the literal is converted to the field's type.
+func (c *expressionCompiler) ordering(e criteria.BinaryExpression, op string) interface{} {
+	field, def := c.fieldDefinition(e.Left())
+	if def == nil {
+		return c.binary(e, op)
+	}
+	if !isOrdered(def.Type) {
+		c.err = append(c.err, errors.NewBadParameterError(field.FieldName, op).Expected("a field of kind string, integer, float, instant, duration or workitem"))
+		return nil
+	}
+	left := field.Accept(c)
+	literal, ok := e.Right().(*criteria.LiteralExpression)
+	if !ok {
+		right := e.Right().Accept(c)
+		if left == nil || right == nil {
+			return nil
 		}
-		if stringArr, ok := v.Value.([]string); ok {
-			return "[" + c.wrapStrings(stringArr) + "]}'"
+		return "(" + left.(string) + " " + op + " " + right.(string) + ")"
+	}
+	value, ok := c.convertLiteral(field.FieldName, def, literal.Value)
+	if left == nil || !ok {
+		return nil
+	}
+	c.parameters = append(c.parameters, value)
+	return "(" + left.(string) + " " + op + " ?)"
+}
+
+// In expands the list on the right hand side into one parameter per element.
+// For list fields the expression is true if any element of the field is in the given list.
+func (c *expressionCompiler) In(e *criteria.InExpression) interface{} {
+	field, def := c.fieldDefinition(e.Left())
+	if def != nil && def.Type.GetKind() == KindMarkup {
+		c.err = append(c.err, errors.NewBadParameterError(field.FieldName, "in").Expected("a field that is not of kind markup"))
+		return nil
+	}
+	left := e.Left().Accept(c)
+	values, ok := c.literalList(e.Right())
+	if left == nil || !ok {
+		return nil
+	}
+	if len(values) == 0 {
+		// nothing can be a member of the empty list
+		return "(false)"
+	}
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		if def != nil {
+			if v, ok = c.convertLiteral(field.FieldName, def, v); !ok {
+				return nil
+			}
 		}
-		c.err = append(c.err, err)
+		placeholders[i] = "?"
+		c.parameters = append(c.parameters, v)
+	}
+	if def != nil && def.Type.GetKind() == KindList {
+		// we can't use the "?|" operator because gorm treats every "?" as a placeholder
+		return "(exists (select 1 from jsonb_array_elements_text(" + left.(string) + ") as element where element in (" + strings.Join(placeholders, ",") + ")))"
+	}
+	return "(" + left.(string) + " in (" + strings.Join(placeholders, ",") + "))"
+}
+
+func (c *expressionCompiler) IsNull(e *criteria.IsNullExpression) interface{} {
+	operand := e.Operand().Accept(c)
+	if operand == nil {
+		return nil
+	}
+	return "(" + operand.(string) + " is null)"
+}
+
+// Substring is compiled to a case insensitive "ilike" with the wildcards in the value escaped
+func (c *expressionCompiler) Substring(e *criteria.SubstringExpression) interface{} {
+	if field, def := c.fieldDefinition(e.Left()); def != nil {
+		switch def.Type.GetKind() {
+		case KindString, KindMarkup, KindURL:
+		default:
+			c.err = append(c.err, errors.NewBadParameterError(field.FieldName, "contains").Expected("a field of kind string, markup or url"))
+			return nil
+		}
+	}
+	left := e.Left().Accept(c)
+	literal, ok := e.Right().(*criteria.LiteralExpression)
+	if !ok {
+		c.err = append(c.err, fmt.Errorf("right hand side of substring must be a literal"))
 		return nil
 	}
+	value, ok := literal.Value.(string)
+	if !ok {
+		c.err = append(c.err, fmt.Errorf("substring value must be a string but is %T", literal.Value))
+		return nil
+	}
+	if left == nil {
+		return nil
+	}
+	escaper := strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+	c.parameters = append(c.parameters, "%"+escaper.Replace(value)+"%")
+	return "(" + left.(string) + " ilike ?)"
+}
+
+// literalList extracts the elements of a literal slice expression
+func (c *expressionCompiler) literalList(exp criteria.Expression) ([]interface{}, bool) {
+	literal, ok := exp.(*criteria.LiteralExpression)
+	if !ok {
+		c.err = append(c.err, fmt.Errorf("right hand side of in must be a literal list"))
+		return nil, false
+	}
+	value := reflect.ValueOf(literal.Value)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		c.err = append(c.err, fmt.Errorf("right hand side of in must be a list but is %T", literal.Value))
+		return nil, false
+	}
+	result := make([]interface{}, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		result[i] = value.Index(i).Interface()
+	}
+	return result, true
+}
+
+func (c *expressionCompiler) Parameter(v *criteria.ParameterExpression) interface{} {
+	c.err = append(c.err, fmt.Errorf("Parameter expression not supported"))
+	return nil
+}
+
+func (c *expressionCompiler) Literal(v *criteria.LiteralExpression) interface{} {
 	c.parameters = append(c.parameters, v.Value)
 	return "?"
 }
 
-func (c *expressionCompiler) wrapStrings(value []string) string {
-	wrapped := []string{}
-	for i := 0; i < len(value); i++ {
-		wrapped = append(wrapped, "\""+value[i]+"\"")
-	}
-	return strings.Join(wrapped, ",")
-}
-
-func (c *expressionCompiler) convertToString(value interface{}) (string, error) {
-	var result string
-	switch t := value.(type) {
-	case float64:
-		result = strconv.FormatFloat(t, 'f', -1, 64)
-	case int:
-		result = strconv.FormatInt(int64(t), 10)
-	case int64:
-		result = strconv.FormatInt(t, 10)
-	case uint:
-		result = strconv.FormatUint(uint64(t), 10)
-	case uint64:
-		result = strconv.FormatUint(t, 10)
-	case string:
-		result = "\"" + t + "\""
-	case bool:
-		result = strconv.FormatBool(t)
+// typedJSONValue converts the value for a containment check against a field with a known definition.
+// Scalar values compared with a list field are wrapped in a list, so that "system.assignees == 'joe'"
+// matches all items that have joe among their assignees.
+func (c *expressionCompiler) typedJSONValue(fieldName string, def *FieldDefinition, value interface{}) (interface{}, bool) {
+	var values []interface{}
+	list := reflect.ValueOf(value)
+	if value != nil && (list.Kind() == reflect.Slice || list.Kind() == reflect.Array) {
+		if def.Type.GetKind() != KindList {
+			c.err = append(c.err, errors.NewBadParameterError(fieldName, value).Expected(def.Type.GetKind()))
+			return nil, false
+		}
+		for i := 0; i < list.Len(); i++ {
+			values = append(values, list.Index(i).Interface())
+		}
+	} else {
+		values = []interface{}{value}
+	}
+	converted := make([]interface{}, len(values))
+	for i, v := range values {
+		model, ok := c.convertLiteral(fieldName, def, v)
+		if !ok {
+			return nil, false
+		}
+		if def.Type.GetKind() == KindMarkup {
+			model = map[string]interface{}{rendering.ContentKey: model}
+		}
+		converted[i] = model
+	}
+	if def.Type.GetKind() == KindList {
+		return converted, true
+	}
+	return converted[0], true
+}
+
+// fieldDefinition returns the json field referenced by the given expression and its definition,
+// or nil if the expression is not a field or the definition of the field is unknown
+func (c *expressionCompiler) fieldDefinition(exp criteria.Expression) (*criteria.FieldExpression, *FieldDefinition) {
+	field, ok := exp.(*criteria.FieldExpression)
+	if !ok || !isJSONField(field.FieldName) {
+		return nil, nil
+	}
+	def, ok := c.fields[field.FieldName]
+	if !ok {
+		return field, nil
+	}
+	return field, &def
+}
+
+// convertLiteral converts a literal value to the representation stored for the given field.
+// Records a BadParameterError if the value does not fit the field kind.
+func (c *expressionCompiler) convertLiteral(fieldName string, def *FieldDefinition, value interface{}) (interface{}, bool) {
+	result, ok := convertLiteralToKind(elementKind(def.Type), value)
+	if !ok {
+		c.err = append(c.err, errors.NewBadParameterError(fieldName, value).Expected(def.Type.GetKind()))
+	}
+	return result, ok
+}
+
+func convertLiteralToKind(kind Kind, value interface{}) (interface{}, bool) {
+	switch kind {
+	case KindInteger, KindDuration, KindWorkitemReference:
+		switch v := value.(type) {
+		case int:
+			return int64(v), true
+		case int64:
+			return v, true
+		case float64:
+			if v == math.Trunc(v) {
+				return int64(v), true
+			}
+		case string:
+			// work item references are passed around as strings in the API
+			if kind == KindWorkitemReference {
+				if id, err := strconv.ParseInt(v, 10, 64); err == nil {
+					return id, true
+				}
+			}
+		}
+	case KindFloat:
+		switch v := value.(type) {
+		case int:
+			return float64(v), true
+		case int64:
+			return float64(v), true
+		case float64:
+			return v, true
+		}
+	case KindInstant:
+		// instants are stored as nanoseconds since the epoch
+		switch v := value.(type) {
+		case time.Time:
+			return v.UnixNano(), true
+		case string:
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return t.UnixNano(), true
+			}
+		case int:
+			return int64(v), true
+		case int64:
+			return v, true
+		}
+	case KindBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, true
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, true
+			}
+		}
+	case KindDate:
+		// dates are stored as text in the DateFormat, which sorts like the dates
+		switch v := value.(type) {
+		case time.Time:
+			return v.Format(DateFormat), true
+		case string:
+			if t, err := time.Parse(DateFormat, v); err == nil {
+				return t.Format(DateFormat), true
+			}
+		}
 	default:
-		return "", fmt.Errorf("unknown value type of %v: %T", value, value)
+		if v, ok := value.(string); ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// elementKind returns the kind of the values stored for a field type, looking through lists and enums
+func elementKind(t FieldType) Kind {
+	switch t := t.(type) {
+	case ListType:
+		return elementKind(t.ComponentType)
+	case EnumType:
+		return t.BaseType.GetKind()
+	}
+	return t.GetKind()
+}
+
+// sqlCast returns the Postgres type the text of a json value of the given kind is cast to for comparison,
+// or the empty string if the value is compared as text
+func sqlCast(kind Kind) string {
+	switch kind {
+	case KindInteger, KindDuration, KindInstant, KindWorkitemReference:
+		return "bigint"
+	case KindFloat:
+		return "double precision"
+	case KindBoolean:
+		return "boolean"
+	}
+	return ""
+}
+
+// isOrdered tells whether the relational operators can be applied to values of the given type
+func isOrdered(t FieldType) bool {
+	switch t.GetKind() {
+	case KindString, KindInteger, KindFloat, KindInstant, KindDuration, KindWorkitemReference, KindDate:
+		return true
 	}
-	return result, nil
+	return false
 }
@@ -1,20 +1,24 @@
 package workitem_test
 
 import (
+	"fmt"
 	"reflect"
 	"runtime/debug"
 	"testing"
+	"time"
 
 	. "github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/resource"
 	. "github.com/almighty/almighty-core/workitem"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestField(t *testing.T) {
 	t.Parallel()
 	resource.Require(t, resource.UnitTest)
-	expect(t, Equals(Field("foo"), Literal(23)), "(Fields@>'{\"foo\" : 23}')", []interface{}{})
+	expect(t, Equals(Field("foo"), Literal(23)), "(Fields @> ?::jsonb)", []interface{}{`{"foo":23}`})
 	expect(t, Equals(Field("Type"), Literal("abcd")), "(Type = ?)", []interface{}{"abcd"})
 }
 
@@ -23,8 +27,146 @@ func TestAndOr(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
 	expect(t, Or(Literal(true), Literal(false)), "(? or ?)", []interface{}{true, false})
 
-	expect(t, And(Equals(Field("foo"), Literal("abcd")), Equals(Literal(true), Literal(false))), "((Fields@>'{\"foo\" : \"abcd\"}') and (? = ?))", []interface{}{true, false})
-	expect(t, Or(Equals(Field("foo"), Literal("abcd")), Equals(Literal(true), Literal(false))), "((Fields@>'{\"foo\" : \"abcd\"}') or (? = ?))", []interface{}{true, false})
+	expect(t, And(Equals(Field("foo"), Literal("abcd")), Equals(Literal(true), Literal(false))), "((Fields @> ?::jsonb) and (? = ?))", []interface{}{`{"foo":"abcd"}`, true, false})
+	expect(t, Or(Equals(Field("foo"), Literal("abcd")), Equals(Literal(true), Literal(false))), "((Fields @> ?::jsonb) or (? = ?))", []interface{}{`{"foo":"abcd"}`, true, false})
+}
+
+func TestNot(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expect(t, Not(Equals(Field("foo"), Literal("abcd"))), "(not (Fields @> ?::jsonb))", []interface{}{`{"foo":"abcd"}`})
+	expect(t, Not(Equals(Field("Type"), Literal("abcd"))), "(not (Type = ?))", []interface{}{"abcd"})
+}
+
+func TestNotEquals(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expect(t, NotEquals(Field("system.state"), Literal("closed")), "(not (Fields @> ?::jsonb))", []interface{}{`{"system.state":"closed"}`})
+	expect(t, NotEquals(Field("Version"), Literal(3)), "(Version <> ?)", []interface{}{3})
+}
+
+func TestRelationalOperators(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expect(t, LessThan(Field("Version"), Literal(3)), "(Version < ?)", []interface{}{3})
+	expect(t, LessOrEqual(Field("Version"), Literal(3)), "(Version <= ?)", []interface{}{3})
+	expect(t, GreaterThan(Field("foo"), Literal("2016-01-01")), "(Fields->>'foo' > ?)", []interface{}{"2016-01-01"})
+	expect(t, GreaterOrEqual(Field("system.created_at"), Literal("2016-01-01")), "(created_at >= ?)", []interface{}{"2016-01-01"})
+	expect(t, And(Equals(Field("foo"), Literal("abcd")), LessThan(Field("bar"), Literal("x"))), "((Fields @> ?::jsonb) and (Fields->>'bar' < ?))", []interface{}{`{"foo":"abcd"}`, "x"})
+}
+
+func TestIn(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expect(t, In(Field("system.state"), Literal([]string{"new", "open"})), "(Fields->>'system.state' in (?,?))", []interface{}{"new", "open"})
+	expect(t, In(Field("Type"), Literal([]interface{}{"bug"})), "(Type in (?))", []interface{}{"bug"})
+	expect(t, In(Field("Type"), Literal([]string{})), "(false)", []interface{}{})
+	_, _, err := Compile(In(Field("Type"), Literal("bug")))
+	assert.NotEmpty(t, err)
+}
+
+func TestIsNull(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expect(t, IsNull(Field("system.iteration")), "(Fields->>'system.iteration' is null)", []interface{}{})
+	expect(t, Not(IsNull(Field("Type"))), "(not (Type is null))", []interface{}{})
+}
+
+func TestSubstring(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expect(t, Substring(Field("system.title"), Literal("50%_off")), "(Fields->>'system.title' ilike ?)", []interface{}{"%50\\%\\_off%"})
+	_, _, err := Compile(Substring(Field("system.title"), Literal(5)))
+	assert.NotEmpty(t, err)
+}
+
+var typedFields = FieldDefinitions{
+	"system.title":       {Type: SimpleType{Kind: KindString}},
+	"system.state":       {Type: EnumType{SimpleType: SimpleType{Kind: KindEnum}, BaseType: SimpleType{Kind: KindString}, Values: []interface{}{"new", "open"}}},
+	"system.assignees":   {Type: ListType{SimpleType: SimpleType{Kind: KindList}, ComponentType: SimpleType{Kind: KindUser}}},
+	"system.created_at":  {Type: SimpleType{Kind: KindInstant}},
+	"duedate":            {Type: SimpleType{Kind: KindInstant}},
+	"system.description": {Type: SimpleType{Kind: KindMarkup}},
+	"storypoints":        {Type: SimpleType{Kind: KindInteger}},
+	"estimate":           {Type: SimpleType{Kind: KindFloat}},
+	"parent":             {Type: SimpleType{Kind: KindWorkitemReference}},
+	"blocked":            {Type: SimpleType{Kind: KindBoolean}},
+	"targetdate":         {Type: SimpleType{Kind: KindDate}},
+	"labels":             {Type: ListType{SimpleType: SimpleType{Kind: KindList}, ComponentType: SimpleType{Kind: KindLabel}}},
+	"area":               {Type: SimpleType{Kind: KindArea}},
+}
+
+func TestTypedComparison(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expectTyped(t, GreaterThan(Field("storypoints"), Literal(2)), "((Fields->>'storypoints')::bigint > ?)", []interface{}{int64(2)})
+	expectTyped(t, LessOrEqual(Field("estimate"), Literal(2)), "((Fields->>'estimate')::double precision <= ?)", []interface{}{float64(2)})
+	expectTyped(t, GreaterOrEqual(Field("parent"), Literal("42")), "((Fields->>'parent')::bigint >= ?)", []interface{}{int64(42)})
+	created := time.Date(2016, 11, 1, 0, 0, 0, 0, time.UTC)
+	expectTyped(t, GreaterThan(Field("duedate"), Literal("2016-11-01T00:00:00Z")), "((Fields->>'duedate')::bigint > ?)", []interface{}{created.UnixNano()})
+	expectTyped(t, LessThan(Field("duedate"), Literal(created)), "((Fields->>'duedate')::bigint < ?)", []interface{}{created.UnixNano()})
+	expectTyped(t, LessThan(Field("system.title"), Literal("m")), "(Fields->>'system.title' < ?)", []interface{}{"m"})
+	// unknown fields are compared as text
+	expectTyped(t, LessThan(Field("foo"), Literal("m")), "(Fields->>'foo' < ?)", []interface{}{"m"})
+	expectTyped(t, Equals(Field("duedate"), Literal("2016-11-01T00:00:00Z")), "(Fields @> ?::jsonb)", []interface{}{fmt.Sprintf(`{"duedate":%d}`, created.UnixNano())})
+	expectTyped(t, Equals(Field("system.description"), Literal("foo")), "(Fields @> ?::jsonb)", []interface{}{`{"system.description":{"content":"foo"}}`})
+	// the creation time is stored in a column
+	expectTyped(t, LessThan(Field("system.created_at"), Literal(created)), "(created_at < ?)", []interface{}{created})
+	expectTyped(t, IsNull(Field("storypoints")), "((Fields->>'storypoints')::bigint is null)", []interface{}{})
+}
+
+func TestBooleanDateLabelAreaComparison(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expectTyped(t, Equals(Field("blocked"), Literal(true)), "(Fields @> ?::jsonb)", []interface{}{`{"blocked":true}`})
+	expectTyped(t, Equals(Field("blocked"), Literal("false")), "(Fields @> ?::jsonb)", []interface{}{`{"blocked":false}`})
+	expectTyped(t, In(Field("blocked"), Literal([]interface{}{true})), "((Fields->>'blocked')::boolean in (?))", []interface{}{true})
+	expectTyped(t, Equals(Field("targetdate"), Literal("2017-03-01")), "(Fields @> ?::jsonb)", []interface{}{`{"targetdate":"2017-03-01"}`})
+	expectTyped(t, LessThan(Field("targetdate"), Literal(time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC))), "(Fields->>'targetdate' < ?)", []interface{}{"2017-03-01"})
+	expectTyped(t, Equals(Field("labels"), Literal("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")), "(Fields @> ?::jsonb)", []interface{}{`{"labels":["40bbdd3d-8b5d-4fd6-ac90-7236b669af04"]}`})
+	expectTyped(t, Equals(Field("area"), Literal("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")), "(Fields @> ?::jsonb)", []interface{}{`{"area":"40bbdd3d-8b5d-4fd6-ac90-7236b669af04"}`})
+
+	expectBadParameter(t, Equals(Field("blocked"), Literal("maybe")))
+	expectBadParameter(t, LessThan(Field("blocked"), Literal(true)))
+	expectBadParameter(t, GreaterThan(Field("targetdate"), Literal("March 1st")))
+	expectBadParameter(t, LessThan(Field("area"), Literal("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")))
+}
+
+func TestTypedListComparison(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expectTyped(t, Equals(Field("system.assignees"), Literal("joe")), "(Fields @> ?::jsonb)", []interface{}{`{"system.assignees":["joe"]}`})
+	expectTyped(t, Equals(Field("system.assignees"), Literal([]string{"joe", "jane"})), "(Fields @> ?::jsonb)", []interface{}{`{"system.assignees":["joe","jane"]}`})
+	expectTyped(t, NotEquals(Field("system.assignees"), Literal("joe")), "(not (Fields @> ?::jsonb))", []interface{}{`{"system.assignees":["joe"]}`})
+	expectTyped(t, In(Field("system.assignees"), Literal([]string{"joe", "jane"})), "(exists (select 1 from jsonb_array_elements_text(Fields->'system.assignees') as element where element in (?,?)))", []interface{}{"joe", "jane"})
+	expectTyped(t, In(Field("storypoints"), Literal([]interface{}{1, 2})), "((Fields->>'storypoints')::bigint in (?,?))", []interface{}{int64(1), int64(2)})
+}
+
+func TestTypedComparisonErrors(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expectBadParameter(t, GreaterThan(Field("storypoints"), Literal("many")))
+	expectBadParameter(t, GreaterThan(Field("storypoints"), Literal(2.5)))
+	expectBadParameter(t, LessThan(Field("duedate"), Literal("yesterday")))
+	expectBadParameter(t, LessThan(Field("system.assignees"), Literal("joe")))
+	expectBadParameter(t, LessThan(Field("system.state"), Literal("open")))
+	expectBadParameter(t, Substring(Field("storypoints"), Literal("1")))
+	expectBadParameter(t, Equals(Field("storypoints"), Literal([]string{"1"})))
+	expectBadParameter(t, In(Field("system.description"), Literal([]string{"foo"})))
+}
+
+func expectTyped(t *testing.T, expr Expression, expectedClause string, expectedParameters []interface{}) {
+	clause, parameters, err := CompileWithFields(expr, typedFields)
+	require.Empty(t, err)
+	assert.Equal(t, expectedClause, clause)
+	assert.Equal(t, expectedParameters, parameters)
+}
+
+func expectBadParameter(t *testing.T, expr Expression) {
+	_, _, err := CompileWithFields(expr, typedFields)
+	require.NotEmpty(t, err)
+	_, ok := err[0].(errors.BadParameterError)
+	assert.True(t, ok, "expected a BadParameterError but got %v", err[0])
 }
 
 func expect(t *testing.T, expr Expression, expectedClause string, expectedParameters []interface{}) {
@@ -48,7 +190,17 @@ func TestArray(t *testing.T) {
 	assignees := []string{"1", "2", "3"}
 
 	exp := Equals(Field("system.assignees"), Literal(assignees))
-	where, _, _ := Compile(exp)
+	where, parameters, _ := Compile(exp)
+
+	assert.Equal(t, "(Fields @> ?::jsonb)", where)
+	assert.Equal(t, []interface{}{`{"system.assignees":["1","2","3"]}`}, parameters)
+}
 
-	assert.Equal(t, "(Fields@>'{\"system.assignees\" : [\"1\",\"2\",\"3\"]}')", where)
+func TestQuotesInValues(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	// values are passed as parameters, quotes can't end the json document or the query
+	expect(t, Equals(Field("system.title"), Literal(`it's "here"'}') or (true`)), "(Fields @> ?::jsonb)", []interface{}{`{"system.title":"it's \"here\"'}') or (true"}`})
+	expectTyped(t, NotEquals(Field("system.assignees"), Literal(`jo"e'`)), "(not (Fields @> ?::jsonb))", []interface{}{`{"system.assignees":["jo\"e'"]}`})
+	expect(t, Equals(Field(`foo"bar`), Literal("x")), "(Fields @> ?::jsonb)", []interface{}{`{"foo\"bar":"x"}`})
 }
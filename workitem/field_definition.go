@@ -3,12 +3,15 @@ package workitem
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
+	"time"
 
 	"strings"
 
 	"github.com/almighty/almighty-core/convert"
-	"github.com/pkg/errors"
+	"github.com/almighty/almighty-core/errors"
+	errs "github.com/pkg/errors"
 )
 
 // constants for describing possible field types
@@ -25,8 +28,15 @@ const (
 	KindEnum              Kind = "enum"
 	KindList              Kind = "list"
 	KindMarkup            Kind = "markup"
+	KindBoolean           Kind = "boolean"
+	KindDate              Kind = "date"
+	KindLabel             Kind = "label"
+	KindArea              Kind = "area"
 )
 
+// DateFormat is the layout of the values of date fields, a calendar date without time
+const DateFormat = "2006-01-02"
+
 // Kind is the kind of field type
 type Kind string
 
@@ -46,10 +56,27 @@ type FieldType interface {
 	Equal(u convert.Equaler) bool
 }
 
+// Context defaults of fields, see FieldDefinition.DefaultFrom
+const (
+	DefaultFromCurrentUser      = "currentUser"
+	DefaultFromCurrentIteration = "currentIteration"
+	DefaultFromNow              = "now"
+)
+
 // FieldDefinition describes type & other restrictions of a field
 type FieldDefinition struct {
 	Required bool
-	Type     FieldType
+	// Deprecated fields are kept for existing work items but should not be used anymore
+	Deprecated bool `json:",omitempty"`
+	Type       FieldType
+	// Default is the value of the field of new work items that are created without one
+	Default interface{} `json:",omitempty"`
+	// DefaultFrom names the context the default of the field of new work items is drawn from:
+	// the creator, the running iteration of the space of the type or the creation time
+	DefaultFrom string `json:",omitempty"`
+	// Computed is an expression the value of the field is computed with whenever a work item
+	// is saved, see ParseComputation
+	Computed string `json:",omitempty"`
 }
 
 // Ensure FieldDefinition implements the Equaler interface
@@ -65,6 +92,12 @@ func (self FieldDefinition) Equal(u convert.Equaler) bool {
 	if self.Required != other.Required {
 		return false
 	}
+	if self.Deprecated != other.Deprecated {
+		return false
+	}
+	if !reflect.DeepEqual(self.Default, other.Default) || self.DefaultFrom != other.DefaultFrom || self.Computed != other.Computed {
+		return false
+	}
 	return self.Type.Equal(other.Type)
 }
 
@@ -76,6 +109,100 @@ func (f FieldDefinition) ConvertToModel(name string, value interface{}) (interfa
 	return f.Type.ConvertToModel(value)
 }
 
+// ValidateValueSource returns an error if the default or the computation of the field with
+// the given name does not fit its type. A field has at most one of them.
+// returns BadParameterError
+func (f FieldDefinition) ValidateValueSource(name string) error {
+	sources := 0
+	for _, set := range []bool{f.Default != nil, f.DefaultFrom != "", f.Computed != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return errors.NewBadParameterError("fields."+name, name).Expected("at most one of default, defaultFrom and computed")
+	}
+	kind := f.Type.GetKind()
+	if list, ok := f.Type.(ListType); ok {
+		kind = list.ComponentType.GetKind()
+	}
+	switch {
+	case f.Default != nil:
+		if kind == KindMarkup {
+			return errors.NewBadParameterError("fields."+name+".default", f.Default).Expected("no default for a markup field")
+		}
+		if _, err := f.Type.ConvertToModel(f.staticDefault()); err != nil {
+			return errors.NewBadParameterError("fields."+name+".default", f.Default).Expected(err.Error())
+		}
+	case f.DefaultFrom != "":
+		expected := map[string][]Kind{
+			DefaultFromCurrentUser:      {KindUser},
+			DefaultFromCurrentIteration: {KindIteration},
+			DefaultFromNow:              {KindInstant, KindDate},
+		}[f.DefaultFrom]
+		if expected == nil {
+			return errors.NewBadParameterError("fields."+name+".defaultFrom", f.DefaultFrom).Expected(fmt.Sprintf("one of %s, %s or %s", DefaultFromCurrentUser, DefaultFromCurrentIteration, DefaultFromNow))
+		}
+		if !containsKind(expected, kind) || (f.DefaultFrom != DefaultFromCurrentUser && kind != f.Type.GetKind()) {
+			return errors.NewBadParameterError("fields."+name+".defaultFrom", f.DefaultFrom).Expected(fmt.Sprintf("a field of kind %v", expected))
+		}
+	case f.Computed != "":
+		if _, err := ParseComputation(f.Computed); err != nil {
+			return errors.NewBadParameterError("fields."+name+".computed", f.Computed).Expected(err.Error())
+		}
+		if f.Type.GetKind() != KindInteger && f.Type.GetKind() != KindFloat {
+			return errors.NewBadParameterError("fields."+name+".computed", f.Computed).Expected("a field of kind integer or float")
+		}
+	}
+	return nil
+}
+
+// staticDefault returns the static default in the representation expected by ConvertToModel.
+// Defaults are stored as JSON, hence numbers are floats and instants are strings.
+func (f FieldDefinition) staticDefault() interface{} {
+	switch t := f.Type.(type) {
+	case ListType:
+		values, ok := f.Default.([]interface{})
+		if !ok {
+			return f.Default
+		}
+		result := make([]interface{}, len(values))
+		for i, v := range values {
+			result[i] = jsonToAPIValue(t.ComponentType.GetKind(), v)
+		}
+		return result
+	case EnumType:
+		return jsonToAPIValue(t.BaseType.GetKind(), f.Default)
+	}
+	return jsonToAPIValue(f.Type.GetKind(), f.Default)
+}
+
+// jsonToAPIValue converts a value decoded from JSON to the representation of the given kind
+func jsonToAPIValue(kind Kind, value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		if (kind == KindInteger || kind == KindDuration) && v == math.Trunc(v) {
+			return int(v)
+		}
+	case string:
+		if kind == KindInstant {
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				return t
+			}
+		}
+	}
+	return value
+}
+
+func containsKind(kinds []Kind, kind Kind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
 // ConvertFromModel converts a field value for use in the REST API layer
 func (f FieldDefinition) ConvertFromModel(name string, value interface{}) (interface{}, error) {
 	if f.Required && value == nil {
@@ -85,8 +212,12 @@ func (f FieldDefinition) ConvertFromModel(name string, value interface{}) (inter
 }
 
 type rawFieldDef struct {
-	Required bool
-	Type     *json.RawMessage
+	Required    bool
+	Deprecated  bool
+	Type        *json.RawMessage
+	Default     interface{}
+	DefaultFrom string
+	Computed    string
 }
 
 // Ensure rawFieldDef implements the Equaler interface
@@ -102,6 +233,12 @@ func (self rawFieldDef) Equal(u convert.Equaler) bool {
 	if self.Required != other.Required {
 		return false
 	}
+	if self.Deprecated != other.Deprecated {
+		return false
+	}
+	if !reflect.DeepEqual(self.Default, other.Default) || self.DefaultFrom != other.DefaultFrom || self.Computed != other.Computed {
+		return false
+	}
 	if self.Type == nil && other.Type == nil {
 		return true
 	}
@@ -117,7 +254,7 @@ func (f *FieldDefinition) UnmarshalJSON(bytes []byte) error {
 
 	err := json.Unmarshal(bytes, &temp)
 	if err != nil {
-		return errors.WithStack(err)
+		return errs.WithStack(err)
 	}
 	rawType := map[string]interface{}{}
 	json.Unmarshal(*temp.Type, &rawType)
@@ -125,30 +262,31 @@ func (f *FieldDefinition) UnmarshalJSON(bytes []byte) error {
 	kind, err := convertAnyToKind(rawType["Kind"])
 
 	if err != nil {
-		return errors.WithStack(err)
+		return errs.WithStack(err)
 	}
+	*f = FieldDefinition{Required: temp.Required, Deprecated: temp.Deprecated, Default: temp.Default, DefaultFrom: temp.DefaultFrom, Computed: temp.Computed}
 	switch *kind {
 	case KindList:
 		theType := ListType{}
 		err = json.Unmarshal(*temp.Type, &theType)
 		if err != nil {
-			return errors.WithStack(err)
+			return errs.WithStack(err)
 		}
-		*f = FieldDefinition{Type: theType, Required: temp.Required}
+		f.Type = theType
 	case KindEnum:
 		theType := EnumType{}
 		err = json.Unmarshal(*temp.Type, &theType)
 		if err != nil {
-			return errors.WithStack(err)
+			return errs.WithStack(err)
 		}
-		*f = FieldDefinition{Type: theType, Required: temp.Required}
+		f.Type = theType
 	default:
 		theType := SimpleType{}
 		err = json.Unmarshal(*temp.Type, &theType)
 		if err != nil {
-			return errors.WithStack(err)
+			return errs.WithStack(err)
 		}
-		*f = FieldDefinition{Type: theType, Required: temp.Required}
+		f.Type = theType
 	}
 	return nil
 }
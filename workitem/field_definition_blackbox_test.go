@@ -6,8 +6,12 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/resource"
 	. "github.com/almighty/almighty-core/workitem"
+	errs "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListFieldDefMarshalling(t *testing.T) {
@@ -34,3 +38,91 @@ func TestListFieldDefMarshalling(t *testing.T) {
 		t.Errorf("field should be %v, but is %v", def, unmarshalled)
 	}
 }
+
+func TestDeprecatedFieldDefMarshalling(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	def := FieldDefinition{
+		Deprecated: true,
+		Type:       SimpleType{Kind: KindString},
+	}
+	bytes, err := json.Marshal(def)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	unmarshalled := FieldDefinition{}
+	json.Unmarshal(bytes, &unmarshalled)
+
+	if !reflect.DeepEqual(def, unmarshalled) {
+		t.Errorf("field should be %v, but is %v", def, unmarshalled)
+	}
+
+	// definitions stored before fields could be deprecated are not deprecated
+	json.Unmarshal([]byte(`{"Required":false,"Type":{"Kind":"string"}}`), &unmarshalled)
+	if unmarshalled.Deprecated {
+		t.Errorf("field should not be deprecated")
+	}
+}
+
+func TestValueSourceFieldDefMarshalling(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	for _, def := range []FieldDefinition{
+		{Type: SimpleType{Kind: KindString}, Default: "none"},
+		{Type: SimpleType{Kind: KindUser}, DefaultFrom: DefaultFromCurrentUser},
+		{Type: SimpleType{Kind: KindFloat}, Computed: "sum(children.storypoints)"},
+	} {
+		bytes, err := json.Marshal(def)
+		require.Nil(t, err)
+		unmarshalled := FieldDefinition{}
+		require.Nil(t, json.Unmarshal(bytes, &unmarshalled))
+		assert.Equal(t, def, unmarshalled)
+		assert.True(t, def.Equal(unmarshalled))
+	}
+}
+
+func TestValidateValueSource(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	stUser := SimpleType{Kind: KindUser}
+	valid := map[string]FieldDefinition{
+		"no source":          {Type: SimpleType{Kind: KindString}},
+		"string default":     {Type: SimpleType{Kind: KindString}, Default: "none"},
+		"integer from JSON":  {Type: SimpleType{Kind: KindInteger}, Default: float64(3)},
+		"instant from JSON":  {Type: SimpleType{Kind: KindInstant}, Default: "2017-01-31T12:00:00Z"},
+		"enum default":       {Type: EnumType{SimpleType: SimpleType{Kind: KindEnum}, BaseType: SimpleType{Kind: KindString}, Values: []interface{}{"a", "b"}}, Default: "b"},
+		"current user":       {Type: stUser, DefaultFrom: DefaultFromCurrentUser},
+		"current user list":  {Type: ListType{SimpleType: SimpleType{Kind: KindList}, ComponentType: stUser}, DefaultFrom: DefaultFromCurrentUser},
+		"current iteration":  {Type: SimpleType{Kind: KindIteration}, DefaultFrom: DefaultFromCurrentIteration},
+		"now as date":        {Type: SimpleType{Kind: KindDate}, DefaultFrom: DefaultFromNow},
+		"computed sum":       {Type: SimpleType{Kind: KindFloat}, Computed: "sum(children.storypoints)"},
+		"computed count":     {Type: SimpleType{Kind: KindInteger}, Computed: "count(children)"},
+		"computed max float": {Type: SimpleType{Kind: KindFloat}, Computed: "max(children.scrum.effort)"},
+	}
+	for name, def := range valid {
+		assert.Nil(t, def.ValidateValueSource("f"), name)
+	}
+	invalid := map[string]FieldDefinition{
+		"two sources":            {Type: SimpleType{Kind: KindUser}, Default: "x", DefaultFrom: DefaultFromCurrentUser},
+		"wrong default type":     {Type: SimpleType{Kind: KindInteger}, Default: "three"},
+		"fractional integer":     {Type: SimpleType{Kind: KindInteger}, Default: 3.5},
+		"no enum value":          {Type: EnumType{SimpleType: SimpleType{Kind: KindEnum}, BaseType: SimpleType{Kind: KindString}, Values: []interface{}{"a", "b"}}, Default: "c"},
+		"markup default":         {Type: SimpleType{Kind: KindMarkup}, Default: "text"},
+		"unknown context":        {Type: stUser, DefaultFrom: "currentSpace"},
+		"user from now":          {Type: stUser, DefaultFrom: DefaultFromNow},
+		"iteration list":         {Type: ListType{SimpleType: SimpleType{Kind: KindList}, ComponentType: SimpleType{Kind: KindIteration}}, DefaultFrom: DefaultFromCurrentIteration},
+		"computed string":        {Type: SimpleType{Kind: KindString}, Computed: "count(children)"},
+		"unknown function":       {Type: SimpleType{Kind: KindFloat}, Computed: "avg(children.storypoints)"},
+		"invalid computed":       {Type: SimpleType{Kind: KindFloat}, Computed: "storypoints + 1"},
+		"sum without a field":    {Type: SimpleType{Kind: KindFloat}, Computed: "sum(children)"},
+		"count with a field":     {Type: SimpleType{Kind: KindFloat}, Computed: "count(children.storypoints)"},
+		"computed from parents":  {Type: SimpleType{Kind: KindFloat}, Computed: "sum(parents.storypoints)"},
+		"computed list of float": {Type: ListType{SimpleType: SimpleType{Kind: KindList}, ComponentType: SimpleType{Kind: KindFloat}}, Computed: "sum(children.storypoints)"},
+	}
+	for name, def := range invalid {
+		err := def.ValidateValueSource("f")
+		require.NotNil(t, err, name)
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err), name)
+	}
+}
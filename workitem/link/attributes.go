@@ -0,0 +1,128 @@
+package link
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+)
+
+// Kinds of work item link attributes
+const (
+	AttributeKindString  = "string"
+	AttributeKindInteger = "integer"
+	AttributeKindFloat   = "float"
+	AttributeKindBoolean = "boolean"
+)
+
+// AttributeDefinition describes a single attribute of the links of a work
+// item link type
+type AttributeDefinition struct {
+	Kind     string `json:"kind"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// AttributesSchema restricts the attributes of the links of a work item link
+// type. Links of types without a schema may have arbitrary attributes.
+type AttributesSchema map[string]AttributeDefinition
+
+// Value implements driver.Valuer, empty schemas are stored as NULL
+func (s AttributesSchema) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+// Scan implements sql.Scanner
+func (s *AttributesSchema) Scan(src interface{}) error {
+	*s = nil
+	if src == nil {
+		return nil
+	}
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("Scan source was not []byte but %T", src)
+	}
+	return json.Unmarshal(b, s)
+}
+
+// Check returns an error if an attribute of the schema has an unknown kind.
+// returns BadParameterError
+func (s AttributesSchema) Check() error {
+	for name, def := range s {
+		switch def.Kind {
+		case AttributeKindString, AttributeKindInteger, AttributeKindFloat, AttributeKindBoolean:
+		default:
+			return errors.NewBadParameterError("attributes_schema."+name+".kind", def.Kind).Expected(AttributeKindString + "|" + AttributeKindInteger + "|" + AttributeKindFloat + "|" + AttributeKindBoolean)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if the given link attributes do not match the
+// schema: required attributes must be given, every attribute must be part of
+// the schema and its value must be of the attribute's kind. Without a schema
+// all attributes are valid.
+// returns BadParameterError
+func (s AttributesSchema) Validate(attributes workitem.Fields) error {
+	if len(s) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := attributes[name]; !ok && s[name].Required {
+			return errors.NewBadParameterError("data.attributes.attributes."+name, nil).Expected("a value")
+		}
+	}
+	for name, value := range attributes {
+		def, ok := s[name]
+		if !ok {
+			return errors.NewBadParameterError("data.attributes.attributes", name).Expected("one of the attributes " + fmt.Sprint(names))
+		}
+		if value == nil {
+			if def.Required {
+				return errors.NewBadParameterError("data.attributes.attributes."+name, nil).Expected("a value")
+			}
+			continue
+		}
+		if !isOfKind(value, def.Kind) {
+			return errors.NewBadParameterError("data.attributes.attributes."+name, value).Expected("a value of kind " + def.Kind)
+		}
+	}
+	return nil
+}
+
+// isOfKind returns true if the given value, as decoded from JSON, is of the
+// given attribute kind
+func isOfKind(value interface{}, kind string) bool {
+	switch kind {
+	case AttributeKindString:
+		_, ok := value.(string)
+		return ok
+	case AttributeKindBoolean:
+		_, ok := value.(bool)
+		return ok
+	case AttributeKindInteger:
+		switch v := value.(type) {
+		case int, int32, int64:
+			return true
+		case float64:
+			return v == math.Trunc(v)
+		}
+	case AttributeKindFloat:
+		switch value.(type) {
+		case int, int32, int64, float32, float64:
+			return true
+		}
+	}
+	return false
+}
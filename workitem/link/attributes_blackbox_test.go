@@ -0,0 +1,61 @@
+package link_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
+	errs "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttributesSchemaCheck(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	assert.Nil(t, link.AttributesSchema{}.Check())
+	assert.Nil(t, link.AttributesSchema{
+		"reason":   {Kind: link.AttributeKindString, Required: true},
+		"priority": {Kind: link.AttributeKindInteger},
+		"weight":   {Kind: link.AttributeKindFloat},
+		"hard":     {Kind: link.AttributeKindBoolean},
+	}.Check())
+	err := link.AttributesSchema{"reason": {Kind: "text"}}.Check()
+	require.NotNil(t, err)
+	assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+}
+
+func TestAttributesSchemaValidate(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	// without a schema everything goes
+	assert.Nil(t, link.AttributesSchema(nil).Validate(workitem.Fields{"anything": []interface{}{1, "a"}}))
+
+	schema := link.AttributesSchema{
+		"reason":   {Kind: link.AttributeKindString, Required: true},
+		"priority": {Kind: link.AttributeKindInteger},
+		"weight":   {Kind: link.AttributeKindFloat},
+		"hard":     {Kind: link.AttributeKindBoolean},
+	}
+	assert.Nil(t, schema.Validate(workitem.Fields{"reason": "waiting for review"}))
+	assert.Nil(t, schema.Validate(workitem.Fields{"reason": "waiting for review", "priority": float64(2), "weight": 0.5, "hard": true}))
+	assert.Nil(t, schema.Validate(workitem.Fields{"reason": "waiting for review", "priority": nil, "weight": 1}))
+
+	for name, attributes := range map[string]workitem.Fields{
+		"missing required":  {"priority": 1},
+		"nil required":      {"reason": nil},
+		"unknown attribute": {"reason": "a", "color": "red"},
+		"no string":         {"reason": 1},
+		"no integer":        {"reason": "a", "priority": 1.5},
+		"no float":          {"reason": "a", "weight": "heavy"},
+		"no boolean":        {"reason": "a", "hard": "yes"},
+	} {
+		err := schema.Validate(attributes)
+		require.NotNil(t, err, name)
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err), name)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	convert "github.com/almighty/almighty-core/convert"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
 	satoriuuid "github.com/satori/go.uuid"
 )
 
@@ -20,6 +21,11 @@ type WorkItemLink struct {
 	SourceID   uint64
 	TargetID   uint64
 	LinkTypeID satoriuuid.UUID `sql:"type:uuid default uuid_generate_v4()"`
+	// Attributes are free-form values of the link, validated against the
+	// attributes schema of the link type
+	Attributes workitem.Fields `sql:"type:jsonb"`
+	// Position orders the links with the same source and link type
+	Position int
 }
 
 // Ensure Fields implements the Equaler interface
@@ -50,6 +56,14 @@ func (l WorkItemLink) Equal(u convert.Equaler) bool {
 	if l.LinkTypeID != other.LinkTypeID {
 		return false
 	}
+	if len(l.Attributes) != 0 || len(other.Attributes) != 0 {
+		if !l.Attributes.Equal(other.Attributes) {
+			return false
+		}
+	}
+	if l.Position != other.Position {
+		return false
+	}
 	return true
 }
 
@@ -75,7 +89,9 @@ func ConvertLinkFromModel(t WorkItemLink) app.WorkItemLinkSingle {
 			Type: EndpointWorkItemLinks,
 			ID:   &id,
 			Attributes: &app.WorkItemLinkAttributes{
-				Version: &t.Version,
+				Version:    &t.Version,
+				Attributes: t.Attributes,
+				Position:   &t.Position,
 			},
 			Relationships: &app.WorkItemLinkRelationships{
 				LinkType: &app.RelationWorkItemLinkType{
@@ -104,7 +120,7 @@ func ConvertLinkFromModel(t WorkItemLink) app.WorkItemLinkSingle {
 
 // ConvertLinkToModel converts the incoming app representation of a work item link to the model layout.
 // Values are only overwrriten if they are set in "in", otherwise the values in "out" remain.
-// NOTE: Only the LinkTypeID, SourceID, and TargetID fields will be set.
+// NOTE: Only the LinkTypeID, SourceID, TargetID and Attributes fields will be set.
 //       You need to preload the elements after calling this function.
 func ConvertLinkToModel(in app.WorkItemLinkSingle, out *WorkItemLink) error {
 	attrs := in.Data.Attributes
@@ -129,6 +145,9 @@ func ConvertLinkToModel(in app.WorkItemLinkSingle, out *WorkItemLink) error {
 		if attrs.Version != nil {
 			out.Version = *attrs.Version
 		}
+		if attrs.Attributes != nil {
+			out.Attributes = workitem.Fields(attrs.Attributes)
+		}
 	}
 
 	if rel != nil && rel.LinkType != nil && rel.LinkType.Data != nil {
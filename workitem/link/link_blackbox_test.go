@@ -8,6 +8,7 @@ import (
 	"github.com/almighty/almighty-core/convert"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
 	satoriuuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/require"
@@ -62,6 +63,18 @@ func TestWorkItemLink_Equal(t *testing.T) {
 	b = a
 	b.LinkTypeID = satoriuuid.FromStringOrNil("10a41146-3868-47cd-84ae-f96ea4c9d797")
 	require.False(t, a.Equal(b))
+
+	// Test Attributes
+	b = a
+	b.Attributes = workitem.Fields{"reason": "waiting for review"}
+	require.False(t, a.Equal(b))
+	b.Attributes = workitem.Fields{}
+	require.True(t, a.Equal(b))
+
+	// Test Position
+	b = a
+	b.Position = 3
+	require.False(t, a.Equal(b))
 }
 
 func TestWorkItemLinkCheckValidForCreation(t *testing.T) {
@@ -1,8 +1,11 @@
 package link
 
 import (
+	"database/sql"
+	"fmt"
 	"log"
 	"strconv"
+	"strings"
 
 	"golang.org/x/net/context"
 
@@ -23,14 +26,22 @@ const (
 	EndpointWorkItemLinks          = "workitemlinks"
 )
 
+// Directions in which a work item link can be moved relative to a sibling
+const (
+	ReorderBefore = "before"
+	ReorderAfter  = "after"
+)
+
 // WorkItemLinkRepository encapsulates storage & retrieval of work item links
 type WorkItemLinkRepository interface {
-	Create(ctx context.Context, sourceID, targetID uint64, linkTypeID satoriuuid.UUID) (*app.WorkItemLinkSingle, error)
+	Create(ctx context.Context, sourceID, targetID uint64, linkTypeID satoriuuid.UUID, attributes workitem.Fields) (*app.WorkItemLinkSingle, error)
 	Load(ctx context.Context, ID string) (*app.WorkItemLinkSingle, error)
 	List(ctx context.Context) (*app.WorkItemLinkList, error)
 	ListByWorkItemID(ctx context.Context, wiIDStr string) (*app.WorkItemLinkList, error)
+	Traverse(ctx context.Context, wiIDStr string, linkTypeID satoriuuid.UUID, direction string, maxDepth int) ([]TraversedWorkItem, error)
 	Delete(ctx context.Context, ID string) error
 	Save(ctx context.Context, linkCat app.WorkItemLinkSingle) (*app.WorkItemLinkSingle, error)
+	Reorder(ctx context.Context, ID string, direction string, siblingID string) (*app.WorkItemLinkSingle, error)
 }
 
 // NewWorkItemLinkRepository creates a work item link repository based on gorm
@@ -70,11 +81,11 @@ func (r *GormWorkItemLinkRepository) ValidateCorrectSourceAndTargetType(sourceID
 		return errs.WithStack(err)
 	}
 	// Fetch the concrete work item types of the target and the source.
-	sourceWorkItemType, err := r.workItemTypeRepo.LoadTypeFromDB(source.Type)
+	sourceWorkItemType, err := r.workItemTypeRepo.LoadTypeFromDB(source.SpaceID, source.Type)
 	if err != nil {
 		return errs.WithStack(err)
 	}
-	targetWorkItemType, err := r.workItemTypeRepo.LoadTypeFromDB(target.Type)
+	targetWorkItemType, err := r.workItemTypeRepo.LoadTypeFromDB(target.SpaceID, target.Type)
 	if err != nil {
 		return errs.WithStack(err)
 	}
@@ -88,13 +99,168 @@ func (r *GormWorkItemLinkRepository) ValidateCorrectSourceAndTargetType(sourceID
 	return nil
 }
 
-// Create creates a new work item link in the repository.
+// ValidateTopology returns an error if a link of the given type from the
+// source to the target work item would violate the topology of the link
+// type: a work item can have only one parent in a tree and neither trees nor
+// dependencies may contain cycles. The link with the given ID is ignored so
+// that an existing link can be validated before it is saved; pass
+// satoriuuid.Nil for new links.
+// Returns BadParameterError, NotFoundError or InternalError
+func (r *GormWorkItemLinkRepository) ValidateTopology(sourceID, targetID uint64, linkTypeID satoriuuid.UUID, linkID satoriuuid.UUID) error {
+	linkType, err := r.workItemLinkTypeRepo.LoadTypeFromDBByID(linkTypeID)
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	if linkType.Topology != TopologyTree && linkType.Topology != TopologyDependency {
+		return nil
+	}
+	if linkType.Topology == TopologyTree {
+		var parents []WorkItemLink
+		db := r.db.Where("link_type_id = ? AND target_id = ? AND id <> ?", linkTypeID, targetID, linkID).Find(&parents)
+		if db.Error != nil {
+			return errors.NewInternalError(db.Error.Error())
+		}
+		if len(parents) > 0 {
+			return errors.NewBadParameterError("data.relationships.target", fmt.Sprintf("%d -> %d", parents[0].SourceID, targetID)).Expected("a work item without a parent")
+		}
+	}
+	cycle, err := r.findCycle(sourceID, targetID, linkTypeID, linkID)
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	if cycle != "" {
+		return errors.NewBadParameterError("data.relationships", cycle).Expected("a link that does not create a cycle")
+	}
+	return nil
+}
+
+// reachableQuery selects the IDs of the work items reachable from a work item
+// via the links of a link type, ignoring a link that is about to be replaced.
+// UNION keeps every work item only once so that the recursion terminates even
+// if the links already contain a cycle. The work items reachable from a stop
+// work item are not visited. The parameters are the start work item, the link
+// type, the ignored link and the stop work item.
+const reachableQuery = `
+	WITH RECURSIVE reachable(id) AS (
+		SELECT ?::bigint
+	UNION
+		SELECT l.target_id
+		FROM work_item_links l JOIN reachable r ON l.source_id = r.id
+		WHERE l.link_type_id = ?
+			AND l.id <> ?
+			AND l.deleted_at IS NULL
+			AND r.id <> ?
+	)`
+
+// findCycle returns the path of the cycle that a link of the given type from
+// the source to the target work item would close, e.g. "1 -> 2 -> 3 -> 1",
+// or an empty string if the link would not create a cycle.
+func (r *GormWorkItemLinkRepository) findCycle(sourceID, targetID uint64, linkTypeID satoriuuid.UUID, linkID satoriuuid.UUID) (string, error) {
+	// The new link closes a cycle if the source can be reached from the
+	// target, the search stops as soon as the source is found.
+	var cycle bool
+	query := reachableQuery + ` SELECT EXISTS (SELECT 1 FROM reachable WHERE id = ?)`
+	if err := r.db.Raw(query, targetID, linkTypeID, linkID, sourceID, sourceID).Row().Scan(&cycle); err != nil {
+		return "", errors.NewInternalError(err.Error())
+	}
+	if !cycle {
+		return "", nil
+	}
+	path, err := r.findPath(targetID, sourceID, linkTypeID, linkID)
+	if err != nil {
+		return "", errs.WithStack(err)
+	}
+	return fmt.Sprintf("%d -> %s", sourceID, path), nil
+}
+
+// findPath returns a shortest path from one work item to another one that is
+// reachable from it via links of the given type, e.g. "2 -> 3 -> 1". The path
+// is only needed to report a cycle, so the links between the reachable work
+// items are loaded and searched breadth first.
+func (r *GormWorkItemLinkRepository) findPath(fromID, toID uint64, linkTypeID satoriuuid.UUID, linkID satoriuuid.UUID) (string, error) {
+	query := reachableQuery + `
+		SELECT l.source_id, l.target_id
+		FROM work_item_links l JOIN reachable r ON l.source_id = r.id
+		WHERE l.link_type_id = ?
+			AND l.id <> ?
+			AND l.deleted_at IS NULL
+		ORDER BY l.source_id, l.target_id`
+	rows, err := r.db.Raw(query, fromID, linkTypeID, linkID, toID, linkTypeID, linkID).Rows()
+	if err != nil {
+		return "", errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+	targets := map[uint64][]uint64{}
+	for rows.Next() {
+		var sourceID, targetID uint64
+		if err := rows.Scan(&sourceID, &targetID); err != nil {
+			return "", errors.NewInternalError(err.Error())
+		}
+		targets[sourceID] = append(targets[sourceID], targetID)
+	}
+	if err := rows.Err(); err != nil {
+		return "", errors.NewInternalError(err.Error())
+	}
+	predecessors := map[uint64]uint64{fromID: fromID}
+	queue := []uint64{fromID}
+	for len(queue) > 0 && queue[0] != toID {
+		id := queue[0]
+		queue = queue[1:]
+		for _, targetID := range targets[id] {
+			if _, ok := predecessors[targetID]; !ok {
+				predecessors[targetID] = id
+				queue = append(queue, targetID)
+			}
+		}
+	}
+	if _, ok := predecessors[toID]; !ok {
+		return "", errors.NewInternalError(fmt.Sprintf("work item %d is not reachable from %d", toID, fromID))
+	}
+	path := []string{strconv.FormatUint(toID, 10)}
+	for id := toID; id != fromID; {
+		id = predecessors[id]
+		path = append([]string{strconv.FormatUint(id, 10)}, path...)
+	}
+	return strings.Join(path, " -> "), nil
+}
+
+// ValidateAttributes returns an error if the given attributes do not match
+// the attributes schema of the link type.
+// Returns BadParameterError, NotFoundError or InternalError
+func (r *GormWorkItemLinkRepository) ValidateAttributes(linkTypeID satoriuuid.UUID, attributes workitem.Fields) error {
+	linkType, err := r.workItemLinkTypeRepo.LoadTypeFromDBByID(linkTypeID)
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	return linkType.AttributesSchema.Validate(attributes)
+}
+
+// nextPosition returns the position after the last link with the given
+// source and link type
+func (r *GormWorkItemLinkRepository) nextPosition(sourceID uint64, linkTypeID satoriuuid.UUID) (int, error) {
+	var position sql.NullInt64
+	row := r.db.Model(&WorkItemLink{}).Where("source_id = ? AND link_type_id = ?", sourceID, linkTypeID).Select("max(position)").Row()
+	if err := row.Scan(&position); err != nil {
+		return 0, errors.NewInternalError(err.Error())
+	}
+	if !position.Valid {
+		return 0, nil
+	}
+	return int(position.Int64) + 1, nil
+}
+
+// Create creates a new work item link in the repository. The link is placed
+// after all existing links with the same source and link type.
 // Returns BadParameterError, ConversionError or InternalError
-func (r *GormWorkItemLinkRepository) Create(ctx context.Context, sourceID, targetID uint64, linkTypeID satoriuuid.UUID) (*app.WorkItemLinkSingle, error) {
+func (r *GormWorkItemLinkRepository) Create(ctx context.Context, sourceID, targetID uint64, linkTypeID satoriuuid.UUID, attributes workitem.Fields) (*app.WorkItemLinkSingle, error) {
+	if attributes == nil {
+		attributes = workitem.Fields{}
+	}
 	link := &WorkItemLink{
 		SourceID:   sourceID,
 		TargetID:   targetID,
 		LinkTypeID: linkTypeID,
+		Attributes: attributes,
 	}
 	if err := link.CheckValidForCreation(); err != nil {
 		return nil, errs.WithStack(err)
@@ -102,6 +268,17 @@ func (r *GormWorkItemLinkRepository) Create(ctx context.Context, sourceID, targe
 	if err := r.ValidateCorrectSourceAndTargetType(sourceID, targetID, linkTypeID); err != nil {
 		return nil, errs.WithStack(err)
 	}
+	if err := r.ValidateTopology(sourceID, targetID, linkTypeID, satoriuuid.Nil); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := r.ValidateAttributes(linkTypeID, attributes); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	position, err := r.nextPosition(sourceID, linkTypeID)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	link.Position = position
 	db := r.db.Create(link)
 	if db.Error != nil {
 		if gormsupport.IsUniqueViolation(db.Error, "work_item_links_unique_idx") {
@@ -110,6 +287,10 @@ func (r *GormWorkItemLinkRepository) Create(ctx context.Context, sourceID, targe
 		}
 		return nil, errors.NewInternalError(db.Error.Error())
 	}
+	// the source may aggregate the fields of its new child
+	if err := r.workItemRepo.ComputeFields(ctx, sourceID); err != nil {
+		return nil, errs.WithStack(err)
+	}
 	// Convert the created link type entry into a JSONAPI response
 	result := ConvertLinkFromModel(*link)
 	return &result, nil
@@ -159,7 +340,8 @@ func (r *GormWorkItemLinkRepository) list(ctx context.Context, fetchFunc fetchLi
 	return &res, nil
 }
 
-// ListByWorkItemID returns the work item links that have wiID as source or target.
+// ListByWorkItemID returns the work item links that have wiID as source or
+// target. Links with the same source and link type are ordered by position.
 // TODO: Handle pagination
 func (r *GormWorkItemLinkRepository) ListByWorkItemID(ctx context.Context, wiIDStr string) (*app.WorkItemLinkList, error) {
 	fetchFunc := func() ([]WorkItemLink, error) {
@@ -169,7 +351,7 @@ func (r *GormWorkItemLinkRepository) ListByWorkItemID(ctx context.Context, wiIDS
 			return nil, errs.WithStack(err)
 		}
 		// Now fetch all links for that work item
-		db := r.db.Model(&WorkItemLink{}).Where("? IN (source_id, target_id)", wi.ID).Find(&rows)
+		db := r.db.Model(&WorkItemLink{}).Where("? IN (source_id, target_id)", wi.ID).Order("link_type_id, source_id, position").Find(&rows)
 		if db.Error != nil {
 			return nil, db.Error
 		}
@@ -201,11 +383,16 @@ func (r *GormWorkItemLinkRepository) Delete(ctx context.Context, ID string) erro
 		// treat as not found: clients don't know it must be a UUID
 		return errors.NewNotFoundError("work item link", ID)
 	}
-	var link = WorkItemLink{
-		ID: id,
+	var link = WorkItemLink{}
+	db := r.db.Where("id=?", id).First(&link)
+	if db.RecordNotFound() {
+		return errors.NewNotFoundError("work item link", id.String())
+	}
+	if db.Error != nil {
+		return errors.NewInternalError(db.Error.Error())
 	}
 	log.Printf("work item link to delete %v\n", link)
-	db := r.db.Delete(&link)
+	db = r.db.Delete(&link)
 	if db.Error != nil {
 		log.Print(db.Error.Error())
 		return errors.NewInternalError(db.Error.Error())
@@ -213,7 +400,8 @@ func (r *GormWorkItemLinkRepository) Delete(ctx context.Context, ID string) erro
 	if db.RowsAffected == 0 {
 		return errors.NewNotFoundError("work item link", id.String())
 	}
-	return nil
+	// the source no longer aggregates the fields of the former child
+	return r.workItemRepo.ComputeFields(ctx, link.SourceID)
 }
 
 // Save updates the given work item link in storage. Version must be the same as the one int the stored version.
@@ -235,6 +423,7 @@ func (r *GormWorkItemLinkRepository) Save(ctx context.Context, lt app.WorkItemLi
 	if lt.Data.Attributes.Version == nil || res.Version != *lt.Data.Attributes.Version {
 		return nil, errors.NewVersionConflictError("version conflict")
 	}
+	sourceID, linkTypeID := res.SourceID, res.LinkTypeID
 	if err := ConvertLinkToModel(lt, &res); err != nil {
 		return nil, errs.WithStack(err)
 	}
@@ -242,12 +431,99 @@ func (r *GormWorkItemLinkRepository) Save(ctx context.Context, lt app.WorkItemLi
 	if err := r.ValidateCorrectSourceAndTargetType(res.SourceID, res.TargetID, res.LinkTypeID); err != nil {
 		return nil, errs.WithStack(err)
 	}
+	if err := r.ValidateTopology(res.SourceID, res.TargetID, res.LinkTypeID, res.ID); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if res.Attributes == nil {
+		res.Attributes = workitem.Fields{}
+	}
+	if err := r.ValidateAttributes(res.LinkTypeID, res.Attributes); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	// a link that is moved to another source or link type goes to the end
+	if res.SourceID != sourceID || res.LinkTypeID != linkTypeID {
+		position, err := r.nextPosition(res.SourceID, res.LinkTypeID)
+		if err != nil {
+			return nil, errs.WithStack(err)
+		}
+		res.Position = position
+	}
 	db = r.db.Save(&res)
 	if db.Error != nil {
 		log.Print(db.Error.Error())
 		return nil, errors.NewInternalError(db.Error.Error())
 	}
 	log.Printf("updated work item link to %v\n", res)
+	// both the former and the new source may aggregate a changed set of children
+	for _, ID := range []uint64{sourceID, res.SourceID} {
+		if err := r.workItemRepo.ComputeFields(ctx, ID); err != nil {
+			return nil, errs.WithStack(err)
+		}
+	}
+	result := ConvertLinkFromModel(res)
+	return &result, nil
+}
+
+// Reorder moves the work item link with the given ID directly before or after
+// the given sibling, i.e. a link with the same source and link type. The
+// positions of all links with that source and link type are renumbered.
+// returns BadParameterError, NotFoundError or InternalError
+func (r *GormWorkItemLinkRepository) Reorder(ctx context.Context, ID string, direction string, siblingID string) (*app.WorkItemLinkSingle, error) {
+	if direction != ReorderBefore && direction != ReorderAfter {
+		return nil, errors.NewBadParameterError("direction", direction).Expected(ReorderBefore + "|" + ReorderAfter)
+	}
+	id, err := satoriuuid.FromString(ID)
+	if err != nil {
+		// treat as not found: clients don't know it must be a UUID
+		return nil, errors.NewNotFoundError("work item link", ID)
+	}
+	res := WorkItemLink{}
+	db := r.db.Where("id=?", id).First(&res)
+	if db.RecordNotFound() {
+		return nil, errors.NewNotFoundError("work item link", ID)
+	}
+	if db.Error != nil {
+		return nil, errors.NewInternalError(db.Error.Error())
+	}
+	var siblings []WorkItemLink
+	db = r.db.Where("source_id = ? AND link_type_id = ?", res.SourceID, res.LinkTypeID).Order("position, created_at, id").Find(&siblings)
+	if db.Error != nil {
+		return nil, errors.NewInternalError(db.Error.Error())
+	}
+	// build the new order without the moved link and insert it next to the sibling
+	ordered := make([]WorkItemLink, 0, len(siblings))
+	for _, l := range siblings {
+		if !satoriuuid.Equal(l.ID, res.ID) {
+			ordered = append(ordered, l)
+		}
+	}
+	index := -1
+	for i, l := range ordered {
+		if l.ID.String() == siblingID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, errors.NewBadParameterError(direction, siblingID).Expected("the ID of another link with the same source and link type")
+	}
+	if direction == ReorderAfter {
+		index++
+	}
+	ordered = append(ordered[:index], append([]WorkItemLink{res}, ordered[index:]...)...)
+	for position, l := range ordered {
+		if l.Position == position {
+			continue
+		}
+		db = r.db.Model(&WorkItemLink{}).Where("id = ?", l.ID).Update("position", position)
+		if db.Error != nil {
+			return nil, errors.NewInternalError(db.Error.Error())
+		}
+		if satoriuuid.Equal(l.ID, res.ID) {
+			res.Position = position
+		}
+	}
+	log.Printf("moved work item link %s %s %s\n", res.ID, direction, siblingID)
 	result := ConvertLinkFromModel(res)
 	return &result, nil
 }
@@ -0,0 +1,128 @@
+package link
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/errors"
+	errs "github.com/pkg/errors"
+	satoriuuid "github.com/satori/go.uuid"
+)
+
+// Directions in which work item links can be traversed
+const (
+	TraverseForward = "forward" // from the source to the target of a link
+	TraverseReverse = "reverse" // from the target to the source of a link
+)
+
+// TraversedWorkItem is a work item that was reached when walking work item links transitively
+type TraversedWorkItem struct {
+	WorkItemID uint64
+	// Depth is the number of links between the start work item and this one
+	Depth int
+	// Path holds the IDs of the work items on the shortest way from the start
+	// work item to this one, both included
+	Path []uint64
+}
+
+// Traverse walks the links of the given type transitively, starting at the
+// given work item, and returns every work item that can be reached with at
+// most maxDepth links, ordered by depth. Each work item is returned only once
+// with the shortest path to it. The start work item itself is only included
+// if it can be reached again.
+// Returns BadParameterError, NotFoundError or InternalError
+func (r *GormWorkItemLinkRepository) Traverse(ctx context.Context, wiIDStr string, linkTypeID satoriuuid.UUID, direction string, maxDepth int) ([]TraversedWorkItem, error) {
+	wi, err := r.workItemRepo.LoadFromDB(wiIDStr)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if _, err := r.workItemLinkTypeRepo.LoadTypeFromDBByID(linkTypeID); err != nil {
+		if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+			return nil, errors.NewBadParameterError("type", linkTypeID).Expected("the ID of an existing work item link type")
+		}
+		return nil, errs.WithStack(err)
+	}
+	if maxDepth < 1 {
+		return nil, errors.NewBadParameterError("depth", maxDepth).Expected("a positive number")
+	}
+	from, to := "source_id", "target_id"
+	switch direction {
+	case TraverseForward:
+	case TraverseReverse:
+		from, to = to, from
+	default:
+		return nil, errors.NewBadParameterError("direction", direction).Expected(TraverseForward + "|" + TraverseReverse)
+	}
+
+	// Every work item is visited once per depth, UNION drops the duplicates,
+	// so that the recursion terminates even for cyclic links. The walk ends
+	// at the start work item if it is reached again. Each reached work item
+	// is returned with its minimum depth and the smallest ID of the work items
+	// it can be reached from at the depth before, the paths are built from
+	// those predecessors.
+	query := fmt.Sprintf(`
+		WITH RECURSIVE traversal(id, depth) AS (
+			SELECT ?::bigint, 0
+		UNION
+			SELECT l.%[2]s, t.depth + 1
+			FROM work_item_links l JOIN traversal t ON l.%[1]s = t.id
+			WHERE l.link_type_id = ?
+				AND l.deleted_at IS NULL
+				AND t.depth < ?
+				AND (t.depth = 0 OR t.id <> ?)
+		), reached AS (
+			SELECT DISTINCT ON (id) id, depth FROM traversal WHERE depth > 0 ORDER BY id, depth
+		), predecessors AS (
+			SELECT ?::bigint AS id, 0 AS depth
+		UNION ALL
+			SELECT id, depth FROM reached WHERE id <> ?
+		)
+		SELECT id, depth, predecessor FROM (
+			SELECT DISTINCT ON (r.id) r.id, r.depth, p.id AS predecessor
+			FROM reached r
+				JOIN work_item_links l ON l.%[2]s = r.id
+				JOIN predecessors p ON l.%[1]s = p.id AND p.depth = r.depth - 1
+			WHERE l.link_type_id = ?
+				AND l.deleted_at IS NULL
+			ORDER BY r.id, p.id
+		) AS steps ORDER BY depth, id`, from, to)
+	rows, err := r.db.Raw(query, wi.ID, linkTypeID, maxDepth, wi.ID, wi.ID, wi.ID, linkTypeID).Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+
+	result := []TraversedWorkItem{}
+	predecessors := map[uint64]uint64{}
+	for rows.Next() {
+		var item TraversedWorkItem
+		var predecessor uint64
+		if err := rows.Scan(&item.WorkItemID, &item.Depth, &predecessor); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		predecessors[item.WorkItemID] = predecessor
+		result = append(result, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	// the predecessors are one link closer to the start work item, which is
+	// never the predecessor of a work item other than at depth 0
+	for i := range result {
+		path := []uint64{result[i].WorkItemID}
+		for id := result[i].WorkItemID; ; {
+			predecessor, ok := predecessors[id]
+			if !ok {
+				return nil, errors.NewInternalError(fmt.Sprintf("no predecessor of work item %d", id))
+			}
+			id = predecessor
+			path = append([]uint64{id}, path...)
+			if id == wi.ID {
+				break
+			}
+		}
+		result[i].Path = path
+	}
+	return result, nil
+}
@@ -58,6 +58,9 @@ type WorkItemLinkType struct {
 	ReverseName string
 
 	LinkCategoryID satoriuuid.UUID
+
+	// AttributesSchema optionally restricts the attributes of the links of this type
+	AttributesSchema AttributesSchema `sql:"type:jsonb"`
 }
 
 // Ensure Fields implements the Equaler interface
@@ -103,6 +106,14 @@ func (t WorkItemLinkType) Equal(u convert.Equaler) bool {
 	if !satoriuuid.Equal(t.LinkCategoryID, other.LinkCategoryID) {
 		return false
 	}
+	if len(t.AttributesSchema) != len(other.AttributesSchema) {
+		return false
+	}
+	for name, def := range t.AttributesSchema {
+		if otherDef, ok := other.AttributesSchema[name]; !ok || def != otherDef {
+			return false
+		}
+	}
 	return true
 }
 
@@ -130,6 +141,9 @@ func (t *WorkItemLinkType) CheckValidForCreation() error {
 	if t.LinkCategoryID == satoriuuid.Nil {
 		return errors.NewBadParameterError("link_category_id", t.LinkCategoryID)
 	}
+	if err := t.AttributesSchema.Check(); err != nil {
+		return errs.WithStack(err)
+	}
 	return nil
 }
 
@@ -150,6 +164,14 @@ func CheckValidTopology(t string) error {
 // ConvertLinkTypeFromModel converts a work item link type from model to REST representation
 func ConvertLinkTypeFromModel(t WorkItemLinkType) app.WorkItemLinkTypeSingle {
 	id := t.ID.String()
+	var schema map[string]*app.WorkItemLinkAttributeDefinition
+	if len(t.AttributesSchema) > 0 {
+		schema = make(map[string]*app.WorkItemLinkAttributeDefinition, len(t.AttributesSchema))
+		for name, def := range t.AttributesSchema {
+			required := def.Required
+			schema[name] = &app.WorkItemLinkAttributeDefinition{Kind: def.Kind, Required: &required}
+		}
+	}
 	var converted = app.WorkItemLinkTypeSingle{
 		Data: &app.WorkItemLinkTypeData{
 			Type: EndpointWorkItemLinkTypes,
@@ -161,6 +183,8 @@ func ConvertLinkTypeFromModel(t WorkItemLinkType) app.WorkItemLinkTypeSingle {
 				ForwardName: &t.ForwardName,
 				ReverseName: &t.ReverseName,
 				Topology:    &t.Topology,
+
+				AttributesSchema: schema,
 			},
 			Relationships: &app.WorkItemLinkTypeRelationships{
 				LinkCategory: &app.RelationWorkItemLinkCategory{
@@ -247,6 +271,20 @@ func ConvertLinkTypeToModel(in app.WorkItemLinkTypeSingle, out *WorkItemLinkType
 			}
 			out.Topology = *attrs.Topology
 		}
+
+		if attrs.AttributesSchema != nil {
+			schema := AttributesSchema{}
+			for name, def := range attrs.AttributesSchema {
+				if def == nil {
+					continue
+				}
+				schema[name] = AttributeDefinition{Kind: def.Kind, Required: def.Required != nil && *def.Required}
+			}
+			if err := schema.Check(); err != nil {
+				return errs.WithStack(err)
+			}
+			out.AttributesSchema = schema
+		}
 	}
 
 	if rel != nil && rel.LinkCategory != nil && rel.LinkCategory.Data != nil {
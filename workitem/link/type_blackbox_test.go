@@ -96,6 +96,13 @@ func TestWorkItemLinkType_Equal(t *testing.T) {
 	b = a
 	b.LinkCategoryID = satoriuuid.FromStringOrNil("aaa71e36-871b-43a6-9166-0c4bd573eCCC")
 	require.False(t, a.Equal(b))
+
+	// Test AttributesSchema
+	b = a
+	b.AttributesSchema = link.AttributesSchema{"reason": {Kind: link.AttributeKindString}}
+	require.False(t, a.Equal(b))
+	a.AttributesSchema = link.AttributesSchema{"reason": {Kind: link.AttributeKindString, Required: true}}
+	require.False(t, a.Equal(b))
 }
 
 func TestWorkItemLinkTypeCheckValidForCreation(t *testing.T) {
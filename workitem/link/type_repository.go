@@ -16,7 +16,7 @@ import (
 
 // WorkItemLinkTypeRepository encapsulates storage & retrieval of work item link types
 type WorkItemLinkTypeRepository interface {
-	Create(ctx context.Context, name string, description *string, sourceTypeName, targetTypeName, forwardName, reverseName, topology string, linkCategory satoriuuid.UUID) (*app.WorkItemLinkTypeSingle, error)
+	Create(ctx context.Context, name string, description *string, sourceTypeName, targetTypeName, forwardName, reverseName, topology string, linkCategory satoriuuid.UUID, attributesSchema AttributesSchema) (*app.WorkItemLinkTypeSingle, error)
 	Load(ctx context.Context, ID string) (*app.WorkItemLinkTypeSingle, error)
 	List(ctx context.Context) (*app.WorkItemLinkTypeList, error)
 	Delete(ctx context.Context, ID string) error
@@ -41,7 +41,7 @@ type GormWorkItemLinkTypeRepository struct {
 
 // Create creates a new work item link type in the repository.
 // Returns BadParameterError, ConversionError or InternalError
-func (r *GormWorkItemLinkTypeRepository) Create(ctx context.Context, name string, description *string, sourceTypeName, targetTypeName, forwardName, reverseName, topology string, linkCategoryID satoriuuid.UUID) (*app.WorkItemLinkTypeSingle, error) {
+func (r *GormWorkItemLinkTypeRepository) Create(ctx context.Context, name string, description *string, sourceTypeName, targetTypeName, forwardName, reverseName, topology string, linkCategoryID satoriuuid.UUID, attributesSchema AttributesSchema) (*app.WorkItemLinkTypeSingle, error) {
 	linkType := &WorkItemLinkType{
 		Name:           name,
 		Description:    description,
@@ -51,6 +51,8 @@ func (r *GormWorkItemLinkTypeRepository) Create(ctx context.Context, name string
 		ReverseName:    reverseName,
 		Topology:       topology,
 		LinkCategoryID: linkCategoryID,
+
+		AttributesSchema: attributesSchema,
 	}
 	if err := linkType.CheckValidForCreation(); err != nil {
 		return nil, errs.WithStack(err)
@@ -65,6 +67,16 @@ func (r *GormWorkItemLinkTypeRepository) Create(ctx context.Context, name string
 	if db.Error != nil {
 		return nil, errors.NewInternalError(fmt.Sprintf("Failed to find work item link category: %s", db.Error.Error()))
 	}
+	// Check the work item types exist, the types of all spaces with their name may be linked
+	for _, typeName := range []string{linkType.SourceTypeName, linkType.TargetTypeName} {
+		var count int
+		if err := r.db.Model(&workitem.WorkItemType{}).Where("name = ?", typeName).Count(&count).Error; err != nil {
+			return nil, errors.NewInternalError(fmt.Sprintf("Failed to find work item type: %s", err.Error()))
+		}
+		if count == 0 {
+			return nil, errors.NewBadParameterError("work item type", typeName)
+		}
+	}
 	db = r.db.Create(linkType)
 	if db.Error != nil {
 		return nil, errors.NewInternalError(db.Error.Error())
@@ -32,10 +32,27 @@ func (self ListType) Equal(u convert.Equaler) bool {
 // ConvertToModel implements the FieldType interface
 func (fieldType ListType) ConvertToModel(value interface{}) (interface{}, error) {
 	// the assumption is that work item types do not change over time...only new ones can be created
-	return convertList(func(fieldType FieldType, value interface{}) (interface{}, error) {
+	converted, err := convertList(func(fieldType FieldType, value interface{}) (interface{}, error) {
 		return fieldType.ConvertToModel(value)
 	}, fieldType.ComponentType, value)
+	if err == nil && converted != nil && fieldType.ComponentType.GetKind() == KindLabel {
+		// a work item has every label at most once
+		return uniqueValues(converted), nil
+	}
+	return converted, err
+}
 
+// uniqueValues returns the given values without duplicates, in the order of their first occurrence
+func uniqueValues(values []interface{}) []interface{} {
+	result := []interface{}{}
+	seen := map[interface{}]bool{}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
 }
 
 // ConvertFromModel implements the FieldType interface
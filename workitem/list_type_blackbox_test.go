@@ -43,3 +43,21 @@ func TestListType_Equal(t *testing.T) {
 	assert.True(t, d.Equal(a))
 	assert.True(t, a.Equal(d)) // test the inverse
 }
+
+func TestLabelSetConvertToModel(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	labels := ListType{
+		SimpleType:    SimpleType{Kind: KindList},
+		ComponentType: SimpleType{Kind: KindLabel},
+	}
+	a := "40bbdd3d-8b5d-4fd6-ac90-7236b669af04"
+	b := "40bbdd3d-8b5d-4fd6-ac90-7236b669af05"
+	res, err := labels.ConvertToModel([]interface{}{a, b, a})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{a, b}, res)
+
+	_, err = labels.ConvertToModel([]interface{}{"important"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,37 @@
+package workitem
+
+import (
+	"strings"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+)
+
+// CompileOrder takes a list of sort keys and compiles it to sql sort keys, see gormsupport.OrderClause.
+// Each key must either reference a column or a field with a definition in fields that can be ordered.
+// The work item ID is always appended as the last key so that the order is stable across pages.
+func CompileOrder(orderBy []criteria.OrderBy, fields FieldDefinitions) ([]gormsupport.SortKey, error) {
+	keys := []gormsupport.SortKey{}
+	for _, key := range orderBy {
+		var expression string
+		if column, ok := columnName(key.FieldName); ok {
+			expression = column
+		} else {
+			def, ok := fields[key.FieldName]
+			if !ok || strings.Contains(key.FieldName, "'") {
+				return nil, errors.NewBadParameterError("sort", key.FieldName).Expected("a field of a work item type")
+			}
+			if def.Type.GetKind() == KindList {
+				return nil, errors.NewBadParameterError("sort", key.FieldName).Expected("a field that is not of kind list")
+			}
+			expression = jsonFieldValue(key.FieldName, fields)
+		}
+		keys = append(keys, gormsupport.SortKey{Expression: expression, Descending: key.Descending})
+		if key.FieldName == "ID" {
+			// the ID is unique, any further keys would not change the order
+			return keys, nil
+		}
+	}
+	return append(keys, gormsupport.SortKey{Expression: "ID"}), nil
+}
@@ -0,0 +1,40 @@
+package workitem_test
+
+import (
+	"testing"
+
+	. "github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/resource"
+	. "github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileOrder(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expectOrder(t, nil, "ID")
+	expectOrder(t, []OrderBy{Descending(SystemCreatedAt), Ascending(SystemTitle)}, "created_at desc, Fields->>'system.title', ID")
+	expectOrder(t, []OrderBy{Descending("storypoints")}, "(Fields->>'storypoints')::bigint desc, ID")
+	expectOrder(t, []OrderBy{Ascending("system.description"), Descending("ID")}, "(Fields->'system.description'->>'content'), ID desc")
+	expectOrder(t, []OrderBy{Ascending("system.state")}, "Fields->>'system.state', ID")
+}
+
+func TestCompileOrderErrors(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	for _, orderBy := range [][]OrderBy{{Ascending("unknown")}, {Ascending(SystemAssignees)}} {
+		_, err := CompileOrder(orderBy, typedFields)
+		require.NotNil(t, err)
+		_, ok := err.(errors.BadParameterError)
+		assert.True(t, ok, "expected a BadParameterError but got %v", err)
+	}
+}
+
+func expectOrder(t *testing.T, orderBy []OrderBy, expected string) {
+	keys, err := CompileOrder(orderBy, typedFields)
+	require.Nil(t, err)
+	assert.Equal(t, expected, gormsupport.OrderClause(keys, false))
+}
@@ -0,0 +1,148 @@
+package workitem
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/almighty/almighty-core/errors"
+)
+
+// Operations supported in a JSON Patch, see https://tools.ietf.org/html/rfc6902
+const (
+	PatchOpAdd     = "add"
+	PatchOpRemove  = "remove"
+	PatchOpReplace = "replace"
+	PatchOpTest    = "test"
+)
+
+// PatchOperation is a single operation of a JSON Patch document
+type PatchOperation struct {
+	Op    string
+	Path  string
+	Value interface{}
+}
+
+// ApplyPatch applies the operations to a copy of the given document and returns the
+// top level members changed by the operations. Paths either address a member, e.g.
+// "/system.title", or an element of a list member, e.g. "/system.assignees/0". The
+// index "-" appends to a list. Removing a member sets it to nil, members set to nil
+// can't be replaced or removed. The document is not modified and the patch is applied
+// completely or not at all.
+// returns BadParameterError for malformed operations, operations on missing members
+// and failed test operations
+func ApplyPatch(document map[string]interface{}, ops []PatchOperation) (map[string]interface{}, error) {
+	changed := map[string]interface{}{}
+	value := func(name string) interface{} {
+		if v, ok := changed[name]; ok {
+			return v
+		}
+		return document[name]
+	}
+	for _, op := range ops {
+		name, index, err := parsePatchPath(op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if index == nil {
+			switch op.Op {
+			case PatchOpAdd:
+				changed[name] = op.Value
+			case PatchOpReplace:
+				if value(name) == nil {
+					return nil, errors.NewBadParameterError("path", op.Path).Expected("an existing member")
+				}
+				changed[name] = op.Value
+			case PatchOpRemove:
+				if value(name) == nil {
+					return nil, errors.NewBadParameterError("path", op.Path).Expected("an existing member")
+				}
+				changed[name] = nil
+			case PatchOpTest:
+				if !patchValuesEqual(value(name), op.Value) {
+					return nil, errors.NewBadParameterError("value", op.Value).Expected(fmt.Sprintf("the value of %s", op.Path))
+				}
+			default:
+				return nil, errors.NewBadParameterError("op", op.Op).Expected("add, remove, replace or test")
+			}
+			continue
+		}
+
+		list, err := patchList(value(name))
+		if err != nil {
+			return nil, errors.NewBadParameterError("path", op.Path).Expected("a list member")
+		}
+		i := len(list)
+		if *index != "-" {
+			i, err = strconv.Atoi(*index)
+			if err != nil || i < 0 || i > len(list) || (i == len(list) && op.Op != PatchOpAdd) {
+				return nil, errors.NewBadParameterError("path", op.Path).Expected("an index within the list")
+			}
+		} else if op.Op != PatchOpAdd {
+			return nil, errors.NewBadParameterError("path", op.Path).Expected("an index within the list")
+		}
+		switch op.Op {
+		case PatchOpAdd:
+			list = append(list[:i], append([]interface{}{op.Value}, list[i:]...)...)
+		case PatchOpRemove:
+			list = append(list[:i], list[i+1:]...)
+		case PatchOpReplace:
+			list[i] = op.Value
+		case PatchOpTest:
+			if !patchValuesEqual(list[i], op.Value) {
+				return nil, errors.NewBadParameterError("value", op.Value).Expected(fmt.Sprintf("the value of %s", op.Path))
+			}
+			continue
+		default:
+			return nil, errors.NewBadParameterError("op", op.Op).Expected("add, remove, replace or test")
+		}
+		changed[name] = list
+	}
+	return changed, nil
+}
+
+// parsePatchPath splits a JSON pointer into the member name and the optional list index
+func parsePatchPath(path string) (string, *string, error) {
+	segments := strings.Split(path, "/")
+	if len(segments) < 2 || len(segments) > 3 || segments[0] != "" || segments[1] == "" {
+		return "", nil, errors.NewBadParameterError("path", path).Expected("/<field> or /<field>/<index>")
+	}
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	name := unescape.Replace(segments[1])
+	if len(segments) == 2 {
+		return name, nil, nil
+	}
+	return name, &segments[2], nil
+}
+
+// patchList returns a copy of the given list value, nil is treated as an empty list
+func patchList(value interface{}) ([]interface{}, error) {
+	if value == nil {
+		return []interface{}{}, nil
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, errors.NewBadParameterError("value", value)
+	}
+	list := make([]interface{}, v.Len())
+	for i := range list {
+		list[i] = v.Index(i).Interface()
+	}
+	return list, nil
+}
+
+// patchValuesEqual compares the JSON representation of both values so that e.g. numbers
+// compare equal regardless of their go type
+func patchValuesEqual(a, b interface{}) bool {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(ja) == string(jb)
+}
@@ -0,0 +1,86 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	. "github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatch(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	document := map[string]interface{}{
+		"version":         float64(3),
+		SystemTitle:       "title",
+		SystemState:       SystemStateNew,
+		SystemDescription: "description",
+		SystemAssignees:   []interface{}{"A", "B"},
+	}
+
+	changed, err := ApplyPatch(document, []PatchOperation{
+		{Op: PatchOpTest, Path: "/version", Value: 3},
+		{Op: PatchOpAdd, Path: "/system.assignees/-", Value: "C"},
+		{Op: PatchOpRemove, Path: "/system.assignees/0"},
+		{Op: PatchOpAdd, Path: "/system.assignees/0", Value: "D"},
+		{Op: PatchOpReplace, Path: "/system.state", Value: SystemStateOpen},
+		{Op: PatchOpRemove, Path: "/system.description"},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		SystemAssignees:   []interface{}{"D", "B", "C"},
+		SystemState:       SystemStateOpen,
+		SystemDescription: nil,
+	}, changed)
+	// the document itself is left untouched
+	assert.Equal(t, []interface{}{"A", "B"}, document[SystemAssignees])
+
+	changed, err = ApplyPatch(map[string]interface{}{}, []PatchOperation{{Op: PatchOpAdd, Path: "/system.assignees/-", Value: "A"}})
+	require.Nil(t, err)
+	assert.Equal(t, []interface{}{"A"}, changed[SystemAssignees])
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	document := map[string]interface{}{
+		"version":       float64(3),
+		SystemTitle:     "title",
+		SystemAssignees: []interface{}{"A"},
+	}
+
+	t.Log("Failed tests are reported as bad parameters")
+	_, err := ApplyPatch(document, []PatchOperation{{Op: PatchOpTest, Path: "/version", Value: 2}})
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, err = ApplyPatch(document, []PatchOperation{{Op: PatchOpTest, Path: "/system.assignees/0", Value: "B"}})
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	t.Log("Missing members can't be replaced or removed")
+	_, err = ApplyPatch(document, []PatchOperation{{Op: PatchOpReplace, Path: "/system.state", Value: SystemStateOpen}})
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, err = ApplyPatch(document, []PatchOperation{{Op: PatchOpRemove, Path: "/system.description"}})
+	assert.IsType(t, errors.BadParameterError{}, err)
+	_, err = ApplyPatch(document, []PatchOperation{
+		{Op: PatchOpRemove, Path: "/system.title"},
+		{Op: PatchOpReplace, Path: "/system.title", Value: "title"},
+	})
+	assert.IsType(t, errors.BadParameterError{}, err)
+
+	for _, op := range []PatchOperation{
+		{Op: "move", Path: "/system.title"},
+		{Op: PatchOpAdd, Path: "system.title"},
+		{Op: PatchOpAdd, Path: "/"},
+		{Op: PatchOpAdd, Path: "/system.assignees/0/id"},
+		{Op: PatchOpAdd, Path: "/system.title/0", Value: "x"},
+		{Op: PatchOpAdd, Path: "/system.assignees/2", Value: "x"},
+		{Op: PatchOpRemove, Path: "/system.assignees/1"},
+		{Op: PatchOpRemove, Path: "/system.assignees/-"},
+		{Op: PatchOpReplace, Path: "/system.assignees/x", Value: "x"},
+	} {
+		_, err := ApplyPatch(document, []PatchOperation{op})
+		assert.IsType(t, errors.BadParameterError{}, err, "expected a BadParameterError for %v", op)
+	}
+}
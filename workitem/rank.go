@@ -0,0 +1,116 @@
+package workitem
+
+import (
+	"strings"
+
+	"github.com/almighty/almighty-core/errors"
+)
+
+// Ranks order the work items of a backlog. A rank is a string of base 36
+// digits that is read as the fraction 0.<digits>, so that there is always
+// room for another rank between two ranks. Ranks never end with a 0 digit,
+// which makes their lexicographic order (in the "C" collation) the same as
+// their numeric order.
+const (
+	rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+	rankBase   = len(rankDigits)
+	// rankLength is the number of digits of the ranks given to new work
+	// items and assigned when rebalancing
+	rankLength = 8
+	// maxRankLength is the length from which ranks are considered too dense
+	// and all ranks are rebalanced
+	maxRankLength = 32
+	// rankLockID is the first key of the transaction level advisory locks
+	// that serialise the allocation of ranks per space, the second key is
+	// derived from the space, see migration.AdvisoryLockID
+	rankLockID = 43
+)
+
+// Directions in which a work item can be moved relative to another one
+const (
+	ReorderAbove = "above"
+	ReorderBelow = "below"
+)
+
+func rankDigit(rank string, i int) int {
+	if i >= len(rank) {
+		return 0
+	}
+	return strings.IndexByte(rankDigits, rank[i])
+}
+
+// validRank returns true if the rank only consists of rank digits and
+// doesn't end with 0
+func validRank(rank string) bool {
+	for i := range rank {
+		if rankDigit(rank, i) < 0 {
+			return false
+		}
+	}
+	return !strings.HasSuffix(rank, "0")
+}
+
+// RankBetween returns a rank that sorts strictly between lower and upper. An
+// empty lower rank stands for the start and an empty upper rank for the end of
+// the backlog.
+// returns BadParameterError if the ranks are malformed or lower doesn't sort
+// before upper
+func RankBetween(lower string, upper string) (string, error) {
+	if !validRank(lower) {
+		return "", errors.NewBadParameterError("rank", lower).Expected("a rank of the digits " + rankDigits)
+	}
+	if !validRank(upper) {
+		return "", errors.NewBadParameterError("rank", upper).Expected("a rank of the digits " + rankDigits)
+	}
+	if upper != "" && lower >= upper {
+		return "", errors.NewBadParameterError("rank", upper).Expected("a rank after " + lower)
+	}
+	result := []byte{}
+	bounded := upper != ""
+	for i := 0; ; i++ {
+		lo := rankDigit(lower, i)
+		hi := rankBase
+		if bounded {
+			hi = rankDigit(upper, i)
+		}
+		switch {
+		case hi-lo > 1:
+			return string(append(result, rankDigits[(lo+hi)/2])), nil
+		case hi-lo == 1:
+			// any rank with this prefix is below upper, the remaining digits
+			// only need to be above the remaining digits of lower
+			bounded = false
+		}
+		result = append(result, rankDigits[lo])
+	}
+}
+
+// RankAfter returns a rank that sorts after the given one and leaves room for
+// other ranks in between. Ranks returned for the same lower rank are at most
+// rankLength digits long, so appending work items never makes ranks dense.
+func RankAfter(lower string) string {
+	digits := make([]int, rankLength)
+	for i := range digits {
+		digits[i] = rankDigit(lower, i)
+	}
+	// add one at the last digit
+	for i := rankLength - 1; i >= 0; i-- {
+		digits[i]++
+		if digits[i] < rankBase {
+			return formatRank(digits)
+		}
+		digits[i] = 0
+	}
+	// lower is as close to the end as rankLength digits allow
+	result, _ := RankBetween(lower, "")
+	return result
+}
+
+// formatRank renders the digits as a rank without trailing zeros
+func formatRank(digits []int) string {
+	result := make([]byte, len(digits))
+	for i, d := range digits {
+		result[i] = rankDigits[d]
+	}
+	return strings.TrimRight(string(result), "0")
+}
@@ -0,0 +1,70 @@
+package workitem
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRankBetween(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	for _, bounds := range [][2]string{
+		{"", ""},
+		{"", "1"},
+		{"a", ""},
+		{"a", "a1"},
+		{"a", "a5"},
+		{"a", "b"},
+		{"azz", "b"},
+		{"zz", ""},
+		{"00001", "00002"},
+	} {
+		rank, err := RankBetween(bounds[0], bounds[1])
+		require.Nil(t, err, "%v", bounds)
+		assert.True(t, bounds[0] < rank, "%q < %q", bounds[0], rank)
+		if bounds[1] != "" {
+			assert.True(t, rank < bounds[1], "%q < %q", rank, bounds[1])
+		}
+		assert.True(t, validRank(rank), rank)
+	}
+
+	for _, bounds := range [][2]string{{"b", "a"}, {"a", "a"}, {"a0", ""}, {"", "A"}} {
+		_, err := RankBetween(bounds[0], bounds[1])
+		assert.NotNil(t, err, "%v", bounds)
+	}
+}
+
+func TestRankBetweenGetsDense(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	// moving work items to the top again and again makes the ranks longer
+	upper := RankAfter("")
+	for i := 0; i < 200; i++ {
+		rank, err := RankBetween("", upper)
+		require.Nil(t, err)
+		require.True(t, rank < upper)
+		upper = rank
+	}
+	assert.True(t, len(upper) > maxRankLength)
+}
+
+func TestRankAfter(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	rank := ""
+	for i := 0; i < 1000; i++ {
+		next := RankAfter(rank)
+		require.True(t, rank < next, "%q < %q", rank, next)
+		require.True(t, len(next) <= rankLength)
+		require.True(t, validRank(next))
+		rank = next
+	}
+	assert.Equal(t, "zzzzzzzzi", RankAfter("zzzzzzzz"))
+	assert.Equal(t, "b", RankAfter("azzzzzzz"))
+}
@@ -0,0 +1,116 @@
+package workitem
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// RevisionType defines the type of change that was made to a work item in a revision
+type RevisionType int
+
+// The types of revisions
+const (
+	RevisionTypeCreate RevisionType = iota + 1 // 1
+	RevisionTypeUpdate                         // 2
+	RevisionTypeDelete                         // 3
+)
+
+// String returns the name of the revision type as used in the REST API
+func (t RevisionType) String() string {
+	switch t {
+	case RevisionTypeCreate:
+		return "create"
+	case RevisionTypeUpdate:
+		return "update"
+	case RevisionTypeDelete:
+		return "delete"
+	}
+	return "unknown"
+}
+
+// Revision records a single change of a work item: who made it, when, and the
+// field values before and after the change
+type Revision struct {
+	ID   uuid.UUID    `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	Time time.Time    `gorm:"column:revision_time"`
+	Type RevisionType `gorm:"column:revision_type"`
+	// the identity that made the change, uuid.Nil if it is unknown, e.g. for imported work items
+	ModifierIdentity uuid.UUID `sql:"type:uuid" gorm:"column:modifier_id"`
+	WorkItemID       uint64
+	WorkItemType     string
+	// the version of the work item after the change
+	WorkItemVersion int
+	// the field values before the change, nil for created work items
+	OldFields Fields `sql:"type:jsonb"`
+	// the field values after the change, nil for deleted work items
+	Fields Fields `sql:"type:jsonb"`
+}
+
+// TableName implements gorm.tabler
+func (r Revision) TableName() string {
+	return "work_item_revisions"
+}
+
+// RevisionRepository encapsulates storage & retrieval of work item revisions
+type RevisionRepository interface {
+	Create(ctx context.Context, modifierID uuid.UUID, revisionType RevisionType, old *WorkItem, wi *WorkItem) error
+	List(ctx context.Context, workitemID string) ([]*Revision, error)
+}
+
+// NewRevisionRepository creates a GormRevisionRepository
+func NewRevisionRepository(db *gorm.DB) *GormRevisionRepository {
+	return &GormRevisionRepository{db}
+}
+
+// GormRevisionRepository implements RevisionRepository using gorm
+type GormRevisionRepository struct {
+	db *gorm.DB
+}
+
+// Create records a revision of the given work item. old is the work item before the change
+// and nil for created work items, wi is the work item after the change.
+// returns InternalError
+func (r *GormRevisionRepository) Create(ctx context.Context, modifierID uuid.UUID, revisionType RevisionType, old *WorkItem, wi *WorkItem) error {
+	revision := &Revision{
+		ID:               uuid.NewV4(),
+		Time:             time.Now(),
+		Type:             revisionType,
+		ModifierIdentity: modifierID,
+		WorkItemID:       wi.ID,
+		WorkItemType:     wi.Type,
+		WorkItemVersion:  wi.Version,
+	}
+	if old != nil {
+		revision.OldFields = old.Fields
+	}
+	if revisionType != RevisionTypeDelete {
+		revision.Fields = wi.Fields
+	}
+	if err := r.db.Create(revision).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	log.Printf("recorded %s revision of work item %d", revisionType, wi.ID)
+	return nil
+}
+
+// List returns the revisions of the work item with the given id, the oldest first.
+// Work items created before revisions were recorded may have none.
+// returns NotFoundError or InternalError
+func (r *GormRevisionRepository) List(ctx context.Context, workitemID string) ([]*Revision, error) {
+	id, err := strconv.ParseUint(workitemID, 10, 64)
+	if err != nil || id == 0 {
+		// treating this as a not found error: the fact that we're using number internal is implementation detail
+		return nil, errors.NewNotFoundError("work item", workitemID)
+	}
+	var revisions []*Revision
+	if err := r.db.Where("work_item_id = ?", id).Order("revision_time, work_item_version").Find(&revisions).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return revisions, nil
+}
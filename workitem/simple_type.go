@@ -10,6 +10,7 @@ import (
 	"github.com/almighty/almighty-core/rendering"
 	"github.com/asaskevich/govalidator"
 	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 )
 
 // SimpleType is an unstructured FieldType
@@ -66,7 +67,7 @@ func (fieldType SimpleType) ConvertToModel(value interface{}) (interface{}, erro
 		return value, nil
 	case KindInstant:
 		// instant == milliseconds
-		if !valueType.Implements(timeType) {
+		if valueType != timeType {
 			return nil, fmt.Errorf("value %v should be %s, but is %s", value, "time.Time", valueType.Name())
 		}
 		return value.(time.Time).UnixNano(), nil
@@ -84,6 +85,33 @@ func (fieldType SimpleType) ConvertToModel(value interface{}) (interface{}, erro
 	case KindEnum:
 		// to be done yet | not sure what to write here as of now.
 		return value, nil
+	case KindBoolean:
+		if valueType.Kind() != reflect.Bool {
+			return nil, fmt.Errorf("value %v should be %s, but is %s", value, "bool", valueType.Name())
+		}
+		return value, nil
+	case KindDate:
+		// dates are stored in the DateFormat, which sorts like the dates
+		switch v := value.(type) {
+		case time.Time:
+			return v.Format(DateFormat), nil
+		case string:
+			if _, err := time.Parse(DateFormat, v); err != nil {
+				return nil, fmt.Errorf("value %v should be a date formatted as %s", value, DateFormat)
+			}
+			return v, nil
+		}
+		return nil, fmt.Errorf("value %v should be %s, but is %s", value, "date", valueType.Name())
+	case KindLabel, KindArea:
+		// the existence of the referenced label or area is checked by the repository
+		if valueType.Kind() != reflect.String {
+			return nil, fmt.Errorf("value %v should be %s, but is %s", value, "string", valueType.Name())
+		}
+		id, err := uuid.FromString(value.(string))
+		if err != nil {
+			return nil, fmt.Errorf("value %v should be the ID of a %s", value, fieldType.GetKind())
+		}
+		return id.String(), nil
 	case KindMarkup:
 		// 'markup' is just a string in the API layer for now:
 		// it corresponds to the MarkupContent.Content field. The MarkupContent.Markup is set to the default value
@@ -106,7 +134,7 @@ func (fieldType SimpleType) ConvertFromModel(value interface{}) (interface{}, er
 	}
 	valueType := reflect.TypeOf(value)
 	switch fieldType.GetKind() {
-	case KindString, KindURL, KindUser, KindInteger, KindFloat, KindDuration, KindIteration:
+	case KindString, KindURL, KindUser, KindInteger, KindFloat, KindDuration, KindIteration, KindBoolean, KindDate, KindLabel, KindArea:
 		return value, nil
 	case KindInstant:
 		return time.Unix(0, value.(int64)), nil
@@ -2,6 +2,7 @@ package workitem_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/almighty/almighty-core/convert"
 	"github.com/almighty/almighty-core/resource"
@@ -38,3 +39,41 @@ func TestConvertToModel(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Nil(t, res)
 }
+
+func TestConvertBooleanDateLabelAreaToModel(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	id := "40bbdd3d-8b5d-4fd6-ac90-7236b669af04"
+	for name, test := range map[string]struct {
+		kind     Kind
+		value    interface{}
+		expected interface{}
+	}{
+		"boolean":        {KindBoolean, true, true},
+		"date":           {KindDate, "2017-03-01", "2017-03-01"},
+		"date from time": {KindDate, time.Date(2017, 3, 1, 23, 0, 0, 0, time.UTC), "2017-03-01"},
+		"label":          {KindLabel, id, id},
+		"area":           {KindArea, id, id},
+	} {
+		res, err := SimpleType{Kind: test.kind}.ConvertToModel(test.value)
+		assert.Nil(t, err, name)
+		assert.Equal(t, test.expected, res, name)
+		res, err = SimpleType{Kind: test.kind}.ConvertFromModel(res)
+		assert.Nil(t, err, name)
+		assert.Equal(t, test.expected, res, name)
+	}
+	for name, test := range map[string]struct {
+		kind  Kind
+		value interface{}
+	}{
+		"boolean as string": {KindBoolean, "true"},
+		"date with time":    {KindDate, "2017-03-01T12:00:00Z"},
+		"invalid date":      {KindDate, "2017-02-30"},
+		"date as number":    {KindDate, 20170301},
+		"label name":        {KindLabel, "important"},
+		"area as number":    {KindArea, 42},
+	} {
+		_, err := SimpleType{Kind: test.kind}.ConvertToModel(test.value)
+		assert.NotNil(t, err, name)
+	}
+}
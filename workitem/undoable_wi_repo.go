@@ -14,6 +14,7 @@ import (
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/jinzhu/gorm"
 	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 )
 
 var _ WorkItemRepository = &UndoableWorkItemRepository{}
@@ -36,7 +37,7 @@ func (r *UndoableWorkItemRepository) Load(ctx context.Context, ID string) (*app.
 }
 
 // Save implements application.WorkItemRepository
-func (r *UndoableWorkItemRepository) Save(ctx context.Context, wi app.WorkItem) (*app.WorkItem, error) {
+func (r *UndoableWorkItemRepository) Save(ctx context.Context, wi app.WorkItem, modifierID uuid.UUID) (*app.WorkItem, error) {
 	id, err := strconv.ParseUint(wi.ID, 10, 64)
 	if err != nil {
 		// treating this as a not found error: the fact that we're using number internal is implementation detail
@@ -50,7 +51,7 @@ func (r *UndoableWorkItemRepository) Save(ctx context.Context, wi app.WorkItem)
 		return nil, errors.NewInternalError(fmt.Sprintf("could not load %s, %s", wi.ID, db.Error.Error()))
 	}
 
-	res, err := r.wrapped.Save(ctx, wi)
+	res, err := r.wrapped.Save(ctx, wi, modifierID)
 	if err == nil {
 		r.undo.Append(func(db *gorm.DB) error {
 			db = db.Save(&old)
@@ -60,8 +61,25 @@ func (r *UndoableWorkItemRepository) Save(ctx context.Context, wi app.WorkItem)
 	return res, errs.WithStack(err)
 }
 
+// Update implements application.WorkItemRepository
+func (r *UndoableWorkItemRepository) Update(ctx context.Context, ID string, version int, fields map[string]interface{}, modifierID uuid.UUID) (*app.WorkItem, error) {
+	old, err := r.wrapped.LoadFromDB(ID)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+
+	res, err := r.wrapped.Update(ctx, ID, version, fields, modifierID)
+	if err == nil {
+		r.undo.Append(func(db *gorm.DB) error {
+			db = db.Save(old)
+			return db.Error
+		})
+	}
+	return res, errs.WithStack(err)
+}
+
 // Delete implements application.WorkItemRepository
-func (r *UndoableWorkItemRepository) Delete(ctx context.Context, ID string) error {
+func (r *UndoableWorkItemRepository) Delete(ctx context.Context, ID string, modifierID uuid.UUID) error {
 	id, err := strconv.ParseUint(ID, 10, 64)
 	if err != nil {
 		// treating this as a not found error: the fact that we're using number internal is implementation detail
@@ -75,7 +93,7 @@ func (r *UndoableWorkItemRepository) Delete(ctx context.Context, ID string) erro
 		return errors.NewInternalError(fmt.Sprintf("could not load %s, %s", ID, db.Error.Error()))
 	}
 
-	err = r.wrapped.Delete(ctx, ID)
+	err = r.wrapped.Delete(ctx, ID, modifierID)
 	if err == nil {
 		r.undo.Append(func(db *gorm.DB) error {
 			old.DeletedAt = nil
@@ -87,8 +105,8 @@ func (r *UndoableWorkItemRepository) Delete(ctx context.Context, ID string) erro
 }
 
 // Create implements application.WorkItemRepository
-func (r *UndoableWorkItemRepository) Create(ctx context.Context, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error) {
-	result, err := r.wrapped.Create(ctx, typeID, fields, creator)
+func (r *UndoableWorkItemRepository) Create(ctx context.Context, spaceID *uuid.UUID, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error) {
+	result, err := r.wrapped.Create(ctx, spaceID, typeID, fields, creator)
 	if err != nil {
 		return result, errs.WithStack(err)
 	}
@@ -109,6 +127,33 @@ func (r *UndoableWorkItemRepository) Create(ctx context.Context, typeID string,
 }
 
 // List implements application.WorkItemRepository
-func (r *UndoableWorkItemRepository) List(ctx context.Context, criteria criteria.Expression, start *int, length *int) ([]*app.WorkItem, uint64, error) {
-	return r.wrapped.List(ctx, criteria, start, length)
+func (r *UndoableWorkItemRepository) List(ctx context.Context, criteria criteria.Expression, orderBy []criteria.OrderBy, start *int, length *int, cursor *gormsupport.Cursor) ([]*app.WorkItem, uint64, *gormsupport.PageCursors, error) {
+	return r.wrapped.List(ctx, criteria, orderBy, start, length, cursor)
+}
+
+// Aggregate implements application.WorkItemRepository
+func (r *UndoableWorkItemRepository) Aggregate(ctx context.Context, criteria criteria.Expression, groupBy string) ([]Bucket, uint64, error) {
+	return r.wrapped.Aggregate(ctx, criteria, groupBy)
+}
+
+// NextStates implements application.WorkItemRepository
+func (r *UndoableWorkItemRepository) NextStates(ctx context.Context, ID string) ([]NextState, error) {
+	return r.wrapped.NextStates(ctx, ID)
+}
+
+// Reorder implements application.WorkItemRepository
+func (r *UndoableWorkItemRepository) Reorder(ctx context.Context, ID string, direction string, otherID string) (*app.WorkItem, error) {
+	old, err := r.wrapped.LoadFromDB(ID)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+
+	res, err := r.wrapped.Reorder(ctx, ID, direction, otherID)
+	if err == nil {
+		r.undo.Append(func(db *gorm.DB) error {
+			db = db.Model(old).UpdateColumn("rank", old.Rank)
+			return db.Error
+		})
+	}
+	return res, errs.WithStack(err)
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
+	satoriuuid "github.com/satori/go.uuid"
 )
 
 var _ WorkItemTypeRepository = &UndoableWorkItemTypeRepository{}
@@ -24,23 +25,60 @@ type UndoableWorkItemTypeRepository struct {
 }
 
 // Load implements application.WorkItemTypeRepository
-func (r *UndoableWorkItemTypeRepository) Load(ctx context.Context, name string) (*app.WorkItemType, error) {
-	return r.wrapped.Load(ctx, name)
+func (r *UndoableWorkItemTypeRepository) Load(ctx context.Context, spaceID *satoriuuid.UUID, name string) (*app.WorkItemType, error) {
+	return r.wrapped.Load(ctx, spaceID, name)
 }
 
 // List implements application.WorkItemTypeRepository
-func (r *UndoableWorkItemTypeRepository) List(ctx context.Context, start *int, length *int) ([]*app.WorkItemType, error) {
-	return r.wrapped.List(ctx, start, length)
+func (r *UndoableWorkItemTypeRepository) List(ctx context.Context, spaceID *satoriuuid.UUID, start *int, length *int) ([]*app.WorkItemType, error) {
+	return r.wrapped.List(ctx, spaceID, start, length)
 }
 
 // Create implements application.WorkItemTypeRepository
-func (r *UndoableWorkItemTypeRepository) Create(ctx context.Context, extendedTypeID *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error) {
-	res, err := r.wrapped.Create(ctx, extendedTypeID, name, fields)
+func (r *UndoableWorkItemTypeRepository) Create(ctx context.Context, spaceID *satoriuuid.UUID, extendedTypeID *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error) {
+	res, err := r.wrapped.Create(ctx, spaceID, extendedTypeID, name, fields)
 	if err == nil {
 		r.undo.Append(func(db *gorm.DB) error {
-			db = db.Unscoped().Delete(&WorkItemType{Name: name})
+			db = db.Unscoped().Where("name = ?", name)
+			if spaceID != nil {
+				db = db.Where("space_id = ?", *spaceID)
+			} else {
+				db = db.Where("space_id IS NULL")
+			}
+			db = db.Delete(&WorkItemType{})
 			return db.Error
 		})
 	}
 	return res, errors.WithStack(err)
 }
+
+// Update implements application.WorkItemTypeRepository
+func (r *UndoableWorkItemTypeRepository) Update(ctx context.Context, spaceID *satoriuuid.UUID, name string, version int, fields map[string]app.FieldDefinition, removedFields []string, workflow *app.Workflow) (*app.WorkItemType, error) {
+	old, err := r.wrapped.LoadTypeFromDB(spaceID, name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	subtypes := r.wrapped.db.Where("path <@ ?::ltree", old.Path)
+	if old.SpaceID != nil {
+		subtypes = subtypes.Where("space_id = ?", *old.SpaceID)
+	}
+	var types []WorkItemType
+	if err := subtypes.Find(&types).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res, err := r.wrapped.Update(ctx, spaceID, name, version, fields, removedFields, workflow)
+	if err == nil {
+		// the values of removed fields cannot be restored
+		r.undo.Append(func(db *gorm.DB) error {
+			for _, t := range types {
+				db = db.Model(&WorkItemType{}).Where("id = ?", t.ID).Updates(map[string]interface{}{"version": t.Version, "fields": t.Fields, "workflow": t.Workflow})
+				if db.Error != nil {
+					return db.Error
+				}
+			}
+			ClearGlobalWorkItemTypeCache()
+			return nil
+		})
+	}
+	return res, errors.WithStack(err)
+}
@@ -0,0 +1,214 @@
+package workitem
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/query"
+)
+
+// Workflow restricts how the system.state field of the work items of a type
+// may change. A workflow without transitions allows every change of the state.
+type Workflow struct {
+	// Transitions are the allowed changes of the state
+	Transitions []Transition `json:"transitions,omitempty"`
+	// RequiredFields lists per state the fields that must have a value when
+	// a work item enters the state
+	RequiredFields map[string][]string `json:"requiredFields,omitempty"`
+}
+
+// Transition allows work items to change from one state to another
+type Transition struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Guard is an optional filter expression (see package query) that the
+	// work item must match after the transition
+	Guard string `json:"guard,omitempty"`
+}
+
+// NextState is a state a work item may change to
+type NextState struct {
+	State string
+	// RequiredFields must have a value when the work item enters the state
+	RequiredFields []string
+}
+
+// IsEmpty returns true if the workflow does not restrict the state at all
+func (w Workflow) IsEmpty() bool {
+	return len(w.Transitions) == 0 && len(w.RequiredFields) == 0
+}
+
+// Value implements driver.Valuer, empty workflows are stored as NULL
+func (w Workflow) Value() (driver.Value, error) {
+	if w.IsEmpty() {
+		return nil, nil
+	}
+	return toBytes(w)
+}
+
+// Scan implements sql.Scanner
+func (w *Workflow) Scan(src interface{}) error {
+	*w = Workflow{}
+	return fromBytes(src, w)
+}
+
+// Validate returns an error if the workflow does not fit the given fields of
+// its work item type: the states must be values of the system.state field,
+// the required fields must exist and the guards must be valid filters.
+// returns BadParameterError
+func (w Workflow) Validate(fields FieldDefinitions) error {
+	if w.IsEmpty() {
+		return nil
+	}
+	stateDef, ok := fields[SystemState]
+	if !ok {
+		return errors.NewBadParameterError("workflow", w).Expected("a work item type with a " + SystemState + " field")
+	}
+	isState := func(state string) bool { return state != "" }
+	if enum, ok := stateDef.Type.(EnumType); ok {
+		isState = func(state string) bool { return contains(enum.Values, state) }
+	}
+	for i, t := range w.Transitions {
+		if !isState(t.From) {
+			return errors.NewBadParameterError(fmt.Sprintf("workflow.transitions[%d].from", i), t.From).Expected("a value of " + SystemState)
+		}
+		if !isState(t.To) {
+			return errors.NewBadParameterError(fmt.Sprintf("workflow.transitions[%d].to", i), t.To).Expected("a value of " + SystemState)
+		}
+		if t.Guard != "" {
+			exp, err := query.Parse(&t.Guard)
+			if err != nil {
+				return errors.NewBadParameterError(fmt.Sprintf("workflow.transitions[%d].guard", i), t.Guard).Expected(err.Error())
+			}
+			if _, _, compileErrors := CompileWithFields(exp, fields); len(compileErrors) > 0 {
+				return errors.NewBadParameterError(fmt.Sprintf("workflow.transitions[%d].guard", i), t.Guard).Expected(compileErrors[0].Error())
+			}
+		}
+	}
+	for state, required := range w.RequiredFields {
+		if !isState(state) {
+			return errors.NewBadParameterError("workflow.requiredFields", state).Expected("a value of " + SystemState)
+		}
+		for _, field := range required {
+			if _, ok := fields[field]; !ok {
+				return errors.NewBadParameterError("workflow.requiredFields."+state, field).Expected("a field of the work item type")
+			}
+		}
+	}
+	return nil
+}
+
+// CheckStateChange returns an error if the workflow does not allow a work
+// item to change from the old to the new fields; old is nil for new work
+// items. Otherwise it returns the guards of the transition, one of which the
+// work item must match. No guards are returned for unguarded transitions.
+// returns BadParameterError
+func (w Workflow) CheckStateChange(old Fields, changed Fields) ([]string, error) {
+	newState := stateName(changed[SystemState])
+	if old != nil && stateName(old[SystemState]) == newState {
+		return nil, nil
+	}
+	var guards []string
+	if old != nil && len(w.Transitions) > 0 {
+		oldState := stateName(old[SystemState])
+		if !w.hasTransition(oldState, newState) {
+			next := []string{}
+			for _, s := range w.nextStates(oldState) {
+				next = append(next, s.State)
+			}
+			if len(next) == 0 {
+				return nil, errors.NewBadParameterError(SystemState, newState).Expected(fmt.Sprintf("no change of the final state %q", oldState))
+			}
+			return nil, errors.NewBadParameterError(SystemState, newState).Expected(fmt.Sprintf("one of the next states of %q: %s", oldState, strings.Join(next, ", ")))
+		}
+		guards = w.guards(oldState, newState)
+	}
+	for _, field := range w.RequiredFields[newState] {
+		if isEmptyValue(changed[field]) {
+			return nil, errors.NewBadParameterError(field, changed[field]).Expected(fmt.Sprintf("a value when entering the state %q", newState))
+		}
+	}
+	return guards, nil
+}
+
+func (w Workflow) hasTransition(from string, to string) bool {
+	for _, t := range w.Transitions {
+		if t.From == from && t.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// nextStates returns the states the transitions lead to from the given state
+// in the order of the transitions, together with their required fields
+func (w Workflow) nextStates(from string) []NextState {
+	result := []NextState{}
+	seen := map[string]bool{}
+	for _, t := range w.Transitions {
+		if t.From != from || seen[t.To] {
+			continue
+		}
+		seen[t.To] = true
+		result = append(result, NextState{State: t.To, RequiredFields: w.RequiredFields[t.To]})
+	}
+	return result
+}
+
+// guards returns the guards of the transitions from one state to another,
+// none if one of the transitions is unguarded
+func (w Workflow) guards(from string, to string) []string {
+	var result []string
+	for _, t := range w.Transitions {
+		if t.From == from && t.To == to {
+			if t.Guard == "" {
+				return nil
+			}
+			result = append(result, t.Guard)
+		}
+	}
+	return result
+}
+
+// stateName returns the state as stored in the system.state field, "" if there is none
+func stateName(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return false
+}
+
+// parseGuards combines the given guards into a single expression that
+// matches if any of them matches
+// returns BadParameterError
+func parseGuards(guards []string) (criteria.Expression, error) {
+	var result criteria.Expression
+	for _, guard := range guards {
+		exp, err := query.Parse(&guard)
+		if err != nil {
+			return nil, errors.NewBadParameterError("guard", guard).Expected(err.Error())
+		}
+		if result == nil {
+			result = exp
+		} else {
+			result = criteria.Or(result, exp)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	. "github.com/almighty/almighty-core/workitem"
+	errs "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testWorkflow = Workflow{
+	Transitions: []Transition{
+		{From: "new", To: "in progress"},
+		{From: "in progress", To: "resolved"},
+		{From: "resolved", To: "closed"},
+		{From: "resolved", To: "in progress", Guard: `{"resolution":"not fixed"}`},
+	},
+	RequiredFields: map[string][]string{
+		"resolved": {"resolution"},
+	},
+}
+
+func TestWorkflowValidate(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	str := SimpleType{Kind: KindString}
+	fields := FieldDefinitions{
+		SystemState:  {Required: true, Type: EnumType{SimpleType: SimpleType{Kind: KindEnum}, BaseType: str, Values: []interface{}{"new", "in progress", "resolved", "closed"}}},
+		"resolution": {Type: str},
+	}
+	assert.Nil(t, testWorkflow.Validate(fields))
+	assert.Nil(t, Workflow{}.Validate(FieldDefinitions{}))
+
+	for name, w := range map[string]Workflow{
+		"unknown from state": {Transitions: []Transition{{From: "open", To: "new"}}},
+		"unknown to state":   {Transitions: []Transition{{From: "new", To: "open"}}},
+		"invalid guard":      {Transitions: []Transition{{From: "new", To: "closed", Guard: `{"resolution":`}}},
+		"unknown state":      {RequiredFields: map[string][]string{"open": {"resolution"}}},
+		"unknown field":      {RequiredFields: map[string][]string{"closed": {"reason"}}},
+	} {
+		err := w.Validate(fields)
+		require.NotNil(t, err, name)
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err), name)
+	}
+
+	err := testWorkflow.Validate(FieldDefinitions{"resolution": {Type: str}})
+	assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+}
+
+func TestWorkflowCheckStateChange(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	guards, err := testWorkflow.CheckStateChange(Fields{SystemState: "new"}, Fields{SystemState: "in progress"})
+	assert.Nil(t, err)
+	assert.Empty(t, guards)
+
+	// unchanged states are always allowed
+	guards, err = testWorkflow.CheckStateChange(Fields{SystemState: "closed", SystemTitle: "a"}, Fields{SystemState: "closed", SystemTitle: "b"})
+	assert.Nil(t, err)
+	assert.Empty(t, guards)
+
+	guards, err = testWorkflow.CheckStateChange(Fields{SystemState: "resolved"}, Fields{SystemState: "in progress"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{`{"resolution":"not fixed"}`}, guards)
+
+	_, err = testWorkflow.CheckStateChange(Fields{SystemState: "new"}, Fields{SystemState: "closed"})
+	require.NotNil(t, err)
+	assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	assert.Contains(t, err.Error(), "in progress")
+
+	_, err = testWorkflow.CheckStateChange(Fields{SystemState: "closed"}, Fields{SystemState: "new"})
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "final state")
+
+	_, err = testWorkflow.CheckStateChange(Fields{SystemState: "in progress"}, Fields{SystemState: "resolved"})
+	require.NotNil(t, err)
+	assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	assert.Contains(t, err.Error(), "resolution")
+
+	_, err = testWorkflow.CheckStateChange(Fields{SystemState: "in progress"}, Fields{SystemState: "resolved", "resolution": "fixed"})
+	assert.Nil(t, err)
+
+	// new work items may start in any state but need its required fields
+	_, err = testWorkflow.CheckStateChange(nil, Fields{SystemState: "resolved"})
+	assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	_, err = testWorkflow.CheckStateChange(nil, Fields{SystemState: "closed"})
+	assert.Nil(t, err)
+
+	// without transitions every change is allowed
+	_, err = Workflow{}.CheckStateChange(Fields{SystemState: "closed"}, Fields{SystemState: "new"})
+	assert.Nil(t, err)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/almighty/almighty-core/convert"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
+	uuid "github.com/satori/go.uuid"
 )
 
 // WorkItem represents a work item as it is stored in the database
@@ -14,10 +15,14 @@ type WorkItem struct {
 	ID uint64 `gorm:"primary_key"`
 	// Id of the type of this work item
 	Type string
+	// the space this work item belongs to, its type is a system type or a type of this space
+	SpaceID *uuid.UUID `sql:"type:uuid"`
 	// Version for optimistic concurrency control
 	Version int
 	// the field values
 	Fields Fields `sql:"type:jsonb"`
+	// Rank orders the work items of a backlog, see RankBetween
+	Rank string
 }
 
 // TableName implements gorm.tabler
@@ -42,12 +47,18 @@ func (wi WorkItem) Equal(u convert.Equaler) bool {
 	if wi.Type != other.Type {
 		return false
 	}
+	if (wi.SpaceID == nil) != (other.SpaceID == nil) || (wi.SpaceID != nil && !uuid.Equal(*wi.SpaceID, *other.SpaceID)) {
+		return false
+	}
 	if wi.ID != other.ID {
 		return false
 	}
 	if wi.Version != other.Version {
 		return false
 	}
+	if wi.Rank != other.Rank {
+		return false
+	}
 	return wi.Fields.Equal(other.Fields)
 }
 
@@ -1,32 +1,56 @@
 package workitem
 
 import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
 	"log"
 	"strconv"
+	"strings"
+	"time"
 
 	"golang.org/x/net/context"
 
+	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/area"
 	"github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/label"
 	"github.com/almighty/almighty-core/rendering"
 	"github.com/jinzhu/gorm"
 	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 )
 
 // WorkItemRepository encapsulates storage & retrieval of work items
 type WorkItemRepository interface {
 	Load(ctx context.Context, ID string) (*app.WorkItem, error)
-	Save(ctx context.Context, wi app.WorkItem) (*app.WorkItem, error)
-	Delete(ctx context.Context, ID string) error
-	Create(ctx context.Context, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error)
-	List(ctx context.Context, criteria criteria.Expression, start *int, length *int) ([]*app.WorkItem, uint64, error)
+	Save(ctx context.Context, wi app.WorkItem, modifierID uuid.UUID) (*app.WorkItem, error)
+	Update(ctx context.Context, ID string, version int, fields map[string]interface{}, modifierID uuid.UUID) (*app.WorkItem, error)
+	Delete(ctx context.Context, ID string, modifierID uuid.UUID) error
+	Create(ctx context.Context, spaceID *uuid.UUID, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error)
+	List(ctx context.Context, criteria criteria.Expression, orderBy []criteria.OrderBy, start *int, length *int, cursor *gormsupport.Cursor) ([]*app.WorkItem, uint64, *gormsupport.PageCursors, error)
+	Aggregate(ctx context.Context, criteria criteria.Expression, groupBy string) ([]Bucket, uint64, error)
+	NextStates(ctx context.Context, ID string) ([]NextState, error)
+	Reorder(ctx context.Context, ID string, direction string, otherID string) (*app.WorkItem, error)
+}
+
+// Bucket holds the number of work items that have a certain value in the field they are grouped by
+type Bucket struct {
+	// Value is the value of the field, nil for the work items without a value
+	Value interface{}
+	Count uint64
 }
 
 // GormWorkItemRepository implements WorkItemRepository using gorm
 type GormWorkItemRepository struct {
 	db  *gorm.DB
 	wir *GormWorkItemTypeRepository
+	rr  *GormRevisionRepository
 }
 
 // LoadFromDB returns the work item with the given ID in model representation.
@@ -56,24 +80,21 @@ func (r *GormWorkItemRepository) Load(ctx context.Context, ID string) (*app.Work
 	if err != nil {
 		return nil, errs.WithStack(err)
 	}
-	wiType, err := r.wir.LoadTypeFromDB(res.Type)
+	wiType, err := r.wir.LoadTypeFromDB(res.SpaceID, res.Type)
 	if err != nil {
 		return nil, errors.NewInternalError(err.Error())
 	}
 	return convertWorkItemModelToApp(wiType, res)
 }
 
-// Delete deletes the work item with the given id
+// Delete deletes the work item with the given id and records the deletion as a revision made by modifierID
 // returns NotFoundError or InternalError
-func (r *GormWorkItemRepository) Delete(ctx context.Context, ID string) error {
-	var workItem = WorkItem{}
-	id, err := strconv.ParseUint(ID, 10, 64)
-	if err != nil || id == 0 {
-		// treat as not found: clients don't know it must be a number
-		return errors.NewNotFoundError("work item", ID)
+func (r *GormWorkItemRepository) Delete(ctx context.Context, ID string, modifierID uuid.UUID) error {
+	old, err := r.LoadFromDB(ID)
+	if err != nil {
+		return errs.WithStack(err)
 	}
-	workItem.ID = id
-	tx := r.db.Delete(workItem)
+	tx := r.db.Delete(WorkItem{ID: old.ID})
 
 	if err = tx.Error; err != nil {
 		return errors.NewInternalError(err.Error())
@@ -81,13 +102,17 @@ func (r *GormWorkItemRepository) Delete(ctx context.Context, ID string) error {
 	if tx.RowsAffected == 0 {
 		return errors.NewNotFoundError("work item", ID)
 	}
-
-	return nil
+	// the deleted work item is no longer aggregated by its parents
+	if err := r.computeParentFields(ctx, old.ID); err != nil {
+		return errs.WithStack(err)
+	}
+	return r.rr.Create(ctx, modifierID, RevisionTypeDelete, old, old)
 }
 
-// Save updates the given work item in storage. Version must be the same as the one int the stored version
+// Save updates the given work item in storage and records the change as a revision made by modifierID.
+// Version must be the same as the one int the stored version
 // returns NotFoundError, VersionConflictError, ConversionError or InternalError
-func (r *GormWorkItemRepository) Save(ctx context.Context, wi app.WorkItem) (*app.WorkItem, error) {
+func (r *GormWorkItemRepository) Save(ctx context.Context, wi app.WorkItem, modifierID uuid.UUID) (*app.WorkItem, error) {
 	res := WorkItem{}
 	id, err := strconv.ParseUint(wi.ID, 10, 64)
 	if err != nil || id == 0 {
@@ -107,17 +132,19 @@ func (r *GormWorkItemRepository) Save(ctx context.Context, wi app.WorkItem) (*ap
 		return nil, errors.NewVersionConflictError("version conflict")
 	}
 
-	wiType, err := r.wir.LoadTypeFromDB(wi.Type)
+	// the space of a work item doesn't change, the new type must be available in it
+	wiType, err := r.wir.LoadTypeFromDB(res.SpaceID, wi.Type)
 	if err != nil {
 		return nil, errors.NewBadParameterError("Type", wi.Type)
 	}
 
+	old := res
 	res.Version = res.Version + 1
 	res.Type = wi.Type
 	res.Fields = Fields{}
 
 	for fieldName, fieldDef := range wiType.Fields {
-		if fieldName == SystemCreatedAt {
+		if fieldName == SystemCreatedAt || fieldDef.Computed != "" {
 			continue
 		}
 		fieldValue := wi.Fields[fieldName]
@@ -127,6 +154,15 @@ func (r *GormWorkItemRepository) Save(ctx context.Context, wi app.WorkItem) (*ap
 			return nil, errors.NewBadParameterError(fieldName, fieldValue)
 		}
 	}
+	if err := r.computeFields(wiType, &res); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := r.checkReferences(ctx, wiType, res.SpaceID, old.Fields, res.Fields); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := r.checkWorkflow(wiType, old.Fields, &res); err != nil {
+		return nil, errs.WithStack(err)
+	}
 
 	tx = tx.Where("Version = ?", wi.Version).Save(&res)
 	if err := tx.Error; err != nil {
@@ -137,27 +173,112 @@ func (r *GormWorkItemRepository) Save(ctx context.Context, wi app.WorkItem) (*ap
 		return nil, errors.NewVersionConflictError("version conflict")
 	}
 	log.Printf("updated item to %v\n", res)
+	if err := r.computeParentFields(ctx, res.ID); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := r.rr.Create(ctx, modifierID, RevisionTypeUpdate, &old, &res); err != nil {
+		return nil, errs.WithStack(err)
+	}
 	return convertWorkItemModelToApp(wiType, &res)
 }
 
-// Create creates a new work item in the repository
+// Update changes the given fields of the work item with the given id and leaves all other
+// fields untouched. The change is recorded as a revision made by modifierID. A nil value clears the field. Fields that are not defined by the type of
+// the work item are ignored. Version must be the same as the one in the stored version
+// returns NotFoundError, VersionConflictError, BadParameterError or InternalError
+func (r *GormWorkItemRepository) Update(ctx context.Context, ID string, version int, fields map[string]interface{}, modifierID uuid.UUID) (*app.WorkItem, error) {
+	res, err := r.LoadFromDB(ID)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if res.Version != version {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+	wiType, err := r.wir.LoadTypeFromDB(res.SpaceID, res.Type)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	old := *res
+	res.Fields = Fields{}
+	for fieldName, fieldValue := range old.Fields {
+		res.Fields[fieldName] = fieldValue
+	}
+
+	for fieldName, fieldValue := range fields {
+		fieldDef, ok := wiType.Fields[fieldName]
+		if !ok || fieldName == SystemCreatedAt || fieldName == SystemCreator || fieldDef.Computed != "" {
+			continue
+		}
+		converted, err := fieldDef.ConvertToModel(fieldName, fieldValue)
+		if err != nil {
+			return nil, errors.NewBadParameterError(fieldName, fieldValue)
+		}
+		if converted == nil {
+			delete(res.Fields, fieldName)
+		} else {
+			res.Fields[fieldName] = converted
+		}
+	}
+	res.Version = version + 1
+	if err := r.computeFields(wiType, res); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := r.checkReferences(ctx, wiType, res.SpaceID, old.Fields, res.Fields); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := r.checkWorkflow(wiType, old.Fields, res); err != nil {
+		return nil, errs.WithStack(err)
+	}
+
+	tx := r.db.Where("Version = ?", version).Save(res)
+	if err := tx.Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+	log.Printf("updated item to %v\n", res)
+	if err := r.computeParentFields(ctx, res.ID); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := r.rr.Create(ctx, modifierID, RevisionTypeUpdate, &old, res); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	return convertWorkItemModelToApp(wiType, res)
+}
+
+// Create creates a new work item of the given space in the repository. The
+// type is a system type or a type of that space. Work items without a space
+// can only have system types.
 // returns BadParameterError, ConversionError or InternalError
-func (r *GormWorkItemRepository) Create(ctx context.Context, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error) {
-	wiType, err := r.wir.LoadTypeFromDB(typeID)
+func (r *GormWorkItemRepository) Create(ctx context.Context, spaceID *uuid.UUID, typeID string, fields map[string]interface{}, creator string) (*app.WorkItem, error) {
+	if spaceID != nil {
+		if err := checkSpace(r.db, *spaceID); err != nil {
+			return nil, errs.WithStack(err)
+		}
+	}
+	wiType, err := r.wir.LoadTypeFromDB(spaceID, typeID)
 	if err != nil {
 		return nil, errors.NewBadParameterError("type", typeID)
 	}
 	wi := WorkItem{
-		Type:   typeID,
-		Fields: Fields{},
+		Type:    typeID,
+		SpaceID: spaceID,
+		Fields:  Fields{},
 	}
 	fields[SystemCreator] = creator
 	for fieldName, fieldDef := range wiType.Fields {
-		if fieldName == SystemCreatedAt {
+		if fieldName == SystemCreatedAt || fieldDef.Computed != "" {
 			continue
 		}
 		fieldValue := fields[fieldName]
 		var err error
+		if fieldValue == nil {
+			fieldValue, err = r.defaultValue(ctx, spaceID, fieldDef, creator)
+			if err != nil {
+				return nil, errs.WithStack(err)
+			}
+		}
 		wi.Fields[fieldName], err = fieldDef.ConvertToModel(fieldName, fieldValue)
 		if err != nil {
 			return nil, errors.NewBadParameterError(fieldName, fieldValue)
@@ -169,13 +290,573 @@ func (r *GormWorkItemRepository) Create(ctx context.Context, typeID string, fiel
 			}
 		}
 	}
+	if err := r.computeFields(wiType, &wi); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := r.checkReferences(ctx, wiType, spaceID, nil, wi.Fields); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := r.checkWorkflow(wiType, nil, &wi); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	// new work items go to the end of the backlog of their space
+	if err := r.lockRanks(spaceID); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	last, err := r.aggregateRank("max", spaceID, "true")
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	wi.Rank = RankAfter(last)
 	tx := r.db
 	if err = tx.Create(&wi).Error; err != nil {
 		return nil, errors.NewInternalError(err.Error())
 	}
+	// creators of imported work items are no identities
+	if err = r.rr.Create(ctx, uuid.FromStringOrNil(creator), RevisionTypeCreate, nil, &wi); err != nil {
+		return nil, errs.WithStack(err)
+	}
 	return convertWorkItemModelToApp(wiType, &wi)
 }
 
+// defaultValue returns the default of a field of a new work item of the given
+// space in the representation expected by ConvertToModel, nil if there is
+// none. There is no current iteration for work items that belong to no space
+// or if no iteration of the space is running.
+// returns InternalError
+func (r *GormWorkItemRepository) defaultValue(ctx context.Context, spaceID *uuid.UUID, def FieldDefinition, creator string) (interface{}, error) {
+	switch def.DefaultFrom {
+	case DefaultFromCurrentUser:
+		if _, ok := def.Type.(ListType); ok {
+			return []interface{}{creator}, nil
+		}
+		return creator, nil
+	case DefaultFromCurrentIteration:
+		if spaceID == nil {
+			return nil, nil
+		}
+		current, err := iteration.NewIterationRepository(r.db).LoadCurrent(ctx, *spaceID)
+		if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, errs.WithStack(err)
+		}
+		return current.ID.String(), nil
+	case DefaultFromNow:
+		return time.Now(), nil
+	}
+	if def.Default == nil {
+		return nil, nil
+	}
+	return def.staticDefault(), nil
+}
+
+// computeFields sets the values of the computed fields of the work item, see
+// Computation. New work items have no children yet.
+// returns InternalError
+func (r *GormWorkItemRepository) computeFields(wiType *WorkItemType, wi *WorkItem) error {
+	for name, def := range wiType.Fields {
+		if def.Computed == "" {
+			continue
+		}
+		computation, err := ParseComputation(def.Computed)
+		if err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+		expression, parameters := computation.sql()
+		query := "SELECT " + expression + ` FROM work_item_links l
+			JOIN work_item_link_types t ON t.id = l.link_type_id
+			JOIN work_items c ON c.id = l.target_id
+			WHERE l.source_id = ? AND t.topology = 'tree'
+			AND l.deleted_at IS NULL AND t.deleted_at IS NULL AND c.deleted_at IS NULL`
+		var value sql.NullFloat64
+		if err := r.db.Raw(query, append(parameters, wi.ID)...).Row().Scan(&value); err != nil {
+			return errors.NewInternalError(err.Error())
+		}
+		switch {
+		case !value.Valid:
+			delete(wi.Fields, name)
+		case def.Type.GetKind() == KindInteger:
+			wi.Fields[name] = int(value.Float64)
+		default:
+			wi.Fields[name] = value.Float64
+		}
+	}
+	return nil
+}
+
+// ComputeFields recomputes the computed fields of the given work item and, as
+// long as values change, those of its parents, i.e. the sources of the links
+// of tree topology targeting it, and of their parents in turn. It is called
+// when a link of the work item to a child is created, changed or deleted.
+// Clients can't set computed fields, so the values are stored without changing
+// the version of the work items.
+// returns InternalError
+func (r *GormWorkItemRepository) ComputeFields(ctx context.Context, ID uint64) error {
+	changed, err := r.recomputeFields(ID)
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	if !changed {
+		return nil
+	}
+	return r.computeParentFields(ctx, ID)
+}
+
+// computeParentFields recomputes the computed fields of the parents of the
+// given work item, see ComputeFields. It is called when a child is changed.
+// returns InternalError
+func (r *GormWorkItemRepository) computeParentFields(ctx context.Context, childID uint64) error {
+	children := []uint64{childID}
+	visited := map[uint64]bool{childID: true}
+	for len(children) > 0 {
+		parentIDs, err := r.parentIDs(children[0])
+		if err != nil {
+			return errs.WithStack(err)
+		}
+		children = children[1:]
+		for _, parentID := range parentIDs {
+			if visited[parentID] {
+				continue
+			}
+			visited[parentID] = true
+			changed, err := r.recomputeFields(parentID)
+			if err != nil {
+				return errs.WithStack(err)
+			}
+			if changed {
+				children = append(children, parentID)
+			}
+		}
+	}
+	return nil
+}
+
+// parentIDs returns the IDs of the sources of the links of tree topology that
+// target the given work item
+// returns InternalError
+func (r *GormWorkItemRepository) parentIDs(childID uint64) ([]uint64, error) {
+	rows, err := r.db.Raw(`SELECT DISTINCT l.source_id FROM work_item_links l
+		JOIN work_item_link_types t ON t.id = l.link_type_id
+		WHERE l.target_id = ? AND t.topology = 'tree'
+		AND l.deleted_at IS NULL AND t.deleted_at IS NULL`, childID).Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+	var result []uint64
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		result = append(result, id)
+	}
+	return result, nil
+}
+
+// recomputeFields computes and stores the computed fields of the given work
+// item and returns true if a value changed
+// returns InternalError
+func (r *GormWorkItemRepository) recomputeFields(ID uint64) (bool, error) {
+	wi, err := r.LoadFromDB(strconv.FormatUint(ID, 10))
+	if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+		// deleted work items keep their links
+		return false, nil
+	}
+	if err != nil {
+		return false, errs.WithStack(err)
+	}
+	wiType, err := r.wir.LoadTypeFromDB(wi.SpaceID, wi.Type)
+	if err != nil {
+		return false, errors.NewInternalError(err.Error())
+	}
+	old, err := json.Marshal(wi.Fields)
+	if err != nil {
+		return false, errors.NewInternalError(err.Error())
+	}
+	if err := r.computeFields(wiType, wi); err != nil {
+		return false, errs.WithStack(err)
+	}
+	computed, err := json.Marshal(wi.Fields)
+	if err != nil {
+		return false, errors.NewInternalError(err.Error())
+	}
+	if bytes.Equal(old, computed) {
+		return false, nil
+	}
+	if err := r.db.Model(wi).UpdateColumn("fields", wi.Fields).Error; err != nil {
+		return false, errors.NewInternalError(err.Error())
+	}
+	return true, nil
+}
+
+// checkReferences returns an error if a field that references other entities
+// (or a list of them) references an area, label, user, iteration or work item
+// that does not exist. The areas and labels of the work items of a space must
+// belong to that space. Only references that are not in the old fields (nil
+// for new work items) are checked, so that work items referencing a deleted
+// entity can still be changed. The creator is the current identity when the
+// work item is created and is not checked, neither are the users of work
+// items imported from a remote tracker, which are user names of the tracker.
+// returns BadParameterError or InternalError
+func (r *GormWorkItemRepository) checkReferences(ctx context.Context, wiType *WorkItemType, spaceID *uuid.UUID, old Fields, changed Fields) error {
+	for name, def := range wiType.Fields {
+		kind := elementKind(def.Type)
+		switch kind {
+		case KindArea, KindLabel, KindUser, KindIteration, KindWorkitemReference:
+		default:
+			continue
+		}
+		if name == SystemCreator || (kind == KindUser && changed[SystemRemoteItemID] != nil) {
+			continue
+		}
+		existing := map[string]bool{}
+		for _, id := range ReferenceValues(old[name]) {
+			existing[fmt.Sprint(id)] = true
+		}
+		for _, id := range ReferenceValues(changed[name]) {
+			if existing[fmt.Sprint(id)] || (kind == KindUser && id == changed[SystemCreator]) {
+				continue
+			}
+			if kind != KindArea && kind != KindLabel {
+				err := r.checkReference(ctx, kind, id)
+				if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+					return errors.NewBadParameterError(name, id).Expected(fmt.Sprintf("the ID of an existing %s", kind))
+				}
+				if err != nil {
+					return errs.WithStack(err)
+				}
+				continue
+			}
+			referenced, err := r.referencedSpace(ctx, kind, id)
+			if err != nil {
+				if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+					return errors.NewBadParameterError(name, id).Expected(fmt.Sprintf("the ID of an existing %s", kind))
+				}
+				return errs.WithStack(err)
+			}
+			if spaceID != nil && referenced != *spaceID {
+				return errors.NewBadParameterError(name, id).Expected(fmt.Sprintf("the ID of a %s of the space %s", kind, spaceID.String()))
+			}
+		}
+	}
+	return nil
+}
+
+// ReferenceValues returns the values of a field that is either a single
+// reference or a list of references
+func ReferenceValues(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return v
+	case []string:
+		result := make([]interface{}, len(v))
+		for i, id := range v {
+			result[i] = id
+		}
+		return result
+	}
+	return []interface{}{value}
+}
+
+// checkReference returns an error if the referenced user, iteration or work
+// item does not exist
+// returns NotFoundError or InternalError
+func (r *GormWorkItemRepository) checkReference(ctx context.Context, kind Kind, id interface{}) error {
+	if kind == KindWorkitemReference {
+		_, err := r.LoadFromDB(fmt.Sprint(id))
+		return errs.WithStack(err)
+	}
+	uid, err := uuid.FromString(fmt.Sprint(id))
+	if err != nil {
+		return errors.NewNotFoundError(string(kind), fmt.Sprint(id))
+	}
+	if kind == KindUser {
+		if !account.NewIdentityRepository(r.db).IsValid(ctx, uid) {
+			return errors.NewNotFoundError(string(kind), uid.String())
+		}
+		return nil
+	}
+	_, err = iteration.NewIterationRepository(r.db).Load(ctx, uid)
+	return errs.WithStack(err)
+}
+
+// referencedSpace returns the space of the referenced area or label
+// returns NotFoundError or InternalError
+func (r *GormWorkItemRepository) referencedSpace(ctx context.Context, kind Kind, id interface{}) (uuid.UUID, error) {
+	uid, err := uuid.FromString(fmt.Sprint(id))
+	if err != nil {
+		return uuid.Nil, errors.NewNotFoundError(string(kind), fmt.Sprint(id))
+	}
+	if kind == KindArea {
+		a, err := area.NewAreaRepository(r.db).Load(ctx, uid)
+		if err != nil {
+			return uuid.Nil, errs.WithStack(err)
+		}
+		return a.SpaceID, nil
+	}
+	l, err := label.NewLabelRepository(r.db).Load(ctx, uid)
+	if err != nil {
+		return uuid.Nil, errs.WithStack(err)
+	}
+	return l.SpaceID, nil
+}
+
+// checkWorkflow returns an error if the workflow of the type does not allow
+// the work item to change from the old fields (nil for new work items) to its
+// current fields
+// returns BadParameterError or InternalError
+func (r *GormWorkItemRepository) checkWorkflow(wiType *WorkItemType, old Fields, wi *WorkItem) error {
+	guards, err := wiType.Workflow.CheckStateChange(old, wi.Fields)
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	if len(guards) == 0 {
+		return nil
+	}
+	matches, err := r.matchesAnyGuard(wiType, wi, guards)
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	if !matches {
+		return errors.NewBadParameterError(SystemState, wi.Fields[SystemState]).Expected(fmt.Sprintf("a work item matching %s", strings.Join(guards, " or ")))
+	}
+	return nil
+}
+
+// matchesAnyGuard returns true if the given, possibly unsaved, work item
+// matches one of the guards. The guards are compiled like filters and
+// evaluated on a row holding the values of the work item.
+// returns BadParameterError or InternalError
+func (r *GormWorkItemRepository) matchesAnyGuard(wiType *WorkItemType, wi *WorkItem, guards []string) (bool, error) {
+	exp, err := parseGuards(guards)
+	if err != nil {
+		return false, errs.WithStack(err)
+	}
+	where, parameters, err := compileCriteria(exp, wiType.Fields)
+	if err != nil {
+		return false, errs.WithStack(err)
+	}
+	fields, err := json.Marshal(wi.Fields)
+	if err != nil {
+		return false, errors.NewInternalError(err.Error())
+	}
+	query := "SELECT count(*) FROM (SELECT ?::bigint AS id, ?::text AS type, ?::integer AS version, ?::jsonb AS fields, ?::timestamp with time zone AS created_at) AS work_items WHERE " + where
+	var count int
+	if err := r.db.Raw(query, append([]interface{}{wi.ID, wi.Type, wi.Version, string(fields), wi.CreatedAt}, parameters...)...).Row().Scan(&count); err != nil {
+		return false, errors.NewInternalError(err.Error())
+	}
+	return count > 0, nil
+}
+
+// NextStates returns the states the work item with the given id may change
+// to, in the order of the transitions of its type. Transitions whose guards
+// the work item would not match in the next state are left out. Without
+// transitions, all other values of the system.state field are returned.
+// returns NotFoundError, BadParameterError or InternalError
+func (r *GormWorkItemRepository) NextStates(ctx context.Context, ID string) ([]NextState, error) {
+	wi, err := r.LoadFromDB(ID)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	wiType, err := r.wir.LoadTypeFromDB(wi.SpaceID, wi.Type)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	current := stateName(wi.Fields[SystemState])
+	workflow := wiType.Workflow
+	result := []NextState{}
+	if len(workflow.Transitions) == 0 {
+		if enum, ok := wiType.Fields[SystemState].Type.(EnumType); ok {
+			for _, value := range enum.Values {
+				if state := stateName(value); state != current {
+					result = append(result, NextState{State: state, RequiredFields: workflow.RequiredFields[state]})
+				}
+			}
+		}
+		return result, nil
+	}
+	for _, next := range workflow.nextStates(current) {
+		if guards := workflow.guards(current, next.State); len(guards) > 0 {
+			// guards apply to the work item after the transition
+			changed := *wi
+			changed.Fields = Fields{}
+			for name, value := range wi.Fields {
+				changed.Fields[name] = value
+			}
+			changed.Fields[SystemState] = next.State
+			matches, err := r.matchesAnyGuard(wiType, &changed, guards)
+			if err != nil {
+				return nil, errs.WithStack(err)
+			}
+			if !matches {
+				continue
+			}
+		}
+		result = append(result, next)
+	}
+	return result, nil
+}
+
+// Reorder moves the work item with the given ID directly above or below the
+// other work item in the backlog order. Both work items must belong to the
+// same space or iteration. The ranks of the work items of the space are
+// rebalanced when they get too dense. The version of the moved work item
+// doesn't change.
+// returns BadParameterError, NotFoundError or InternalError
+func (r *GormWorkItemRepository) Reorder(ctx context.Context, ID string, direction string, otherID string) (*app.WorkItem, error) {
+	if direction != ReorderAbove && direction != ReorderBelow {
+		return nil, errors.NewBadParameterError("direction", direction).Expected(ReorderAbove + "|" + ReorderBelow)
+	}
+	wi, err := r.LoadFromDB(ID)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	// the ranks are read after taking the lock of the space
+	if err := r.lockRanks(wi.SpaceID); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	other, err := r.LoadFromDB(otherID)
+	if err != nil {
+		if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+			return nil, errors.NewBadParameterError(direction, otherID).Expected("the ID of an existing work item")
+		}
+		return nil, errs.WithStack(err)
+	}
+	if wi.ID == other.ID {
+		return nil, errors.NewBadParameterError(direction, otherID).Expected("the ID of another work item")
+	}
+	wiType, err := r.wir.LoadTypeFromDB(wi.SpaceID, wi.Type)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if !sameBacklog(wi, other) {
+		return nil, errors.NewBadParameterError(direction, otherID).Expected("the ID of a work item of the same space or iteration")
+	}
+	rank, err := r.rankNextTo(other, direction, wi.ID)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if len(rank) > maxRankLength || other.Rank == "" {
+		if err := r.rebalanceRanks(wi.SpaceID); err != nil {
+			return nil, errs.WithStack(err)
+		}
+		if other, err = r.LoadFromDB(otherID); err != nil {
+			return nil, errs.WithStack(err)
+		}
+		if rank, err = r.rankNextTo(other, direction, wi.ID); err != nil {
+			return nil, errs.WithStack(err)
+		}
+	}
+	if err := r.db.Model(wi).UpdateColumn("rank", rank).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	wi.Rank = rank
+	log.Printf("moved work item %d %s %d", wi.ID, direction, other.ID)
+	return convertWorkItemModelToApp(wiType, wi)
+}
+
+// sameBacklog returns true if both work items belong to the same space or to
+// the same iteration
+func sameBacklog(a *WorkItem, b *WorkItem) bool {
+	if a.SpaceID == nil && b.SpaceID == nil {
+		return true
+	}
+	if a.SpaceID != nil && b.SpaceID != nil && uuid.Equal(*a.SpaceID, *b.SpaceID) {
+		return true
+	}
+	iteration := a.Fields[SystemIteration]
+	return iteration != nil && iteration == b.Fields[SystemIteration]
+}
+
+// rankNextTo returns a rank directly above or below the other work item in the
+// backlog of its space, ignoring the work item with the given ID that is being
+// moved
+func (r *GormWorkItemRepository) rankNextTo(other *WorkItem, direction string, movedID uint64) (string, error) {
+	if direction == ReorderAbove {
+		lower, err := r.aggregateRank("max", other.SpaceID, "rank < ? AND id <> ?", other.Rank, movedID)
+		if err != nil {
+			return "", errs.WithStack(err)
+		}
+		return RankBetween(lower, other.Rank)
+	}
+	upper, err := r.aggregateRank("min", other.SpaceID, "rank > ? AND id <> ?", other.Rank, movedID)
+	if err != nil {
+		return "", errs.WithStack(err)
+	}
+	return RankBetween(other.Rank, upper)
+}
+
+// aggregateRank returns the max or min rank of the work items of the given
+// space matching the condition, "" if there are none
+// returns InternalError
+func (r *GormWorkItemRepository) aggregateRank(aggregate string, spaceID *uuid.UUID, condition string, parameters ...interface{}) (string, error) {
+	var result sql.NullString
+	inSpace, spaceParameters := spaceCondition(spaceID)
+	row := r.db.Model(&WorkItem{}).Where(condition, parameters...).Where(inSpace, spaceParameters...).Select(aggregate + "(rank)").Row()
+	if err := row.Scan(&result); err != nil {
+		return "", errors.NewInternalError(err.Error())
+	}
+	return result.String, nil
+}
+
+// spaceCondition returns the condition that restricts the work items to the
+// given space, or to the work items without a space. Ranks are only allocated
+// and compared within a space.
+func spaceCondition(spaceID *uuid.UUID) (string, []interface{}) {
+	if spaceID == nil {
+		return "space_id IS NULL", nil
+	}
+	return "space_id = ?", []interface{}{*spaceID}
+}
+
+// lockRanks takes the advisory lock that serialises the allocation of ranks in
+// the given space until the end of the transaction, so that concurrent
+// requests don't compute the same rank
+// returns InternalError
+func (r *GormWorkItemRepository) lockRanks(spaceID *uuid.UUID) error {
+	key := ""
+	if spaceID != nil {
+		key = spaceID.String()
+	}
+	if err := r.db.Exec("SELECT pg_advisory_xact_lock(?, hashtext(?))", rankLockID, key).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// rebalanceQuery gives the n-th of the work items of a space ordered by rank
+// the rank of rankLength digits with the value n * max / (count + 1), where
+// max is the number of ranks of rankLength digits. The space condition is
+// filled in, see spaceCondition.
+const rebalanceQuery = `UPDATE work_items w SET rank = r.rank FROM (
+		SELECT o.id, rtrim(string_agg(substr(?::text, (div(o.value, power(?::numeric, ?::int - 1 - d)) % ?::numeric)::int + 1, 1), '' ORDER BY d), '0') AS rank
+		FROM (SELECT id, row_number() OVER (ORDER BY rank, id) * div(power(?::numeric, ?::int), count(*) OVER () + 1) AS value
+			FROM work_items WHERE deleted_at IS NULL AND %s) o
+		CROSS JOIN generate_series(0, ?::int - 1) d
+		GROUP BY o.id
+	) r
+	WHERE w.id = r.id AND w.rank <> r.rank`
+
+// rebalanceRanks spreads the ranks of the work items of the given space
+// evenly, keeping their order
+// returns InternalError
+func (r *GormWorkItemRepository) rebalanceRanks(spaceID *uuid.UUID) error {
+	inSpace, spaceParameters := spaceCondition(spaceID)
+	parameters := []interface{}{rankDigits, rankBase, rankLength, rankBase, rankBase, rankLength}
+	parameters = append(append(parameters, spaceParameters...), rankLength)
+	db := r.db.Exec(fmt.Sprintf(rebalanceQuery, inSpace), parameters...)
+	if db.Error != nil {
+		return errors.NewInternalError(db.Error.Error())
+	}
+	log.Printf("rebalanced the ranks of %d work items", db.RowsAffected)
+	return nil
+}
+
 func convertWorkItemModelToApp(wiType *WorkItemType, wi *WorkItem) (*app.WorkItem, error) {
 	result, err := wiType.ConvertFromModel(*wi)
 	if err != nil {
@@ -188,98 +869,203 @@ func convertWorkItemModelToApp(wiType *WorkItemType, wi *WorkItem) (*app.WorkIte
 
 }
 
+// compileCriteria compiles the criteria to a where clause, see CompileWithFields
+// returns BadParameterError if the criteria can not be compiled
+func compileCriteria(criteria criteria.Expression, fields FieldDefinitions) (string, []interface{}, error) {
+	where, parameters, compileError := CompileWithFields(criteria, fields)
+	if compileError != nil {
+		if badParameter, ok := compileError[0].(errors.BadParameterError); ok {
+			return "", nil, badParameter
+		}
+		return "", nil, errors.NewBadParameterError("expression", criteria)
+	}
+	return where, parameters, nil
+}
+
 // extracted this function from List() in order to close the rows object with "defer" for more readability
 // workaround for https://github.com/lib/pq/issues/81
-func (r *GormWorkItemRepository) listItemsFromDB(ctx context.Context, criteria criteria.Expression, start *int, limit *int) ([]WorkItem, uint64, error) {
-	where, parameters, compileError := Compile(criteria)
-	if compileError != nil {
-		return nil, 0, errors.NewBadParameterError("expression", criteria)
+func (r *GormWorkItemRepository) listItemsFromDB(ctx context.Context, criteria criteria.Expression, orderBy []criteria.OrderBy, start *int, limit *int, cursor *gormsupport.Cursor) ([]WorkItem, uint64, *gormsupport.PageCursors, error) {
+	fields, err := r.wir.loadFieldDefinitions()
+	if err != nil {
+		return nil, 0, nil, errs.WithStack(err)
+	}
+	where, parameters, err := compileCriteria(criteria, fields)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	keys, err := CompileOrder(orderBy, fields)
+	if err != nil {
+		return nil, 0, nil, errs.WithStack(err)
 	}
 
-	log.Printf("executing query: '%s' with params %v", where, parameters)
+	log.Printf("executing query: '%s' with params %v ordered by %v", where, parameters, keys)
 
 	db := r.db.Model(&WorkItem{}).Where(where, parameters...)
 	orgDB := db
-	if start != nil {
-		if *start < 0 {
-			return nil, 0, errors.NewBadParameterError("start", *start)
+	if cursor != nil {
+		db, err = gormsupport.ApplyCursor(db, keys, *cursor)
+		if err != nil {
+			return nil, 0, nil, errs.WithStack(err)
+		}
+	} else {
+		db = db.Order(gormsupport.OrderClause(keys, false))
+		if start != nil {
+			if *start < 0 {
+				return nil, 0, nil, errors.NewBadParameterError("start", *start)
+			}
+			db = db.Offset(*start)
 		}
-		db = db.Offset(*start)
 	}
 	if limit != nil {
 		if *limit <= 0 {
-			return nil, 0, errors.NewBadParameterError("limit", *limit)
+			return nil, 0, nil, errors.NewBadParameterError("limit", *limit)
 		}
 		db = db.Limit(*limit)
 	}
-	db = db.Select("count(*) over () as cnt2 , *")
+	db = db.Select("count(*) over () as cnt2 , *, " + gormsupport.SelectSortKeys(keys))
 
 	rows, err := db.Rows()
 	if err != nil {
-		return nil, 0, errs.WithStack(err)
+		return nil, 0, nil, errs.WithStack(err)
 	}
 	defer rows.Close()
 
 	result := []WorkItem{}
 	columns, err := rows.Columns()
 	if err != nil {
-		return nil, 0, errors.NewInternalError(err.Error())
+		return nil, 0, nil, errors.NewInternalError(err.Error())
 	}
 
-	// need to set up a result for Scan() in order to extract total count.
+	// need to read the total count and the sort keys of the first and last row to compute the cursors
 	var count uint64
-	var ignore interface{}
-	columnValues := make([]interface{}, len(columns))
-
-	for index := range columnValues {
-		columnValues[index] = &ignore
-	}
-	columnValues[0] = &count
+	var firstKeys, lastKeys []*string
 	first := true
 
 	for rows.Next() {
 		value := WorkItem{}
 		db.ScanRows(rows, &value)
+		var countTarget *uint64
+		if first {
+			countTarget = &count
+		}
+		lastKeys, err = gormsupport.ScanSortKeys(rows, columns, countTarget)
+		if err != nil {
+			return nil, 0, nil, errs.WithStack(err)
+		}
 		if first {
 			first = false
-			if err = rows.Scan(columnValues...); err != nil {
-				return nil, 0, errors.NewInternalError(err.Error())
-			}
+			firstKeys = lastKeys
 		}
 		result = append(result, value)
 
 	}
-	if first {
+	// when paging with a cursor, the window count only covers the rows beyond the cursor
+	more := count > uint64(len(result))
+	if first || cursor != nil {
 		// means 0 rows were returned from the first query (maybe becaus of offset outside of total count),
-		// need to do a count(*) to find out total
+		// or the count is restricted by the cursor, need to do a count(*) to find out total
 		orgDB := orgDB.Select("count(*)")
 		rows2, err := orgDB.Rows()
-		defer rows2.Close()
 		if err != nil {
-			return nil, 0, errs.WithStack(err)
+			return nil, 0, nil, errs.WithStack(err)
 		}
+		defer rows2.Close()
 		rows2.Next() // count(*) will always return a row
 		rows2.Scan(&count)
 	}
-	return result, count, nil
+	var hasPrev, hasNext bool
+	if cursor != nil {
+		hasPrev, hasNext = cursor.Neighbours(more)
+		if cursor.Before {
+			// the rows were fetched in reverse order
+			for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+				result[i], result[j] = result[j], result[i]
+			}
+			firstKeys, lastKeys = lastKeys, firstKeys
+		}
+	} else {
+		offset := 0
+		if start != nil {
+			offset = *start
+		}
+		hasPrev = offset > 0
+		hasNext = uint64(offset+len(result)) < count
+	}
+	return result, count, gormsupport.NewPageCursors(firstKeys, lastKeys, hasPrev, hasNext), nil
 }
 
-// List returns work item selected by the given criteria.Expression, starting with start (zero-based) and returning at most limit items
-func (r *GormWorkItemRepository) List(ctx context.Context, criteria criteria.Expression, start *int, limit *int) ([]*app.WorkItem, uint64, error) {
-	result, count, err := r.listItemsFromDB(ctx, criteria, start, limit)
+// List returns work item selected by the given criteria.Expression, sorted by the given keys, starting with start (zero-based) and returning at most limit items.
+// If a cursor is given, start is ignored and the items after (or before) the cursor are returned instead.
+// The returned cursors point at the pages before and after the returned items.
+func (r *GormWorkItemRepository) List(ctx context.Context, criteria criteria.Expression, orderBy []criteria.OrderBy, start *int, limit *int, cursor *gormsupport.Cursor) ([]*app.WorkItem, uint64, *gormsupport.PageCursors, error) {
+	result, count, cursors, err := r.listItemsFromDB(ctx, criteria, orderBy, start, limit, cursor)
 	if err != nil {
-		return nil, 0, errs.WithStack(err)
+		return nil, 0, nil, errs.WithStack(err)
 	}
 
 	res := make([]*app.WorkItem, len(result))
 
 	for index, value := range result {
-		wiType, err := r.wir.LoadTypeFromDB(value.Type)
+		wiType, err := r.wir.LoadTypeFromDB(value.SpaceID, value.Type)
 		if err != nil {
-			return nil, 0, errors.NewInternalError(err.Error())
+			return nil, 0, nil, errors.NewInternalError(err.Error())
 		}
 		res[index], err = convertWorkItemModelToApp(wiType, &value)
 	}
 
-	return res, count, nil
+	return res, count, cursors, nil
+}
+
+// Aggregate counts the work items selected by the given criteria.Expression, grouped by the value of the given field.
+// The buckets are ordered by descending count. A work item with several values in a list field is counted in
+// the bucket of each value. The total number of selected work items is returned as well.
+// returns BadParameterError or InternalError
+func (r *GormWorkItemRepository) Aggregate(ctx context.Context, criteria criteria.Expression, groupBy string) ([]Bucket, uint64, error) {
+	fields, err := r.wir.loadFieldDefinitions()
+	if err != nil {
+		return nil, 0, errs.WithStack(err)
+	}
+	where, parameters, err := compileCriteria(criteria, fields)
+	if err != nil {
+		return nil, 0, err
+	}
+	expression, join, err := CompileGroupBy(groupBy, fields)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	log.Printf("executing query: '%s' with params %v grouped by %s", where, parameters, expression)
+
+	db := r.db.Model(&WorkItem{}).Where(where, parameters...)
+	var total uint64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, errors.NewInternalError(err.Error())
+	}
+	if join != "" {
+		db = db.Joins(join)
+	}
+	rows, err := db.Select(expression + " as value, count(*) as cnt").Group("1").Order("cnt desc, value").Rows()
+	if err != nil {
+		return nil, 0, errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+
+	result := []Bucket{}
+	for rows.Next() {
+		var value []byte
+		bucket := Bucket{}
+		if err := rows.Scan(&value, &bucket.Count); err != nil {
+			return nil, 0, errors.NewInternalError(err.Error())
+		}
+		if value != nil {
+			// keep numbers as they are instead of converting them to float64
+			decoder := json.NewDecoder(bytes.NewReader(value))
+			decoder.UseNumber()
+			if err := decoder.Decode(&bucket.Value); err != nil {
+				return nil, 0, errors.NewConversionError(err.Error())
+			}
+		}
+		result = append(result, bucket)
+	}
+	return result, total, nil
 }
@@ -2,18 +2,29 @@ package workitem_test
 
 import (
 	"os"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/area"
+	"github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/label"
 	"github.com/almighty/almighty-core/migration"
 	"github.com/almighty/almighty-core/models"
 	"github.com/almighty/almighty-core/rendering"
 	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
 	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
 	"github.com/jinzhu/gorm"
 	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -52,14 +63,14 @@ func (s *workItemRepoBlackBoxTest) TestFailDeleteZeroID() {
 
 	// Create at least 1 item to avoid RowsEffectedCheck
 	_, err := s.repo.Create(
-		context.Background(), workitem.SystemBug,
+		context.Background(), nil, workitem.SystemBug,
 		map[string]interface{}{
 			workitem.SystemTitle: "Title",
 			workitem.SystemState: workitem.SystemStateNew,
 		}, "xx")
 	require.Nil(s.T(), err, "Could not create work item")
 
-	err = s.repo.Delete(context.Background(), "0")
+	err = s.repo.Delete(context.Background(), "0", uuid.Nil)
 	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
 }
 
@@ -68,14 +79,14 @@ func (s *workItemRepoBlackBoxTest) TestFailSaveZeroID() {
 
 	// Create at least 1 item to avoid RowsEffectedCheck
 	wi, err := s.repo.Create(
-		context.Background(), workitem.SystemBug,
+		context.Background(), nil, workitem.SystemBug,
 		map[string]interface{}{
 			workitem.SystemTitle: "Title",
 			workitem.SystemState: workitem.SystemStateNew,
 		}, "xx")
 	require.Nil(s.T(), err, "Could not create workitem")
 	wi.ID = "0"
-	_, err = s.repo.Save(context.Background(), *wi)
+	_, err = s.repo.Save(context.Background(), *wi, uuid.Nil)
 	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
 }
 
@@ -84,7 +95,7 @@ func (s *workItemRepoBlackBoxTest) TestFaiLoadZeroID() {
 
 	// Create at least 1 item to avoid RowsEffectedCheck
 	_, err := s.repo.Create(
-		context.Background(), workitem.SystemBug,
+		context.Background(), nil, workitem.SystemBug,
 		map[string]interface{}{
 			workitem.SystemTitle: "Title",
 			workitem.SystemState: workitem.SystemStateNew,
@@ -95,29 +106,146 @@ func (s *workItemRepoBlackBoxTest) TestFaiLoadZeroID() {
 	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
 }
 
+// createIdentity creates an identity that user fields can reference
+func (s *workItemRepoBlackBoxTest) createIdentity() string {
+	identity := account.Identity{ID: uuid.NewV4(), Username: "Test User Repository"}
+	require.Nil(s.T(), account.NewIdentityRepository(s.DB).Create(context.Background(), &identity))
+	return identity.ID.String()
+}
+
 func (s *workItemRepoBlackBoxTest) TestSaveAssignees() {
 	defer cleaner.DeleteCreatedEntities(s.DB)()
+	a, b := s.createIdentity(), s.createIdentity()
 
 	wi, err := s.repo.Create(
-		context.Background(), workitem.SystemBug,
+		context.Background(), nil, workitem.SystemBug,
 		map[string]interface{}{
 			workitem.SystemTitle:     "Title",
 			workitem.SystemState:     workitem.SystemStateNew,
-			workitem.SystemAssignees: []string{"A", "B"},
+			workitem.SystemAssignees: []string{a, b},
 		}, "xx")
 	require.Nil(s.T(), err, "Could not create workitem")
 
 	wi, err = s.repo.Load(context.Background(), wi.ID)
 	require.Nil(s.T(), err)
 
-	assert.Equal(s.T(), "A", wi.Fields[workitem.SystemAssignees].([]interface{})[0])
+	assert.Equal(s.T(), a, wi.Fields[workitem.SystemAssignees].([]interface{})[0])
+}
+
+func (s *workItemRepoBlackBoxTest) TestUpdateChangesOnlyGivenFields() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+	assignee := s.createIdentity()
+
+	wi, err := s.repo.Create(
+		context.Background(), nil, workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle:     "Title",
+			workitem.SystemState:     workitem.SystemStateNew,
+			workitem.SystemAssignees: []string{assignee},
+		}, "xx")
+	require.Nil(s.T(), err, "Could not create workitem")
+
+	updated, err := s.repo.Update(context.Background(), wi.ID, wi.Version, map[string]interface{}{
+		workitem.SystemState:     workitem.SystemStateOpen,
+		workitem.SystemAssignees: nil,
+		"version":                "ignored",
+	}, uuid.Nil)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), wi.Version+1, updated.Version)
+	assert.Equal(s.T(), "Title", updated.Fields[workitem.SystemTitle])
+	assert.Equal(s.T(), workitem.SystemStateOpen, updated.Fields[workitem.SystemState])
+	assert.Nil(s.T(), updated.Fields[workitem.SystemAssignees])
+	assert.Equal(s.T(), wi.Fields[workitem.SystemCreatedAt], updated.Fields[workitem.SystemCreatedAt])
+
+	_, err = s.repo.Update(context.Background(), wi.ID, wi.Version, map[string]interface{}{workitem.SystemTitle: "Other"}, uuid.Nil)
+	require.IsType(s.T(), errors.VersionConflictError{}, errs.Cause(err))
+	_, err = s.repo.Update(context.Background(), wi.ID, updated.Version, map[string]interface{}{workitem.SystemTitle: nil}, uuid.Nil)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	_, err = s.repo.Update(context.Background(), "0", updated.Version, map[string]interface{}{}, uuid.Nil)
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
+
+func (s *workItemRepoBlackBoxTest) TestUpdateListedWorkItems() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+	ctx := context.Background()
+
+	// a bulk change by filter updates the listed work items in their listed version
+	title := "Bulk " + uuid.NewV4().String()
+	var created []*app.WorkItem
+	for i := 0; i < 2; i++ {
+		wi, err := s.repo.Create(ctx, nil, workitem.SystemBug, map[string]interface{}{
+			workitem.SystemTitle: title,
+			workitem.SystemState: workitem.SystemStateNew,
+		}, "xx")
+		require.Nil(s.T(), err)
+		created = append(created, wi)
+	}
+	// the first work item was changed before the bulk request, the change is overwritten
+	changed, err := s.repo.Update(ctx, created[0].ID, created[0].Version, map[string]interface{}{workitem.SystemState: workitem.SystemStateResolved}, uuid.Nil)
+	require.Nil(s.T(), err)
+
+	listed, _, _, err := s.repo.List(ctx, criteria.Equals(criteria.Field(workitem.SystemTitle), criteria.Literal(title)), nil, nil, nil, nil)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), listed, 2)
+	for _, wi := range listed {
+		updated, err := s.repo.Update(ctx, wi.ID, wi.Version, map[string]interface{}{workitem.SystemState: workitem.SystemStateClosed}, uuid.Nil)
+		require.Nil(s.T(), err)
+		assert.Equal(s.T(), workitem.SystemStateClosed, updated.Fields[workitem.SystemState])
+	}
+
+	// the versions given by the client detect the change
+	_, err = s.repo.Update(ctx, changed.ID, changed.Version, map[string]interface{}{workitem.SystemState: workitem.SystemStateOpen}, uuid.Nil)
+	require.IsType(s.T(), errors.VersionConflictError{}, errs.Cause(err))
+	_, err = s.repo.Update(ctx, created[1].ID, created[1].Version, map[string]interface{}{workitem.SystemState: workitem.SystemStateOpen}, uuid.Nil)
+	require.IsType(s.T(), errors.VersionConflictError{}, errs.Cause(err))
+}
+
+func (s *workItemRepoBlackBoxTest) TestRevisions() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+
+	creator := uuid.NewV4()
+	modifier := uuid.NewV4()
+	wi, err := s.repo.Create(
+		context.Background(), nil, workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle: "Title",
+			workitem.SystemState: workitem.SystemStateNew,
+		}, creator.String())
+	require.Nil(s.T(), err, "Could not create workitem")
+	updated, err := s.repo.Update(context.Background(), wi.ID, wi.Version, map[string]interface{}{
+		workitem.SystemState: workitem.SystemStateOpen,
+	}, modifier)
+	require.Nil(s.T(), err)
+	err = s.repo.Delete(context.Background(), wi.ID, modifier)
+	require.Nil(s.T(), err)
+
+	revisions, err := workitem.NewRevisionRepository(s.DB).List(context.Background(), wi.ID)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), revisions, 3)
+	assert.Equal(s.T(), workitem.RevisionTypeCreate, revisions[0].Type)
+	assert.Equal(s.T(), creator, revisions[0].ModifierIdentity)
+	assert.Nil(s.T(), revisions[0].OldFields)
+	assert.Equal(s.T(), workitem.SystemStateNew, revisions[0].Fields[workitem.SystemState])
+
+	assert.Equal(s.T(), workitem.RevisionTypeUpdate, revisions[1].Type)
+	assert.Equal(s.T(), modifier, revisions[1].ModifierIdentity)
+	assert.Equal(s.T(), updated.Version, revisions[1].WorkItemVersion)
+	assert.Equal(s.T(), workitem.SystemStateNew, revisions[1].OldFields[workitem.SystemState])
+	assert.Equal(s.T(), workitem.SystemStateOpen, revisions[1].Fields[workitem.SystemState])
+
+	assert.Equal(s.T(), workitem.RevisionTypeDelete, revisions[2].Type)
+	assert.Equal(s.T(), workitem.SystemStateOpen, revisions[2].OldFields[workitem.SystemState])
+	assert.Nil(s.T(), revisions[2].Fields)
+
+	_, err = workitem.NewRevisionRepository(s.DB).List(context.Background(), "xyz")
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
 }
 
 func (s *workItemRepoBlackBoxTest) TestSaveForUnchangedCreatedDate() {
 	defer cleaner.DeleteCreatedEntities(s.DB)()
 
 	wi, err := s.repo.Create(
-		context.Background(), workitem.SystemBug,
+		context.Background(), nil, workitem.SystemBug,
 		map[string]interface{}{
 			workitem.SystemTitle: "Title",
 			workitem.SystemState: workitem.SystemStateNew,
@@ -127,7 +255,7 @@ func (s *workItemRepoBlackBoxTest) TestSaveForUnchangedCreatedDate() {
 	wi, err = s.repo.Load(context.Background(), wi.ID)
 	require.Nil(s.T(), err)
 
-	wiNew, err := s.repo.Save(context.Background(), *wi)
+	wiNew, err := s.repo.Save(context.Background(), *wi, uuid.Nil)
 	require.Nil(s.T(), err)
 	assert.Equal(s.T(), wi.Fields[workitem.SystemCreatedAt], wiNew.Fields[workitem.SystemCreatedAt])
 }
@@ -136,7 +264,7 @@ func (s *workItemRepoBlackBoxTest) TestCreateWorkItemWithDescriptionNoMarkup() {
 	defer cleaner.DeleteCreatedEntities(s.DB)()
 
 	wi, err := s.repo.Create(
-		context.Background(), workitem.SystemBug,
+		context.Background(), nil, workitem.SystemBug,
 		map[string]interface{}{
 			workitem.SystemTitle:       "Title",
 			workitem.SystemDescription: rendering.NewMarkupContentFromLegacy("Description"),
@@ -153,7 +281,7 @@ func (s *workItemRepoBlackBoxTest) TestCreateWorkItemWithDescriptionNoMarkup() {
 func (s *workItemRepoBlackBoxTest) TestCreateWorkItemWithDescriptionMarkup() {
 	defer cleaner.DeleteCreatedEntities(s.DB)()
 	wi, err := s.repo.Create(
-		context.Background(), workitem.SystemBug,
+		context.Background(), nil, workitem.SystemBug,
 		map[string]interface{}{
 			workitem.SystemTitle:       "Title",
 			workitem.SystemDescription: rendering.NewMarkupContent("Description", rendering.SystemMarkupMarkdown),
@@ -174,7 +302,7 @@ func (s *workItemRepoBlackBoxTest) TestTypeChangeIsNotProhibitedOnDBLayer() {
 
 	// Create at least 1 item to avoid RowsAffectedCheck
 	wi, err := s.repo.Create(
-		context.Background(), "bug",
+		context.Background(), nil, "bug",
 		map[string]interface{}{
 			workitem.SystemTitle: "Title",
 			workitem.SystemState: workitem.SystemStateNew,
@@ -184,7 +312,395 @@ func (s *workItemRepoBlackBoxTest) TestTypeChangeIsNotProhibitedOnDBLayer() {
 
 	wi.Type = "feature"
 
-	newWi, err := s.repo.Save(context.Background(), *wi)
+	newWi, err := s.repo.Save(context.Background(), *wi, uuid.Nil)
 	require.Nil(s.T(), err)
 	require.Equal(s.T(), "feature", newWi.Type)
 }
+
+func (s *workItemRepoBlackBoxTest) TestAggregate() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+
+	title := "TestAggregate " + uuid.NewV4().String()
+	a, b := s.createIdentity(), s.createIdentity()
+	create := func(state string, assignees []string) {
+		_, err := s.repo.Create(
+			context.Background(), nil, workitem.SystemBug,
+			map[string]interface{}{
+				workitem.SystemTitle:     title,
+				workitem.SystemState:     state,
+				workitem.SystemAssignees: assignees,
+			}, "xx")
+		require.Nil(s.T(), err, "Could not create workitem")
+	}
+	create(workitem.SystemStateNew, []string{a, b})
+	create(workitem.SystemStateNew, []string{a})
+	create(workitem.SystemStateOpen, nil)
+	filter := criteria.Equals(criteria.Field(workitem.SystemTitle), criteria.Literal(title))
+
+	buckets, total, err := s.repo.Aggregate(context.Background(), filter, workitem.SystemState)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), uint64(3), total)
+	assert.Equal(s.T(), []workitem.Bucket{
+		{Value: workitem.SystemStateNew, Count: 2},
+		{Value: workitem.SystemStateOpen, Count: 1},
+	}, buckets)
+
+	buckets, total, err = s.repo.Aggregate(context.Background(), filter, workitem.SystemAssignees)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), uint64(3), total)
+	assert.Equal(s.T(), []workitem.Bucket{
+		{Value: a, Count: 2},
+		{Value: b, Count: 1},
+		{Value: nil, Count: 1},
+	}, buckets)
+
+	_, _, err = s.repo.Aggregate(context.Background(), filter, workitem.SystemDescription)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+}
+
+func (s *workItemRepoBlackBoxTest) TestReorder() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+
+	title := "TestReorder " + uuid.NewV4().String()
+	create := func() string {
+		wi, err := s.repo.Create(
+			context.Background(), nil, workitem.SystemBug,
+			map[string]interface{}{
+				workitem.SystemTitle: title,
+				workitem.SystemState: workitem.SystemStateNew,
+			}, "xx")
+		require.Nil(s.T(), err, "Could not create workitem")
+		require.NotNil(s.T(), wi.Rank)
+		return wi.ID
+	}
+	a, b, c := create(), create(), create()
+	backlog := func() []string {
+		filter := criteria.Equals(criteria.Field(workitem.SystemTitle), criteria.Literal(title))
+		items, _, _, err := s.repo.List(context.Background(), filter, []criteria.OrderBy{{FieldName: "rank"}}, nil, nil, nil)
+		require.Nil(s.T(), err)
+		ids := []string{}
+		for _, wi := range items {
+			ids = append(ids, wi.ID)
+		}
+		return ids
+	}
+	// new work items are appended to the backlog
+	assert.Equal(s.T(), []string{a, b, c}, backlog())
+
+	moved, err := s.repo.Reorder(context.Background(), c, workitem.ReorderAbove, a)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), c, moved.ID)
+	assert.Equal(s.T(), []string{c, a, b}, backlog())
+
+	_, err = s.repo.Reorder(context.Background(), a, workitem.ReorderBelow, b)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), []string{c, b, a}, backlog())
+
+	// ranks are allocated per space and other spaces are not rebalanced
+	sp, err := space.NewRepository(s.DB).Create(context.Background(), &space.Space{Name: "ranks " + uuid.NewV4().String()})
+	require.Nil(s.T(), err)
+	other, err := s.repo.Create(
+		context.Background(), &sp.ID, workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle: "Other " + title,
+			workitem.SystemState: workitem.SystemStateNew,
+		}, "xx")
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), workitem.RankAfter(""), *other.Rank)
+
+	// moving a work item to the top again and again makes the ranks dense until they are rebalanced
+	for i := 0; i < 100; i++ {
+		top, next := a, c
+		if i%2 == 1 {
+			top, next = c, a
+		}
+		moved, err = s.repo.Reorder(context.Background(), top, workitem.ReorderAbove, next)
+		require.Nil(s.T(), err)
+		assert.True(s.T(), len(*moved.Rank) <= 32, *moved.Rank)
+	}
+	assert.Equal(s.T(), []string{c, a, b}, backlog())
+	loaded, err := s.repo.Load(context.Background(), other.ID)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), *other.Rank, *loaded.Rank)
+
+	_, err = s.repo.Reorder(context.Background(), a, workitem.ReorderAbove, a)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	_, err = s.repo.Reorder(context.Background(), a, workitem.ReorderAbove, "4294967295")
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	_, err = s.repo.Reorder(context.Background(), a, "sideways", b)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	_, err = s.repo.Reorder(context.Background(), "4294967295", workitem.ReorderAbove, b)
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
+
+func (s *workItemRepoBlackBoxTest) TestWorkflow() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+
+	witRepo := workitem.NewWorkItemTypeRepository(s.DB)
+	stString := "string"
+	_, err := witRepo.Create(context.Background(), nil, nil, "workflowtest", map[string]app.FieldDefinition{
+		workitem.SystemTitle: {Required: true, Type: &app.FieldType{Kind: "string"}},
+		workitem.SystemState: {Required: true, Type: &app.FieldType{Kind: "enum", BaseType: &stString, Values: []interface{}{"new", "in progress", "resolved", "closed"}}},
+		"resolution":         {Required: false, Type: &app.FieldType{Kind: "string"}},
+	})
+	require.Nil(s.T(), err)
+	guard := `{"resolution":"not fixed"}`
+	_, err = witRepo.Update(context.Background(), nil, "workflowtest", 0, map[string]app.FieldDefinition{}, nil, &app.Workflow{
+		Transitions: []*app.WorkflowTransition{
+			{From: "new", To: "in progress"},
+			{From: "in progress", To: "resolved"},
+			{From: "resolved", To: "closed"},
+			{From: "resolved", To: "in progress", Guard: &guard},
+		},
+		RequiredFields: map[string][]string{"resolved": {"resolution"}},
+	})
+	require.Nil(s.T(), err)
+	defer workitem.ClearGlobalWorkItemTypeCache()
+
+	wi, err := s.repo.Create(context.Background(), nil, "workflowtest", map[string]interface{}{
+		workitem.SystemTitle: "Title",
+		workitem.SystemState: "new",
+	}, "xx")
+	require.Nil(s.T(), err)
+	states, err := s.repo.NextStates(context.Background(), wi.ID)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), []workitem.NextState{{State: "in progress"}}, states)
+
+	_, err = s.repo.Update(context.Background(), wi.ID, wi.Version, map[string]interface{}{workitem.SystemState: "closed"}, uuid.Nil)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	wi, err = s.repo.Update(context.Background(), wi.ID, wi.Version, map[string]interface{}{workitem.SystemState: "in progress"}, uuid.Nil)
+	require.Nil(s.T(), err)
+
+	// entering resolved requires a resolution
+	states, err = s.repo.NextStates(context.Background(), wi.ID)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), []workitem.NextState{{State: "resolved", RequiredFields: []string{"resolution"}}}, states)
+	_, err = s.repo.Update(context.Background(), wi.ID, wi.Version, map[string]interface{}{workitem.SystemState: "resolved"}, uuid.Nil)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	wi, err = s.repo.Update(context.Background(), wi.ID, wi.Version, map[string]interface{}{workitem.SystemState: "resolved", "resolution": "fixed"}, uuid.Nil)
+	require.Nil(s.T(), err)
+
+	// the guard only allows reopening work items that were not fixed
+	states, err = s.repo.NextStates(context.Background(), wi.ID)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), []workitem.NextState{{State: "closed"}}, states)
+	_, err = s.repo.Update(context.Background(), wi.ID, wi.Version, map[string]interface{}{workitem.SystemState: "in progress"}, uuid.Nil)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	wi, err = s.repo.Update(context.Background(), wi.ID, wi.Version, map[string]interface{}{"resolution": "not fixed"}, uuid.Nil)
+	require.Nil(s.T(), err)
+	states, err = s.repo.NextStates(context.Background(), wi.ID)
+	require.Nil(s.T(), err)
+	assert.Len(s.T(), states, 2)
+	_, err = s.repo.Update(context.Background(), wi.ID, wi.Version, map[string]interface{}{workitem.SystemState: "in progress"}, uuid.Nil)
+	require.Nil(s.T(), err)
+
+	_, err = s.repo.NextStates(context.Background(), "0")
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
+
+func (s *workItemRepoBlackBoxTest) TestAreaAndLabelReferences() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+	defer workitem.ClearGlobalWorkItemTypeCache()
+
+	ctx := context.Background()
+	sp, err := space.NewRepository(s.DB).Create(ctx, &space.Space{Name: "references " + uuid.NewV4().String()})
+	require.Nil(s.T(), err)
+	other, err := space.NewRepository(s.DB).Create(ctx, &space.Space{Name: "other references " + uuid.NewV4().String()})
+	require.Nil(s.T(), err)
+	a := area.Area{SpaceID: sp.ID, Name: "backend"}
+	require.Nil(s.T(), area.NewAreaRepository(s.DB).Create(ctx, &a))
+	l := label.Label{SpaceID: sp.ID, Name: "urgent", Color: "#ff0000"}
+	require.Nil(s.T(), label.NewLabelRepository(s.DB).Create(ctx, &l))
+	otherLabel := label.Label{SpaceID: other.ID, Name: "urgent", Color: "#ff0000"}
+	require.Nil(s.T(), label.NewLabelRepository(s.DB).Create(ctx, &otherLabel))
+
+	stLabel := "label"
+	_, err = workitem.NewWorkItemTypeRepository(s.DB).Create(ctx, &sp.ID, nil, "referencetest", map[string]app.FieldDefinition{
+		workitem.SystemTitle: {Required: true, Type: &app.FieldType{Kind: "string"}},
+		"area":               {Required: false, Type: &app.FieldType{Kind: "area"}},
+		"labels":             {Required: false, Type: &app.FieldType{Kind: "list", ComponentType: &stLabel}},
+		"blocked":            {Required: false, Type: &app.FieldType{Kind: "boolean"}},
+		"targetdate":         {Required: false, Type: &app.FieldType{Kind: "date"}},
+	})
+	require.Nil(s.T(), err)
+
+	wi, err := s.repo.Create(ctx, &sp.ID, "referencetest", map[string]interface{}{
+		workitem.SystemTitle: "Title",
+		"area":               a.ID.String(),
+		"labels":             []interface{}{l.ID.String(), l.ID.String()},
+		"blocked":            true,
+		"targetdate":         "2017-03-01",
+	}, "xx")
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), a.ID.String(), wi.Fields["area"])
+	assert.Equal(s.T(), []interface{}{l.ID.String()}, wi.Fields["labels"])
+	assert.Equal(s.T(), true, wi.Fields["blocked"])
+	assert.Equal(s.T(), "2017-03-01", wi.Fields["targetdate"])
+
+	for name, fields := range map[string]map[string]interface{}{
+		"unknown area":             {"area": uuid.NewV4().String()},
+		"unknown label":            {"labels": []interface{}{uuid.NewV4().String()}},
+		"label of the other space": {"labels": []interface{}{l.ID.String(), otherLabel.ID.String()}},
+		"label name":               {"labels": []interface{}{"urgent"}},
+	} {
+		_, err = s.repo.Update(ctx, wi.ID, wi.Version, fields, uuid.Nil)
+		require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err), name)
+	}
+	_, err = s.repo.Update(ctx, wi.ID, wi.Version, map[string]interface{}{"labels": nil, "area": nil}, uuid.Nil)
+	require.Nil(s.T(), err)
+}
+
+func (s *workItemRepoBlackBoxTest) TestUserIterationAndWorkItemReferences() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+
+	ctx := context.Background()
+	assignee := s.createIdentity()
+	sprint := iteration.Iteration{Name: "Sprint " + uuid.NewV4().String()}
+	require.Nil(s.T(), iteration.NewIterationRepository(s.DB).Create(ctx, &sprint))
+	wi, err := s.repo.Create(ctx, nil, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle:     "Title",
+		workitem.SystemState:     workitem.SystemStateNew,
+		workitem.SystemAssignees: []string{assignee},
+		workitem.SystemIteration: sprint.ID.String(),
+	}, "xx")
+	require.Nil(s.T(), err)
+
+	for name, fields := range map[string]map[string]interface{}{
+		"unknown assignee":  {workitem.SystemAssignees: []string{assignee, uuid.NewV4().String()}},
+		"assignee name":     {workitem.SystemAssignees: []string{"pranav"}},
+		"unknown iteration": {workitem.SystemIteration: uuid.NewV4().String()},
+	} {
+		_, err = s.repo.Update(ctx, wi.ID, wi.Version, fields, uuid.Nil)
+		require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err), name)
+		_, err = s.repo.Create(ctx, nil, workitem.SystemBug, map[string]interface{}{
+			workitem.SystemTitle:     "Title",
+			workitem.SystemState:     workitem.SystemStateNew,
+			workitem.SystemAssignees: fields[workitem.SystemAssignees],
+			workitem.SystemIteration: fields[workitem.SystemIteration],
+		}, "xx")
+		require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err), name)
+	}
+
+	// the assignees of imported work items are user names of the remote tracker
+	_, err = s.repo.Create(ctx, nil, workitem.SystemBug, map[string]interface{}{
+		workitem.SystemTitle:        "Title",
+		workitem.SystemState:        workitem.SystemStateNew,
+		workitem.SystemAssignees:    []string{"pranav"},
+		workitem.SystemRemoteItemID: "https://api.github.com/repos/almighty-test/almighty-test-unit/issues/1",
+	}, "xx")
+	require.Nil(s.T(), err)
+}
+
+func (s *workItemRepoBlackBoxTest) TestDefaultAndComputedValues() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+	defer workitem.ClearGlobalWorkItemTypeCache()
+
+	ctx := context.Background()
+	sp, err := space.NewRepository(s.DB).Create(ctx, &space.Space{Name: "values " + uuid.NewV4().String()})
+	require.Nil(s.T(), err)
+	iterationRepo := iteration.NewIterationRepository(s.DB)
+	sprint := iteration.Iteration{SpaceID: sp.ID, Name: "Sprint 1"}
+	require.Nil(s.T(), iterationRepo.Create(ctx, &sprint))
+	sprint.State = iteration.IterationStateStart
+	_, err = iterationRepo.Save(ctx, sprint)
+	require.Nil(s.T(), err)
+
+	defaultFrom := func(context string) *string { return &context }
+	computed := func(exp string) *string { return &exp }
+	_, err = workitem.NewWorkItemTypeRepository(s.DB).Create(ctx, &sp.ID, nil, "valuesourcetest", map[string]app.FieldDefinition{
+		workitem.SystemTitle: {Required: true, Type: &app.FieldType{Kind: "string"}},
+		"reviewer":           {Required: true, Type: &app.FieldType{Kind: "user"}, DefaultFrom: defaultFrom(workitem.DefaultFromCurrentUser)},
+		"sprint":             {Required: false, Type: &app.FieldType{Kind: "iteration"}, DefaultFrom: defaultFrom(workitem.DefaultFromCurrentIteration)},
+		"due":                {Required: false, Type: &app.FieldType{Kind: "date"}, DefaultFrom: defaultFrom(workitem.DefaultFromNow)},
+		"priority":           {Required: true, Type: &app.FieldType{Kind: "integer"}, Default: float64(2)},
+		"storypoints":        {Required: false, Type: &app.FieldType{Kind: "integer"}},
+		"total":              {Required: true, Type: &app.FieldType{Kind: "float"}, Computed: computed("sum(children.storypoints)")},
+		"children":           {Required: false, Type: &app.FieldType{Kind: "integer"}, Computed: computed("count(children)")},
+	})
+	require.Nil(s.T(), err)
+
+	creator := uuid.NewV4().String()
+	parent, err := s.repo.Create(ctx, &sp.ID, "valuesourcetest", map[string]interface{}{
+		workitem.SystemTitle: "Parent",
+		"total":              100.0,
+	}, creator)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), creator, parent.Fields["reviewer"])
+	assert.Equal(s.T(), sprint.ID.String(), parent.Fields["sprint"])
+	assert.Equal(s.T(), time.Now().Format(workitem.DateFormat), parent.Fields["due"])
+	assert.Equal(s.T(), 2, parent.Fields["priority"])
+	// computed values cannot be set
+	assert.Equal(s.T(), 0.0, parent.Fields["total"])
+	assert.Equal(s.T(), 0, parent.Fields["children"])
+
+	// given values take precedence over the defaults
+	child, err := s.repo.Create(ctx, &sp.ID, "valuesourcetest", map[string]interface{}{
+		workitem.SystemTitle: "Child",
+		"priority":           1,
+		"storypoints":        3,
+	}, creator)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, child.Fields["priority"])
+
+	category := link.WorkItemLinkCategory{Name: "values " + uuid.NewV4().String()}
+	require.Nil(s.T(), s.DB.Create(&category).Error)
+	linkType := link.WorkItemLinkType{
+		Name:           "values " + uuid.NewV4().String(),
+		Topology:       link.TopologyTree,
+		SourceTypeName: "valuesourcetest",
+		TargetTypeName: "valuesourcetest",
+		ForwardName:    "parent of",
+		ReverseName:    "child of",
+		LinkCategoryID: category.ID,
+	}
+	require.Nil(s.T(), s.DB.Create(&linkType).Error)
+	parentID, err := strconv.ParseUint(parent.ID, 10, 64)
+	require.Nil(s.T(), err)
+	childID, err := strconv.ParseUint(child.ID, 10, 64)
+	require.Nil(s.T(), err)
+	require.Nil(s.T(), s.DB.Create(&link.WorkItemLink{SourceID: parentID, TargetID: childID, LinkTypeID: linkType.ID}).Error)
+
+	updated, err := s.repo.Update(ctx, parent.ID, parent.Version, map[string]interface{}{workitem.SystemTitle: "Updated parent"}, uuid.Nil)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 3.0, updated.Fields["total"])
+	assert.Equal(s.T(), 1, updated.Fields["children"])
+	// defaults only apply to new work items
+	updated, err = s.repo.Update(ctx, updated.ID, updated.Version, map[string]interface{}{"sprint": nil}, uuid.Nil)
+	require.Nil(s.T(), err)
+	assert.Nil(s.T(), updated.Fields["sprint"])
+
+	// changes of children and links recompute the parent
+	secondChild, err := s.repo.Create(ctx, &sp.ID, "valuesourcetest", map[string]interface{}{
+		workitem.SystemTitle: "Second child",
+		"storypoints":        5,
+	}, creator)
+	require.Nil(s.T(), err)
+	secondChildID, err := strconv.ParseUint(secondChild.ID, 10, 64)
+	require.Nil(s.T(), err)
+	linkRepo := link.NewWorkItemLinkRepository(s.DB)
+	secondLink, err := linkRepo.Create(ctx, parentID, secondChildID, linkType.ID, nil)
+	require.Nil(s.T(), err)
+	loaded, err := s.repo.Load(ctx, parent.ID)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 8.0, loaded.Fields["total"])
+	assert.Equal(s.T(), 2, loaded.Fields["children"])
+	assert.Equal(s.T(), updated.Version, loaded.Version)
+
+	_, err = s.repo.Update(ctx, child.ID, child.Version, map[string]interface{}{"storypoints": 4}, uuid.Nil)
+	require.Nil(s.T(), err)
+	loaded, err = s.repo.Load(ctx, parent.ID)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 9.0, loaded.Fields["total"])
+
+	require.Nil(s.T(), linkRepo.Delete(ctx, secondLink.Data.ID.String()))
+	loaded, err = s.repo.Load(ctx, parent.ID)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 4.0, loaded.Fields["total"])
+	assert.Equal(s.T(), 1, loaded.Fields["children"])
+
+	require.Nil(s.T(), s.repo.Delete(ctx, child.ID, uuid.Nil))
+	loaded, err = s.repo.Load(ctx, parent.ID)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 0.0, loaded.Fields["total"])
+	assert.Equal(s.T(), 0, loaded.Fields["children"])
+}
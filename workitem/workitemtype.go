@@ -1,6 +1,7 @@
 package workitem
 
 import (
+	"reflect"
 	"strconv"
 	"strings"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/almighty/almighty-core/convert"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/pkg/errors"
+	satoriuuid "github.com/satori/go.uuid"
 )
 
 // String constants for the local work item types.
@@ -47,14 +49,20 @@ const (
 // WorkItemType represents a work item type as it is stored in the db
 type WorkItemType struct {
 	gormsupport.Lifecycle
-	// the unique name of this work item type.
-	Name string `gorm:"primary_key"`
+	ID satoriuuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	// the name of this work item type, unique within its space. The names of
+	// system types are unique across all spaces.
+	Name string
 	// Version for optimistic concurrency control
 	Version int
 	// the id's of the parents, separated with some separator
 	Path string
 	// definitions of the fields this work item type supports
 	Fields FieldDefinitions `sql:"type:jsonb"`
+	// the space this type belongs to, system types available in all spaces have none
+	SpaceID *satoriuuid.UUID `sql:"type:uuid"`
+	// the allowed changes of the state of work items of this type
+	Workflow Workflow `sql:"type:jsonb"`
 }
 
 // GetTypePathSeparator returns the work item type's path separator "."
@@ -80,6 +88,9 @@ func (wit WorkItemType) Equal(u convert.Equaler) bool {
 	if !wit.Lifecycle.Equal(other.Lifecycle) {
 		return false
 	}
+	if !satoriuuid.Equal(wit.ID, other.ID) {
+		return false
+	}
 	if wit.Version != other.Version {
 		return false
 	}
@@ -89,6 +100,12 @@ func (wit WorkItemType) Equal(u convert.Equaler) bool {
 	if wit.Path != other.Path {
 		return false
 	}
+	if (wit.SpaceID == nil) != (other.SpaceID == nil) || (wit.SpaceID != nil && !satoriuuid.Equal(*wit.SpaceID, *other.SpaceID)) {
+		return false
+	}
+	if !reflect.DeepEqual(wit.Workflow, other.Workflow) {
+		return false
+	}
 	if len(wit.Fields) != len(other.Fields) {
 		return false
 	}
@@ -109,7 +126,9 @@ func (wit WorkItemType) ConvertFromModel(workItem WorkItem) (*app.WorkItem, erro
 	result := app.WorkItem{
 		ID:      strconv.FormatUint(workItem.ID, 10),
 		Type:    workItem.Type,
+		Space:   workItem.SpaceID,
 		Version: workItem.Version,
+		Rank:    &workItem.Rank,
 		Fields:  map[string]interface{}{}}
 
 	for name, field := range wit.Fields {
@@ -3,11 +3,14 @@ package workitem
 import (
 	"log"
 	"sync"
+
+	satoriuuid "github.com/satori/go.uuid"
 )
 
 // WorkItemTypeCache represents WorkItemType cache
 type WorkItemTypeCache struct {
 	cache   map[string]WorkItemType
+	all     bool
 	mapLock sync.RWMutex
 }
 
@@ -18,12 +21,21 @@ func NewWorkItemTypeCache() *WorkItemTypeCache {
 	return &witCache
 }
 
-// Get returns WorkItemType by name.
+// cacheKey returns the key of the type with the given name of the given
+// space, system types are keyed by their name only
+func cacheKey(spaceID *satoriuuid.UUID, typeName string) string {
+	if spaceID == nil {
+		return typeName
+	}
+	return spaceID.String() + "/" + typeName
+}
+
+// Get returns WorkItemType by space and name, the space of system types is nil.
 // The second value (ok) is a bool that is true if the WorkItemType exists in the cache, and false if not.
-func (c *WorkItemTypeCache) Get(typeName string) (WorkItemType, bool) {
+func (c *WorkItemTypeCache) Get(spaceID *satoriuuid.UUID, typeName string) (WorkItemType, bool) {
 	c.mapLock.RLock()
 	defer c.mapLock.RUnlock()
-	w, ok := c.cache[typeName]
+	w, ok := c.cache[cacheKey(spaceID, typeName)]
 	return w, ok
 }
 
@@ -31,7 +43,32 @@ func (c *WorkItemTypeCache) Get(typeName string) (WorkItemType, bool) {
 func (c *WorkItemTypeCache) Put(wit WorkItemType) {
 	c.mapLock.Lock()
 	defer c.mapLock.Unlock()
-	c.cache[wit.Name] = wit
+	c.cache[cacheKey(wit.SpaceID, wit.Name)] = wit
+}
+
+// GetAll returns all work item types.
+// The second value (ok) is a bool that is true if all work item types have been put to the cache with PutAll, and false if not.
+func (c *WorkItemTypeCache) GetAll() ([]WorkItemType, bool) {
+	c.mapLock.RLock()
+	defer c.mapLock.RUnlock()
+	if !c.all {
+		return nil, false
+	}
+	result := make([]WorkItemType, 0, len(c.cache))
+	for _, wit := range c.cache {
+		result = append(result, wit)
+	}
+	return result, true
+}
+
+// PutAll puts all existing work item types to the cache
+func (c *WorkItemTypeCache) PutAll(wits []WorkItemType) {
+	c.mapLock.Lock()
+	defer c.mapLock.Unlock()
+	for _, wit := range wits {
+		c.cache[cacheKey(wit.SpaceID, wit.Name)] = wit
+	}
+	c.all = true
 }
 
 // Clear clears the cache
@@ -40,4 +77,5 @@ func (c *WorkItemTypeCache) Clear() {
 	defer c.mapLock.Unlock()
 	log.Println("Clearing work item cache")
 	c.cache = make(map[string]WorkItemType)
+	c.all = false
 }
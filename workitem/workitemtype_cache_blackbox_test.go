@@ -6,6 +6,7 @@ import (
 
 	"github.com/almighty/almighty-core/resource"
 	"github.com/almighty/almighty-core/workitem"
+	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,7 +16,7 @@ func TestGetNotExistingTypeReturnsNotOk(t *testing.T) {
 	t.Parallel()
 	resource.Require(t, resource.UnitTest)
 
-	_, ok := cache.Get("testNotExistingType")
+	_, ok := cache.Get(nil, "testNotExistingType")
 	assert.False(t, ok)
 }
 
@@ -23,13 +24,13 @@ func TestGetReturnsPreviouslyPutWIT(t *testing.T) {
 	t.Parallel()
 	resource.Require(t, resource.UnitTest)
 
-	_, ok := cache.Get("testReadingWriting")
+	_, ok := cache.Get(nil, "testReadingWriting")
 	assert.False(t, ok)
 
 	wit := workitem.WorkItemType{Name: "testReadingWriting"}
 	cache.Put(wit)
 
-	cachedWit, ok := cache.Get("testReadingWriting")
+	cachedWit, ok := cache.Get(nil, "testReadingWriting")
 	assert.True(t, ok)
 	assert.Equal(t, wit, cachedWit)
 }
@@ -40,11 +41,52 @@ func TestGetReturnNotOkAfterClear(t *testing.T) {
 
 	c := workitem.NewWorkItemTypeCache()
 	c.Put(workitem.WorkItemType{Name: "testClear"})
-	_, ok := c.Get("testClear")
+	_, ok := c.Get(nil, "testClear")
 	assert.True(t, ok)
 
 	c.Clear()
-	_, ok = c.Get("testClear")
+	_, ok = c.Get(nil, "testClear")
+	assert.False(t, ok)
+}
+
+func TestGetReturnsTypeOfSpace(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	c := workitem.NewWorkItemTypeCache()
+
+	spaceID1, spaceID2 := uuid.NewV4(), uuid.NewV4()
+	wit1 := workitem.WorkItemType{Name: "testSpace", SpaceID: &spaceID1, Version: 1}
+	wit2 := workitem.WorkItemType{Name: "testSpace", SpaceID: &spaceID2, Version: 2}
+	c.Put(wit1)
+	c.Put(wit2)
+
+	cachedWit, ok := c.Get(&spaceID1, "testSpace")
+	assert.True(t, ok)
+	assert.Equal(t, wit1, cachedWit)
+	cachedWit, ok = c.Get(&spaceID2, "testSpace")
+	assert.True(t, ok)
+	assert.Equal(t, wit2, cachedWit)
+	_, ok = c.Get(nil, "testSpace")
+	assert.False(t, ok)
+}
+
+func TestGetAllReturnsTypesAfterPutAll(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	c := workitem.NewWorkItemTypeCache()
+	c.Put(workitem.WorkItemType{Name: "testSingle"})
+	_, ok := c.GetAll()
+	assert.False(t, ok)
+
+	wits := []workitem.WorkItemType{{Name: "testAll1"}, {Name: "testAll2"}}
+	c.PutAll(wits)
+	all, ok := c.GetAll()
+	assert.True(t, ok)
+	assert.Len(t, all, 3)
+
+	c.Clear()
+	_, ok = c.GetAll()
 	assert.False(t, ok)
 }
 
@@ -67,7 +109,7 @@ func TestNoFailuresWithConcurrentMapReadAndMapWrite(t *testing.T) {
 	go func() {
 		defer wg.Done()
 		for i := 0; i < 1000; i++ {
-			cachedWit, ok := cache.Get("testConcurrentAccess")
+			cachedWit, ok := cache.Get(nil, "testConcurrentAccess")
 			assert.True(t, ok)
 			assert.Equal(t, wit, cachedWit)
 		}
@@ -0,0 +1,43 @@
+package workitem
+
+import (
+	"fmt"
+
+	"github.com/almighty/almighty-core/errors"
+)
+
+// CheckFieldEvolution returns an error if the definition of the field with the
+// given name cannot be changed from existing to changed without invalidating
+// the values stored in existing work items; existing is nil for new fields.
+// Allowed changes are adding optional fields, making required fields optional,
+// (un)deprecating fields and adding values to enums.
+// returns BadParameterError
+func CheckFieldEvolution(name string, existing *FieldDefinition, changed FieldDefinition) error {
+	if changed.Required && changed.Deprecated {
+		return errors.NewBadParameterError("fields."+name+".required", true).Expected("false for a deprecated field")
+	}
+	if existing == nil {
+		if changed.Required {
+			return errors.NewBadParameterError("fields."+name+".required", true).Expected("false for a new field")
+		}
+		return nil
+	}
+	if changed.Required && !existing.Required {
+		return errors.NewBadParameterError("fields."+name+".required", true).Expected("false for an optional field")
+	}
+	if existing.Type.Equal(changed.Type) {
+		return nil
+	}
+	existingEnum, isEnum := existing.Type.(EnumType)
+	changedEnum, staysEnum := changed.Type.(EnumType)
+	if !isEnum || !staysEnum || !existingEnum.BaseType.Equal(changedEnum.BaseType) {
+		return errors.NewBadParameterError("fields."+name+".type", changed.Type.GetKind()).Expected(fmt.Sprintf("the unchanged type of kind %s", existing.Type.GetKind()))
+	}
+	// values may only be added to an enum, work items could still use the others
+	for _, value := range existingEnum.Values {
+		if !contains(changedEnum.Values, value) {
+			return errors.NewBadParameterError("fields."+name+".type.values", changedEnum.Values).Expected(fmt.Sprintf("all existing values %v", existingEnum.Values))
+		}
+	}
+	return nil
+}
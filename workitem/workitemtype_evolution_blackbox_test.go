@@ -0,0 +1,51 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/resource"
+	. "github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckFieldEvolution(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	str := SimpleType{Kind: KindString}
+	enum := EnumType{SimpleType: SimpleType{Kind: KindEnum}, BaseType: str, Values: []interface{}{"a", "b"}}
+	required := FieldDefinition{Required: true, Type: str}
+	optional := FieldDefinition{Type: str}
+
+	for name, test := range map[string]struct {
+		existing *FieldDefinition
+		changed  FieldDefinition
+	}{
+		"new optional field":    {nil, optional},
+		"unchanged field":       {&required, required},
+		"make field optional":   {&required, optional},
+		"deprecate field":       {&optional, FieldDefinition{Deprecated: true, Type: str}},
+		"add enum value":        {&FieldDefinition{Type: enum}, FieldDefinition{Type: EnumType{SimpleType: enum.SimpleType, BaseType: str, Values: []interface{}{"b", "a", "c"}}}},
+		"make enum optional":    {&FieldDefinition{Required: true, Type: enum}, FieldDefinition{Type: enum}},
+		"reorder enum values":   {&FieldDefinition{Type: enum}, FieldDefinition{Type: EnumType{SimpleType: enum.SimpleType, BaseType: str, Values: []interface{}{"b", "a"}}}},
+		"undeprecate the field": {&FieldDefinition{Deprecated: true, Type: str}, optional},
+	} {
+		assert.Nil(t, CheckFieldEvolution("f", test.existing, test.changed), name)
+	}
+
+	for name, test := range map[string]struct {
+		existing *FieldDefinition
+		changed  FieldDefinition
+	}{
+		"new required field":         {nil, required},
+		"make field required":        {&optional, required},
+		"deprecate required field":   {&required, FieldDefinition{Required: true, Deprecated: true, Type: str}},
+		"change kind":                {&optional, FieldDefinition{Type: SimpleType{Kind: KindInteger}}},
+		"string to enum":             {&optional, FieldDefinition{Type: enum}},
+		"remove enum value":          {&FieldDefinition{Type: enum}, FieldDefinition{Type: EnumType{SimpleType: enum.SimpleType, BaseType: str, Values: []interface{}{"a"}}}},
+		"change enum base type":      {&FieldDefinition{Type: enum}, FieldDefinition{Type: EnumType{SimpleType: enum.SimpleType, BaseType: SimpleType{Kind: KindInteger}, Values: []interface{}{1, 2}}}},
+		"change list component type": {&FieldDefinition{Type: ListType{SimpleType: SimpleType{Kind: KindList}, ComponentType: str}}, FieldDefinition{Type: ListType{SimpleType: SimpleType{Kind: KindList}, ComponentType: SimpleType{Kind: KindUser}}}},
+	} {
+		assert.IsType(t, errors.BadParameterError{}, CheckFieldEvolution("f", test.existing, test.changed), name)
+	}
+}
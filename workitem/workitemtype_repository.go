@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"reflect"
+	"strings"
 
 	"golang.org/x/net/context"
 
@@ -11,20 +12,22 @@ import (
 	"github.com/almighty/almighty-core/errors"
 	"github.com/jinzhu/gorm"
 	errs "github.com/pkg/errors"
+	satoriuuid "github.com/satori/go.uuid"
 )
 
 var cache = NewWorkItemTypeCache()
 
 // WorkItemTypeRepository encapsulates storage & retrieval of work item types
 type WorkItemTypeRepository interface {
-	Load(ctx context.Context, name string) (*app.WorkItemType, error)
-	Create(ctx context.Context, extendedTypeID *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error)
-	List(ctx context.Context, start *int, length *int) ([]*app.WorkItemType, error)
+	Load(ctx context.Context, spaceID *satoriuuid.UUID, name string) (*app.WorkItemType, error)
+	Create(ctx context.Context, spaceID *satoriuuid.UUID, extendedTypeID *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error)
+	Update(ctx context.Context, spaceID *satoriuuid.UUID, name string, version int, fields map[string]app.FieldDefinition, removedFields []string, workflow *app.Workflow) (*app.WorkItemType, error)
+	List(ctx context.Context, spaceID *satoriuuid.UUID, start *int, length *int) ([]*app.WorkItemType, error)
 }
 
 // NewWorkItemRepository creates a wi repository based on gorm
 func NewWorkItemRepository(db *gorm.DB) *GormWorkItemRepository {
-	return &GormWorkItemRepository{db, &GormWorkItemTypeRepository{db}}
+	return &GormWorkItemRepository{db, &GormWorkItemTypeRepository{db}, NewRevisionRepository(db)}
 }
 
 // NewWorkItemTypeRepository creates a wi type repository based on gorm
@@ -37,10 +40,10 @@ type GormWorkItemTypeRepository struct {
 	db *gorm.DB
 }
 
-// Load returns the work item for the given id
+// Load returns the work item type with the given name of the given space, see LoadTypeFromDB
 // returns NotFoundError, InternalError
-func (r *GormWorkItemTypeRepository) Load(ctx context.Context, name string) (*app.WorkItemType, error) {
-	res, err := r.LoadTypeFromDB(name)
+func (r *GormWorkItemTypeRepository) Load(ctx context.Context, spaceID *satoriuuid.UUID, name string) (*app.WorkItemType, error) {
+	res, err := r.LoadTypeFromDB(spaceID, name)
 	if err != nil {
 		return nil, errs.WithStack(err)
 	}
@@ -49,15 +52,27 @@ func (r *GormWorkItemTypeRepository) Load(ctx context.Context, name string) (*ap
 	return &result, nil
 }
 
-// LoadTypeFromDB return work item type for the given id
-func (r *GormWorkItemTypeRepository) LoadTypeFromDB(name string) (*WorkItemType, error) {
+// LoadTypeFromDB returns the work item type with the given name of the given
+// space. The system types are available in all spaces, only system types are
+// returned if no space is given.
+func (r *GormWorkItemTypeRepository) LoadTypeFromDB(spaceID *satoriuuid.UUID, name string) (*WorkItemType, error) {
 	log.Printf("loading work item type %s", name)
-	res, ok := cache.Get(name)
+	// the names of system types are unique across all spaces
+	res, ok := cache.Get(nil, name)
+	if !ok && spaceID != nil {
+		res, ok = cache.Get(spaceID, name)
+	}
 	if !ok {
 		log.Printf("Work item type %s doesn't exist in the cache. Loading from DB...", name)
 		res = WorkItemType{}
 
-		db := r.db.Model(&res).Where("name=?", name).First(&res)
+		db := r.db.Model(&res).Where("name = ?", name)
+		if spaceID != nil {
+			db = db.Where("space_id IS NULL OR space_id = ?", *spaceID)
+		} else {
+			db = db.Where("space_id IS NULL")
+		}
+		db = db.First(&res)
 		if db.RecordNotFound() {
 			log.Printf("not found, res=%v", res)
 			return nil, errors.NewNotFoundError("work item type", name)
@@ -76,43 +91,69 @@ func ClearGlobalWorkItemTypeCache() {
 	cache.Clear()
 }
 
-// Create creates a new work item in the repository
+// checkSpace returns an error if there is no space with the given ID
+// returns BadParameterError or InternalError
+func checkSpace(db *gorm.DB, spaceID satoriuuid.UUID) error {
+	var count int
+	if err := db.Table("spaces").Where("id = ? AND deleted_at IS NULL", spaceID).Count(&count).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	if count == 0 {
+		return errors.NewBadParameterError("space", spaceID).Expected("the ID of an existing space")
+	}
+	return nil
+}
+
+// Create creates a new work item in the repository. Types created without a
+// space are system types available in all spaces, a type of a space may only
+// extend system types or types of the same space. Type names are unique
+// within a space, the names of system types are unique across all spaces.
 // returns BadParameterError, ConversionError or InternalError
-func (r *GormWorkItemTypeRepository) Create(ctx context.Context, extendedTypeName *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error) {
-	existing, _ := r.LoadTypeFromDB(name)
-	if existing != nil {
+func (r *GormWorkItemTypeRepository) Create(ctx context.Context, spaceID *satoriuuid.UUID, extendedTypeName *string, name string, fields map[string]app.FieldDefinition) (*app.WorkItemType, error) {
+	if spaceID != nil {
+		if err := checkSpace(r.db, *spaceID); err != nil {
+			return nil, errs.WithStack(err)
+		}
+	}
+	// system types must not clash with the types of any space
+	existing := r.db.Model(&WorkItemType{}).Where("name = ?", name)
+	if spaceID != nil {
+		existing = existing.Where("space_id IS NULL OR space_id = ?", *spaceID)
+	}
+	var count int
+	if err := existing.Count(&count).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if count > 0 {
 		log.Printf("creating type %s again", name)
 		return nil, errors.NewBadParameterError("name", name)
 	}
 	allFields := map[string]FieldDefinition{}
 	path := name
+	var workflow Workflow
 	if extendedTypeName != nil {
-		extendedType := WorkItemType{}
-		db := r.db.First(&extendedType, "name = ?", extendedTypeName)
-		if db.RecordNotFound() {
-			return nil, errors.NewBadParameterError("extendedTypeName", *extendedTypeName)
-		}
-		if err := db.Error; err != nil {
-			return nil, errors.NewInternalError(err.Error())
+		extendedType, err := r.LoadTypeFromDB(spaceID, *extendedTypeName)
+		if err != nil {
+			if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+				return nil, errors.NewBadParameterError("extendedTypeName", *extendedTypeName).Expected("a system type or a type of the same space")
+			}
+			return nil, errs.WithStack(err)
 		}
-		// copy fields from extended type
+		// copy fields and workflow from extended type
 		for key, value := range extendedType.Fields {
 			allFields[key] = value
 		}
+		workflow = extendedType.Workflow
 		path = extendedType.Path + pathSep + name
 	}
 
 	// now process new fields, checking whether they are ok to add.
 	for field, definition := range fields {
 		existing, exists := allFields[field]
-		ct, err := convertFieldTypeToModels(*definition.Type)
+		converted, err := convertFieldDefinitionToModel(field, definition)
 		if err != nil {
 			return nil, errs.WithStack(err)
 		}
-		converted := FieldDefinition{
-			Required: definition.Required,
-			Type:     ct,
-		}
 		if exists && !compatibleFields(existing, converted) {
 			return nil, fmt.Errorf("incompatible change for field %s", field)
 		}
@@ -120,26 +161,160 @@ func (r *GormWorkItemTypeRepository) Create(ctx context.Context, extendedTypeNam
 	}
 
 	created := WorkItemType{
-		Version: 0,
-		Name:    name,
-		Path:    path,
-		Fields:  allFields,
+		ID:       satoriuuid.NewV4(),
+		Version:  0,
+		Name:     name,
+		Path:     path,
+		Fields:   allFields,
+		SpaceID:  spaceID,
+		Workflow: workflow,
 	}
 
 	if err := r.db.Save(&created).Error; err != nil {
 		return nil, errors.NewInternalError(err.Error())
 	}
+	// the cache may hold the list of all types
+	cache.Clear()
 
 	result := convertTypeFromModels(&created)
 	return &result, nil
 }
 
-// List returns work item types selected by the given criteria.Expression, starting with start (zero-based) and returning at most "limit" item types
-func (r *GormWorkItemTypeRepository) List(ctx context.Context, start *int, limit *int) ([]*app.WorkItemType, error) {
-	// Currently we don't implement filtering here, so leave this empty
+// Update changes the field definitions of the work item type with the given
+// name of the given space, or of the system type if no space is given, and of
+// all types extending it. The given fields are added or replace existing
+// definitions, see CheckFieldEvolution for the allowed changes. The removed
+// fields are deleted from the types and from the fields of all work items of
+// these types. A given workflow replaces the workflow of the type with the
+// given name only. The version must be the same as the stored version.
+// returns NotFoundError, VersionConflictError, BadParameterError or InternalError
+func (r *GormWorkItemTypeRepository) Update(ctx context.Context, spaceID *satoriuuid.UUID, name string, version int, fields map[string]app.FieldDefinition, removedFields []string, workflow *app.Workflow) (*app.WorkItemType, error) {
+	// don't use the cache, we need the current version
+	wit := WorkItemType{}
+	db := r.db.Where("name = ?", name)
+	if spaceID != nil {
+		db = db.Where("space_id = ?", *spaceID)
+	} else {
+		db = db.Where("space_id IS NULL")
+	}
+	db = db.First(&wit)
+	if db.RecordNotFound() {
+		return nil, errors.NewNotFoundError("work item type", name)
+	}
+	if err := db.Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	if wit.Version != version {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+
+	changes := map[string]FieldDefinition{}
+	for field, definition := range fields {
+		if definition.Type == nil {
+			return nil, errors.NewBadParameterError("fields."+field+".type", nil)
+		}
+		if _, err := convertFieldTypeToModels(*definition.Type); err != nil {
+			return nil, errors.NewBadParameterError("fields."+field+".type", err.Error())
+		}
+		converted, err := convertFieldDefinitionToModel(field, definition)
+		if err != nil {
+			return nil, errs.WithStack(err)
+		}
+		changes[field] = converted
+	}
+	for _, field := range removedFields {
+		if _, ok := wit.Fields[field]; !ok {
+			return nil, errors.NewBadParameterError("removedFields", field).Expected("a field of the work item type")
+		}
+		if _, ok := changes[field]; ok {
+			return nil, errors.NewBadParameterError("removedFields", field).Expected("a field that is not changed at the same time")
+		}
+	}
+
+	// subtypes carry copies of the fields of their supertypes. The types of
+	// other spaces may have the same path, the subtypes of system types are
+	// found in all spaces.
+	subtypes := r.db.Where("path <@ ?::ltree", wit.Path)
+	if wit.SpaceID != nil {
+		subtypes = subtypes.Where("space_id = ?", *wit.SpaceID)
+	}
+	var types []WorkItemType
+	if err := subtypes.Find(&types).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	// the work items of a type are the ones with its name in its space, the
+	// names of system types are used in all spaces
+	var conditions []string
+	var parameters []interface{}
+	var updated *WorkItemType
+	for i := range types {
+		t := &types[i]
+		if t.SpaceID == nil {
+			conditions = append(conditions, "type = ?")
+			parameters = append(parameters, t.Name)
+		} else {
+			conditions = append(conditions, "(type = ? AND space_id = ?)")
+			parameters = append(parameters, t.Name, *t.SpaceID)
+		}
+		for field, changed := range changes {
+			var existing *FieldDefinition
+			if def, ok := t.Fields[field]; ok {
+				existing = &def
+			}
+			if err := CheckFieldEvolution(field, existing, changed); err != nil {
+				return nil, errs.WithStack(err)
+			}
+			t.Fields[field] = changed
+		}
+		for _, field := range removedFields {
+			delete(t.Fields, field)
+		}
+		if t.ID == wit.ID {
+			if workflow != nil {
+				t.Workflow = convertWorkflowToModel(*workflow)
+			}
+			updated = t
+		}
+		// removed fields may still be required by the workflow
+		if err := t.Workflow.Validate(t.Fields); err != nil {
+			return nil, errs.WithStack(err)
+		}
+		t.Version = t.Version + 1
+	}
+	// only save once all types have been checked
+	for i := range types {
+		if err := r.db.Save(&types[i]).Error; err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+	}
+	for _, field := range removedFields {
+		// soft deleted work items are migrated as well in case they are restored
+		db := r.db.Unscoped().Model(&WorkItem{}).Where(strings.Join(conditions, " OR "), parameters...).UpdateColumn("fields", gorm.Expr("fields - ?::text", field))
+		if db.Error != nil {
+			return nil, errors.NewInternalError(db.Error.Error())
+		}
+		log.Printf("removed field %s from %d work items of %d types", field, db.RowsAffected, len(types))
+	}
+	// The cache must not serve the old definitions anymore. Callers should
+	// clear it again once the transaction has ended: other requests may cache
+	// the old definitions before the commit, and the new ones are wrong after
+	// a rollback.
+	cache.Clear()
+
+	result := convertTypeFromModels(updated)
+	return &result, nil
+}
+
+// List returns work item types selected by the given criteria.Expression, starting with start (zero-based) and returning at most "limit" item types.
+// If a space is given, only the system types and the types of that space are returned.
+func (r *GormWorkItemTypeRepository) List(ctx context.Context, spaceID *satoriuuid.UUID, start *int, limit *int) ([]*app.WorkItemType, error) {
 	// TODO: (kwk) implement criteria parsing just like for work items
 	var where string
 	var parameters []interface{}
+	if spaceID != nil {
+		where = "space_id IS NULL OR space_id = ?"
+		parameters = append(parameters, *spaceID)
+	}
 
 	var rows []WorkItemType
 	db := r.db.Where(where, parameters...)
@@ -162,6 +337,38 @@ func (r *GormWorkItemTypeRepository) List(ctx context.Context, start *int, limit
 	return result, nil
 }
 
+// loadFieldDefinitions returns the field definitions of all work item types, for use by the expression compiler.
+// Fields that have different definitions in different types are left out and thus compared as text.
+// The types are read from the database only if the cache does not hold all of them.
+func (r *GormWorkItemTypeRepository) loadFieldDefinitions() (FieldDefinitions, error) {
+	rows, ok := cache.GetAll()
+	if !ok {
+		log.Println("Not all work item types are in the cache. Loading from DB...")
+		if err := r.db.Find(&rows).Error; err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		cache.PutAll(rows)
+	}
+	return mergeFieldDefinitions(rows), nil
+}
+
+func mergeFieldDefinitions(types []WorkItemType) FieldDefinitions {
+	result := FieldDefinitions{}
+	conflicting := map[string]bool{}
+	for _, wit := range types {
+		for name, def := range wit.Fields {
+			existing, exists := result[name]
+			if conflicting[name] || (exists && !existing.Type.Equal(def.Type)) {
+				conflicting[name] = true
+				delete(result, name)
+				continue
+			}
+			result[name] = def
+		}
+	}
+	return result
+}
+
 func compatibleFields(existing FieldDefinition, new FieldDefinition) bool {
 	return reflect.DeepEqual(existing, new)
 }
@@ -169,9 +376,11 @@ func compatibleFields(existing FieldDefinition, new FieldDefinition) bool {
 // converts from models to app representation
 func convertTypeFromModels(t *WorkItemType) app.WorkItemType {
 	var converted = app.WorkItemType{
-		Name:    t.Name,
-		Version: t.Version,
-		Fields:  map[string]*app.FieldDefinition{},
+		Name:     t.Name,
+		Version:  t.Version,
+		Fields:   map[string]*app.FieldDefinition{},
+		Space:    t.SpaceID,
+		Workflow: convertWorkflowFromModel(t.Workflow),
 	}
 	for name, def := range t.Fields {
 		ct := convertFieldTypeFromModels(def.Type)
@@ -179,6 +388,52 @@ func convertTypeFromModels(t *WorkItemType) app.WorkItemType {
 			Required: def.Required,
 			Type:     &ct,
 		}
+		if def.Deprecated {
+			deprecated := true
+			converted.Fields[name].Deprecated = &deprecated
+		}
+		converted.Fields[name].Default = def.Default
+		if def.DefaultFrom != "" {
+			defaultFrom := def.DefaultFrom
+			converted.Fields[name].DefaultFrom = &defaultFrom
+		}
+		if def.Computed != "" {
+			computed := def.Computed
+			converted.Fields[name].Computed = &computed
+		}
+	}
+	return converted
+}
+
+// converts the workflow from models to app representation, nil if it is empty
+func convertWorkflowFromModel(w Workflow) *app.Workflow {
+	if w.IsEmpty() {
+		return nil
+	}
+	converted := &app.Workflow{
+		Transitions:    []*app.WorkflowTransition{},
+		RequiredFields: w.RequiredFields,
+	}
+	for _, t := range w.Transitions {
+		transition := &app.WorkflowTransition{From: t.From, To: t.To}
+		if t.Guard != "" {
+			guard := t.Guard
+			transition.Guard = &guard
+		}
+		converted.Transitions = append(converted.Transitions, transition)
+	}
+	return converted
+}
+
+// converts the workflow from app to models representation
+func convertWorkflowToModel(w app.Workflow) Workflow {
+	converted := Workflow{RequiredFields: w.RequiredFields}
+	for _, t := range w.Transitions {
+		transition := Transition{From: t.From, To: t.To}
+		if t.Guard != nil {
+			transition.Guard = *t.Guard
+		}
+		converted.Transitions = append(converted.Transitions, transition)
 	}
 	return converted
 }
@@ -212,7 +467,8 @@ func convertAnyToKind(any interface{}) (*Kind, error) {
 func convertStringToKind(k string) (*Kind, error) {
 	kind := Kind(k)
 	switch kind {
-	case KindString, KindInteger, KindFloat, KindInstant, KindDuration, KindURL, KindWorkitemReference, KindUser, KindEnum, KindList, KindIteration, KindMarkup:
+	case KindString, KindInteger, KindFloat, KindInstant, KindDuration, KindURL, KindWorkitemReference, KindUser, KindEnum, KindList, KindIteration, KindMarkup,
+		KindBoolean, KindDate, KindLabel, KindArea:
 		return &kind, nil
 	}
 	return nil, fmt.Errorf("Not a simple type")
@@ -260,15 +516,37 @@ func TEMPConvertFieldTypesToModel(fields map[string]app.FieldDefinition) (map[st
 
 	allFields := map[string]FieldDefinition{}
 	for field, definition := range fields {
-		ct, err := convertFieldTypeToModels(*definition.Type)
+		converted, err := convertFieldDefinitionToModel(field, definition)
 		if err != nil {
 			return nil, errs.WithStack(err)
 		}
-		converted := FieldDefinition{
-			Required: definition.Required,
-			Type:     ct,
-		}
 		allFields[field] = converted
 	}
 	return allFields, nil
 }
+
+// convertFieldDefinitionToModel converts a field definition from the app
+// representation and validates its default or computation
+// returns BadParameterError or the error of the type conversion
+func convertFieldDefinitionToModel(name string, definition app.FieldDefinition) (FieldDefinition, error) {
+	ct, err := convertFieldTypeToModels(*definition.Type)
+	if err != nil {
+		return FieldDefinition{}, errs.WithStack(err)
+	}
+	converted := FieldDefinition{
+		Required:   definition.Required,
+		Deprecated: definition.Deprecated != nil && *definition.Deprecated,
+		Type:       ct,
+		Default:    definition.Default,
+	}
+	if definition.DefaultFrom != nil {
+		converted.DefaultFrom = *definition.DefaultFrom
+	}
+	if definition.Computed != nil {
+		converted.Computed = *definition.Computed
+	}
+	if err := converted.ValidateValueSource(name); err != nil {
+		return FieldDefinition{}, errs.WithStack(err)
+	}
+	return converted, nil
+}
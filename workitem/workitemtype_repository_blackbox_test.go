@@ -8,8 +8,11 @@ import (
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/space"
 	"github.com/almighty/almighty-core/workitem"
+	"github.com/jinzhu/gorm"
 	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -31,7 +34,7 @@ func (s *workItemTypeRepoBlackBoxTest) SetupTest() {
 	gWitRepo := workitem.NewWorkItemTypeRepository(s.DB)
 	s.repo = workitem.NewUndoableWorkItemTypeRepository(gWitRepo, s.undoScript)
 
-	db2 := s.DB.Unscoped().Delete(workitem.WorkItemType{Name: "foo_bar"})
+	db2 := s.DB.Unscoped().Where("name IN (?)", []string{"foo_bar", "foo_baz"}).Delete(workitem.WorkItemType{})
 
 	if db2.Error != nil {
 		s.T().Fatalf("Could not setup test %s", db2.Error.Error())
@@ -46,7 +49,7 @@ func (s *workItemTypeRepoBlackBoxTest) TearDownTest() {
 
 func (s *workItemTypeRepoBlackBoxTest) TestCreateLoadWIT() {
 
-	wit, err := s.repo.Create(context.Background(), nil, "foo_bar", map[string]app.FieldDefinition{
+	wit, err := s.repo.Create(context.Background(), nil, nil, "foo_bar", map[string]app.FieldDefinition{
 		"foo": {
 			Required: true,
 			Type:     &app.FieldType{Kind: string(workitem.KindFloat)},
@@ -55,11 +58,11 @@ func (s *workItemTypeRepoBlackBoxTest) TestCreateLoadWIT() {
 	assert.Nil(s.T(), err)
 	assert.NotNil(s.T(), wit)
 
-	wit3, err := s.repo.Create(context.Background(), nil, "foo_bar", map[string]app.FieldDefinition{})
+	wit3, err := s.repo.Create(context.Background(), nil, nil, "foo_bar", map[string]app.FieldDefinition{})
 	assert.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
 	assert.Nil(s.T(), wit3)
 
-	wit2, err := s.repo.Load(context.Background(), "foo_bar")
+	wit2, err := s.repo.Load(context.Background(), nil, "foo_bar")
 	assert.Nil(s.T(), err)
 	require.NotNil(s.T(), wit2)
 	field := wit2.Fields["foo"]
@@ -73,7 +76,7 @@ func (s *workItemTypeRepoBlackBoxTest) TestCreateLoadWIT() {
 
 func (s *workItemTypeRepoBlackBoxTest) TestCreateLoadWITWithList() {
 	bt := "string"
-	wit, err := s.repo.Create(context.Background(), nil, "foo_bar", map[string]app.FieldDefinition{
+	wit, err := s.repo.Create(context.Background(), nil, nil, "foo_bar", map[string]app.FieldDefinition{
 		"foo": {
 			Required: true,
 			Type: &app.FieldType{
@@ -85,11 +88,11 @@ func (s *workItemTypeRepoBlackBoxTest) TestCreateLoadWITWithList() {
 	assert.Nil(s.T(), err)
 	assert.NotNil(s.T(), wit)
 
-	wit3, err := s.repo.Create(context.Background(), nil, "foo_bar", map[string]app.FieldDefinition{})
+	wit3, err := s.repo.Create(context.Background(), nil, nil, "foo_bar", map[string]app.FieldDefinition{})
 	assert.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
 	assert.Nil(s.T(), wit3)
 
-	wit2, err := s.repo.Load(context.Background(), "foo_bar")
+	wit2, err := s.repo.Load(context.Background(), nil, "foo_bar")
 	assert.Nil(s.T(), err)
 	require.NotNil(s.T(), wit2)
 	field := wit2.Fields["foo"]
@@ -103,7 +106,7 @@ func (s *workItemTypeRepoBlackBoxTest) TestCreateLoadWITWithList() {
 func (s *workItemTypeRepoBlackBoxTest) TestCreateWITWithBaseType() {
 	bt := "string"
 	basetype := "foo.bar"
-	baseWit, err := s.repo.Create(context.Background(), nil, basetype, map[string]app.FieldDefinition{
+	baseWit, err := s.repo.Create(context.Background(), nil, nil, basetype, map[string]app.FieldDefinition{
 		"foo": {
 			Required: true,
 			Type: &app.FieldType{
@@ -114,7 +117,7 @@ func (s *workItemTypeRepoBlackBoxTest) TestCreateWITWithBaseType() {
 	})
 	assert.Nil(s.T(), err)
 	assert.NotNil(s.T(), baseWit)
-	extendedWit, err := s.repo.Create(context.Background(), &basetype, "foo.baz", map[string]app.FieldDefinition{})
+	extendedWit, err := s.repo.Create(context.Background(), nil, &basetype, "foo.baz", map[string]app.FieldDefinition{})
 	assert.Nil(s.T(), err)
 	assert.NotNil(s.T(), extendedWit)
 	// the Field 'foo' must exist since it is inherited from the base work item type
@@ -123,8 +126,108 @@ func (s *workItemTypeRepoBlackBoxTest) TestCreateWITWithBaseType() {
 
 func (s *workItemTypeRepoBlackBoxTest) TestDoNotCreateWITWithMissingBaseType() {
 	basetype := "unknown"
-	extendedWit, err := s.repo.Create(context.Background(), &basetype, "foo.baz", map[string]app.FieldDefinition{})
+	extendedWit, err := s.repo.Create(context.Background(), nil, &basetype, "foo.baz", map[string]app.FieldDefinition{})
 	// expect an error as the given base type does not exist
 	assert.NotNil(s.T(), err)
 	assert.Nil(s.T(), extendedWit)
 }
+
+func (s *workItemTypeRepoBlackBoxTest) TestUpdateWIT() {
+	bt := "string"
+	stateType := &app.FieldType{Kind: string(workitem.KindEnum), BaseType: &bt, Values: []interface{}{"a", "b"}}
+	_, err := s.repo.Create(context.Background(), nil, nil, "foo_bar", map[string]app.FieldDefinition{
+		"foo":   {Required: true, Type: &app.FieldType{Kind: string(workitem.KindFloat)}},
+		"state": {Required: true, Type: stateType},
+		"old":   {Required: false, Type: &app.FieldType{Kind: string(workitem.KindString)}},
+	})
+	require.Nil(s.T(), err)
+	basetype := "foo_bar"
+	_, err = s.repo.Create(context.Background(), nil, &basetype, "foo_baz", map[string]app.FieldDefinition{})
+	require.Nil(s.T(), err)
+	wi, err := workitem.NewWorkItemRepository(s.DB).Create(context.Background(), nil, "foo_baz", map[string]interface{}{
+		"foo":   1.5,
+		"state": "a",
+		"old":   "value",
+	}, "xx")
+	require.Nil(s.T(), err)
+	defer s.DB.Unscoped().Delete(&workitem.WorkItem{ID: wi.ID})
+
+	deprecated := true
+	wit, err := s.repo.Update(context.Background(), nil, "foo_bar", 0, map[string]app.FieldDefinition{
+		"new":   {Required: false, Type: &app.FieldType{Kind: string(workitem.KindString)}},
+		"foo":   {Required: false, Deprecated: &deprecated, Type: &app.FieldType{Kind: string(workitem.KindFloat)}},
+		"state": {Required: true, Type: &app.FieldType{Kind: string(workitem.KindEnum), BaseType: &bt, Values: []interface{}{"a", "b", "c"}}},
+	}, []string{"old"}, nil)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, wit.Version)
+
+	// the subtype was changed as well, the cache does not return the old definition
+	subtype, err := s.repo.Load(context.Background(), nil, "foo_baz")
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), 1, subtype.Version)
+	assert.NotNil(s.T(), subtype.Fields["new"])
+	assert.Nil(s.T(), subtype.Fields["old"])
+	assert.False(s.T(), subtype.Fields["foo"].Required)
+	require.NotNil(s.T(), subtype.Fields["foo"].Deprecated)
+	assert.True(s.T(), *subtype.Fields["foo"].Deprecated)
+	assert.Len(s.T(), subtype.Fields["state"].Type.Values, 3)
+
+	// the removed field is gone from existing work items
+	migrated, err := workitem.NewWorkItemRepository(s.DB).Load(context.Background(), wi.ID)
+	require.Nil(s.T(), err)
+	assert.Nil(s.T(), migrated.Fields["old"])
+	assert.Equal(s.T(), "a", migrated.Fields["state"])
+
+	_, err = s.repo.Update(context.Background(), nil, "foo_bar", 0, map[string]app.FieldDefinition{}, nil, nil)
+	assert.IsType(s.T(), errors.VersionConflictError{}, errs.Cause(err))
+	_, err = s.repo.Update(context.Background(), nil, "foo_bar", 1, map[string]app.FieldDefinition{
+		"other": {Required: true, Type: &app.FieldType{Kind: string(workitem.KindString)}},
+	}, nil, nil)
+	assert.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	_, err = s.repo.Update(context.Background(), nil, "foo_bar", 1, map[string]app.FieldDefinition{
+		"state": {Required: true, Type: stateType},
+	}, nil, nil)
+	assert.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	_, err = s.repo.Update(context.Background(), nil, "foo_bar", 1, map[string]app.FieldDefinition{}, []string{"unknown"}, nil)
+	assert.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	_, err = s.repo.Update(context.Background(), nil, "unknown", 1, map[string]app.FieldDefinition{}, nil, nil)
+	assert.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
+
+func (s *workItemTypeRepoBlackBoxTest) TestCreateListSpaceScopedWIT() {
+	sp, err := space.NewRepository(s.DB).Create(context.Background(), &space.Space{Name: "work item type test space"})
+	require.Nil(s.T(), err)
+	_, err = s.repo.Create(context.Background(), &sp.ID, nil, "foo_bar", map[string]app.FieldDefinition{
+		"foo": {Required: true, Type: &app.FieldType{Kind: string(workitem.KindFloat)}},
+	})
+	require.Nil(s.T(), err)
+	basetype := "foo_bar"
+	extended, err := s.repo.Create(context.Background(), &sp.ID, &basetype, "foo_baz", map[string]app.FieldDefinition{})
+	require.Nil(s.T(), err)
+	// the types must be deleted before the space
+	s.undoScript.Append(func(db *gorm.DB) error {
+		return db.Unscoped().Delete(&space.Space{ID: sp.ID}).Error
+	})
+	require.NotNil(s.T(), extended.Space)
+	assert.Equal(s.T(), sp.ID, *extended.Space)
+
+	names := func(spaceID *uuid.UUID) map[string]bool {
+		wits, err := s.repo.List(context.Background(), spaceID, nil, nil)
+		require.Nil(s.T(), err)
+		result := map[string]bool{}
+		for _, wit := range wits {
+			result[wit.Name] = true
+		}
+		return result
+	}
+	assert.True(s.T(), names(&sp.ID)["foo_baz"])
+	assert.True(s.T(), names(nil)["foo_baz"])
+	otherSpace := uuid.NewV4()
+	assert.False(s.T(), names(&otherSpace)["foo_baz"])
+
+	// types of a space cannot be extended by system types or in other spaces
+	_, err = s.repo.Create(context.Background(), nil, &basetype, "foo.system", map[string]app.FieldDefinition{})
+	assert.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	_, err = s.repo.Create(context.Background(), &otherSpace, nil, "foo.other", map[string]app.FieldDefinition{})
+	assert.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+}
@@ -29,6 +29,20 @@ func TestCompatibleFields(t *testing.T) {
 	assert.True(t, compatibleFields(a, b))
 }
 
+func TestMergeFieldDefinitions(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	stringField := FieldDefinition{Type: SimpleType{Kind: KindString}}
+	integerField := FieldDefinition{Type: SimpleType{Kind: KindInteger}}
+	types := []WorkItemType{
+		{Name: "a", Fields: FieldDefinitions{"title": stringField, "points": integerField, "size": integerField}},
+		{Name: "b", Fields: FieldDefinitions{"title": stringField, "points": stringField}},
+		{Name: "c", Fields: FieldDefinitions{"points": integerField}},
+	}
+	merged := mergeFieldDefinitions(types)
+	assert.Equal(t, FieldDefinitions{"title": stringField, "size": integerField}, merged)
+}
+
 func TestConvertTypeFromModels(t *testing.T) {
 	t.Parallel()
 	resource.Require(t, resource.UnitTest)
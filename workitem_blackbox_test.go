@@ -18,7 +18,10 @@ import (
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/app/test"
 	"github.com/almighty/almighty-core/configuration"
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/gormsupport/cleaner"
 	"github.com/almighty/almighty-core/iteration"
 	"github.com/almighty/almighty-core/jsonapi"
@@ -77,7 +80,7 @@ func TestGetWorkItemWithLegacyDescription(t *testing.T) {
 	payload2.Data.ID = wi.Data.ID
 	payload2.Data.Attributes = wi.Data.Attributes
 
-	_, updated := test.UpdateWorkitemOK(t, nil, nil, controller, *wi.Data.ID, &payload2)
+	_, updated := test.UpdateWorkitemOK(t, svc.Context, svc, controller, *wi.Data.ID, &payload2)
 	assert.NotNil(t, updated.Data.Attributes[workitem.SystemCreatedAt])
 
 	assert.Equal(t, (result.Data.Attributes["version"].(int) + 1), updated.Data.Attributes["version"])
@@ -85,7 +88,7 @@ func TestGetWorkItemWithLegacyDescription(t *testing.T) {
 	assert.Equal(t, wi.Data.Attributes[workitem.SystemTitle], updated.Data.Attributes[workitem.SystemTitle])
 	assert.Equal(t, updatedDescription, updated.Data.Attributes[workitem.SystemDescription])
 
-	test.DeleteWorkitemOK(t, nil, nil, controller, *result.Data.ID)
+	test.DeleteWorkitemOK(t, svc.Context, svc, controller, *result.Data.ID)
 }
 
 func TestCreateWI(t *testing.T) {
@@ -140,7 +143,7 @@ func TestListByFields(t *testing.T) {
 	filter := "{\"system.title\":\"run integration test\"}"
 	offset := "0"
 	limit := 1
-	_, result := test.ListWorkitemOK(t, nil, nil, controller, &filter, nil, nil, &limit, &offset)
+	_, result := test.ListWorkitemOK(t, nil, nil, controller, &filter, nil, nil, nil, &limit, &offset, nil)
 
 	if result == nil {
 		t.Errorf("nil result")
@@ -151,7 +154,7 @@ func TestListByFields(t *testing.T) {
 	}
 
 	filter = fmt.Sprintf("{\"system.creator\":\"%s\"}", testsupport.TestIdentity.ID.String())
-	_, result = test.ListWorkitemOK(t, nil, nil, controller, &filter, nil, nil, &limit, &offset)
+	_, result = test.ListWorkitemOK(t, nil, nil, controller, &filter, nil, nil, nil, &limit, &offset, nil)
 
 	if result == nil {
 		t.Errorf("nil result")
@@ -161,7 +164,7 @@ func TestListByFields(t *testing.T) {
 		t.Errorf("unexpected length, should be %d but is %d ", 1, len(result.Data))
 	}
 
-	test.DeleteWorkitemOK(t, nil, nil, controller, *wi.Data.ID)
+	test.DeleteWorkitemOK(t, svc.Context, svc, controller, *wi.Data.ID)
 }
 
 func getWorkItemTestData(t *testing.T) []testSecureAPI {
@@ -305,9 +308,9 @@ func TestUnauthorizeWorkItemCUD(t *testing.T) {
 func createPagingTest(t *testing.T, controller *WorkitemController, repo *testsupport.WorkItemRepository, totalCount int) func(start int, limit int, first string, last string, prev string, next string) {
 	return func(start int, limit int, first string, last string, prev string, next string) {
 		count := computeCount(totalCount, int(start), int(limit))
-		repo.ListReturns(makeWorkItems(count), uint64(totalCount), nil)
+		repo.ListReturns(makeWorkItems(count), uint64(totalCount), &gormsupport.PageCursors{}, nil)
 		offset := strconv.Itoa(start)
-		_, response := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, &limit, &offset)
+		_, response := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, &limit, &offset, nil)
 		assertLink(t, "first", first, response.Links.First)
 		assertLink(t, "last", last, response.Links.Last)
 		assertLink(t, "prev", prev, response.Links.Prev)
@@ -382,32 +385,32 @@ func TestPagingErrors(t *testing.T) {
 	db := testsupport.NewMockDB()
 	controller := NewWorkitemController(svc, db)
 	repo := db.WorkItems().(*testsupport.WorkItemRepository)
-	repo.ListReturns(makeWorkItems(100), uint64(100), nil)
+	repo.ListReturns(makeWorkItems(100), uint64(100), &gormsupport.PageCursors{}, nil)
 
 	var offset string = "-1"
 	var limit int = 2
-	_, result := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, &limit, &offset)
+	_, result := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, &limit, &offset, nil)
 	if !strings.Contains(*result.Links.First, "page[offset]=0") {
 		assert.Fail(t, "Offset is negative", "Expected offset to be %d, but was %s", 0, *result.Links.First)
 	}
 
 	offset = "0"
 	limit = 0
-	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, &limit, &offset)
+	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, &limit, &offset, nil)
 	if !strings.Contains(*result.Links.First, "page[limit]=20") {
 		assert.Fail(t, "Limit is 0", "Expected limit to be default size %d, but was %s", 20, *result.Links.First)
 	}
 
 	offset = "0"
 	limit = -1
-	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, &limit, &offset)
+	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, &limit, &offset, nil)
 	if !strings.Contains(*result.Links.First, "page[limit]=20") {
 		assert.Fail(t, "Limit is negative", "Expected limit to be default size %d, but was %s", 20, *result.Links.First)
 	}
 
 	offset = "-3"
 	limit = -1
-	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, &limit, &offset)
+	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, &limit, &offset, nil)
 	if !strings.Contains(*result.Links.First, "page[limit]=20") {
 		assert.Fail(t, "Limit is negative", "Expected limit to be default size %d, but was %s", 20, *result.Links.First)
 	}
@@ -417,7 +420,7 @@ func TestPagingErrors(t *testing.T) {
 
 	offset = "ALPHA"
 	limit = 40
-	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, &limit, &offset)
+	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, &limit, &offset, nil)
 	if !strings.Contains(*result.Links.First, "page[limit]=40") {
 		assert.Fail(t, "Limit is within range", "Expected limit to be size %d, but was %s", 40, *result.Links.First)
 	}
@@ -436,9 +439,9 @@ func TestPagingLinksHasAbsoluteURL(t *testing.T) {
 	limit := 10
 
 	repo := db.WorkItems().(*testsupport.WorkItemRepository)
-	repo.ListReturns(makeWorkItems(10), uint64(100), nil)
+	repo.ListReturns(makeWorkItems(10), uint64(100), &gormsupport.PageCursors{}, nil)
 
-	_, result := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, &limit, &offset)
+	_, result := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, &limit, &offset, nil)
 	if !strings.HasPrefix(*result.Links.First, "http://") {
 		assert.Fail(t, "Not Absolute URL", "Expected link %s to contain absolute URL but was %s", "First", *result.Links.First)
 	}
@@ -462,25 +465,72 @@ func TestPagingDefaultAndMaxSize(t *testing.T) {
 	offset := "0"
 	var limit int
 	repo := db.WorkItems().(*testsupport.WorkItemRepository)
-	repo.ListReturns(makeWorkItems(10), uint64(100), nil)
+	repo.ListReturns(makeWorkItems(10), uint64(100), &gormsupport.PageCursors{}, nil)
 
-	_, result := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, &offset)
+	_, result := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, nil, &offset, nil)
 	if !strings.Contains(*result.Links.First, "page[limit]=20") {
 		assert.Fail(t, "Limit is nil", "Expected limit to be default size %d, got %v", 20, *result.Links.First)
 	}
 	limit = 1000
-	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, &limit, &offset)
+	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, &limit, &offset, nil)
 	if !strings.Contains(*result.Links.First, "page[limit]=100") {
 		assert.Fail(t, "Limit is more than max", "Expected limit to be %d, got %v", 100, *result.Links.First)
 	}
 
 	limit = 50
-	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, &limit, &offset)
+	_, result = test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, &limit, &offset, nil)
 	if !strings.Contains(*result.Links.First, "page[limit]=50") {
 		assert.Fail(t, "Limit is within range", "Expected limit to be %d, got %v", 50, *result.Links.First)
 	}
 }
 
+func TestListSort(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	svc := goa.New("TestListSort-Service")
+	db := testsupport.NewMockDB()
+	controller := NewWorkitemController(svc, db)
+	repo := db.WorkItems().(*testsupport.WorkItemRepository)
+	repo.ListReturns(makeWorkItems(10), uint64(100), &gormsupport.PageCursors{}, nil)
+
+	offset := "0"
+	limit := 10
+	sort := "-system.created_at,system.title"
+	_, result := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, nil, &limit, &offset, &sort)
+	_, _, orderBy, _, _, _ := repo.ListArgsForCall(repo.ListCallCount() - 1)
+	assert.Equal(t, []criteria.OrderBy{criteria.Descending(workitem.SystemCreatedAt), criteria.Ascending(workitem.SystemTitle)}, orderBy)
+	assert.Contains(t, *result.Links.First, "sort="+sort)
+	assert.Contains(t, *result.Links.Next, "sort="+sort)
+	assert.Contains(t, *result.Links.Last, "sort="+sort)
+
+	sort = "system.title,"
+	test.ListWorkitemBadRequest(t, context.Background(), nil, controller, nil, nil, nil, nil, &limit, &offset, &sort)
+}
+
+func TestListCursorPaging(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	svc := goa.New("TestListCursorPaging-Service")
+	db := testsupport.NewMockDB()
+	controller := NewWorkitemController(svc, db)
+	repo := db.WorkItems().(*testsupport.WorkItemRepository)
+	next := "next-cursor"
+	repo.ListReturns(makeWorkItems(10), uint64(100), &gormsupport.PageCursors{Next: &next}, nil)
+
+	limit := 10
+	cursor := ""
+	_, result := test.ListWorkitemOK(t, context.Background(), nil, controller, nil, nil, nil, &cursor, &limit, nil, nil)
+	_, _, _, _, _, passed := repo.ListArgsForCall(repo.ListCallCount() - 1)
+	assert.Equal(t, &gormsupport.Cursor{}, passed)
+	assert.Nil(t, result.Links.Prev)
+	require.NotNil(t, result.Links.Next)
+	assert.Contains(t, *result.Links.Next, "page[cursor]=next-cursor&page[limit]=10")
+	assert.Contains(t, *result.Links.First, "page[cursor]=&page[limit]=10")
+	assert.Contains(t, *result.Links.Last, "page[cursor]="+gormsupport.Cursor{Before: true}.Encode())
+	assert.Equal(t, 100, result.Meta.TotalCount)
+
+	cursor = "not a cursor"
+	test.ListWorkitemBadRequest(t, context.Background(), nil, controller, nil, nil, nil, &cursor, &limit, nil, nil)
+}
+
 // ========== helper functions for tests inside WorkItem2Suite ==========
 func getMinimumRequiredUpdatePayload(wi *app.WorkItem2) *app.UpdateWorkitemPayload {
 	return &app.UpdateWorkitemPayload{
@@ -635,6 +685,135 @@ func (s *WorkItem2Suite) TestWI2UpdateVersionConflict() {
 	test.UpdateWorkitemBadRequest(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, *s.wi.ID, s.minimumPayload)
 }
 
+func (s *WorkItem2Suite) TestWI2UpdateKeepsOtherAttributes() {
+	s.minimumPayload.Data.Attributes[workitem.SystemState] = workitem.SystemStateOpen
+	_, updatedWI := test.UpdateWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, *s.wi.ID, s.minimumPayload)
+	assert.Equal(s.T(), workitem.SystemStateOpen, updatedWI.Data.Attributes[workitem.SystemState])
+	assert.Equal(s.T(), "Test WI", updatedWI.Data.Attributes[workitem.SystemTitle])
+}
+
+func (s *WorkItem2Suite) TestWI2PatchAssignees() {
+	newUser := createOneRandomUserIdentity(s.svc.Context, s.db)
+	newUser2 := createOneRandomUserIdentity(s.svc.Context, s.db)
+	version := s.wi.Attributes["version"]
+
+	_, patched := test.PatchWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, *s.wi.ID, app.PatchWorkitemPayload{
+		{Op: workitem.PatchOpTest, Path: "/version", Value: version},
+		{Op: workitem.PatchOpAdd, Path: "/system.assignees/-", Value: newUser.ID.String()},
+		{Op: workitem.PatchOpAdd, Path: "/system.assignees/-", Value: newUser2.ID.String()},
+	})
+	require.Len(s.T(), patched.Data.Relationships.Assignees.Data, 2)
+	assert.Equal(s.T(), "Test WI", patched.Data.Attributes[workitem.SystemTitle])
+
+	_, patched = test.PatchWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, *s.wi.ID, app.PatchWorkitemPayload{
+		{Op: workitem.PatchOpRemove, Path: "/system.assignees/0"},
+	})
+	require.Len(s.T(), patched.Data.Relationships.Assignees.Data, 1)
+	assert.Equal(s.T(), newUser2.ID.String(), *patched.Data.Relationships.Assignees.Data[0].ID)
+
+	// the version tested against is outdated
+	test.PatchWorkitemBadRequest(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, *s.wi.ID, app.PatchWorkitemPayload{
+		{Op: workitem.PatchOpTest, Path: "/version", Value: version},
+		{Op: workitem.PatchOpRemove, Path: "/system.assignees/0"},
+	})
+	test.PatchWorkitemBadRequest(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, *s.wi.ID, app.PatchWorkitemPayload{
+		{Op: workitem.PatchOpAdd, Path: "/system.assignees/-", Value: uuid.NewV4().String()},
+	})
+	test.PatchWorkitemBadRequest(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, *s.wi.ID, app.PatchWorkitemPayload{
+		{Op: workitem.PatchOpReplace, Path: "/version", Value: 1},
+	})
+	test.PatchWorkitemNotFound(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, "2398475203", app.PatchWorkitemPayload{
+		{Op: workitem.PatchOpReplace, Path: "/system.title", Value: "title"},
+	})
+}
+
+func (s *WorkItem2Suite) TestWI2Bulk() {
+	c := minimumRequiredCreateWithType(workitem.SystemBug)
+	c.Data.Attributes[workitem.SystemTitle] = "Bulk " + uuid.NewV4().String()
+	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
+	_, wi1 := test.CreateWorkitemCreated(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &c)
+	_, wi2 := test.CreateWorkitemCreated(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &c)
+	version, err := strconv.Atoi(fmt.Sprintf("%v", wi1.Data.Attributes["version"]))
+	require.Nil(s.T(), err)
+
+	_, result := test.BulkWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &app.BulkWorkitemPayload{
+		Workitems: []*app.WorkItemVersion{
+			{ID: *wi1.Data.ID, Version: version},
+			{ID: *wi2.Data.ID, Version: version + 1},
+			{ID: "2398475203", Version: version},
+		},
+		Attributes: map[string]interface{}{workitem.SystemState: workitem.SystemStateClosed},
+	})
+	assert.Equal(s.T(), 1, result.Meta.Succeeded)
+	assert.Equal(s.T(), 2, result.Meta.Failed)
+	require.Len(s.T(), result.Meta.Results, 3)
+	assert.Equal(s.T(), "updated", result.Meta.Results[0].Status)
+	assert.Equal(s.T(), version+1, *result.Meta.Results[0].Version)
+	assert.Equal(s.T(), "conflict", result.Meta.Results[1].Status)
+	assert.Equal(s.T(), "not_found", result.Meta.Results[2].Status)
+	_, shown := test.ShowWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, *wi1.Data.ID)
+	assert.Equal(s.T(), workitem.SystemStateClosed, shown.Data.Attributes[workitem.SystemState])
+	assert.Equal(s.T(), c.Data.Attributes[workitem.SystemTitle], shown.Data.Attributes[workitem.SystemTitle])
+
+	filter := fmt.Sprintf("%s == '%s'", workitem.SystemTitle, c.Data.Attributes[workitem.SystemTitle])
+	del := true
+	_, result = test.BulkWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &app.BulkWorkitemPayload{
+		Filter: &filter,
+		Delete: &del,
+	})
+	assert.Equal(s.T(), 2, result.Meta.Succeeded)
+	assert.Equal(s.T(), "deleted", result.Meta.Results[0].Status)
+	test.ShowWorkitemNotFound(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, *wi2.Data.ID)
+
+	// either work items or a filter and either attributes or delete are required
+	test.BulkWorkitemBadRequest(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &app.BulkWorkitemPayload{Delete: &del})
+	test.BulkWorkitemBadRequest(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &app.BulkWorkitemPayload{Filter: &filter})
+}
+
+func (s *WorkItem2Suite) TestWI2Reorder() {
+	c := minimumRequiredCreateWithType(workitem.SystemBug)
+	c.Data.Attributes[workitem.SystemTitle] = "Reorder " + uuid.NewV4().String()
+	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
+	_, wi1 := test.CreateWorkitemCreated(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &c)
+	_, wi2 := test.CreateWorkitemCreated(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &c)
+	assert.True(s.T(), wi1.Data.Attributes["rank"].(string) < wi2.Data.Attributes["rank"].(string))
+
+	_, moved := test.ReorderWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, *wi2.Data.ID, &app.WorkItemReorder{Above: wi1.Data.ID})
+	assert.True(s.T(), moved.Data.Attributes["rank"].(string) < wi1.Data.Attributes["rank"].(string))
+
+	filter := fmt.Sprintf("%s == '%s'", workitem.SystemTitle, c.Data.Attributes[workitem.SystemTitle])
+	sort := "rank"
+	_, list := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &filter, nil, nil, nil, nil, nil, &sort)
+	require.Len(s.T(), list.Data, 2)
+	assert.Equal(s.T(), *wi2.Data.ID, *list.Data[0].ID)
+	assert.Equal(s.T(), *wi1.Data.ID, *list.Data[1].ID)
+
+	// exactly one of above and below is required
+	test.ReorderWorkitemBadRequest(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, *wi2.Data.ID, &app.WorkItemReorder{})
+	test.ReorderWorkitemBadRequest(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, *wi2.Data.ID, &app.WorkItemReorder{Above: wi1.Data.ID, Below: wi1.Data.ID})
+	test.ReorderWorkitemNotFound(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, "2398475203", &app.WorkItemReorder{Above: wi1.Data.ID})
+}
+
+func (s *WorkItem2Suite) TestWI2ListRevisions() {
+	s.minimumPayload.Data.Attributes[workitem.SystemState] = workitem.SystemStateClosed
+	test.UpdateWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, *s.wi.ID, s.minimumPayload)
+
+	revCtrl := NewWorkItemRevisionsController(s.svc, gormapplication.NewGormDB(s.db))
+	_, revisions := test.ListWorkItemRevisionsOK(s.T(), s.svc.Context, s.svc, revCtrl, *s.wi.ID)
+	require.Len(s.T(), revisions.Data, 2)
+	assert.Equal(s.T(), "create", *revisions.Data[0].Attributes.RevisionType)
+	assert.Nil(s.T(), revisions.Data[0].Attributes.OldFields)
+	update := revisions.Data[1]
+	assert.Equal(s.T(), "update", *update.Attributes.RevisionType)
+	assert.Equal(s.T(), workitem.SystemStateNew, update.Attributes.OldFields[workitem.SystemState])
+	assert.Equal(s.T(), workitem.SystemStateClosed, update.Attributes.Fields[workitem.SystemState])
+	require.NotNil(s.T(), update.Relationships.Modifier)
+	assert.Equal(s.T(), testsupport.TestIdentity.ID.String(), *update.Relationships.Modifier.Data.ID)
+	assert.Equal(s.T(), *s.wi.ID, *update.Relationships.Workitem.Data.ID)
+
+	test.ListWorkItemRevisionsNotFound(s.T(), s.svc.Context, s.svc, revCtrl, "2398475203")
+}
+
 func (s *WorkItem2Suite) TestWI2UpdateWithNonExistentID() {
 	id := "2398475203"
 	s.minimumPayload.Data.ID = &id
@@ -949,7 +1128,6 @@ func (s *WorkItem2Suite) TestWI2FailCreateMissingBaseType() {
 
 func (s *WorkItem2Suite) TestWI2FailCreateWithAssigneeAsField() {
 	// given
-	s.T().Skip("Not working.. require WIT understanding on server side")
 	c := minimumRequiredCreatePayload()
 	c.Data.Attributes[workitem.SystemTitle] = "Title"
 	c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
@@ -962,14 +1140,8 @@ func (s *WorkItem2Suite) TestWI2FailCreateWithAssigneeAsField() {
 			},
 		},
 	}
-	// when
-	_, wi := test.CreateWorkitemCreated(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &c)
-	// then
-	assert.NotNil(s.T(), wi.Data)
-	assert.NotNil(s.T(), wi.Data.ID)
-	assert.NotNil(s.T(), wi.Data.Type)
-	assert.NotNil(s.T(), wi.Data.Attributes)
-	assert.Nil(s.T(), wi.Data.Relationships.Assignees.Data)
+	// when/then
+	test.CreateWorkitemBadRequest(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &c)
 }
 
 func (s *WorkItem2Suite) TestWI2FailCreateWithMissingTitle() {
@@ -1117,7 +1289,7 @@ func (s *WorkItem2Suite) TestWI2ListByAssigneeFilter() {
 	assert.Len(s.T(), wi.Data.Relationships.Assignees.Data, 1)
 	assert.Equal(s.T(), newUser.ID.String(), *wi.Data.Relationships.Assignees.Data[0].ID)
 	newUserID := newUser.ID.String()
-	_, list := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, &newUserID, nil, nil, nil)
+	_, list := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, &newUserID, nil, nil, nil, nil)
 	assert.Len(s.T(), list.Data, 1)
 	assert.Equal(s.T(), newUser.ID.String(), *list.Data[0].Relationships.Assignees.Data[0].ID)
 	assert.True(s.T(), strings.Contains(*list.Links.First, "filter[assignee]"))
@@ -1151,7 +1323,7 @@ func (s *WorkItem2Suite) TestWI2ListByIterationFilter() {
 	require.NotNil(s.T(), wi.Data.Relationships.Iteration)
 	assert.Equal(s.T(), iterationID, *wi.Data.Relationships.Iteration.Data.ID)
 
-	_, list := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, nil, &iterationID, nil, nil)
+	_, list := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, nil, &iterationID, nil, nil, nil)
 	require.Len(s.T(), list.Data, 1)
 	assert.Equal(s.T(), iterationID, *list.Data[0].Relationships.Iteration.Data.ID)
 	assert.True(s.T(), strings.Contains(*list.Links.First, "filter[iteration]"))
@@ -1432,6 +1604,53 @@ func (s *WorkItem2Suite) TestWI2CreateUnknownIteration() {
 	test.CreateWorkitemBadRequest(t, s.svc.Context, s.svc, s.wi2Ctrl, &c)
 }
 
+func (s *WorkItem2Suite) TestWI2CreateWithReferenceFields() {
+	// given a type with custom fields referencing users, iterations and work items
+	stUser := string(workitem.KindUser)
+	witName := "references_" + strings.Replace(uuid.NewV4().String(), "-", "", -1)
+	_, err := workitem.NewWorkItemTypeRepository(s.db).Create(s.svc.Context, nil, nil, witName, map[string]app.FieldDefinition{
+		workitem.SystemTitle:   {Type: &app.FieldType{Kind: string(workitem.KindString)}, Required: true},
+		workitem.SystemCreator: {Type: &app.FieldType{Kind: string(workitem.KindUser)}, Required: true},
+		"reviewers":            {Type: &app.FieldType{Kind: string(workitem.KindList), ComponentType: &stUser}, Required: false},
+		"release":              {Type: &app.FieldType{Kind: string(workitem.KindIteration)}, Required: false},
+		"duplicate_of":         {Type: &app.FieldType{Kind: string(workitem.KindWorkitemReference)}, Required: false},
+	})
+	require.Nil(s.T(), err)
+	reviewer := createOneRandomUserIdentity(s.svc.Context, s.db)
+	release := createOneRandomIteration(s.svc.Context, s.db)
+	c := minimumRequiredCreateWithType(witName)
+	c.Data.Attributes[workitem.SystemTitle] = "Title"
+	c.Data.Attributes["reviewers"] = []string{reviewer.ID.String()}
+	c.Data.Attributes["release"] = release.ID.String()
+	c.Data.Attributes["duplicate_of"] = *s.wi.ID
+	// when
+	_, wi := test.CreateWorkitemCreated(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &c)
+	// then
+	require.NotNil(s.T(), wi.Data.Relationships.References)
+	require.Len(s.T(), wi.Data.Relationships.References["reviewers"].Data, 1)
+	assert.Equal(s.T(), reviewer.ID.String(), *wi.Data.Relationships.References["reviewers"].Data[0].ID)
+	assert.Equal(s.T(), release.ID.String(), *wi.Data.Relationships.References["release"].Data[0].ID)
+	assert.Equal(s.T(), *s.wi.ID, *wi.Data.Relationships.References["duplicate_of"].Data[0].ID)
+	// the creator is no identity in the database, all other references are included
+	assert.Len(s.T(), wi.Included, 3)
+
+	for name, value := range map[string]interface{}{
+		"reviewers":    []string{uuid.NewV4().String()},
+		"release":      uuid.NewV4().String(),
+		"duplicate_of": "2398475203",
+	} {
+		c := minimumRequiredCreateWithType(witName)
+		c.Data.Attributes[workitem.SystemTitle] = "Title"
+		c.Data.Attributes[name] = value
+		test.CreateWorkitemBadRequest(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, &c)
+	}
+	test.PatchWorkitemBadRequest(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, *wi.Data.ID, app.PatchWorkitemPayload{
+		{Op: workitem.PatchOpAdd, Path: "/reviewers/-", Value: uuid.NewV4().String()},
+	})
+	_, shown := test.ShowWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, *wi.Data.ID)
+	assert.Len(s.T(), shown.Included, 3)
+}
+
 func (s *WorkItem2Suite) TestWI2SuccessCreateAndPreventJavascriptInjectionWithLegacyDescription() {
 	c := minimumRequiredCreatePayload()
 	title := "<img src=x onerror=alert('title') />"
@@ -1515,3 +1734,52 @@ func ident(id uuid.UUID) *app.GenericData {
 		ID:   &i,
 	}
 }
+
+func TestAggregate(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	svc := goa.New("TestAggregate-Service")
+	db := testsupport.NewMockDB()
+	controller := NewWorkitemController(svc, db)
+	repo := db.WorkItems().(*testsupport.WorkItemRepository)
+	repo.AggregateReturns([]workitem.Bucket{{Value: workitem.SystemStateNew, Count: 2}, {Value: nil, Count: 1}}, uint64(3), nil)
+
+	assignee := "A"
+	_, result := test.AggregateWorkitemOK(t, context.Background(), nil, controller, nil, &assignee, nil, workitem.SystemState)
+	_, exp, groupBy := repo.AggregateArgsForCall(repo.AggregateCallCount() - 1)
+	assert.Equal(t, workitem.SystemState, groupBy)
+	assert.NotNil(t, exp)
+	assert.Equal(t, workitem.SystemState, result.Meta.GroupBy)
+	assert.Equal(t, 3, result.Meta.TotalCount)
+	require.Len(t, result.Meta.Buckets, 2)
+	assert.Equal(t, workitem.SystemStateNew, result.Meta.Buckets[0].Value)
+	assert.Equal(t, 2, result.Meta.Buckets[0].Count)
+	assert.Nil(t, result.Meta.Buckets[1].Value)
+
+	repo.AggregateReturns(nil, 0, errors.NewBadParameterError("groupBy", "system.description"))
+	test.AggregateWorkitemBadRequest(t, context.Background(), nil, controller, nil, nil, nil, workitem.SystemDescription)
+
+	filter := "Type =="
+	test.AggregateWorkitemBadRequest(t, context.Background(), nil, controller, &filter, nil, nil, workitem.SystemState)
+}
+
+func TestListNextStates(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	svc := goa.New("TestListNextStates-Service")
+	db := testsupport.NewMockDB()
+	controller := NewWorkitemController(svc, db)
+	repo := db.WorkItems().(*testsupport.WorkItemRepository)
+	repo.NextStatesReturns([]workitem.NextState{{State: "resolved", RequiredFields: []string{"resolution"}}, {State: "closed"}}, nil)
+
+	_, result := test.ListNextStatesWorkitemOK(t, context.Background(), nil, controller, "42")
+	_, id := repo.NextStatesArgsForCall(repo.NextStatesCallCount() - 1)
+	assert.Equal(t, "42", id)
+	require.Len(t, result.Data, 2)
+	assert.Equal(t, APIStringTypeWorkItemState, result.Data[0].Type)
+	assert.Equal(t, "resolved", result.Data[0].ID)
+	assert.Equal(t, []string{"resolution"}, result.Data[0].Attributes.RequiredFields)
+	assert.Equal(t, "closed", result.Data[1].Attributes.Name)
+	assert.Empty(t, result.Data[1].Attributes.RequiredFields)
+
+	repo.NextStatesReturns(nil, errors.NewNotFoundError("work item", "43"))
+	test.ListNextStatesWorkitemNotFound(t, context.Background(), nil, controller, "43")
+}
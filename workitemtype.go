@@ -6,6 +6,7 @@ import (
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
 	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/workitem"
 	"github.com/goadesign/goa"
 )
 
@@ -31,7 +32,7 @@ func NewWorkitemtypeController(service *goa.Service, db application.DB) *Workite
 // Show runs the show action.
 func (c *WorkitemtypeController) Show(ctx *app.ShowWorkitemtypeContext) error {
 	return application.Transactional(c.db, func(appl application.Application) error {
-		res, err := appl.WorkItemTypes().Load(ctx.Context, ctx.Name)
+		res, err := appl.WorkItemTypes().Load(ctx.Context, ctx.Space, ctx.Name)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, err)
 		}
@@ -47,7 +48,7 @@ func (c *WorkitemtypeController) Create(ctx *app.CreateWorkitemtypeContext) erro
 		for key, fd := range ctx.Payload.Fields {
 			fields[key] = *fd
 		}
-		wit, err := appl.WorkItemTypes().Create(ctx.Context, ctx.Payload.ExtendedTypeName, ctx.Payload.Name, fields)
+		wit, err := appl.WorkItemTypes().Create(ctx.Context, ctx.Payload.Space, ctx.Payload.ExtendedTypeName, ctx.Payload.Name, fields)
 
 		if err != nil {
 			jerrors, httpStatusCode := jsonapi.ErrorToJSONAPIErrors(err)
@@ -58,6 +59,25 @@ func (c *WorkitemtypeController) Create(ctx *app.CreateWorkitemtypeContext) erro
 	})
 }
 
+// Update runs the update action.
+func (c *WorkitemtypeController) Update(ctx *app.UpdateWorkitemtypeContext) error {
+	var fields = map[string]app.FieldDefinition{}
+	for key, fd := range ctx.Payload.Fields {
+		fields[key] = *fd
+	}
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		wit, err := appl.WorkItemTypes().Update(ctx.Context, ctx.Space, ctx.Name, ctx.Payload.Version, fields, ctx.Payload.RemovedFields, ctx.Payload.Workflow)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(wit)
+	})
+	// Definitions cached while the transaction was running are either
+	// outdated (after a commit) or were never valid (after a rollback)
+	workitem.ClearGlobalWorkItemTypeCache()
+	return err
+}
+
 // List runs the list action
 func (c *WorkitemtypeController) List(ctx *app.ListWorkitemtypeContext) error {
 	start, limit, err := parseLimit(ctx.Page)
@@ -66,7 +86,7 @@ func (c *WorkitemtypeController) List(ctx *app.ListWorkitemtypeContext) error {
 		return ctx.BadRequest(jerrors)
 	}
 	return application.Transactional(c.db, func(appl application.Application) error {
-		result, err := appl.WorkItemTypes().List(ctx.Context, start, &limit)
+		result, err := appl.WorkItemTypes().List(ctx.Context, ctx.Space, start, &limit)
 		if err != nil {
 			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("Error listing work item types: %s", err.Error())))
 			return ctx.BadRequest(jerrors)
@@ -79,7 +99,7 @@ func (c *WorkitemtypeController) List(ctx *app.ListWorkitemtypeContext) error {
 func (c *WorkitemtypeController) ListSourceLinkTypes(ctx *app.ListSourceLinkTypesWorkitemtypeContext) error {
 	return application.Transactional(c.db, func(appl application.Application) error {
 		// Test that work item type exists
-		_, err := appl.WorkItemTypes().Load(ctx.Context, ctx.Name)
+		_, err := appl.WorkItemTypes().Load(ctx.Context, ctx.Space, ctx.Name)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, err)
 		}
@@ -103,7 +123,7 @@ func (c *WorkitemtypeController) ListSourceLinkTypes(ctx *app.ListSourceLinkType
 func (c *WorkitemtypeController) ListTargetLinkTypes(ctx *app.ListTargetLinkTypesWorkitemtypeContext) error {
 	return application.Transactional(c.db, func(appl application.Application) error {
 		// Test that work item type exists
-		_, err := appl.WorkItemTypes().Load(ctx.Context, ctx.Name)
+		_, err := appl.WorkItemTypes().Load(ctx.Context, ctx.Space, ctx.Name)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, err)
 		}
@@ -179,6 +179,46 @@ func (s *workItemTypeSuite) TestShowWorkItemType() {
 	require.EqualValues(s.T(), wit, wit2)
 }
 
+// TestUpdateWorkItemType tests if we can add a field to the work item type
+// "animal" and widen the enum of animal types.
+func (s *workItemTypeSuite) TestUpdateWorkItemType() {
+	defer cleaner.DeleteCreatedEntities(s.DB)()
+
+	_, wit := s.createWorkItemTypeAnimal()
+	require.NotNil(s.T(), wit)
+
+	animalType := wit.Fields["animal_type"]
+	animalType.Type.Values = append(animalType.Type.Values, "dodo")
+	payload := app.UpdateWorkItemTypePayload{
+		Version: wit.Version,
+		Fields: map[string]*app.FieldDefinition{
+			"animal_type": animalType,
+			"weight":      {Required: false, Type: &app.FieldType{Kind: "float"}},
+		},
+		RemovedFields: []string{"color"},
+	}
+	_, updated := test.UpdateWorkitemtypeOK(s.T(), nil, nil, s.typeCtrl, wit.Name, &payload)
+	require.NotNil(s.T(), updated)
+	assert.Equal(s.T(), wit.Version+1, updated.Version)
+	assert.Len(s.T(), updated.Fields["animal_type"].Type.Values, 4)
+	assert.NotNil(s.T(), updated.Fields["weight"])
+	assert.Nil(s.T(), updated.Fields["color"])
+
+	_, shown := test.ShowWorkitemtypeOK(s.T(), nil, nil, s.typeCtrl, wit.Name)
+	require.EqualValues(s.T(), updated, shown)
+
+	// outdated version
+	test.UpdateWorkitemtypeBadRequest(s.T(), nil, nil, s.typeCtrl, wit.Name, &payload)
+	// new fields must be optional
+	payload.Version = updated.Version
+	payload.RemovedFields = nil
+	payload.Fields = map[string]*app.FieldDefinition{
+		"legs": {Required: true, Type: &app.FieldType{Kind: "integer"}},
+	}
+	test.UpdateWorkitemtypeBadRequest(s.T(), nil, nil, s.typeCtrl, wit.Name, &payload)
+	test.UpdateWorkitemtypeNotFound(s.T(), nil, nil, s.typeCtrl, "someRandomTestWIT8712", &payload)
+}
+
 // TestListWorkItemType tests if we can find the work item types
 // "person" and "animal" in the list of work item types
 func (s *workItemTypeSuite) TestListWorkItemType() {
@@ -193,7 +233,7 @@ func (s *workItemTypeSuite) TestListWorkItemType() {
 	// Fetch a single work item type
 	// Paging in the format <start>,<limit>"
 	page := "0,-1"
-	_, witCollection := test.ListWorkitemtypeOK(s.T(), nil, nil, s.typeCtrl, &page)
+	_, witCollection := test.ListWorkitemtypeOK(s.T(), nil, nil, s.typeCtrl, &page, nil)
 
 	require.NotNil(s.T(), witCollection)
 	require.Nil(s.T(), witCollection.Validate())
@@ -331,6 +371,15 @@ func getWorkItemTypeTestData(t *testing.T) []testSecureAPI {
 			payload:            createWITPayloadString,
 			jwtToken:           "",
 		},
+		// Update Work Item Type API without a valid token
+		{
+			method:             http.MethodPatch,
+			url:                endpointWorkItemTypes + "/someRandomTestWIT8712",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedErrorCode:  jsonapi.ErrorCodeJWTSecurityError,
+			payload:            bytes.NewBuffer([]byte(`{"version": 0}`)),
+			jwtToken:           "",
+		},
 		// Try fetching a random work Item Type
 		// We do not have security on GET hence this should return 404 not found
 		{